@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/Azure/azure-pipeline-go v0.2.3 // indirect
 	github.com/Azure/azure-sdk-for-go v61.2.0+incompatible
+	github.com/Azure/azure-sdk-for-go/sdk/azcore v0.21.1
 	github.com/Azure/azure-sdk-for-go/sdk/storage/azblob v0.3.0
 	github.com/Azure/azure-storage-queue-go v0.0.0-20191125232315-636801874cdd
 	github.com/Azure/go-autorest/autorest v0.11.24
@@ -15,4 +16,5 @@ require (
 	github.com/google/uuid v1.2.0
 	github.com/kylelemons/godebug v1.1.0
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.3.7
 )
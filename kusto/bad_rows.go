@@ -0,0 +1,23 @@
+package kusto
+
+// bad_rows.go holds the options that make rows with values that cannot be decoded row errors.
+
+// BadRowsAsErrors makes a row of the query with a value that cannot be decoded into the type of its column, which can
+// happen on rare service edge cases, be returned as an inline error, like DoOnRowOrError() and NextRowOrError()
+// return, while the rows after it are still returned. The row is not returned. Without this option such a value
+// fails the whole query with an error that ends the RowIterator. With WithRetryOnPartialFailure(), a query with such
+// a row is run again, as for other inline errors.
+func BadRowsAsErrors() QueryOption {
+	return func(q *queryOptions) error {
+		q.requestProperties.badRowsAsErrors = true
+		return nil
+	}
+}
+
+// MgmtBadRowsAsErrors is BadRowsAsErrors() for management commands.
+func MgmtBadRowsAsErrors() MgmtOption {
+	return func(m *mgmtOptions) error {
+		m.requestProperties.badRowsAsErrors = true
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package kusto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadRowsAsErrors(t *testing.T) {
+	t.Parallel()
+
+	const queryResponse = `[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Count","ColumnType":"long"}],"Rows":[[1],["not a long"],[3]]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`
+	const mgmtResponse = `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Count","ColumnType":"long"}],"Rows":[[1],["not a long"],[3]]}]}`
+
+	tests := []struct {
+		desc       string
+		mgmt       bool
+		badAsError bool
+		wantRows   []int64
+		wantErrors int
+		err        bool
+	}{
+		{desc: "Query fails by default", err: true},
+		{desc: "Query returns the bad row as an error", badAsError: true, wantRows: []int64{1, 3}, wantErrors: 1},
+		{desc: "Mgmt fails by default", mgmt: true, err: true},
+		{desc: "Mgmt returns the bad row as an error", mgmt: true, badAsError: true, wantRows: []int64{1, 3}, wantErrors: 1},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{
+				onQuery: func(string) (string, error) { return queryResponse, nil },
+				onMgmt:  func(string) (string, error) { return mgmtResponse, nil },
+			}}
+
+			var (
+				iter *RowIterator
+				err  error
+			)
+			switch {
+			case test.mgmt && test.badAsError:
+				iter, err = client.Mgmt(context.Background(), "db", NewStmt(".show counts"), MgmtBadRowsAsErrors())
+			case test.mgmt:
+				iter, err = client.Mgmt(context.Background(), "db", NewStmt(".show counts"))
+			case test.badAsError:
+				iter, err = client.Query(context.Background(), "db", NewStmt("T"), BadRowsAsErrors())
+			default:
+				iter, err = client.Query(context.Background(), "db", NewStmt("T"))
+			}
+			var gotRows []int64
+			gotErrors := 0
+			if err == nil {
+				defer iter.Stop()
+				err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+					if e != nil {
+						gotErrors++
+						return nil
+					}
+					gotRows = append(gotRows, r.Values[0].(value.Long).Value)
+					return nil
+				})
+			}
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantRows, gotRows)
+			assert.Equal(t, test.wantErrors, gotErrors)
+		})
+	}
+}
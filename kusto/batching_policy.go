@@ -0,0 +1,172 @@
+package kusto
+
+// batching_policy.go holds helpers to read and alter the ingestion batching policy of a database or table.
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// BatchingPolicy is the ingestion batching policy of a database or table. It controls when the service seals a batch
+// of queued ingestions and ingests it. A batch is sealed when the first of the limits is reached.
+// A zero field means the limit is not set and the service default applies.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/batchingpolicy
+type BatchingPolicy struct {
+	// MaximumBatchingTimeSpan is the maximum time the service waits before sealing a batch.
+	MaximumBatchingTimeSpan time.Duration
+	// MaximumNumberOfItems is the maximum number of items, such as blobs, in a batch.
+	MaximumNumberOfItems int
+	// MaximumRawDataSizeMB is the maximum uncompressed size of a batch in megabytes.
+	MaximumRawDataSizeMB int
+}
+
+// batchingPolicyJSON is the JSON representation of BatchingPolicy used by the service.
+type batchingPolicyJSON struct {
+	MaximumBatchingTimeSpan string `json:",omitempty"`
+	MaximumNumberOfItems    int    `json:",omitempty"`
+	MaximumRawDataSizeMB    int    `json:",omitempty"`
+}
+
+// validate validates the policy before sending it to the service.
+func (b BatchingPolicy) validate() error {
+	switch {
+	case b.MaximumBatchingTimeSpan < 0:
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "BatchingPolicy.MaximumBatchingTimeSpan cannot be negative, was %v", b.MaximumBatchingTimeSpan).SetNoRetry()
+	case b.MaximumNumberOfItems < 0:
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "BatchingPolicy.MaximumNumberOfItems cannot be negative, was %d", b.MaximumNumberOfItems).SetNoRetry()
+	case b.MaximumRawDataSizeMB < 0:
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "BatchingPolicy.MaximumRawDataSizeMB cannot be negative, was %d", b.MaximumRawDataSizeMB).SetNoRetry()
+	case b == BatchingPolicy{}:
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "BatchingPolicy must have at least one limit set").SetNoRetry()
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b BatchingPolicy) MarshalJSON() ([]byte, error) {
+	j := batchingPolicyJSON{
+		MaximumNumberOfItems: b.MaximumNumberOfItems,
+		MaximumRawDataSizeMB: b.MaximumRawDataSizeMB,
+	}
+	if b.MaximumBatchingTimeSpan != 0 {
+		j.MaximumBatchingTimeSpan = value.Timespan{Valid: true, Value: b.MaximumBatchingTimeSpan}.Marshal()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BatchingPolicy) UnmarshalJSON(data []byte) error {
+	j := batchingPolicyJSON{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	ts := value.Timespan{}
+	if j.MaximumBatchingTimeSpan != "" {
+		if err := ts.Unmarshal(j.MaximumBatchingTimeSpan); err != nil {
+			return err
+		}
+	}
+
+	*b = BatchingPolicy{
+		MaximumBatchingTimeSpan: ts.Value,
+		MaximumNumberOfItems:    j.MaximumNumberOfItems,
+		MaximumRawDataSizeMB:    j.MaximumRawDataSizeMB,
+	}
+	return nil
+}
+
+// ShowIngestionBatchingPolicy returns the ingestion batching policy of tableName in db. If tableName is empty, the policy of
+// the database is returned. If no policy is set on the entity, the zero value is returned.
+func (c *Client) ShowIngestionBatchingPolicy(ctx context.Context, db, tableName string) (BatchingPolicy, error) {
+	policy, err := c.showPolicy(ctx, db, tableName, "ingestionbatching")
+	if err != nil {
+		return BatchingPolicy{}, err
+	}
+
+	p := BatchingPolicy{}
+	if policy == "" || policy == "null" {
+		return p, nil
+	}
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		return BatchingPolicy{}, errors.ES(errors.OpMgmt, errors.KInternal, "could not parse the ingestion batching policy %q: %s", policy, err)
+	}
+	return p, nil
+}
+
+// AlterIngestionBatchingPolicy sets the ingestion batching policy of tableName in db to policy. If tableName is empty, the
+// policy of the database is set. Limits that are not set in policy use the service defaults.
+func (c *Client) AlterIngestionBatchingPolicy(ctx context.Context, db, tableName string, policy BatchingPolicy) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return errors.ES(errors.OpMgmt, errors.KInternal, "could not marshal the ingestion batching policy: %s", err).SetNoRetry()
+	}
+
+	// The JSON only holds numbers and timespans, so it cannot contain a single quote that would end the literal.
+	stmt := NewStmt(".alter ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(policyEntity(db, tableName)).Add(" policy ingestionbatching @'").UnsafeAdd(string(b)).Add("'")
+
+	iter, err := c.Mgmt(ctx, db, stmt)
+	if err != nil {
+		return err
+	}
+	defer iter.Stop()
+
+	return iter.DoOnRowOrError(func(_ *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		return nil
+	})
+}
+
+// showPolicy returns the JSON of the kind policy of tableName in db, or of db if tableName is empty, as returned by
+// the ".show policy" command. It is "null" or empty if no policy is set on the entity.
+func (c *Client) showPolicy(ctx context.Context, db, tableName, kind string) (string, error) {
+	stmt := NewStmt(".show ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(policyEntity(db, tableName)).Add(" policy ").UnsafeAdd(kind)
+
+	iter, err := c.Mgmt(ctx, db, stmt)
+	if err != nil {
+		return "", err
+	}
+	defer iter.Stop()
+
+	var policy string
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		row := struct {
+			Policy string `kusto:"Policy"`
+		}{}
+		if err := r.ToStruct(&row); err != nil {
+			return err
+		}
+		policy = row.Policy
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return policy, nil
+}
+
+// policyEntity returns the entity part of a policy command for tableName in db, or for db if tableName is empty.
+func policyEntity(db, tableName string) string {
+	if tableName == "" {
+		return "database " + kql.QuoteName(db)
+	}
+	return "table " + kql.QuoteName(tableName)
+}
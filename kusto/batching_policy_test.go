@@ -0,0 +1,142 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyResponse returns a v1 response body of a ".show policy" command holding policy.
+func policyResponse(policy string) string {
+	return fmt.Sprintf(`{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"PolicyName","ColumnType":"string"},
+{"ColumnName":"EntityName","ColumnType":"string"},
+{"ColumnName":"Policy","ColumnType":"string"},
+{"ColumnName":"ChildEntities","ColumnType":"string"},
+{"ColumnName":"EntityType","ColumnType":"string"}],
+"Rows":[["IngestionBatchingPolicy","[db].[table]",%s,"","Table"]]}]}`, strconv.Quote(policy))
+}
+
+func TestShowIngestionBatchingPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		table     string
+		policy    string
+		wantQuery string
+		want      BatchingPolicy
+		err       bool
+	}{
+		{
+			desc:      "Table policy",
+			table:     "table",
+			policy:    `{"MaximumBatchingTimeSpan":"00:05:00","MaximumNumberOfItems":500,"MaximumRawDataSizeMB":1024}`,
+			wantQuery: ".show table ['table'] policy ingestionbatching",
+			want:      BatchingPolicy{MaximumBatchingTimeSpan: 5 * time.Minute, MaximumNumberOfItems: 500, MaximumRawDataSizeMB: 1024},
+		},
+		{
+			desc:      "Database policy",
+			policy:    `{"MaximumBatchingTimeSpan":"1.00:00:30"}`,
+			wantQuery: ".show database ['db'] policy ingestionbatching",
+			want:      BatchingPolicy{MaximumBatchingTimeSpan: 24*time.Hour + 30*time.Second},
+		},
+		{
+			desc:      "No policy",
+			table:     "table",
+			policy:    "null",
+			wantQuery: ".show table ['table'] policy ingestionbatching",
+		},
+		{
+			desc:      "Bad policy",
+			table:     "table",
+			policy:    `{"MaximumBatchingTimeSpan":"five minutes"}`,
+			wantQuery: ".show table ['table'] policy ingestionbatching",
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery string
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				gotQuery = query
+				return policyResponse(test.policy), nil
+			}}}
+
+			got, err := client.ShowIngestionBatchingPolicy(context.Background(), "db", test.table)
+			assert.Equal(t, test.wantQuery, gotQuery)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestAlterIngestionBatchingPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		table     string
+		policy    BatchingPolicy
+		wantQuery string
+		err       bool
+	}{
+		{
+			desc:      "Table policy",
+			table:     "table",
+			policy:    BatchingPolicy{MaximumBatchingTimeSpan: 30 * time.Second, MaximumNumberOfItems: 20, MaximumRawDataSizeMB: 300},
+			wantQuery: `.alter table ['table'] policy ingestionbatching @'{"MaximumBatchingTimeSpan":"00:00:30","MaximumNumberOfItems":20,"MaximumRawDataSizeMB":300}'`,
+		},
+		{
+			desc:      "Database policy with only a time span",
+			policy:    BatchingPolicy{MaximumBatchingTimeSpan: 2 * time.Minute},
+			wantQuery: `.alter database ['db'] policy ingestionbatching @'{"MaximumBatchingTimeSpan":"00:02:00"}'`,
+		},
+		{
+			desc:   "Negative value",
+			table:  "table",
+			policy: BatchingPolicy{MaximumNumberOfItems: -1},
+			err:    true,
+		},
+		{
+			desc:  "No limits",
+			table: "table",
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery string
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				gotQuery = query
+				return policyResponse("{}"), nil
+			}}}
+
+			err := client.AlterIngestionBatchingPolicy(context.Background(), "db", test.table, test.policy)
+			if test.err {
+				assert.Error(t, err)
+				assert.Empty(t, gotQuery)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantQuery, gotQuery)
+		})
+	}
+}
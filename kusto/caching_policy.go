@@ -0,0 +1,47 @@
+package kusto
+
+// caching_policy.go holds a helper to read the effective caching policy of a database or table.
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// CachingPolicy is the caching policy of a database or table. It controls how much of the most recent data is kept on
+// the local SSD of the cluster nodes, the hot cache, for fast queries.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/cachepolicy
+type CachingPolicy struct {
+	// DataHotSpan is the age up to which data is kept in the hot cache. Zero means the span is not set and all data is
+	// kept in the hot cache.
+	DataHotSpan time.Duration
+	// IndexHotSpan is the age up to which the indexes of data are kept in the hot cache.
+	IndexHotSpan time.Duration
+}
+
+// cachingPolicyJSON is the JSON representation of CachingPolicy used by the service.
+type cachingPolicyJSON struct {
+	DataHotSpan  policyTimespan
+	IndexHotSpan policyTimespan
+}
+
+// ShowCachingPolicy returns the effective caching policy of tableName in db: the policy of the table, or the policy
+// of db if the table has none. If tableName is empty, the policy of the database is returned. If no policy applies,
+// the zero value is returned, which means all data is kept in the hot cache.
+func (c *Client) ShowCachingPolicy(ctx context.Context, db, tableName string) (CachingPolicy, error) {
+	policy, err := c.showEffectivePolicy(ctx, db, tableName, "caching")
+	if err != nil {
+		return CachingPolicy{}, err
+	}
+	if policy == "" {
+		return CachingPolicy{}, nil
+	}
+
+	j := cachingPolicyJSON{}
+	if err := json.Unmarshal([]byte(policy), &j); err != nil {
+		return CachingPolicy{}, errors.ES(errors.OpMgmt, errors.KInternal, "could not parse the caching policy %q: %s", policy, err)
+	}
+	return CachingPolicy{DataHotSpan: time.Duration(j.DataHotSpan), IndexHotSpan: time.Duration(j.IndexHotSpan)}, nil
+}
@@ -0,0 +1,70 @@
+package kusto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowCachingPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tableQuery = ".show table ['table'] policy caching"
+		dbQuery    = ".show database ['db'] policy caching"
+	)
+
+	tests := []struct {
+		desc     string
+		policies map[string]string
+		want     CachingPolicy
+		err      bool
+	}{
+		{
+			desc:     "Spans as objects",
+			policies: map[string]string{tableQuery: `{"DataHotSpan":{"Value":"7.00:00:00"},"IndexHotSpan":{"Value":"14.00:00:00"}}`},
+			want:     CachingPolicy{DataHotSpan: 7 * 24 * time.Hour, IndexHotSpan: 14 * 24 * time.Hour},
+		},
+		{
+			desc:     "Spans as strings",
+			policies: map[string]string{tableQuery: `{"DataHotSpan":"3.00:00:00","IndexHotSpan":"3.00:00:00"}`},
+			want:     CachingPolicy{DataHotSpan: 3 * 24 * time.Hour, IndexHotSpan: 3 * 24 * time.Hour},
+		},
+		{
+			desc:     "Database policy",
+			policies: map[string]string{tableQuery: "null", dbQuery: `{"DataHotSpan":{"Value":"1.00:00:00"}}`},
+			want:     CachingPolicy{DataHotSpan: 24 * time.Hour},
+		},
+		{
+			desc:     "No policy",
+			policies: map[string]string{tableQuery: "null", dbQuery: "null"},
+		},
+		{
+			desc:     "Bad policy",
+			policies: map[string]string{tableQuery: `{"DataHotSpan":7}`},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				return policyResponse(test.policies[query]), nil
+			}}}
+
+			got, err := client.ShowCachingPolicy(context.Background(), "db", "table")
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
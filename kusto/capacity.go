@@ -0,0 +1,76 @@
+package kusto
+
+// capacity.go holds ShowCapacity(), which reads the capacity of the cluster for ingestions and other operations.
+
+import (
+	"context"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+)
+
+// ResourceCapacity is the capacity of the cluster for one kind of operation, as counted in concurrent slots.
+type ResourceCapacity struct {
+	// Total is the number of slots the cluster has.
+	Total int64
+	// Consumed is the number of slots in use.
+	Consumed int64
+	// Remaining is the number of free slots.
+	Remaining int64
+	// Origin is what the total is computed from, such as "CapacityPolicy".
+	Origin string
+}
+
+// Capacity is the capacity of the cluster, as returned by the ".show capacity" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/diagnostics#show-capacity
+type Capacity struct {
+	// Ingestions is the capacity for ingestion operations.
+	Ingestions ResourceCapacity
+	// DataExport is the capacity for data export operations.
+	DataExport ResourceCapacity
+	// Other holds the capacity of the other resources, such as "extents-merge", keyed by the resource name.
+	Other map[string]ResourceCapacity
+}
+
+// ShowCapacity returns the capacity of the cluster. When polled, Capacity.Ingestions.Remaining can be used to scale
+// back client ingestion concurrency before the service starts throttling. This requires database monitor permissions
+// on at least one database and runs against the NetDefaultDB database.
+func (c *Client) ShowCapacity(ctx context.Context) (Capacity, error) {
+	iter, err := c.Mgmt(ctx, clusterDB, NewStmt(".show capacity"))
+	if err != nil {
+		return Capacity{}, err
+	}
+	defer iter.Stop()
+
+	capacity := Capacity{Other: map[string]ResourceCapacity{}}
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		row := struct {
+			Resource  string `kusto:"Resource"`
+			Total     int64  `kusto:"Total"`
+			Consumed  int64  `kusto:"Consumed"`
+			Remaining int64  `kusto:"Remaining"`
+			Origin    string `kusto:"Origin"`
+		}{}
+		if err := r.ToStruct(&row); err != nil {
+			return err
+		}
+
+		rc := ResourceCapacity{Total: row.Total, Consumed: row.Consumed, Remaining: row.Remaining, Origin: row.Origin}
+		switch row.Resource {
+		case "ingestions":
+			capacity.Ingestions = rc
+		case "data-export":
+			capacity.DataExport = rc
+		default:
+			capacity.Other[row.Resource] = rc
+		}
+		return nil
+	})
+	if err != nil {
+		return Capacity{}, err
+	}
+	return capacity, nil
+}
@@ -0,0 +1,67 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const capacityResponse = `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"Resource","ColumnType":"string"},
+{"ColumnName":"Total","ColumnType":"long"},
+{"ColumnName":"Consumed","ColumnType":"long"},
+{"ColumnName":"Remaining","ColumnType":"long"},
+{"ColumnName":"Origin","ColumnType":"string"}],
+"Rows":[
+["ingestions",12,4,8,"CapacityPolicy/Ingestion"],
+["data-export",6,0,6,"CapacityPolicy/Export"],
+["extents-merge",10,1,9,"CapacityPolicy/ExtentsMerge"]]}]}`
+
+func TestShowCapacity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		mgmtErr error
+		want    Capacity
+		err     bool
+	}{
+		{
+			desc: "Success",
+			want: Capacity{
+				Ingestions: ResourceCapacity{Total: 12, Consumed: 4, Remaining: 8, Origin: "CapacityPolicy/Ingestion"},
+				DataExport: ResourceCapacity{Total: 6, Remaining: 6, Origin: "CapacityPolicy/Export"},
+				Other: map[string]ResourceCapacity{
+					"extents-merge": {Total: 10, Consumed: 1, Remaining: 9, Origin: "CapacityPolicy/ExtentsMerge"},
+				},
+			},
+		},
+		{desc: "Mgmt error", mgmtErr: fmt.Errorf("mgmt error"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, ".show capacity", query)
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return capacityResponse, nil
+			}}}
+
+			got, err := client.ShowCapacity(context.Background())
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package kusto
+
+// client_request_id.go holds the options that control the x-ms-client-request-id header sent with queries and
+// management commands.
+
+import (
+	"github.com/google/uuid"
+)
+
+// WithoutAutoClientRequestID stops the client from generating an x-ms-client-request-id header for every request, for
+// setups where a transport, such as an http.RoundTripper passed with WithHttpClient(), sets the header itself. The
+// header is then only sent by the SDK if it is set with the ClientRequestID() or MgmtClientRequestID() options.
+// The client request ID is how the service and Microsoft support find a request in the service logs and in commands
+// like ".show queries". If neither the SDK nor the transport sets it, the service generates an ID that the client
+// never sees, so a failed request can only be found by its time and text.
+// This does not affect streaming ingestion, which always sends a client request ID.
+func WithoutAutoClientRequestID() Option {
+	return func(c *Client) {
+		c.noAutoRequestID = true
+	}
+}
+
+// ClientRequestID sets the x-ms-client-request-id header of the query to id, instead of the ID the client generates.
+// The ID should be unique for each request, so it can be used to find the request in the service logs.
+func ClientRequestID(id string) QueryOption {
+	return func(q *queryOptions) error {
+		q.requestProperties.clientRequestID = id
+		return nil
+	}
+}
+
+// MgmtClientRequestID sets the x-ms-client-request-id header of the management command to id, instead of the ID the
+// client generates. The ID should be unique for each request, so it can be used to find the request in the service logs.
+func MgmtClientRequestID(id string) MgmtOption {
+	return func(m *mgmtOptions) error {
+		m.requestProperties.clientRequestID = id
+		return nil
+	}
+}
+
+// clientRequestID returns the x-ms-client-request-id header to send with a request that has properties, or an empty
+// string if the header should not be sent.
+func (c *conn) clientRequestID(properties requestProperties) string {
+	switch {
+	case properties.clientRequestID != "":
+		return properties.clientRequestID
+	case c.noAutoRequestID:
+		return ""
+	}
+	return "KGC.execute;" + uuid.New().String()
+}
@@ -0,0 +1,52 @@
+package kusto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRequestID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		options    []Option
+		queryOpts  []QueryOption
+		want       string
+		wantPrefix string
+	}{
+		{desc: "Generated by default", wantPrefix: "KGC.execute;"},
+		{desc: "Set by option", queryOpts: []QueryOption{ClientRequestID("myId")}, want: "myId"},
+		{desc: "Not generated", options: []Option{WithoutAutoClientRequestID()}},
+		{desc: "Set by option without generation", options: []Option{WithoutAutoClientRequestID()}, queryOpts: []QueryOption{ClientRequestID("myId")}, want: "myId"},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := New("https://cluster.kusto.windows.net", Authorization{Authorizer: &failingAuthorizer{}}, test.options...)
+			require.NoError(t, err)
+
+			opts := &queryOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}}}
+			for _, o := range test.queryOpts {
+				require.NoError(t, o(opts))
+			}
+
+			got := client.conn.(*conn).clientRequestID(*opts.requestProperties)
+			if test.wantPrefix != "" {
+				assert.True(t, strings.HasPrefix(got, test.wantPrefix), got)
+				return
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	mgmt := &mgmtOptions{requestProperties: &requestProperties{}}
+	require.NoError(t, MgmtClientRequestID("mgmtId")(mgmt))
+	assert.Equal(t, "mgmtId", (&conn{noAutoRequestID: true}).clientRequestID(*mgmt.requestProperties))
+}
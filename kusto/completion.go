@@ -0,0 +1,75 @@
+package kusto
+
+// completion.go holds the accessors for the DataSetCompletion frame that ends a query response.
+
+import (
+	"encoding/json"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// DataSetCompletion is the status the service reports at the end of a query response.
+type DataSetCompletion struct {
+	// HasErrors indicates the service reported errors for the query, even if rows were returned.
+	HasErrors bool
+	// Cancelled indicates the query was cancelled.
+	Cancelled bool
+	// OneAPIErrors are the errors reported with the completion, in the order the service sent them.
+	OneAPIErrors []*errors.Error
+}
+
+// Completion returns the DataSetCompletion of the query. It is sent by the service after all rows, so call it after
+// Do(), DoOnRowOrError() or NextRowOrError() reached the end of the rows. It returns an error if the completion was not
+// received, either because rows remain to be read or because the query failed. A RowIterator using MockRows returns a
+// DataSetCompletion without errors once all rows are read.
+func (r *RowIterator) Completion() (DataSetCompletion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mock != nil && r.drained {
+		return DataSetCompletion{}, nil
+	}
+	if !r.hasCompletion {
+		if r.error != nil {
+			return DataSetCompletion{}, r.error
+		}
+		return DataSetCompletion{}, errors.ES(r.op, errors.KClientArgs, "the DataSetCompletion was not received yet, all rows must be read first").SetNoRetry()
+	}
+
+	c := DataSetCompletion{HasErrors: r.dsCompletion.HasErrors, Cancelled: r.dsCompletion.Cancelled}
+	for _, oneErr := range r.dsCompletion.OneAPIErrors {
+		c.OneAPIErrors = append(c.OneAPIErrors, oneAPIError(oneErr, r.op))
+	}
+	return c, nil
+}
+
+// PartialFailures returns the errors the service reported in the DataSetCompletion of a query whose rows were
+// returned, so that a result can be checked to be complete after reading all rows. It returns nil if the query completed
+// cleanly or if the completion was not received, see Completion(). If the service flagged errors without details,
+// a single error saying so is returned. Inline errors returned with the rows are not included.
+func (r *RowIterator) PartialFailures() []*errors.Error {
+	c, err := r.Completion()
+	if err != nil {
+		return nil
+	}
+	if len(c.OneAPIErrors) > 0 {
+		return c.OneAPIErrors
+	}
+	if c.HasErrors {
+		return []*errors.Error{errors.ES(r.op, errors.KOther, "the service reported that the query had errors, without details")}
+	}
+	return nil
+}
+
+// oneAPIError converts an entry of the DataSetCompletion's OneApiErrors to an *errors.Error. Entries that are not in
+// the OneApiError format are kept as their JSON.
+func oneAPIError(oneErr interface{}, op errors.Op) *errors.Error {
+	if e := errors.OneToErr(map[string]interface{}{"OneApiErrors": []interface{}{oneErr}}, op); e != nil {
+		return e
+	}
+	b, err := json.Marshal(oneErr)
+	if err != nil {
+		return errors.ES(op, errors.KOther, "%v", oneErr)
+	}
+	return errors.ES(op, errors.KOther, "%s", b)
+}
@@ -0,0 +1,107 @@
+package kusto
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func completionResponse(completion string) string {
+	return fmt.Sprintf(`[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"x","ColumnType":"long"}],"Rows":[[1]]},
+%s
+]`, completion)
+}
+
+func TestCompletion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc         string
+		completion   string
+		wantHas      bool
+		wantMessages []string
+		wantKinds    []errors.Kind
+	}{
+		{
+			desc:       "Clean",
+			completion: `{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}`,
+		},
+		{
+			desc: "OneApiErrors",
+			completion: `{"FrameType":"DataSetCompletion","HasErrors":true,"Cancelled":false,"OneApiErrors":[
+				{"error":{"code":"LimitsExceeded","message":"Query result set has exceeded the internal record count limit"}},
+				{"error":{"code":"Other","message":"a shard failed"}},
+				{"unexpected":"format"}
+			]}`,
+			wantHas: true,
+			wantMessages: []string{
+				"Query result set has exceeded the internal record count limit",
+				"a shard failed",
+				`{"unexpected":"format"}`,
+			},
+			wantKinds: []errors.Kind{errors.KLimitsExceeded, errors.KOther, errors.KOther},
+		},
+		{
+			desc:         "HasErrors without details",
+			completion:   `{"FrameType":"DataSetCompletion","HasErrors":true,"Cancelled":false}`,
+			wantHas:      true,
+			wantMessages: []string{"without details"},
+			wantKinds:    []errors.Kind{errors.KOther},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			iter, err := NewRowIteratorFromJSON(strings.NewReader(completionResponse(test.completion)))
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			require.NoError(t, iter.Do(func(*table.Row) error { return nil }))
+
+			c, err := iter.Completion()
+			require.NoError(t, err)
+			assert.Equal(t, test.wantHas, c.HasErrors)
+			assert.False(t, c.Cancelled)
+
+			failures := iter.PartialFailures()
+			require.Len(t, failures, len(test.wantMessages))
+			for i, f := range failures {
+				assert.Contains(t, f.Error(), test.wantMessages[i])
+				assert.Equal(t, test.wantKinds[i], f.Kind)
+			}
+		})
+	}
+}
+
+func TestCompletionBeforeEnd(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewMockRows(table.Columns{{Name: "x", Type: types.Long}})
+	require.NoError(t, err)
+
+	iter := &RowIterator{}
+	require.NoError(t, iter.Mock(m))
+	defer iter.Stop()
+
+	_, err = iter.Completion()
+	assert.Error(t, err)
+	assert.Nil(t, iter.PartialFailures())
+
+	require.NoError(t, iter.Do(func(*table.Row) error { return nil }))
+
+	c, err := iter.Completion()
+	require.NoError(t, err)
+	assert.Equal(t, DataSetCompletion{}, c)
+	assert.Nil(t, iter.PartialFailures())
+}
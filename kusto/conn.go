@@ -15,6 +15,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/internal/frames"
@@ -24,7 +25,6 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto/internal/version"
 
 	"github.com/Azure/go-autorest/autorest"
-	"github.com/google/uuid"
 )
 
 var validURL = regexp.MustCompile(`https://([a-zA-Z0-9_-]+\.){1,2}.*`)
@@ -41,6 +41,9 @@ type conn struct {
 	auth                           autorest.Authorizer
 	endMgmt, endQuery, streamQuery *url.URL
 	client                         *http.Client
+	tokenRetry                     tokenRetry
+	noAutoRequestID                bool
+	noResponseCompression          bool
 }
 
 // newConn returns a new conn object with an injected http.Client
@@ -115,6 +118,8 @@ type execResp struct {
 	reqHeader  http.Header
 	respHeader http.Header
 	frameCh    chan frames.Frame
+	// sent is when the request was sent to the service.
+	sent time.Time
 }
 
 func (c *conn) execute(ctx context.Context, execType int, db string, query Stmt, properties requestProperties) (execResp, error) {
@@ -127,10 +132,12 @@ func (c *conn) execute(ctx context.Context, execType int, db string, query Stmt,
 
 	header := http.Header{}
 	header.Add("Accept", "application/json")
-	header.Add("Accept-Encoding", "gzip")
+	header.Add("Accept-Encoding", c.acceptEncoding())
 	header.Add("x-ms-client-version", "Kusto.Go.Client: "+version.Kusto)
 	header.Add("Content-Type", "application/json; charset=utf-8")
-	header.Add("x-ms-client-request-id", "KGC.execute;"+uuid.New().String())
+	if id := c.clientRequestID(properties); id != "" {
+		header.Add("x-ms-client-request-id", id)
+	}
 
 	var endpoint *url.URL
 	buff := bufferPool.Get().(*bytes.Buffer)
@@ -166,13 +173,12 @@ func (c *conn) execute(ctx context.Context, execType int, db string, query Stmt,
 		Body:   ioutil.NopCloser(buff),
 	}
 
-	var err error
-	prep := c.auth.WithAuthorization()
-	req, err = prep(autorest.CreatePreparer()).Prepare(req)
+	req, err := c.authorize(ctx, req)
 	if err != nil {
 		return execResp{}, errors.E(op, errors.KInternal, err)
 	}
 
+	sent := nower()
 	resp, err := c.client.Do(req.WithContext(ctx))
 	if err != nil {
 		// TODO(jdoak): We need a http error unwrap function that pulls out an *errors.Error.
@@ -185,22 +191,24 @@ func (c *conn) execute(ctx context.Context, execType int, db string, query Stmt,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return execResp{}, errors.HTTP(op, resp.Status, resp.StatusCode, body, fmt.Sprintf("error from Kusto endpoint for query %q: ", query.String()))
+		e := errors.HTTP(op, resp.Status, resp.StatusCode, body, fmt.Sprintf("error from Kusto endpoint for query %q: ", query.String()))
+		e.SetResponseHeaders(resp.Header)
+		return execResp{}, e
 	}
 
 	var dec frames.Decoder
 	switch execType {
 	case execMgmt:
-		dec = &v1.Decoder{}
+		dec = &v1.Decoder{BadRowsAsErrors: properties.badRowsAsErrors}
 	case execQuery:
-		dec = &v2.Decoder{}
+		dec = &v2.Decoder{BadRowsAsErrors: properties.badRowsAsErrors, DuplicateColumns: properties.duplicateColumns}
 	default:
 		return execResp{}, errors.ES(op, errors.KInternal, "unknown execution type was %v", execType).SetNoRetry()
 	}
 
 	frameCh := dec.Decode(ctx, body, op)
 
-	return execResp{reqHeader: header, respHeader: resp.Header, frameCh: frameCh}, nil
+	return execResp{reqHeader: header, respHeader: resp.Header, frameCh: frameCh, sent: sent}, nil
 }
 
 func (c *conn) Close() error {
@@ -0,0 +1,51 @@
+package kusto
+
+// context_options.go holds ContextWithOptions() and ContextWithMgmtOptions(), which carry default options in a context.
+
+import (
+	"context"
+)
+
+type queryOptionsKey struct{}
+
+type mgmtOptionsKey struct{}
+
+// ContextWithOptions returns a copy of ctx that carries options as defaults for all Query() calls made with it, including
+// QueryAsync() and QueryTimeRangeSplit(). The options passed to a call are applied after these, so they override them.
+// Calling ContextWithOptions on a context that already carries options adds to those options.
+// This allows a request scope, like an HTTP handler, to set options once for all of its queries.
+func ContextWithOptions(ctx context.Context, options ...QueryOption) context.Context {
+	parent, _ := ctx.Value(queryOptionsKey{}).([]QueryOption)
+	merged := make([]QueryOption, 0, len(parent)+len(options))
+	merged = append(append(merged, parent...), options...)
+	return context.WithValue(ctx, queryOptionsKey{}, merged)
+}
+
+// ContextWithMgmtOptions returns a copy of ctx that carries options as defaults for all Mgmt() calls made with it. It
+// works the same way as ContextWithOptions().
+func ContextWithMgmtOptions(ctx context.Context, options ...MgmtOption) context.Context {
+	parent, _ := ctx.Value(mgmtOptionsKey{}).([]MgmtOption)
+	merged := make([]MgmtOption, 0, len(parent)+len(options))
+	merged = append(append(merged, parent...), options...)
+	return context.WithValue(ctx, mgmtOptionsKey{}, merged)
+}
+
+// withContextQueryOptions returns the options carried by ctx followed by options.
+func withContextQueryOptions(ctx context.Context, options []QueryOption) []QueryOption {
+	defaults, _ := ctx.Value(queryOptionsKey{}).([]QueryOption)
+	if len(defaults) == 0 {
+		return options
+	}
+	merged := make([]QueryOption, 0, len(defaults)+len(options))
+	return append(append(merged, defaults...), options...)
+}
+
+// withContextMgmtOptions returns the options carried by ctx followed by options.
+func withContextMgmtOptions(ctx context.Context, options []MgmtOption) []MgmtOption {
+	defaults, _ := ctx.Value(mgmtOptionsKey{}).([]MgmtOption)
+	if len(defaults) == 0 {
+		return options
+	}
+	merged := make([]MgmtOption, 0, len(defaults)+len(options))
+	return append(append(merged, defaults...), options...)
+}
@@ -0,0 +1,84 @@
+package kusto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc           string
+		ctx            func() context.Context
+		options        []QueryOption
+		wantMaxRecords interface{}
+		wantVerify     bool
+	}{
+		{
+			desc:           "No defaults",
+			ctx:            context.Background,
+			options:        []QueryOption{MaxRecords(5)},
+			wantMaxRecords: int64(5),
+		},
+		{
+			desc: "Defaults",
+			ctx: func() context.Context {
+				return ContextWithOptions(context.Background(), MaxRecords(10), VerifyRowCount())
+			},
+			wantMaxRecords: int64(10),
+			wantVerify:     true,
+		},
+		{
+			desc: "Overridden by the call",
+			ctx: func() context.Context {
+				return ContextWithOptions(context.Background(), MaxRecords(10))
+			},
+			options:        []QueryOption{MaxRecords(5)},
+			wantMaxRecords: int64(5),
+		},
+		{
+			desc: "Nested",
+			ctx: func() context.Context {
+				ctx := ContextWithOptions(context.Background(), MaxRecords(10))
+				return ContextWithOptions(ctx, VerifyRowCount())
+			},
+			wantMaxRecords: int64(10),
+			wantVerify:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := &Client{}
+			opts, err := c.setQueryOptions(test.ctx(), errors.OpQuery, NewStmt("table"), test.options...)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMaxRecords, opts.requestProperties.Options[takeMaxRecordsOption])
+			assert.Equal(t, test.wantVerify, opts.verifyRowCount)
+		})
+	}
+}
+
+func TestContextWithMgmtOptions(t *testing.T) {
+	t.Parallel()
+
+	c := &Client{}
+	ctx := ContextWithMgmtOptions(context.Background(), AllowWrite())
+
+	opts, err := c.setMgmtOptions(ctx, errors.OpMgmt, NewStmt(".show tables"))
+	require.NoError(t, err)
+	assert.True(t, opts.canWrite)
+
+	// Query options do not apply to Mgmt() and the other way around.
+	opts, err = c.setMgmtOptions(ContextWithOptions(context.Background(), MaxRecords(1)), errors.OpMgmt, NewStmt(".show tables"))
+	require.NoError(t, err)
+	assert.False(t, opts.canWrite)
+	assert.Empty(t, opts.requestProperties.Options)
+}
@@ -71,6 +71,8 @@ type Error struct {
 	restErrMsg []byte
 	decoded    map[string]interface{}
 	permanent  bool
+	// headers are the headers of the HTTP response, set with SetResponseHeaders().
+	headers http.Header
 
 	inner *Error
 }
@@ -180,7 +182,7 @@ func Retry(err error) bool {
 	if errors.As(err, &e) {
 		// e.permanent can be set multiple ways. If it is true, you can never retry.
 		// If it is false, it does not necessarily mean anything, you have to go a little further.
-		if e.permanent {
+		if e.permanent || e.isReadOnly() {
 			return false
 		}
 
@@ -241,6 +243,7 @@ func HTTP(o Op, status string, statusCode int, body io.ReadCloser, prefix string
 	}
 
 	e.UnmarshalREST()
+	e.markReadOnly()
 	return &e
 }
 
@@ -414,6 +417,33 @@ func (c CombinedError) Error() string {
 	return result
 }
 
+// Unwrap returns the combined errors. errors.Is() and errors.As() only use it from Go 1.20, before that they use the
+// Is() and As() methods.
+func (c CombinedError) Unwrap() []error {
+	return c.Errors
+}
+
+// Is reports if any of the combined errors matches target, so that errors.Is() matches any of them.
+func (c CombinedError) Is(target error) bool {
+	for _, err := range c.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the combined errors that matches target and sets target to it, so that errors.As() matches any
+// of them.
+func (c CombinedError) As(target interface{}) bool {
+	for _, err := range c.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func GetCombinedError(errs ...error) *CombinedError {
 	return &CombinedError{Errors: errs}
 }
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/kylelemons/godebug/pretty"
@@ -53,6 +56,32 @@ func TestW(t *testing.T) {
 	}
 }
 
+func TestCombinedError(t *testing.T) {
+	kustoErr := ES(OpMgmt, KClientArgs, "Client supplied bad arguments")
+	combined := GetCombinedError(fmt.Errorf("first"), fmt.Errorf("wrapped: %w", io.EOF), kustoErr)
+
+	// The methods are called directly, as errors.Is() and errors.As() also use Unwrap() from Go 1.20.
+	if !combined.Is(io.EOF) {
+		t.Errorf("TestCombinedError: combined.Is(io.EOF): got false, want true")
+	}
+	if combined.Is(io.ErrUnexpectedEOF) {
+		t.Errorf("TestCombinedError: combined.Is(io.ErrUnexpectedEOF): got true, want false")
+	}
+
+	var err *Error
+	if !combined.As(&err) {
+		t.Errorf("TestCombinedError: combined.As(&Error{}): got false, want true")
+	}
+	if diff := pretty.Compare(kustoErr, err); diff != "" {
+		t.Errorf("TestCombinedError: combined.As(&Error{}): -want/+got:\n%s", diff)
+	}
+
+	var httpErr *HttpError
+	if combined.As(&httpErr) {
+		t.Errorf("TestCombinedError: combined.As(&HttpError{}): got true, want false")
+	}
+}
+
 func TestRetry(t *testing.T) {
 	tests := []struct {
 		desc string
@@ -117,6 +146,14 @@ func TestRetry(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			desc: "read-only database",
+			err: &Error{
+				Kind:       KHTTPError,
+				restErrMsg: []byte(`{"error": {"code": "BadRequest_DatabaseReadOnly", "message": "cannot ingest"}}`),
+			},
+			want: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -128,6 +165,65 @@ func TestRetry(t *testing.T) {
 	}
 }
 
+func TestIsReadOnly(t *testing.T) {
+	httpErr := func(body string) error {
+		return HTTP(OpIngestStream, "400 Bad Request", http.StatusBadRequest, ioutil.NopCloser(strings.NewReader(body)), "streaming ingest issue")
+	}
+
+	tests := []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{desc: "nil", err: nil, want: false},
+		{desc: "standard error", err: fmt.Errorf("blah"), want: false},
+		{
+			desc: "code",
+			err:  httpErr(`{"error": {"code": "BadRequest_DatabaseReadOnly", "message": "request is invalid"}}`),
+			want: true,
+		},
+		{
+			desc: "exception type",
+			err:  httpErr(`{"error": {"code": "BadRequest", "@type": "Kusto.DataNode.Exceptions.ReadOnlyDatabaseException", "message": "request is invalid"}}`),
+			want: true,
+		},
+		{
+			desc: "message",
+			err:  httpErr(`{"error": {"code": "BadRequest", "message": "Cannot ingest into 'db': the database is a follower database"}}`),
+			want: true,
+		},
+		{
+			desc: "other HTTP error",
+			err:  httpErr(`{"error": {"code": "BadRequest_EntityNotFound", "message": "table not found"}}`),
+			want: false,
+		},
+		{
+			desc: "wrapped in a standard error",
+			err:  fmt.Errorf("ingestion failed: %w", ES(OpFileIngest, KOther, "database 'db' is read-only")),
+			want: true,
+		},
+		{
+			desc: "inner error",
+			err:  W(ES(OpMgmt, KOther, "the database is in read-only mode"), ES(OpMgmt, KOther, "outer")),
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		if got := IsReadOnly(test.err); got != test.want {
+			t.Errorf("TestIsReadOnly(%s): got %v, want %v", test.desc, got, test.want)
+		}
+	}
+
+	err := httpErr(`{"error": {"code": "BadRequest_DatabaseReadOnly", "message": "request is invalid"}}`)
+	if !strings.Contains(err.Error(), "follower database") {
+		t.Errorf("TestIsReadOnly: got error message %q, want it to explain the database is read-only", err.Error())
+	}
+	if Retry(err) {
+		t.Errorf("TestIsReadOnly: Retry(read-only error): got true, want false")
+	}
+}
+
 func TestOneToErr(t *testing.T) {
 	tests := []struct {
 		desc  string
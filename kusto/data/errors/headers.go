@@ -0,0 +1,47 @@
+package errors
+
+// headers.go holds the response headers that are attached to errors for support cases.
+
+import (
+	"errors"
+	"net/http"
+)
+
+// redacted replaces the values of the headers in redactedHeaders.
+const redacted = "[REDACTED]"
+
+// redactedHeaders are the headers whose values are not kept by SetResponseHeaders(), as they can hold credentials.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// SetResponseHeaders attaches the headers of the HTTP response that caused e, with the values of headers that can
+// hold credentials, like Authorization and Set-Cookie, redacted. The headers are copied, so h can be changed after.
+func (e *Error) SetResponseHeaders(h http.Header) *Error {
+	if h == nil {
+		return e
+	}
+	headers := make(http.Header, len(h))
+	for k, v := range h {
+		headers[k] = append([]string(nil), v...)
+	}
+	for _, k := range redactedHeaders {
+		if _, ok := headers[k]; ok {
+			headers[k] = []string{redacted}
+		}
+	}
+	e.headers = headers
+	return e
+}
+
+// ResponseHeaders returns the headers of the HTTP response that caused err, or an error it wraps, like the
+// x-ms-activity-id and Date headers that support cases ask for. The values of headers that can hold credentials are
+// redacted. It returns false if err does not hold the headers of a response, like errors that happened before a
+// response was received. The returned headers are a copy.
+func ResponseHeaders(err error) (http.Header, bool) {
+	for err != nil {
+		if e, ok := GetKustoError(err); ok && e.headers != nil {
+			return e.headers.Clone(), true
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil, false
+}
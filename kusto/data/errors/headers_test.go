@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestResponseHeaders(t *testing.T) {
+	header := http.Header{
+		"X-Ms-Activity-Id": {"activity"},
+		"Date":             {"Mon, 26 Apr 2021 15:21:50 GMT"},
+		"Set-Cookie":       {"session=secret"},
+		"Authorization":    {"Bearer secret"},
+	}
+	want := http.Header{
+		"X-Ms-Activity-Id": {"activity"},
+		"Date":             {"Mon, 26 Apr 2021 15:21:50 GMT"},
+		"Set-Cookie":       {redacted},
+		"Authorization":    {redacted},
+	}
+	httpErr := func() *HttpError {
+		e := HTTP(OpQuery, "400 Bad Request", http.StatusBadRequest, ioutil.NopCloser(strings.NewReader("bad")), "query issue")
+		e.SetResponseHeaders(header)
+		return e
+	}
+
+	tests := []struct {
+		desc string
+		err  error
+		want http.Header
+	}{
+		{desc: "nil", err: nil},
+		{desc: "standard error", err: fmt.Errorf("blah")},
+		{desc: "no headers", err: ES(OpQuery, KOther, "blah")},
+		{desc: "HTTP error", err: httpErr(), want: want},
+		{desc: "wrapped in a standard error", err: fmt.Errorf("query failed: %w", httpErr()), want: want},
+		{desc: "inner error", err: W(&httpErr().KustoError, ES(OpQuery, KOther, "outer")), want: want},
+	}
+
+	for _, test := range tests {
+		got, ok := ResponseHeaders(test.err)
+		if ok != (test.want != nil) {
+			t.Errorf("TestResponseHeaders(%s): got ok %v, want %v", test.desc, ok, test.want != nil)
+			continue
+		}
+		if diff := pretty.Compare(test.want, got); diff != "" {
+			t.Errorf("TestResponseHeaders(%s): -want/+got:\n%s", test.desc, diff)
+		}
+	}
+
+	if header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("TestResponseHeaders: SetResponseHeaders() changed the headers it was passed")
+	}
+}
@@ -0,0 +1,70 @@
+package errors
+
+// readonly.go holds the detection of errors caused by writing to a database that is read-only.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// readOnlyMsg prefixes the message of errors detected as read-only errors, as the engine's messages do not point at
+// the cause.
+const readOnlyMsg = "the database is read-only: it may be a follower database or the cluster may be in read-only " +
+	"mode during a failover, writes such as ingestion must be sent to the leader cluster"
+
+// readOnlyCodes are fragments of the error codes and exception types the engine uses when it rejects a write because
+// the database is read-only. They are compared in lower case.
+var readOnlyCodes = []string{"readonly", "follower"}
+
+// readOnlyMessages are fragments of the messages the engine uses when it rejects a write because the database is
+// read-only. They are compared in lower case.
+var readOnlyMessages = []string{"is read-only", "is readonly", "read-only mode", "readonly mode", "follower database", "follower cluster"}
+
+// IsReadOnly indicates that err, or an error it wraps, was returned because the database is read-only, such as a
+// follower database or a cluster in read-only mode during a failover. These errors are never retried by Retry().
+// Detection is based on the error codes and messages of the engine.
+func IsReadOnly(err error) bool {
+	for err != nil {
+		if e, ok := GetKustoError(err); ok && e.isReadOnly() {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// isReadOnly indicates that the REST error or message of e is a read-only error. It does not look at wrapped errors.
+func (e *Error) isReadOnly() bool {
+	if m := e.UnmarshalREST(); m != nil {
+		if errMap, ok := m["error"].(map[string]interface{}); ok {
+			for _, field := range []string{"code", "@type"} {
+				if v, ok := errMap[field].(string); ok && containsAny(strings.ToLower(v), readOnlyCodes) {
+					return true
+				}
+			}
+			if v, ok := errMap["message"].(string); ok && containsAny(strings.ToLower(v), readOnlyMessages) {
+				return true
+			}
+		}
+	}
+	return e.Err != nil && containsAny(strings.ToLower(e.Err.Error()), readOnlyMessages)
+}
+
+// markReadOnly gives e a clear message and makes it permanent if it is a read-only error.
+func (e *Error) markReadOnly() {
+	if !e.isReadOnly() {
+		return
+	}
+	e.Err = fmt.Errorf("%s: %w", readOnlyMsg, e.Err)
+	e.permanent = true
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
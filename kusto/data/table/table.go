@@ -47,6 +47,20 @@ func (c Columns) Validate() error {
 	return nil
 }
 
+// ColumnIndex maps column names to their position in a Columns.
+type ColumnIndex map[string]int
+
+// Index returns the ColumnIndex of c. If several columns have the same name, the name maps to the first of them.
+func (c Columns) Index() ColumnIndex {
+	index := make(ColumnIndex, len(c))
+	for i, col := range c {
+		if _, ok := index[col.Name]; !ok {
+			index[col.Name] = i
+		}
+	}
+	return index
+}
+
 // Row represents a row of Kusto data. Methods are not thread-safe.
 type Row struct {
 	// ColumnType contains all the column type information for the row.
@@ -57,10 +71,18 @@ type Row struct {
 	Op errors.Op
 	// Replace indicates whether the existing result set should be cleared and replaced with this row.
 	Replace bool
-
+	// columnIndex is the index of ColumnTypes used by Value(). The rows of a result share the same index, set by
+	// NewRow(). If it is nil, Value() builds it.
+	columnIndex ColumnIndex
 	columnNames []string
 }
 
+// NewRow returns a Row of values for columns. index is columns.Index(), which the rows of a result share so that
+// Value() does not build it for every row. If index is nil, Value() builds it when it is first called.
+func NewRow(columns Columns, index ColumnIndex, values value.Values) *Row {
+	return &Row{ColumnTypes: columns, Values: values, columnIndex: index}
+}
+
 // ColumnNames returns a list of all column names.
 func (r *Row) ColumnNames() []string {
 	if r.columnNames == nil {
@@ -71,6 +93,19 @@ func (r *Row) ColumnNames() []string {
 	return r.columnNames
 }
 
+// Value returns the value of the column called name, and false if the row has no such column. If several columns
+// have the same name, the value of the first of them is returned.
+func (r *Row) Value(name string) (value.Kusto, bool) {
+	if r.columnIndex == nil {
+		r.columnIndex = r.ColumnTypes.Index()
+	}
+	i, ok := r.columnIndex[name]
+	if !ok || i >= len(r.Values) {
+		return nil, false
+	}
+	return r.Values[i], true
+}
+
 // Size returns the number of columns contained in Row.
 func (r *Row) Size() int {
 	return len(r.ColumnTypes)
@@ -128,6 +163,74 @@ func (r *Row) ExtractValues(ptrs ...interface{}) error {
 	return nil
 }
 
+// Scan copies the values of the row into the values pointed at by dest, similar to database/sql's Rows.Scan().
+// The value of the kth column is stored in the kth argument to Scan, so the number of arguments must be equal
+// to the number of columns. Pass nil to specify that a column should be ignored.
+// Each dest must be a pointer to a type that the column's value.Kusto type can be converted to
+// (int64, *int64, value.Long, ... for a long column). A null column stored into a pointer (such as **int64)
+// sets the pointer to nil and a null column stored into a value.Kusto type sets .Valid to false. Storing a null
+// column into a non-pointer Go type (such as *int64) is an error, as the null would be lost.
+// An error may leave some dest values set and others not.
+func (r *Row) Scan(dest ...interface{}) error {
+	if len(dest) != len(r.ColumnTypes) {
+		return errors.ES(r.Op, errors.KClientArgs, ".Scan() requires %d arguments for this row, had %d", len(r.ColumnTypes), len(dest))
+	}
+	if len(r.Values) != len(r.ColumnTypes) {
+		return errors.ES(r.Op, errors.KClientArgs, "row does not have the correct number of values(%d) for the number of columns(%d)", len(r.Values), len(r.ColumnTypes))
+	}
+
+	for i, val := range r.Values {
+		if dest[i] == nil {
+			continue
+		}
+		col := r.ColumnTypes[i]
+
+		ptr := reflect.ValueOf(dest[i])
+		if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+			return errors.ES(r.Op, errors.KClientArgs, ".Scan() argument at position %d(column %s) must be a non-nil pointer, was %T", i, col.Name, dest[i])
+		}
+		elem := ptr.Elem()
+
+		if isNull(val) {
+			switch {
+			case elem.Kind() == reflect.Ptr:
+				elem.Set(reflect.Zero(elem.Type()))
+				continue
+			case !isKustoType(elem.Type()):
+				return errors.ES(
+					r.Op,
+					errors.KClientArgs,
+					".Scan() column %s(%s) at position %d is null and cannot be stored in %T, use a pointer or a value.%s type instead",
+					col.Name, col.Type, i, dest[i], reflect.TypeOf(val).Name(),
+				)
+			}
+		}
+
+		if err := val.Convert(elem); err != nil {
+			return errors.ES(r.Op, errors.KClientArgs, ".Scan() column %s(%s) at position %d could not be stored in %T: %s", col.Name, col.Type, i, dest[i], err)
+		}
+	}
+
+	return nil
+}
+
+var kustoType = reflect.TypeOf((*value.Kusto)(nil)).Elem()
+
+// isKustoType indicates if t is one of the value.Kusto types.
+func isKustoType(t reflect.Type) bool {
+	return t.Implements(kustoType)
+}
+
+// isNull indicates if a value.Kusto holds a null value. All value.Kusto types have a .Valid field.
+func isNull(k value.Kusto) bool {
+	v := reflect.ValueOf(k)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	valid := v.FieldByName("Valid")
+	return valid.IsValid() && valid.Kind() == reflect.Bool && !valid.Bool()
+}
+
 // ToStruct fetches the columns in a row into the fields of a struct. p must be a pointer to struct.
 // The rules for mapping a row's columns into a struct's exported fields are:
 //
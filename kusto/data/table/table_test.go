@@ -243,3 +243,163 @@ func TestExtractValueAll(t *testing.T) {
 	assert.Equal(t, time.Duration(10), timespanVar)
 	assert.Equal(t, "5.6", decimalVar)
 }
+
+func TestRowScan(t *testing.T) {
+	t.Parallel()
+
+	cols := Columns{
+		{Name: "Name", Type: types.String},
+		{Name: "Count", Type: types.Long},
+	}
+
+	row := &Row{
+		ColumnTypes: cols,
+		Values: value.Values{
+			value.String{Value: "hello", Valid: true},
+			value.Long{Value: 10, Valid: true},
+		},
+	}
+
+	nullRow := &Row{
+		ColumnTypes: cols,
+		Values: value.Values{
+			value.String{Value: "hello", Valid: true},
+			value.Long{},
+		},
+	}
+
+	var (
+		name     string
+		count    int64
+		countPtr *int64
+		countVal value.Long
+		real     float64
+	)
+
+	tests := []struct {
+		desc   string
+		row    *Row
+		dest   []interface{}
+		err    bool
+		verify func(t *testing.T)
+	}{
+		{
+			desc: "Too few arguments",
+			row:  row,
+			dest: []interface{}{&name},
+			err:  true,
+		},
+		{
+			desc: "Non-pointer argument",
+			row:  row,
+			dest: []interface{}{name, &count},
+			err:  true,
+		},
+		{
+			desc: "Type mismatch",
+			row:  row,
+			dest: []interface{}{&name, &real},
+			err:  true,
+		},
+		{
+			desc: "Null into non-pointer type",
+			row:  nullRow,
+			dest: []interface{}{&name, &count},
+			err:  true,
+		},
+		{
+			desc: "Success",
+			row:  row,
+			dest: []interface{}{&name, &count},
+			verify: func(t *testing.T) {
+				assert.Equal(t, "hello", name)
+				assert.Equal(t, int64(10), count)
+			},
+		},
+		{
+			desc: "Success with nil to skip a column",
+			row:  row,
+			dest: []interface{}{nil, &countPtr},
+			verify: func(t *testing.T) {
+				if assert.NotNil(t, countPtr) {
+					assert.Equal(t, int64(10), *countPtr)
+				}
+			},
+		},
+		{
+			desc: "Success with null into pointer",
+			row:  nullRow,
+			dest: []interface{}{nil, &countPtr},
+			verify: func(t *testing.T) {
+				assert.Nil(t, countPtr)
+			},
+		},
+		{
+			desc: "Success with null into value.Long",
+			row:  nullRow,
+			dest: []interface{}{nil, &countVal},
+			verify: func(t *testing.T) {
+				assert.Equal(t, value.Long{}, countVal)
+			},
+		},
+	}
+
+	// Tests are not run in parallel, as they share the destination variables.
+	for _, test := range tests {
+		err := test.row.Scan(test.dest...)
+		switch {
+		case err == nil && test.err:
+			t.Errorf("TestRowScan(%s): got err == nil, want err != nil", test.desc)
+			continue
+		case err != nil && !test.err:
+			t.Errorf("TestRowScan(%s): got err == %s, want err == nil", test.desc, err)
+			continue
+		case err != nil:
+			continue
+		}
+		test.verify(t)
+	}
+}
+
+func TestRowValue(t *testing.T) {
+	t.Parallel()
+
+	cols := Columns{
+		{Name: "Name", Type: types.String},
+		{Name: "Count", Type: types.Long},
+		{Name: "Name", Type: types.String},
+	}
+	index := cols.Index()
+	assert.Equal(t, ColumnIndex{"Name": 0, "Count": 1}, index)
+
+	values := value.Values{
+		value.String{Value: "first", Valid: true},
+		value.Long{Value: 10, Valid: true},
+		value.String{Value: "second", Valid: true},
+	}
+
+	tests := []struct {
+		desc   string
+		row    *Row
+		column string
+		want   value.Kusto
+		found  bool
+	}{
+		{desc: "Shared index", row: NewRow(cols, index, values), column: "Count", want: value.Long{Value: 10, Valid: true}, found: true},
+		{desc: "Index built on use", row: &Row{ColumnTypes: cols, Values: values}, column: "Count", want: value.Long{Value: 10, Valid: true}, found: true},
+		{desc: "Duplicate name returns the first", row: &Row{ColumnTypes: cols, Values: values}, column: "Name", want: value.String{Value: "first", Valid: true}, found: true},
+		{desc: "Names are case sensitive", row: &Row{ColumnTypes: cols, Values: values}, column: "count"},
+		{desc: "Missing column", row: &Row{ColumnTypes: cols, Values: values}, column: "Other"},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, found := test.row.Value(test.column)
+			assert.Equal(t, test.found, found)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
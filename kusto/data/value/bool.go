@@ -26,6 +26,14 @@ func (bo Bool) String() string {
 	return "false"
 }
 
+// Format implements Kusto.Format.
+func (bo Bool) Format(opts FormatOptions) string {
+	if !bo.Valid {
+		return opts.Null
+	}
+	return opts.format(bo.String())
+}
+
 // Unmarshal unmarshals i into Bool. i must be a bool or nil.
 func (bo *Bool) Unmarshal(i interface{}) error {
 	if i == nil {
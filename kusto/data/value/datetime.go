@@ -22,6 +22,22 @@ func (d DateTime) String() string {
 	return fmt.Sprint(d.Value.Format(time.RFC3339Nano))
 }
 
+// Format implements Kusto.Format.
+func (d DateTime) Format(opts FormatOptions) string {
+	if !d.Valid {
+		return opts.Null
+	}
+	t := d.Value
+	if opts.Location != nil {
+		t = t.In(opts.Location)
+	}
+	layout := opts.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	return opts.format(t.Format(layout))
+}
+
 func (DateTime) isKustoVal() {}
 
 // Marshal marshals the DateTime into a Kusto compatible string.
@@ -27,6 +27,14 @@ func (d Decimal) String() string {
 	return d.Value
 }
 
+// Format implements Kusto.Format.
+func (d Decimal) Format(opts FormatOptions) string {
+	if !d.Valid {
+		return opts.Null
+	}
+	return opts.format(d.Value)
+}
+
 // ParseFloat provides builtin support for Go's *big.Float conversion where that type meets your needs.
 func (d *Decimal) ParseFloat(base int, prec uint, mode big.RoundingMode) (f *big.Float, b int, err error) {
 	return big.ParseFloat(d.Value, base, prec, mode)
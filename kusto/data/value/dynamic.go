@@ -1,6 +1,7 @@
 package value
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -25,6 +26,21 @@ func (d Dynamic) String() string {
 	return string(d.Value)
 }
 
+// Format implements Kusto.Format.
+func (d Dynamic) Format(opts FormatOptions) string {
+	if !d.Valid {
+		return opts.Null
+	}
+	if opts.DynamicIndent == "" {
+		return opts.format(string(d.Value))
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, d.Value, "", opts.DynamicIndent); err != nil {
+		return opts.format(string(d.Value))
+	}
+	return opts.format(buf.String())
+}
+
 // Unmarshal unmarshal's i into Dynamic. i must be a string, []byte, map[string]interface{}, []interface{}, other JSON serializable value or nil.
 // If []byte or string, must be a JSON representation of a value.
 func (d *Dynamic) Unmarshal(i interface{}) error {
@@ -0,0 +1,250 @@
+package value
+
+// dynamic_path.go holds Dynamic.Get(), which extracts a nested value from the JSON of a Dynamic by scanning it.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Get returns the value at path in the JSON of d, without unmarshaling the rest of the JSON. path is a list of object
+// keys and array indexes separated by dots, like "a.b.0", which is the first element of the array at key "b" of the
+// object at key "a". A numeric segment is an index for arrays and a key for objects. An empty path returns d.
+// The returned Dynamic shares its memory with d. If d is not valid or path does not exist, the returned Dynamic is not
+// valid. A JSON null at path is returned as a valid Dynamic holding "null". An error is returned if the JSON that is
+// scanned is not valid.
+func (d Dynamic) Get(path string) (Dynamic, error) {
+	if !d.Valid {
+		return Dynamic{}, nil
+	}
+
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, ".")
+	}
+
+	s := &dynamicScanner{data: d.Value}
+	s.skipSpace()
+	for _, segment := range segments {
+		found, err := s.enter(segment)
+		if err != nil {
+			return Dynamic{}, fmt.Errorf("Dynamic.Get(%q): %s", path, err)
+		}
+		if !found {
+			return Dynamic{}, nil
+		}
+	}
+
+	start := s.pos
+	if err := s.skipValue(); err != nil {
+		return Dynamic{}, fmt.Errorf("Dynamic.Get(%q): %s", path, err)
+	}
+	return Dynamic{Value: s.data[start:s.pos], Valid: true}, nil
+}
+
+// dynamicScanner scans JSON without decoding the values it skips.
+type dynamicScanner struct {
+	data []byte
+	pos  int
+}
+
+// enter moves the scanner from the start of an object or array to the start of its value at segment. It returns false
+// if the value does not have segment.
+func (s *dynamicScanner) enter(segment string) (bool, error) {
+	if s.pos >= len(s.data) {
+		return false, s.errorf("unexpected end of JSON")
+	}
+
+	switch s.data[s.pos] {
+	case '{':
+		return s.enterObject(segment)
+	case '[':
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 {
+			return false, nil
+		}
+		return s.enterArray(index)
+	}
+	return false, nil
+}
+
+func (s *dynamicScanner) enterObject(key string) (bool, error) {
+	s.pos++ // '{'
+	s.skipSpace()
+	if s.consume('}') {
+		return false, nil
+	}
+
+	for {
+		k, err := s.readKey()
+		if err != nil {
+			return false, err
+		}
+		s.skipSpace()
+		if !s.consume(':') {
+			return false, s.errorf("expected ':' after object key")
+		}
+		s.skipSpace()
+		if k == key {
+			return true, nil
+		}
+		if err := s.skipValue(); err != nil {
+			return false, err
+		}
+		s.skipSpace()
+		switch {
+		case s.consume(','):
+			s.skipSpace()
+		case s.consume('}'):
+			return false, nil
+		default:
+			return false, s.errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+func (s *dynamicScanner) enterArray(index int) (bool, error) {
+	s.pos++ // '['
+	s.skipSpace()
+	if s.consume(']') {
+		return false, nil
+	}
+
+	for i := 0; ; i++ {
+		if i == index {
+			return true, nil
+		}
+		if err := s.skipValue(); err != nil {
+			return false, err
+		}
+		s.skipSpace()
+		switch {
+		case s.consume(','):
+			s.skipSpace()
+		case s.consume(']'):
+			return false, nil
+		default:
+			return false, s.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// readKey reads an object key. Keys without escapes are not copied to be decoded.
+func (s *dynamicScanner) readKey() (string, error) {
+	start := s.pos
+	if err := s.skipString(); err != nil {
+		return "", err
+	}
+	raw := s.data[start:s.pos]
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", s.errorf("invalid object key: %s", err)
+	}
+	return key, nil
+}
+
+// skipValue moves the scanner past the value at its position.
+func (s *dynamicScanner) skipValue() error {
+	if s.pos >= len(s.data) {
+		return s.errorf("unexpected end of JSON")
+	}
+
+	switch c := s.data[s.pos]; {
+	case c == '"':
+		return s.skipString()
+	case c == '{' || c == '[':
+		return s.skipContainer()
+	case c == '-' || (c >= '0' && c <= '9') || c == 't' || c == 'f' || c == 'n':
+		start := s.pos
+		for s.pos < len(s.data) && !isJSONDelimiter(s.data[s.pos]) {
+			s.pos++
+		}
+		literal := s.data[start:s.pos]
+		if c >= 'a' && c <= 'z' && !(string(literal) == "true" || string(literal) == "false" || string(literal) == "null") {
+			return s.errorf("invalid literal %q", literal)
+		}
+		return nil
+	}
+	return s.errorf("unexpected character %q", s.data[s.pos])
+}
+
+// skipContainer moves the scanner past the object or array at its position, tracking only the nesting depth.
+func (s *dynamicScanner) skipContainer() error {
+	depth := 0
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '"':
+			if err := s.skipString(); err != nil {
+				return err
+			}
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				s.pos++
+				return nil
+			}
+		}
+		s.pos++
+	}
+	return s.errorf("unexpected end of JSON")
+}
+
+// skipString moves the scanner past the string at its position.
+func (s *dynamicScanner) skipString() error {
+	if !s.consume('"') {
+		return s.errorf("expected a string")
+	}
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+			continue
+		case '"':
+			s.pos++
+			return nil
+		}
+		s.pos++
+	}
+	return s.errorf("unexpected end of JSON in string")
+}
+
+func (s *dynamicScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *dynamicScanner) consume(c byte) bool {
+	if s.pos < len(s.data) && s.data[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *dynamicScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSON at offset %d: %s", s.pos, fmt.Sprintf(format, args...))
+}
+
+func isJSONDelimiter(c byte) bool {
+	switch c {
+	case ',', '}', ']', ' ', '\t', '\n', '\r':
+		return true
+	}
+	return false
+}
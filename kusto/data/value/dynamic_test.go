@@ -210,3 +210,54 @@ func TestDynamicConverterNegative(t *testing.T) {
 
 	}
 }
+
+func TestDynamicGet(t *testing.T) {
+	t.Parallel()
+
+	doc := value.Dynamic{
+		Value: []byte(` {"a": {"b": [10, {"c": "x,]}"}, null], "skip": {"d": [1, [2, "]"]]}}, "e\"q": true, "0": "zero", "f": -1.5e3} `),
+		Valid: true,
+	}
+
+	tests := []struct {
+		desc  string
+		value value.Dynamic
+		path  string
+		want  string
+		valid bool
+		err   bool
+	}{
+		{desc: "Array element", value: doc, path: "a.b.0", want: "10", valid: true},
+		{desc: "Object in array", value: doc, path: "a.b.1.c", want: `"x,]}"`, valid: true},
+		{desc: "JSON null", value: doc, path: "a.b.2", want: "null", valid: true},
+		{desc: "Container", value: doc, path: "a.b.1", want: `{"c": "x,]}"}`, valid: true},
+		{desc: "Key after skipped value", value: doc, path: "f", want: "-1.5e3", valid: true},
+		{desc: "Escaped key", value: doc, path: `e"q`, want: "true", valid: true},
+		{desc: "Numeric object key", value: doc, path: "0", want: `"zero"`, valid: true},
+		{desc: "Empty path", value: value.Dynamic{Value: []byte(`[1]`), Valid: true}, path: "", want: "[1]", valid: true},
+		{desc: "Missing key", value: doc, path: "a.x"},
+		{desc: "Index out of range", value: doc, path: "a.b.3"},
+		{desc: "Non-numeric index", value: doc, path: "a.b.x"},
+		{desc: "Path into scalar", value: doc, path: "f.g"},
+		{desc: "Not valid", value: value.Dynamic{}, path: "a"},
+		{desc: "Invalid JSON", value: value.Dynamic{Value: []byte(`{"a" 1}`), Valid: true}, path: "a", err: true},
+		{desc: "Truncated JSON", value: value.Dynamic{Value: []byte(`{"a": [1, 2`), Valid: true}, path: "b", err: true},
+		{desc: "Invalid literal", value: value.Dynamic{Value: []byte(`{"a": nope}`), Valid: true}, path: "a", err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := test.value.Get(test.path)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.valid, got.Valid)
+			assert.Equal(t, test.want, got.String())
+		})
+	}
+}
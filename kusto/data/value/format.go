@@ -0,0 +1,37 @@
+package value
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// FormatOptions are the options of Kusto.Format(), which formats values for display. The zero value formats values
+// like String() does, except for Real values, which use exponent notation only for large and small exponents, like
+// strconv.FormatFloat() with 'g', and Timespan values, which are in the Kusto format, like 1.02:03:04, as Marshal()
+// returns them.
+type FormatOptions struct {
+	// Null is the text of null values. The default is the empty string.
+	Null string
+	// TimeLayout is the layout of DateTime values, as used by time.Time.Format(). The default is time.RFC3339Nano.
+	TimeLayout string
+	// Location is the time zone DateTime values are shown in, like time.Local. The service returns UTC times, which
+	// are shown as UTC if Location is nil.
+	Location *time.Location
+	// HumanTimespan formats Timespan values like "1d2h30m15.5s", instead of the Kusto format "1.02:30:15.5".
+	HumanTimespan bool
+	// DynamicIndent pretty prints Dynamic values on multiple lines, indented with DynamicIndent, like "  ". The
+	// default prints them as they were returned by the service.
+	DynamicIndent string
+	// MaxWidth is the maximum number of characters of a value. Longer values are cut and end with "…", so that they
+	// are MaxWidth characters long. Values of 0 or less do not truncate. The Null text is not truncated.
+	MaxWidth int
+}
+
+// format returns s, truncated to opts.MaxWidth.
+func (opts FormatOptions) format(s string) string {
+	if opts.MaxWidth <= 0 || utf8.RuneCountInString(s) <= opts.MaxWidth {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:opts.MaxWidth-1]) + "…"
+}
@@ -0,0 +1,71 @@
+package value
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	when := time.Date(2021, 4, 26, 15, 21, 50, 500000000, time.UTC)
+	pst := time.FixedZone("PST", -8*60*60)
+
+	tests := []struct {
+		desc  string
+		value Kusto
+		opts  FormatOptions
+		want  string
+	}{
+		{desc: "Null", value: Long{}, opts: FormatOptions{Null: "<null>"}, want: "<null>"},
+		{desc: "Null is not truncated", value: String{}, opts: FormatOptions{Null: "<null>", MaxWidth: 2}, want: "<null>"},
+		{desc: "Bool", value: Bool{Value: true, Valid: true}, want: "true"},
+		{desc: "Int", value: Int{Value: -3, Valid: true}, want: "-3"},
+		{desc: "Long", value: Long{Value: 1234567890123, Valid: true}, want: "1234567890123"},
+		{desc: "Real", value: Real{Value: 1.5, Valid: true}, want: "1.5"},
+		{desc: "Decimal", value: Decimal{Value: "1.10", Valid: true}, want: "1.10"},
+		{desc: "String", value: String{Value: "hello", Valid: true}, want: "hello"},
+		{desc: "String truncated", value: String{Value: "héllo world", Valid: true}, opts: FormatOptions{MaxWidth: 5}, want: "héll…"},
+		{desc: "String that fits", value: String{Value: "hello", Valid: true}, opts: FormatOptions{MaxWidth: 5}, want: "hello"},
+		{
+			desc:  "GUID",
+			value: GUID{Value: uuid.MustParse("c7b2c83a-2d9e-4b6b-9d2d-1c9f0f4e2a11"), Valid: true},
+			want:  "c7b2c83a-2d9e-4b6b-9d2d-1c9f0f4e2a11",
+		},
+		{desc: "DateTime", value: DateTime{Value: when, Valid: true}, want: "2021-04-26T15:21:50.5Z"},
+		{
+			desc:  "DateTime localized",
+			value: DateTime{Value: when, Valid: true},
+			opts:  FormatOptions{TimeLayout: "2006-01-02 15:04:05 MST", Location: pst},
+			want:  "2021-04-26 07:21:50 PST",
+		},
+		{desc: "Timespan", value: Timespan{Value: 26*time.Hour + 30*time.Minute + 1500*time.Millisecond, Valid: true}, want: "1.02:30:01.5"},
+		{
+			desc:  "Timespan human",
+			value: Timespan{Value: 26*time.Hour + 30*time.Minute + 1500*time.Millisecond, Valid: true},
+			opts:  FormatOptions{HumanTimespan: true},
+			want:  "1d2h30m1.5s",
+		},
+		{desc: "Timespan human days", value: Timespan{Value: -48 * time.Hour, Valid: true}, opts: FormatOptions{HumanTimespan: true}, want: "-2d"},
+		{desc: "Timespan human short", value: Timespan{Value: 90 * time.Second, Valid: true}, opts: FormatOptions{HumanTimespan: true}, want: "1m30s"},
+		{desc: "Dynamic", value: Dynamic{Value: []byte(`{"a":[1,2]}`), Valid: true}, want: `{"a":[1,2]}`},
+		{
+			desc:  "Dynamic indented",
+			value: Dynamic{Value: []byte(`{"a":[1,2]}`), Valid: true},
+			opts:  FormatOptions{DynamicIndent: "  "},
+			want:  "{\n  \"a\": [\n    1,\n    2\n  ]\n}",
+		},
+		{desc: "Dynamic truncated", value: Dynamic{Value: []byte(`{"a":[1,2]}`), Valid: true}, opts: FormatOptions{MaxWidth: 6}, want: `{"a":…`},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, test.value.Format(test.opts))
+		})
+	}
+}
@@ -25,6 +25,14 @@ func (g GUID) String() string {
 	return g.Value.String()
 }
 
+// Format implements Kusto.Format.
+func (g GUID) Format(opts FormatOptions) string {
+	if !g.Valid {
+		return opts.Null
+	}
+	return opts.format(g.Value.String())
+}
+
 // Unmarshal unmarshals i into GUID. i must be a string representing a GUID or nil.
 func (g *GUID) Unmarshal(i interface{}) error {
 	if i == nil {
@@ -26,6 +26,14 @@ func (in Int) String() string {
 	return strconv.Itoa(int(in.Value))
 }
 
+// Format implements Kusto.Format.
+func (in Int) Format(opts FormatOptions) string {
+	if !in.Valid {
+		return opts.Null
+	}
+	return opts.format(in.String())
+}
+
 // Unmarshal unmarshals i into Int. i must be an int32 or nil.
 func (in *Int) Unmarshal(i interface{}) error {
 	if i == nil {
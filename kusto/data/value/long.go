@@ -26,6 +26,14 @@ func (l Long) String() string {
 	return strconv.Itoa(int(l.Value))
 }
 
+// Format implements Kusto.Format.
+func (l Long) Format(opts FormatOptions) string {
+	if !l.Valid {
+		return opts.Null
+	}
+	return opts.format(l.String())
+}
+
 // Unmarshal unmarshals i into Long. i must be an int64 or nil.
 func (l *Long) Unmarshal(i interface{}) error {
 	if i == nil {
@@ -71,7 +79,6 @@ func (l Long) Convert(v reflect.Value) error {
 	case t.ConvertibleTo(reflect.TypeOf(new(int64))):
 		if l.Valid {
 			i := &l.Value
-			fmt.Println(i)
 			v.Set(reflect.ValueOf(i))
 		}
 		return nil
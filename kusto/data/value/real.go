@@ -25,6 +25,14 @@ func (r Real) String() string {
 	return strconv.FormatFloat(r.Value, 'e', -1, 64)
 }
 
+// Format implements Kusto.Format.
+func (r Real) Format(opts FormatOptions) string {
+	if !r.Valid {
+		return opts.Null
+	}
+	return opts.format(strconv.FormatFloat(r.Value, 'g', -1, 64))
+}
+
 // Unmarshal unmarshals i into Real. i must be a json.Number(that is a float64), float64 or nil.
 func (r *Real) Unmarshal(i interface{}) error {
 	if i == nil {
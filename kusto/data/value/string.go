@@ -23,6 +23,14 @@ func (s String) String() string {
 	return s.Value
 }
 
+// Format implements Kusto.Format.
+func (s String) Format(opts FormatOptions) string {
+	if !s.Valid {
+		return opts.Null
+	}
+	return opts.format(s.Value)
+}
+
 // Unmarshal unmarshals i into String. i must be a string or nil.
 func (s *String) Unmarshal(i interface{}) error {
 	if i == nil {
@@ -28,6 +28,31 @@ func (t Timespan) String() string {
 	return t.Value.String()
 }
 
+// Format implements Kusto.Format.
+func (t Timespan) Format(opts FormatOptions) string {
+	if !t.Valid {
+		return opts.Null
+	}
+	if !opts.HumanTimespan {
+		return opts.format(t.Marshal())
+	}
+
+	const day = 24 * time.Hour
+	d, sign := t.Value, ""
+	if d < 0 {
+		d, sign = -d, "-"
+	}
+	days := d / day
+	d -= days * day
+	switch {
+	case days == 0:
+		return opts.format(sign + d.String())
+	case d == 0:
+		return opts.format(fmt.Sprintf("%s%dd", sign, days))
+	}
+	return opts.format(fmt.Sprintf("%s%dd%s", sign, days, d))
+}
+
 // Marshal marshals the Timespan into a Kusto compatible string. The string is the contant invariant(c)
 // format. See https://docs.microsoft.com/en-us/dotnet/standard/base-types/standard-timespan-format-strings .
 func (t Timespan) Marshal() string {
@@ -75,15 +100,11 @@ func (t Timespan) Marshal() string {
 	ticks := val / tick
 	if milliseconds > 0 || ticks > 0 {
 		sb.WriteString(fmt.Sprintf(".%03d%d", milliseconds, ticks))
+		// Remove any trailing 0's of the sub-second section.
+		return strings.TrimRight(sb.String(), "0")
 	}
 
-	// Remove any trailing 0's.
-	str := strings.TrimRight(sb.String(), "0")
-	if strings.HasSuffix(str, ":") {
-		str = str + "00"
-	}
-
-	return str
+	return sb.String()
 }
 
 // Unmarshal unmarshals i into Timespan. i must be a string representing a Values timespan or nil.
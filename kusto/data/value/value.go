@@ -26,9 +26,10 @@ Each type defined above has at minimum two fields:
 	.Value - The type specific value
 	.Valid - True if the value was non-null in the Kusto table
 
-Each provides at minimum the following two methods:
+Each provides at minimum the following methods:
 
 	.String() - Returns the string representation of the value.
+	.Format() - Returns the value formatted for display, like in a table.
 	.Unmarshal() - Unmarshals the value into a standard Go type.
 
 The Unmarshal() is for internal use, it should not be needed by an end user. Use .Value or table.Row.ToStruct() instead.
@@ -44,6 +45,8 @@ type Kusto interface {
 	String() string
 	// Convert into reflect value.
 	Convert(v reflect.Value) error
+	// Format returns the value formatted for display with opts.
+	Format(opts FormatOptions) string
 }
 
 // Values is a list of Kusto values, usually an ordered row.
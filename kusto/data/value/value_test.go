@@ -520,6 +520,32 @@ func TestTimespan(t *testing.T) {
 	}
 }
 
+func TestTimespanMarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		in   Timespan
+		want string
+	}{
+		{desc: "Invalid", in: Timespan{}, want: "00:00:00"},
+		{desc: "Zero", in: Timespan{Valid: true}, want: "00:00:00"},
+		{desc: "Seconds ending in 0", in: Timespan{Value: 30 * time.Second, Valid: true}, want: "00:00:30"},
+		{desc: "Minutes ending in 0", in: Timespan{Value: 20 * time.Minute, Valid: true}, want: "00:20:00"},
+		{desc: "Days", in: Timespan{Value: 10*day + 10*time.Hour, Valid: true}, want: "10.10:00:00"},
+		{desc: "Milliseconds", in: Timespan{Value: 10*time.Second + 500*time.Millisecond, Valid: true}, want: "00:00:10.5"},
+		{desc: "Negative", in: Timespan{Value: -40 * time.Second, Valid: true}, want: "-00:00:40"},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, test.in.Marshal())
+		})
+	}
+}
+
 func removeLeadingZeros(s string) string {
 	if len(s) == 0 {
 		return s
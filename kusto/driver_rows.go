@@ -0,0 +1,141 @@
+package kusto
+
+// driver_rows.go holds DriverRows, an adapter from RowIterator to the database/sql/driver.Rows interface.
+
+import (
+	"database/sql/driver"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+)
+
+// DriverRows adapts a RowIterator to the database/sql/driver.Rows interface, so that code written to read
+// database/sql results can read Kusto results. Kusto values are converted to driver.Value as follows:
+//
+//	bool: bool
+//	int, long: int64
+//	real: float64
+//	datetime: time.Time
+//	string: string
+//	decimal: string holding the decimal number
+//	guid: string in the standard 36 character form
+//	timespan: string in the Kusto timespan format, like "1.02:03:04.5"
+//	dynamic: []byte holding the JSON value
+//
+// Null values of any type are converted to nil.
+// Inline errors stop the iteration and are returned by Next().
+type DriverRows struct {
+	iter    *RowIterator
+	columns table.Columns
+}
+
+// NewDriverRows returns a DriverRows reading from iter. Close() stops iter.
+func NewDriverRows(iter *RowIterator) *DriverRows {
+	columns := iter.columns
+	if iter.mock != nil {
+		columns = iter.mock.columns
+	}
+	return &DriverRows{iter: iter, columns: columns}
+}
+
+// Columns implements driver.Rows.Columns().
+func (d *DriverRows) Columns() []string {
+	names := make([]string, len(d.columns))
+	for i, c := range d.columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName. It returns the Kusto type of the column,
+// such as "long".
+func (d *DriverRows) ColumnTypeDatabaseTypeName(index int) string {
+	return string(d.columns[index].Type)
+}
+
+// Next implements driver.Rows.Next(). It returns io.EOF when there are no more rows.
+func (d *DriverRows) Next(dest []driver.Value) error {
+	row, inlineErr, err := d.iter.NextRowOrError()
+	switch {
+	case err != nil:
+		return err
+	case inlineErr != nil:
+		return inlineErr
+	}
+
+	if len(dest) != len(row.Values) {
+		return errors.ES(d.iter.op, errors.KClientArgs, "Next() received %d destinations for %d columns", len(dest), len(row.Values)).SetNoRetry()
+	}
+	for i, v := range row.Values {
+		dv, err := driverValue(v)
+		if err != nil {
+			return err
+		}
+		dest[i] = dv
+	}
+	return nil
+}
+
+// Close implements driver.Rows.Close(). It stops the underlying RowIterator.
+func (d *DriverRows) Close() error {
+	d.iter.Stop()
+	return nil
+}
+
+// driverValue converts a Kusto value to a driver.Value.
+func driverValue(v value.Kusto) (driver.Value, error) {
+	switch t := v.(type) {
+	case value.Bool:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Int:
+		if !t.Valid {
+			return nil, nil
+		}
+		return int64(t.Value), nil
+	case value.Long:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Real:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Decimal:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.String:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.DateTime:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Timespan:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Marshal(), nil
+	case value.GUID:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value.String(), nil
+	case value.Dynamic:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	}
+	return nil, errors.ES(errors.OpQuery, errors.KInternal, "value of type %T cannot be converted to a driver.Value", v)
+}
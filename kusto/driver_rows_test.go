@@ -0,0 +1,88 @@
+package kusto
+
+import (
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriverRows(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	id := uuid.New()
+
+	columns := table.Columns{
+		{Name: "Bool", Type: types.Bool},
+		{Name: "Int", Type: types.Int},
+		{Name: "Long", Type: types.Long},
+		{Name: "Real", Type: types.Real},
+		{Name: "Decimal", Type: types.Decimal},
+		{Name: "String", Type: types.String},
+		{Name: "DateTime", Type: types.DateTime},
+		{Name: "Timespan", Type: types.Timespan},
+		{Name: "GUID", Type: types.GUID},
+		{Name: "Dynamic", Type: types.Dynamic},
+	}
+
+	m, err := NewMockRows(columns)
+	require.NoError(t, err)
+	require.NoError(t, m.Row(value.Values{
+		value.Bool{Value: true, Valid: true},
+		value.Int{Value: 1, Valid: true},
+		value.Long{Value: 2, Valid: true},
+		value.Real{Value: 3.5, Valid: true},
+		value.Decimal{Value: "4.25", Valid: true},
+		value.String{Value: "five", Valid: true},
+		value.DateTime{Value: now, Valid: true},
+		value.Timespan{Value: 90 * time.Second, Valid: true},
+		value.GUID{Value: id, Valid: true},
+		value.Dynamic{Value: []byte(`{"a":1}`), Valid: true},
+	}))
+	require.NoError(t, m.Row(value.Values{
+		value.Bool{}, value.Int{}, value.Long{}, value.Real{}, value.Decimal{},
+		value.String{}, value.DateTime{}, value.Timespan{}, value.GUID{}, value.Dynamic{},
+	}))
+
+	iter := &RowIterator{}
+	require.NoError(t, iter.Mock(m))
+
+	rows := NewDriverRows(iter)
+	defer rows.Close()
+
+	assert.Equal(t, []string{"Bool", "Int", "Long", "Real", "Decimal", "String", "DateTime", "Timespan", "GUID", "Dynamic"}, rows.Columns())
+	assert.Equal(t, "timespan", rows.ColumnTypeDatabaseTypeName(7))
+
+	dest := make([]driver.Value, len(columns))
+	require.NoError(t, rows.Next(dest))
+	assert.Equal(t, []driver.Value{true, int64(1), int64(2), 3.5, "4.25", "five", now, "00:01:30", id.String(), []byte(`{"a":1}`)}, dest)
+
+	require.NoError(t, rows.Next(dest))
+	assert.Equal(t, make([]driver.Value, len(columns)), dest)
+
+	assert.Equal(t, io.EOF, rows.Next(dest))
+}
+
+func TestDriverRowsWrongDestinations(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewMockRows(table.Columns{{Name: "Long", Type: types.Long}})
+	require.NoError(t, err)
+	require.NoError(t, m.Row(value.Values{value.Long{Value: 1, Valid: true}}))
+
+	iter := &RowIterator{}
+	require.NoError(t, iter.Mock(m))
+
+	rows := NewDriverRows(iter)
+	defer rows.Close()
+
+	assert.Error(t, rows.Next(make([]driver.Value, 2)))
+}
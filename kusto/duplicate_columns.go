@@ -0,0 +1,29 @@
+package kusto
+
+// duplicate_columns.go holds the options that control how the columns of a query result with the same name are
+// handled.
+
+import (
+	"github.com/Azure/azure-kusto-go/kusto/internal/frames"
+)
+
+// ErrorOnDuplicateColumns makes the query fail with an error if a table of its result has more than one column with the
+// same name. By default such columns are returned as they are, so table.Row.Value() returns the first of them while
+// table.Row.ToStruct() sets the field of the name to the last of them.
+// The service makes the names of most columns unique, like the columns of both sides of a join, but not all.
+func ErrorOnDuplicateColumns() QueryOption {
+	return func(q *queryOptions) error {
+		q.requestProperties.duplicateColumns = frames.DuplicateColumnsError
+		return nil
+	}
+}
+
+// RenameDuplicateColumns makes the columns of the query result with the same name unique by adding the lowest number
+// that makes the name unique to every one of them after the first, like "Name", "Name1" and "Name2", so that they can
+// be read by name. See ErrorOnDuplicateColumns() for the default.
+func RenameDuplicateColumns() QueryOption {
+	return func(q *queryOptions) error {
+		q.requestProperties.duplicateColumns = frames.DuplicateColumnsRename
+		return nil
+	}
+}
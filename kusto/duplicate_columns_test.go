@@ -0,0 +1,67 @@
+package kusto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateColumns(t *testing.T) {
+	t.Parallel()
+
+	const response = `[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[
+{"ColumnName":"Name","ColumnType":"string"},{"ColumnName":"Name1","ColumnType":"string"},{"ColumnName":"Name","ColumnType":"long"},{"ColumnName":"Name","ColumnType":"string"}],
+"Rows":[["a","b",1,"c"]]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`
+
+	tests := []struct {
+		desc    string
+		options []QueryOption
+		want    table.Columns
+		err     bool
+	}{
+		{
+			desc: "Kept by default",
+			want: table.Columns{{Name: "Name", Type: types.String}, {Name: "Name1", Type: types.String}, {Name: "Name", Type: types.Long}, {Name: "Name", Type: types.String}},
+		},
+		{
+			desc:    "Renamed",
+			options: []QueryOption{RenameDuplicateColumns()},
+			want:    table.Columns{{Name: "Name", Type: types.String}, {Name: "Name1", Type: types.String}, {Name: "Name2", Type: types.Long}, {Name: "Name3", Type: types.String}},
+		},
+		{
+			desc:    "Error",
+			options: []QueryOption{ErrorOnDuplicateColumns()},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) { return response, nil }}}
+
+			iter, err := client.Query(context.Background(), "db", NewStmt("T"), test.options...)
+			var row *table.Row
+			if err == nil {
+				defer iter.Stop()
+				row, _, err = iter.NextRowOrError()
+			}
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, row.ColumnTypes)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package kusto
+
+// endpoint_resolver.go holds the option that sends requests to a different address than the cluster endpoint, such as
+// a private endpoint.
+
+import (
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// WithEndpointResolver sets resolve, which maps the endpoint passed to New(), like
+// "https://mycluster.westus.kusto.windows.net", to the endpoint requests are sent to, like the address of a private
+// endpoint of the cluster. Authentication still uses the endpoint passed to New(), so tokens are requested for the
+// public cluster name as private link requires. resolve is called by New() for the endpoint and, if
+// IngestionEndpoint() is used, on the first management command sent to the "ingest-" endpoint; an error it returns is
+// returned by those calls. The resolved endpoint is what ResolvedEndpoint() returns and is also used by the streaming
+// ingestion of the ingest package. To change the address the host name resolves to instead of the host name, use
+// WithHttpClient() with a client whose transport dials the private address.
+func WithEndpointResolver(resolve func(cluster string) (string, error)) Option {
+	return func(c *Client) {
+		c.endpointResolver = resolve
+	}
+}
+
+// ResolvedEndpoint returns the endpoint requests are sent to, which is Endpoint() unless WithEndpointResolver() was
+// used.
+func (c *Client) ResolvedEndpoint() string {
+	if c.resolvedEndpoint == "" {
+		return c.endpoint
+	}
+	return c.resolvedEndpoint
+}
+
+// resolveEndpoint returns the endpoint requests for endpoint are sent to.
+func (c *Client) resolveEndpoint(endpoint string) (string, error) {
+	if c.endpointResolver == nil {
+		return endpoint, nil
+	}
+	resolved, err := c.endpointResolver(endpoint)
+	if err != nil {
+		return "", errors.ES(errors.OpServConn, errors.KClientArgs, "the endpoint resolver could not resolve endpoint(%s): %s", endpoint, err).SetNoRetry()
+	}
+	return resolved, nil
+}
@@ -0,0 +1,71 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEndpointResolver(t *testing.T) {
+	t.Parallel()
+
+	const (
+		endpoint       = "https://mycluster.westus.kusto.windows.net"
+		ingestEndpoint = "https://ingest-mycluster.westus.kusto.windows.net"
+	)
+
+	mu := sync.Mutex{}
+	var paths []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/rest/mgmt" {
+			fmt.Fprint(w, tablesResponse("table"))
+			return
+		}
+		fmt.Fprint(w, splitResponse("bucket"))
+	}))
+	defer server.Close()
+
+	var resolved []string
+	resolve := func(cluster string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		resolved = append(resolved, cluster)
+		switch cluster {
+		case endpoint, ingestEndpoint:
+			return server.URL, nil
+		}
+		return "", fmt.Errorf("unknown cluster %s", cluster)
+	}
+
+	client, err := New(endpoint, Authorization{Authorizer: autorest.NewBasicAuthorizer("", "")}, WithHttpClient(server.Client()), WithEndpointResolver(resolve))
+	require.NoError(t, err)
+	assert.Equal(t, endpoint, client.Endpoint())
+	assert.Equal(t, server.URL, client.ResolvedEndpoint())
+
+	iter, err := client.Query(context.Background(), "db", NewStmt("MyTable"))
+	require.NoError(t, err)
+	require.NoError(t, iter.Do(func(*table.Row) error { return nil }))
+
+	iter, err = client.Mgmt(context.Background(), "db", NewStmt(".show tables"), IngestionEndpoint())
+	require.NoError(t, err)
+	require.NoError(t, iter.Do(func(*table.Row) error { return nil }))
+
+	assert.Equal(t, []string{endpoint, ingestEndpoint}, resolved)
+	assert.Equal(t, []string{"/v2/rest/query", "/v1/rest/mgmt"}, paths)
+
+	_, err = New("https://other.kusto.windows.net", Authorization{Authorizer: autorest.NewBasicAuthorizer("", "")}, WithEndpointResolver(resolve))
+	assert.Error(t, err)
+}
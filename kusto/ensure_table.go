@@ -0,0 +1,140 @@
+package kusto
+
+// ensure_table.go holds EnsureTable(), which creates a table or adds the columns it is missing.
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// EnsureTable makes tableName in db have columns. If the table does not exist, it is created with columns. If it
+// exists, the columns it does not have are added with ".alter-merge table", and nothing is sent if it has all of them.
+// Existing columns are never dropped, renamed or retyped. If a column of columns exists with another type, an error is
+// returned and the table is not changed. Columns of the table that are not in columns are kept.
+func (c *Client) EnsureTable(ctx context.Context, db, tableName string, columns table.Columns) error {
+	if err := validateColumnDefs(tableName, columns); err != nil {
+		return err
+	}
+
+	existing, found, err := c.tableColumns(ctx, db, tableName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return c.mgmtNoRows(ctx, db, NewStmt(".create table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+			UnsafeAdd(kql.QuoteName(tableName)).UnsafeAdd(" "+columnDefs(columns)))
+	}
+
+	var missing table.Columns
+	var conflicts []string
+	for _, col := range columns {
+		t, ok := existing[col.Name]
+		switch {
+		case !ok:
+			missing = append(missing, col)
+		case t != col.Type:
+			conflicts = append(conflicts, col.Name+" is "+string(t)+", not "+string(col.Type))
+		}
+	}
+	if len(conflicts) > 0 {
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "table %q in database %q has columns of other types: %s", tableName, db, strings.Join(conflicts, ", ")).SetNoRetry()
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return c.mgmtNoRows(ctx, db, NewStmt(".alter-merge table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).UnsafeAdd(" "+columnDefs(missing)))
+}
+
+// validateColumnDefs returns an error if columns cannot be used to define the columns of tableName.
+func validateColumnDefs(tableName string, columns table.Columns) error {
+	if tableName == "" {
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "EnsureTable() requires a table name").SetNoRetry()
+	}
+	if len(columns) == 0 {
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "EnsureTable() requires at least one column").SetNoRetry()
+	}
+
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		switch {
+		case col.Name == "":
+			return errors.ES(errors.OpMgmt, errors.KClientArgs, "EnsureTable() was passed a column without a name").SetNoRetry()
+		case !col.Type.Valid():
+			return errors.ES(errors.OpMgmt, errors.KClientArgs, "EnsureTable() was passed column %q with type %q, which is not a valid column type", col.Name, col.Type).SetNoRetry()
+		case seen[col.Name]:
+			return errors.ES(errors.OpMgmt, errors.KClientArgs, "EnsureTable() was passed column %q more than once", col.Name).SetNoRetry()
+		}
+		seen[col.Name] = true
+	}
+	return nil
+}
+
+// columnDefs returns the column list of a ".create table" command, like "(['a']:string, ['b']:long)".
+func columnDefs(columns table.Columns) string {
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = kql.QuoteName(col.Name) + ":" + string(col.Type)
+	}
+	return "(" + strings.Join(defs, ", ") + ")"
+}
+
+// tableColumns returns the types of the columns of tableName in db by name. found is false if the table does not exist.
+func (c *Client) tableColumns(ctx context.Context, db, tableName string) (columns map[string]types.Column, found bool, err error) {
+	stmt := NewStmt(".show tables | where TableName == ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteString(tableName)).Add(" | project TableName")
+	rows := 0
+	if err := c.mgmtRows(ctx, db, stmt, func(*table.Row) error {
+		rows++
+		return nil
+	}); err != nil {
+		return nil, false, err
+	}
+	if rows == 0 {
+		return nil, false, nil
+	}
+
+	schema, err := ShowTableSchema(ctx, c, db, tableName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	columns = make(map[string]types.Column, len(schema.OrderedColumns))
+	for _, col := range schema.OrderedColumns {
+		columns[col.Name] = col.CslType
+	}
+	return columns, true, nil
+}
+
+// mgmtRows runs a Mgmt() call and calls f with each row. Inline errors are returned.
+func (c *Client) mgmtRows(ctx context.Context, db string, stmt Stmt, f func(*table.Row) error) error {
+	return mgmtRows(ctx, c, db, stmt, f)
+}
+
+// mgmtRows runs a Mgmt() call with client and calls f with each row. Inline errors are returned.
+func mgmtRows(ctx context.Context, client Mgmter, db string, stmt Stmt, f func(*table.Row) error) error {
+	iter, err := client.Mgmt(ctx, db, stmt)
+	if err != nil {
+		return err
+	}
+	defer iter.Stop()
+
+	return iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		return f(r)
+	})
+}
+
+// mgmtNoRows runs a Mgmt() call whose result is not needed.
+func (c *Client) mgmtNoRows(ctx context.Context, db string, stmt Stmt) error {
+	return c.mgmtRows(ctx, db, stmt, func(*table.Row) error { return nil })
+}
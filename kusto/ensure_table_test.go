@@ -0,0 +1,112 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaResponse returns a ".show table schema as json" response for a table with columns.
+func schemaResponse(columns string) string {
+	schema := fmt.Sprintf(`{"Name":"table","OrderedColumns":[%s]}`, columns)
+	return fmt.Sprintf(`{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"TableName","ColumnType":"string"},
+{"ColumnName":"Schema","ColumnType":"string"},
+{"ColumnName":"DatabaseName","ColumnType":"string"},
+{"ColumnName":"Folder","ColumnType":"string"},
+{"ColumnName":"DocString","ColumnType":"string"}],
+"Rows":[["table",%q,"db","",""]]}]}`, schema)
+}
+
+// tablesResponse returns a ".show tables" response with the names.
+func tablesResponse(names ...string) string {
+	var rows []string
+	for _, n := range names {
+		rows = append(rows, fmt.Sprintf("[%q]", n))
+	}
+	return fmt.Sprintf(`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"TableName","ColumnType":"string"}],"Rows":[%s]}]}`, strings.Join(rows, ","))
+}
+
+func TestEnsureTable(t *testing.T) {
+	t.Parallel()
+
+	existing := schemaResponse(`{"Name":"a","Type":"System.String","CslType":"string"},{"Name":"b","Type":"System.Int64","CslType":"long"}`)
+	const tablesQuery = ".show tables | where TableName == 'table' | project TableName"
+	const schemaQuery = ".show table ['table'] schema as json"
+
+	tests := []struct {
+		desc     string
+		columns  table.Columns
+		exists   bool
+		wantMgmt []string
+		err      bool
+	}{
+		{
+			desc:     "Create",
+			columns:  table.Columns{{Name: "a", Type: types.String}, {Name: "c", Type: types.DateTime}},
+			wantMgmt: []string{tablesQuery, ".create table ['table'] (['a']:string, ['c']:datetime)"},
+		},
+		{
+			desc:     "Add missing columns",
+			columns:  table.Columns{{Name: "a", Type: types.String}, {Name: "c", Type: types.DateTime}, {Name: "d", Type: types.Dynamic}},
+			exists:   true,
+			wantMgmt: []string{tablesQuery, schemaQuery, ".alter-merge table ['table'] (['c']:datetime, ['d']:dynamic)"},
+		},
+		{
+			desc:     "Nothing missing",
+			columns:  table.Columns{{Name: "b", Type: types.Long}},
+			exists:   true,
+			wantMgmt: []string{tablesQuery, schemaQuery},
+		},
+		{
+			desc:     "Type conflict",
+			columns:  table.Columns{{Name: "b", Type: types.String}, {Name: "c", Type: types.DateTime}},
+			exists:   true,
+			wantMgmt: []string{tablesQuery, schemaQuery},
+			err:      true,
+		},
+		{desc: "No columns", err: true},
+		{desc: "Invalid type", columns: table.Columns{{Name: "a", Type: "text"}}, err: true},
+		{desc: "Duplicate column", columns: table.Columns{{Name: "a", Type: types.String}, {Name: "a", Type: types.Long}}, err: true},
+		{desc: "Column without name", columns: table.Columns{{Type: types.String}}, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var got []string
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				mu.Lock()
+				got = append(got, query)
+				mu.Unlock()
+				switch {
+				case query == tablesQuery && test.exists:
+					return tablesResponse("table"), nil
+				case query == tablesQuery:
+					return tablesResponse(), nil
+				case query == schemaQuery:
+					return existing, nil
+				}
+				return tablesResponse(), nil
+			}}}
+
+			err := client.EnsureTable(context.Background(), "db", "table", test.columns)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.wantMgmt, got)
+		})
+	}
+}
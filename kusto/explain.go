@@ -0,0 +1,87 @@
+package kusto
+
+// explain.go holds Explain(), which returns the plan the service would use to run a query.
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// The names of the parts of a query plan returned by the ".show queryplan" command.
+const (
+	planPartRelopTree = "RelopTree"
+	planPartQueryPlan = "QueryPlan"
+)
+
+// QueryPlan is the plan of a query, as returned by the ".show queryplan" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/show-query-plan
+type QueryPlan struct {
+	// RelopTree is the tree of the relational operators the query was parsed into, before it is optimized.
+	RelopTree string
+	// Plan is the optimized plan of the query, with the estimated costs of its operators.
+	Plan string
+	// Parts are all the parts of the plan the service returned by name, RelopTree and Plan included. The parts, and
+	// their format, change between service versions.
+	Parts map[string]string
+}
+
+// Explain returns the plan the service would use to run query, which must be a query and not a management command,
+// without running it. This says how a query is executed, like which operators are pushed down to the shards and how
+// joins are distributed, which helps understand why it is slow. Stmt objects with Definitions or Parameters are not
+// supported.
+func (c *Client) Explain(ctx context.Context, db string, query Stmt) (QueryPlan, error) {
+	if !query.params.IsZero() || !query.defs.IsZero() {
+		return QueryPlan{}, errors.ES(errors.OpMgmt, errors.KClientArgs, "Explain() cannot accept a Stmt object that has Definitions or Parameters attached").SetNoRetry()
+	}
+	text := strings.TrimSpace(query.String())
+	if text == "" || strings.HasPrefix(text, ".") {
+		return QueryPlan{}, errors.ES(errors.OpMgmt, errors.KClientArgs, "Explain() requires a query, got %q", text).SetNoRetry()
+	}
+
+	stmt := NewStmt(".show queryplan <| ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).UnsafeAdd(text)
+
+	plan := QueryPlan{Parts: map[string]string{}}
+	err := c.mgmtRows(ctx, db, stmt, func(r *table.Row) error {
+		addPlanParts(plan.Parts, r)
+		return nil
+	})
+	if err != nil {
+		return QueryPlan{}, err
+	}
+	if len(plan.Parts) == 0 {
+		return QueryPlan{}, errors.ES(errors.OpMgmt, errors.KInternal, "the query plan was not returned by %q", stmt.String())
+	}
+
+	plan.RelopTree = plan.Parts[planPartRelopTree]
+	plan.Plan = plan.Parts[planPartQueryPlan]
+	return plan, nil
+}
+
+// addPlanParts adds the parts of the plan in r to parts. A row either has a part in its ResultType and Content
+// columns or has one part per string column.
+func addPlanParts(parts map[string]string, r *table.Row) {
+	if name, ok := r.Value("ResultType"); ok {
+		if content, ok := r.Value("Content"); ok {
+			if n, ok := name.(value.String); ok {
+				if c, ok := content.(value.String); ok {
+					parts[n.Value] = c.Value
+					return
+				}
+			}
+		}
+	}
+
+	for i, col := range r.ColumnTypes {
+		if i >= len(r.Values) {
+			break
+		}
+		if s, ok := r.Values[i].(value.String); ok && s.Valid {
+			parts[col.Name] = s.Value
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package kusto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		query     Stmt
+		response  string
+		wantQuery string
+		want      QueryPlan
+		err       bool
+	}{
+		{
+			desc:  "Part per row",
+			query: NewStmt("MyTable | count"),
+			response: `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"ResultType","ColumnType":"string"},
+{"ColumnName":"Format","ColumnType":"string"},
+{"ColumnName":"Content","ColumnType":"string"}],
+"Rows":[["QueryText","text","MyTable | count"],["RelopTree","xml","<Relop/>"],["QueryPlan","json","{\"Cost\":1}"]]}]}`,
+			wantQuery: ".show queryplan <| MyTable | count",
+			want: QueryPlan{
+				RelopTree: "<Relop/>",
+				Plan:      `{"Cost":1}`,
+				Parts:     map[string]string{"QueryText": "MyTable | count", "RelopTree": "<Relop/>", "QueryPlan": `{"Cost":1}`},
+			},
+		},
+		{
+			desc:  "Part per column",
+			query: NewStmt("MyTable | count"),
+			response: `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"RelopTree","ColumnType":"string"},
+{"ColumnName":"QueryPlan","ColumnType":"string"}],
+"Rows":[["<Relop/>","{\"Cost\":1}"]]}]}`,
+			wantQuery: ".show queryplan <| MyTable | count",
+			want: QueryPlan{
+				RelopTree: "<Relop/>",
+				Plan:      `{"Cost":1}`,
+				Parts:     map[string]string{"RelopTree": "<Relop/>", "QueryPlan": `{"Cost":1}`},
+			},
+		},
+		{
+			desc:      "No plan returned",
+			query:     NewStmt("MyTable | count"),
+			response:  `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"QueryPlan","ColumnType":"string"}],"Rows":[]}]}`,
+			wantQuery: ".show queryplan <| MyTable | count",
+			err:       true,
+		},
+		{
+			desc:  "Management command",
+			query: NewStmt(".show tables"),
+			err:   true,
+		},
+		{
+			desc:  "Parameters",
+			query: NewStmt("MyTable | where a == x").MustDefinitions(NewDefinitions().Must(ParamTypes{"x": ParamType{Type: types.String}})).MustParameters(NewParameters().Must(QueryValues{"x": "y"})),
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery string
+			client := &Client{
+				conn: fakeQueryer{
+					onMgmt: func(query string) (string, error) {
+						gotQuery = query
+						return test.response, nil
+					},
+				},
+			}
+
+			got, err := client.Explain(context.Background(), "db", test.query)
+			assert.Equal(t, test.wantQuery, gotQuery)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package kusto
+
+// extents.go holds ShowTableExtents(), which lists the extents (data shards) of a table, and ShowExtentTags() and
+// ExtentsIngestedBy(), which read their tags.
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+	"github.com/google/uuid"
+)
+
+// Extent is an extent (data shard) of a table, as returned by the ".show table extents" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/show-extents
+type Extent struct {
+	// ID is the ID of the extent.
+	ID uuid.UUID
+	// OriginalSize is the size of the data that was ingested into the extent, in bytes.
+	OriginalSize int64
+	// ExtentSize is the size of the extent in storage, including its indexes, in bytes.
+	ExtentSize int64
+	// CompressedSize is the size of the compressed data of the extent, in bytes.
+	CompressedSize int64
+	// IndexSize is the size of the indexes of the extent, in bytes.
+	IndexSize int64
+	// RowCount is the number of rows in the extent.
+	RowCount int64
+	// MinCreatedOn and MaxCreatedOn are the earliest and latest creation times of the data in the extent.
+	MinCreatedOn, MaxCreatedOn time.Time
+	// Tags are the tags of the extent, such as "drop-by:x" and "ingest-by:y".
+	Tags []string
+}
+
+// extentRow is a row of the ".show table extents" result.
+type extentRow struct {
+	ExtentID       uuid.UUID `kusto:"ExtentId"`
+	OriginalSize   float64
+	ExtentSize     float64
+	CompressedSize float64
+	IndexSize      float64
+	RowCount       int64
+	MinCreatedOn   time.Time
+	MaxCreatedOn   time.Time
+	Tags           string
+}
+
+// ShowTableExtents returns the extents of tableName in db. Tables with many extents return large results, use
+// DoOnTableExtents() to handle them one at a time instead of holding all of them.
+func (c *Client) ShowTableExtents(ctx context.Context, db, tableName string) ([]Extent, error) {
+	var extents []Extent
+	err := c.DoOnTableExtents(ctx, db, tableName, func(e Extent) error {
+		extents = append(extents, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return extents, nil
+}
+
+// DoOnTableExtents calls f with each extent of tableName in db. If f returns an error, iteration stops and the error
+// is returned.
+func (c *Client) DoOnTableExtents(ctx context.Context, db, tableName string, f func(Extent) error) error {
+	stmt := NewStmt(".show table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" extents")
+	return c.doOnExtents(ctx, db, stmt, f)
+}
+
+// ShowExtentTags returns the tags of the extent of tableName in db with ID extentID, such as "drop-by:x" and
+// "ingest-by:y". It returns an error if extentID is not a GUID or the table has no such extent.
+func (c *Client) ShowExtentTags(ctx context.Context, db, tableName, extentID string) ([]string, error) {
+	id, err := uuid.Parse(extentID)
+	if err != nil {
+		return nil, errors.ES(errors.OpMgmt, errors.KClientArgs, "ShowExtentTags(): extent ID %q is not a GUID: %s", extentID, err).SetNoRetry()
+	}
+
+	stmt := NewStmt(".show table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" extents (").UnsafeAdd(id.String()).Add(")")
+
+	var (
+		tags  []string
+		found bool
+	)
+	err = c.doOnExtents(ctx, db, stmt, func(e Extent) error {
+		if e.ID == id {
+			tags, found = e.Tags, true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.ES(errors.OpMgmt, errors.KClientArgs, "table %s has no extent %s", tableName, id).SetNoRetry()
+	}
+	return tags, nil
+}
+
+// ExtentsIngestedBy returns the extents of tableName in db that have the tag "ingest-by:" + ingestByTag, to verify that
+// the data of an idempotent ingestion landed with its tag. The tag is added by the ingest.SkipIfTagExists() option, or
+// by ingest.Tags() with an "ingest-by:" tag, while ingest.IfNotExists() only checks for it. The data of a queued
+// ingestion reaches the table some time after it was queued, so an empty result can also mean the ingestion is not
+// done yet, which ingest.Result.Wait() tells.
+func (c *Client) ExtentsIngestedBy(ctx context.Context, db, tableName, ingestByTag string) ([]Extent, error) {
+	tag := kql.IngestByPrefix + ingestByTag
+	stmt := NewStmt(".show table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" extents where tags has ").UnsafeAdd(kql.QuoteString(tag))
+
+	var extents []Extent
+	err := c.doOnExtents(ctx, db, stmt, func(e Extent) error {
+		// "has" matches terms of the tags, so keep the extents that have the exact tag.
+		for _, t := range e.Tags {
+			if t == tag {
+				extents = append(extents, e)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return extents, nil
+}
+
+// doOnExtents runs stmt, a ".show table extents" command, and calls f with each extent it returns.
+func (c *Client) doOnExtents(ctx context.Context, db string, stmt Stmt, f func(Extent) error) error {
+	iter, err := c.Mgmt(ctx, db, stmt)
+	if err != nil {
+		return err
+	}
+	defer iter.Stop()
+
+	return iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		var row extentRow
+		if err := r.ToStruct(&row); err != nil {
+			return err
+		}
+		return f(Extent{
+			ID:             row.ExtentID,
+			OriginalSize:   int64(row.OriginalSize),
+			ExtentSize:     int64(row.ExtentSize),
+			CompressedSize: int64(row.CompressedSize),
+			IndexSize:      int64(row.IndexSize),
+			RowCount:       row.RowCount,
+			MinCreatedOn:   row.MinCreatedOn,
+			MaxCreatedOn:   row.MaxCreatedOn,
+			Tags:           kql.SplitTags(row.Tags),
+		})
+	})
+}
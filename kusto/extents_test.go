@@ -0,0 +1,219 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extentsResponse = `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"ExtentId","ColumnType":"guid"},
+{"ColumnName":"DatabaseName","ColumnType":"string"},
+{"ColumnName":"TableName","ColumnType":"string"},
+{"ColumnName":"MaxCreatedOn","ColumnType":"datetime"},
+{"ColumnName":"OriginalSize","ColumnType":"real"},
+{"ColumnName":"ExtentSize","ColumnType":"real"},
+{"ColumnName":"CompressedSize","ColumnType":"real"},
+{"ColumnName":"IndexSize","ColumnType":"real"},
+{"ColumnName":"RowCount","ColumnType":"long"},
+{"ColumnName":"MinCreatedOn","ColumnType":"datetime"},
+{"ColumnName":"Tags","ColumnType":"string"}],
+"Rows":[
+["5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c","db","table","2022-01-02T04:00:00Z",2048.0,1024.0,900.0,124.0,10,"2022-01-02T03:00:00Z","drop-by:a\r\ningest-by:b"],
+["6b8f1a7d-9e5a-4a9f-8b66-1a4b5a1f2c3d","db","table","2022-01-03T04:00:00Z",100.0,50.0,40.0,10.0,1,"2022-01-03T03:00:00Z",""]]}]}`
+
+func TestShowTableExtents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		mgmtErr error
+		want    []Extent
+		err     bool
+	}{
+		{
+			desc: "Success",
+			want: []Extent{
+				{
+					ID:             uuid.MustParse("5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c"),
+					OriginalSize:   2048,
+					ExtentSize:     1024,
+					CompressedSize: 900,
+					IndexSize:      124,
+					RowCount:       10,
+					MinCreatedOn:   time.Date(2022, 1, 2, 3, 0, 0, 0, time.UTC),
+					MaxCreatedOn:   time.Date(2022, 1, 2, 4, 0, 0, 0, time.UTC),
+					Tags:           []string{"drop-by:a", "ingest-by:b"},
+				},
+				{
+					ID:             uuid.MustParse("6b8f1a7d-9e5a-4a9f-8b66-1a4b5a1f2c3d"),
+					OriginalSize:   100,
+					ExtentSize:     50,
+					CompressedSize: 40,
+					IndexSize:      10,
+					RowCount:       1,
+					MinCreatedOn:   time.Date(2022, 1, 3, 3, 0, 0, 0, time.UTC),
+					MaxCreatedOn:   time.Date(2022, 1, 3, 4, 0, 0, 0, time.UTC),
+					Tags:           []string{},
+				},
+			},
+		},
+		{desc: "Mgmt error", mgmtErr: fmt.Errorf("mgmt error"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, ".show table ['table'] extents", query)
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return extentsResponse, nil
+			}}}
+
+			got, err := client.ShowTableExtents(context.Background(), "db", "table")
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestDoOnTableExtentsStops(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{conn: fakeQueryer{onMgmt: func(string) (string, error) {
+		return extentsResponse, nil
+	}}}
+
+	calls := 0
+	stop := fmt.Errorf("stop")
+	err := client.DoOnTableExtents(context.Background(), "db", "table", func(Extent) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestShowExtentTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		extentID  string
+		mgmtErr   error
+		wantQuery string
+		want      []string
+		err       bool
+	}{
+		{
+			desc:      "Success",
+			extentID:  "5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c",
+			wantQuery: ".show table ['table'] extents (5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c)",
+			want:      []string{"drop-by:a", "ingest-by:b"},
+		},
+		{
+			desc:      "No tags",
+			extentID:  "6B8F1A7D-9E5A-4A9F-8B66-1A4B5A1F2C3D",
+			wantQuery: ".show table ['table'] extents (6b8f1a7d-9e5a-4a9f-8b66-1a4b5a1f2c3d)",
+			want:      []string{},
+		},
+		{
+			desc:      "Extent not found",
+			extentID:  "7c9a2b8e-0f6b-4b0a-9c77-2b5c6b2a3d4e",
+			wantQuery: ".show table ['table'] extents (7c9a2b8e-0f6b-4b0a-9c77-2b5c6b2a3d4e)",
+			err:       true,
+		},
+		{desc: "Not a GUID", extentID: "5a7e0f6c) | drop", err: true},
+		{
+			desc:      "Mgmt error",
+			extentID:  "5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c",
+			wantQuery: ".show table ['table'] extents (5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c)",
+			mgmtErr:   fmt.Errorf("mgmt error"),
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, test.wantQuery, query)
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return extentsResponse, nil
+			}}}
+
+			got, err := client.ShowExtentTags(context.Background(), "db", "table", test.extentID)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestExtentsIngestedBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		tag       string
+		wantQuery string
+		want      []uuid.UUID
+	}{
+		{
+			desc:      "Tagged",
+			tag:       "b",
+			wantQuery: ".show table ['table'] extents where tags has 'ingest-by:b'",
+			want:      []uuid.UUID{uuid.MustParse("5a7e0f6c-8d4f-4f8e-9a55-0f3a4f0e1b2c")},
+		},
+		{
+			desc:      "Only exact tags",
+			tag:       "a",
+			wantQuery: ".show table ['table'] extents where tags has 'ingest-by:a'",
+		},
+		{
+			desc:      "Quoted",
+			tag:       "it's",
+			wantQuery: `.show table ['table'] extents where tags has 'ingest-by:it\'s'`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, test.wantQuery, query)
+				return extentsResponse, nil
+			}}}
+
+			extents, err := client.ExtentsIngestedBy(context.Background(), "db", "table", test.tag)
+			require.NoError(t, err)
+			var got []uuid.UUID
+			for _, e := range extents {
+				got = append(got, e.ID)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package kusto
+
+// group_by.go holds RowIterator.GroupBy(), which streams groups of contiguous rows that share a key.
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+)
+
+// GroupBy calls fn once for every group of contiguous rows that have the same value in column col. The rows of the group
+// are streamed through rows, which calls yield for every row of the group until yield returns false. Rows are
+// not buffered, so this can be used on results too large to hold in memory.
+// The result must be sorted (or at least grouped) by col, such as with "| order by col". Otherwise rows sharing a key
+// end up in multiple groups.
+// Calling rows after yield returned false continues with the next row of the group. Rows of a group that fn did not
+// read are skipped. If fn returns a non-nil error, iteration stops and the error is returned.
+// Errors inline within the rows stop the iteration and are returned.
+func (r *RowIterator) GroupBy(col string, fn func(key value.Kusto, rows func(yield func(*table.Row) bool)) error) error {
+	g := &grouper{iter: r, index: -1}
+
+	g.pending = g.read()
+	if g.pending != nil {
+		for i, c := range g.pending.ColumnTypes {
+			if c.Name == col {
+				g.index = i
+				break
+			}
+		}
+		if g.index == -1 {
+			return errors.ES(r.op, errors.KClientArgs, "GroupBy(): column %q is not in the result", col).SetNoRetry()
+		}
+	}
+
+	for g.pending != nil {
+		g.key = g.pending.Values[g.index]
+		g.groupDone = false
+
+		if err := fn(g.key, g.rows); err != nil {
+			return err
+		}
+		// Skip what fn did not read, so that pending holds the first row of the next group.
+		g.rows(func(*table.Row) bool { return true })
+	}
+
+	return g.err
+}
+
+// grouper holds the state of a GroupBy() call.
+type grouper struct {
+	iter  *RowIterator
+	index int
+
+	// key is the key of the current group.
+	key value.Kusto
+	// pending is the next row that was read but not returned yet.
+	pending *table.Row
+	// groupDone indicates all rows of the current group were returned.
+	groupDone bool
+	// done indicates the RowIterator has no more rows. err holds the error that ended the iteration, if any.
+	done bool
+	err  error
+}
+
+// rows returns the rows of the current group to yield until the group ends or yield returns false.
+func (g *grouper) rows(yield func(*table.Row) bool) {
+	for !g.groupDone {
+		if g.pending == nil {
+			g.pending = g.read()
+			if g.pending == nil {
+				g.groupDone = true
+				return
+			}
+		}
+		if !reflect.DeepEqual(g.pending.Values[g.index], g.key) {
+			g.groupDone = true
+			return
+		}
+
+		row := g.pending
+		g.pending = nil
+		if !yield(row) {
+			return
+		}
+	}
+}
+
+// read returns the next row of the RowIterator or nil if there are no more rows or the iteration failed.
+func (g *grouper) read() *table.Row {
+	if g.done {
+		return nil
+	}
+
+	row, inlineErr, err := g.iter.NextRowOrError()
+	switch {
+	case err == io.EOF:
+		g.done = true
+		return nil
+	case err != nil:
+		g.done, g.err = true, err
+		return nil
+	case inlineErr != nil:
+		g.done, g.err = true, inlineErr
+		return nil
+	}
+	return row
+}
@@ -0,0 +1,116 @@
+package kusto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBy(t *testing.T) {
+	t.Parallel()
+
+	columns := table.Columns{{Name: "Partition", Type: types.String}, {Name: "Count", Type: types.Long}}
+	row := func(partition string, count int64) value.Values {
+		return value.Values{value.String{Value: partition, Valid: true}, value.Long{Value: count, Valid: true}}
+	}
+
+	tests := []struct {
+		desc string
+		col  string
+		rows []value.Values
+		// take is the maximum number of rows read from each group, 0 reads all of them.
+		take    int
+		mockErr error
+		fnErr   error
+		want    map[string][]int64
+		order   []string
+		err     bool
+	}{
+		{
+			desc:  "No rows",
+			col:   "Partition",
+			want:  map[string][]int64{},
+			order: nil,
+		},
+		{
+			desc:  "Groups",
+			col:   "Partition",
+			rows:  []value.Values{row("a", 1), row("a", 2), row("b", 3), row("c", 4), row("c", 5), row("c", 6)},
+			want:  map[string][]int64{"a": {1, 2}, "b": {3}, "c": {4, 5, 6}},
+			order: []string{"a", "b", "c"},
+		},
+		{
+			desc:  "Unread rows are skipped",
+			col:   "Partition",
+			rows:  []value.Values{row("a", 1), row("a", 2), row("b", 3), row("b", 4)},
+			take:  1,
+			want:  map[string][]int64{"a": {1}, "b": {3}},
+			order: []string{"a", "b"},
+		},
+		{
+			desc: "Unknown column",
+			col:  "Nope",
+			rows: []value.Values{row("a", 1)},
+			err:  true,
+		},
+		{
+			desc:  "fn error stops iteration",
+			col:   "Partition",
+			rows:  []value.Values{row("a", 1), row("b", 2)},
+			fnErr: fmt.Errorf("fn error"),
+			err:   true,
+		},
+		{
+			desc:    "Iteration error",
+			col:     "Partition",
+			rows:    []value.Values{row("a", 1)},
+			mockErr: fmt.Errorf("iteration error"),
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMockRows(columns)
+			require.NoError(t, err)
+			for _, r := range test.rows {
+				require.NoError(t, m.Row(r))
+			}
+			if test.mockErr != nil {
+				require.NoError(t, m.Error(test.mockErr))
+			}
+
+			iter := &RowIterator{}
+			require.NoError(t, iter.Mock(m))
+			defer iter.Stop()
+
+			got := map[string][]int64{}
+			var order []string
+			err = iter.GroupBy(test.col, func(key value.Kusto, rows func(yield func(*table.Row) bool)) error {
+				k := key.(value.String).Value
+				order = append(order, k)
+				got[k] = []int64{}
+				rows(func(r *table.Row) bool {
+					got[k] = append(got[k], r.Values[1].(value.Long).Value)
+					return test.take == 0 || len(got[k]) < test.take
+				})
+				return test.fnErr
+			})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.order, order)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package ingest
+
+// applied_properties.go holds AppliedProperties, the record of the ingestion properties a Result was ingested with.
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// AppliedProperties are the ingestion properties that were sent to the service for an ingestion, once all the
+// FileOption(s), defaults and client settings were applied. They are meant for audit logs and can be encoded as JSON.
+// Credentials, such as the Kusto identity token and the SAS tokens of blob and table URIs, are not included.
+type AppliedProperties struct {
+	// Database and Table are where the data is ingested.
+	Database, Table string
+	// Format is the format of the data. For local files without the FileFormat() option, it is the format
+	// discovered from the file extension. It is DFUnknown if the service discovers it.
+	Format DataFormat
+	// IngestionMapping is the inline mapping set with IngestionMapping() or ColumnOrder(), as JSON.
+	IngestionMapping string `json:",omitempty"`
+	// IngestionMappingRef is the name of the mapping set with IngestionMappingRef().
+	IngestionMappingRef string `json:",omitempty"`
+	// IngestionMappingType is the kind of IngestionMapping or IngestionMappingRef.
+	IngestionMappingType DataFormat `json:",omitempty"`
+	// ValidationPolicy is the validation policy set with ValidationPolicy(), as JSON.
+	ValidationPolicy string `json:",omitempty"`
+	// Tags are the tags of the ingested data, including the "drop-by:" and "ingest-by:" tags.
+	Tags []string `json:",omitempty"`
+	// IngestIfNotExists is the ingest-by tag value set with IfNotExists().
+	IngestIfNotExists string `json:",omitempty"`
+	// CreationTime is the creation time set with SetCreationTime() or CreationTimePattern(), zero if not set.
+	CreationTime time.Time `json:",omitempty"`
+	// FlushImmediately is set with FlushImmediately().
+	FlushImmediately bool `json:",omitempty"`
+	// IgnoreSizeLimit is set with IgnoreSizeLimit().
+	IgnoreSizeLimit bool `json:",omitempty"`
+	// ReportToTable is set if the status is reported to the status table, as set with ReportResultToTable().
+	ReportToTable bool `json:",omitempty"`
+	// Extra are the properties set with AdditionalProperty() that the SDK does not model, as given.
+	Extra map[string]json.RawMessage `json:",omitempty"`
+}
+
+// newAppliedProperties returns the AppliedProperties of props.
+func newAppliedProperties(props properties.All) AppliedProperties {
+	in := props.Ingestion
+	applied := AppliedProperties{
+		Database:             in.DatabaseName,
+		Table:                in.TableName,
+		Format:               in.Additional.Format,
+		IngestionMapping:     in.Additional.IngestionMapping,
+		IngestionMappingRef:  in.Additional.IngestionMappingRef,
+		IngestionMappingType: in.Additional.IngestionMappingType,
+		ValidationPolicy:     in.Additional.ValidationPolicy,
+		IngestIfNotExists:    in.Additional.IngestIfNotExists,
+		CreationTime:         in.Additional.CreationTime,
+		FlushImmediately:     in.FlushImmediately,
+		IgnoreSizeLimit:      in.IgnoreSizeLimit,
+		ReportToTable:        in.ReportMethod == properties.ReportStatusToTable || in.ReportMethod == properties.ReportStatusToQueueAndTable,
+	}
+	if applied.Format == DFUnknown && props.Source.OriginalSource != "" {
+		applied.Format = properties.DataFormatDiscovery(props.Source.OriginalSource)
+	}
+	if len(in.Additional.Tags) > 0 {
+		applied.Tags = append([]string(nil), in.Additional.Tags...)
+	}
+	if len(in.Additional.Extra) > 0 {
+		applied.Extra = make(map[string]json.RawMessage, len(in.Additional.Extra))
+		for k, v := range in.Additional.Extra {
+			applied.Extra[k] = append(json.RawMessage(nil), v...)
+		}
+	}
+	return applied
+}
+
+// AppliedProperties returns the ingestion properties that were sent to the service for this ingestion. For an
+// ingestion that failed before it was sent, they are the properties it would have been sent with, as far as they were
+// resolved.
+func (r *Result) AppliedProperties() AppliedProperties {
+	return r.applied
+}
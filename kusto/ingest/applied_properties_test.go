@@ -0,0 +1,74 @@
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppliedProperties(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		desc    string
+		source  string
+		options []FileOption
+		want    AppliedProperties
+	}{
+		{
+			desc:    "Options",
+			options: []FileOption{FileFormat(JSON), IngestionMappingRef("mapping", JSON), Tags([]string{"a"}), SetCreationTime(created), FlushImmediately(), ReportResultToTable(), AdditionalProperty("zipPattern", "*.json")},
+			want: AppliedProperties{
+				Database:             "db",
+				Table:                "table",
+				Format:               JSON,
+				IngestionMappingRef:  "mapping",
+				IngestionMappingType: JSON,
+				Tags:                 []string{"a"},
+				CreationTime:         created,
+				FlushImmediately:     true,
+				ReportToTable:        true,
+				Extra:                map[string]json.RawMessage{"zipPattern": json.RawMessage(`"*.json"`)},
+			},
+		},
+		{
+			desc:   "Format discovered from the file",
+			source: "/data/file.csv.gz",
+			want:   AppliedProperties{Database: "db", Table: "table", Format: CSV},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{
+				Ingestion: properties.Ingestion{
+					DatabaseName: "db",
+					TableName:    "table",
+					Additional:   properties.Additional{AuthContext: "secret-token"},
+				},
+				Source: properties.SourceOptions{OriginalSource: test.source},
+			}
+			for _, o := range test.options {
+				require.NoError(t, o.Run(&props, QueuedClient, FromFile))
+			}
+
+			result := newResult()
+			result.putProps(props)
+			got := result.AppliedProperties()
+			assert.Equal(t, test.want, got)
+
+			b, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.NotContains(t, string(b), "secret-token")
+		})
+	}
+}
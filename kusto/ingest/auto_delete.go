@@ -0,0 +1,85 @@
+package ingest
+
+// auto_delete.go holds the AutoDeleteAfter() option, which tags data with the time it expires.
+
+import (
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+)
+
+// autoDeleteTagPrefix is the prefix of the drop-by tag set by AutoDeleteAfter(), followed by the expiry time.
+const autoDeleteTagPrefix = "drop-by:expires:"
+
+// autoDeleteTimeFormat is the layout of the expiry time in the drop-by tag set by AutoDeleteAfter(). It is UTC, to
+// the second, and sorts like the time it encodes.
+const autoDeleteTimeFormat = "2006-01-02T15:04:05Z"
+
+// AutoDeleteAfter marks the data as expiring d after it is ingested, for transient data like debug logs that should
+// not stay in a table for as long as the table's retention policy keeps data. It adds the drop-by tag
+// "drop-by:expires:<time>", where <time> is the UTC expiry time like "2024-01-15T10:30:00Z", to the Tags() of the
+// ingestion. The service does not drop data by itself because of a tag, so expired data is dropped with a command
+// that is run periodically, such as:
+//
+//	.drop extents <|
+//	    .show table MyTable extents
+//	    | mv-expand Tag = split(Tags, "\r\n") to typeof(string)
+//	    | where Tag startswith "drop-by:expires:" and todatetime(substring(Tag, 16)) < now()
+//	    | distinct ExtentId
+//
+// Data with different expiry times is not merged into the same extents, as drop-by tags keep extents apart, so a
+// table with many ingestions that use this option has more extents.
+//
+// To have the retention policy of the table delete the data instead, pass the SoftDeletePeriod of the policy as
+// retention: the creation time of the data (see SetCreationTime()) is then set to the expiry time minus retention, so
+// the policy considers the data to be old enough to delete d after the ingestion. The service deletes data some
+// time after it is past its retention, not exactly when. retention must be longer than d, and this cannot be combined
+// with SetCreationTime(). Pass 0 to only set the tag.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/retentionpolicy
+func AutoDeleteAfter(d, retention time.Duration) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			if d <= 0 {
+				return errors.ES(errors.OpFileIngest, errors.KClientArgs, "AutoDeleteAfter() requires a positive duration, was %s", d).SetNoRetry()
+			}
+			if retention < 0 || retention > 0 && retention <= d {
+				return errors.ES(
+					errors.OpFileIngest,
+					errors.KClientArgs,
+					"AutoDeleteAfter() retention must be longer than the duration(%s) or 0, was %s", d, retention,
+				).SetNoRetry()
+			}
+			p.Source.AutoDeleteAfter = d
+			p.Source.AutoDeleteRetention = retention
+			return nil
+		},
+		sourceScope:  FromFile | FromReader | FromBlob,
+		clientScopes: QueuedClient | ManagedClient,
+		name:         "AutoDeleteAfter",
+	}
+}
+
+// applyAutoDelete adds the drop-by tag and sets the creation time if the AutoDeleteAfter() option was provided. It is
+// applied after all the options, so that Tags() does not replace the tag.
+func applyAutoDelete(props *properties.All, c clock.Clock) error {
+	d, retention := props.Source.AutoDeleteAfter, props.Source.AutoDeleteRetention
+	if d == 0 {
+		return nil
+	}
+	props.Source.AutoDeleteAfter, props.Source.AutoDeleteRetention = 0, 0
+
+	expiry := c.Now().UTC().Add(d).Truncate(time.Second)
+	if retention > 0 {
+		if !props.Ingestion.Additional.CreationTime.IsZero() {
+			return errors.ES(errors.OpFileIngest, errors.KClientArgs, "AutoDeleteAfter() with a retention cannot be combined with SetCreationTime()").SetNoRetry()
+		}
+		props.Ingestion.Additional.CreationTime = expiry.Add(-retention)
+	}
+
+	tags := make([]string, 0, len(props.Ingestion.Additional.Tags)+1)
+	tags = append(tags, props.Ingestion.Additional.Tags...)
+	props.Ingestion.Additional.Tags = append(tags, autoDeleteTagPrefix+expiry.Format(autoDeleteTimeFormat))
+	return nil
+}
@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoDeleteAfter(t *testing.T) {
+	t.Parallel()
+
+	const blob = "https://account.blob.core.windows.net/container/data.csv"
+	now := time.Date(2022, 1, 1, 10, 30, 0, 500, time.UTC)
+	created := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc         string
+		options      []FileOption
+		wantTags     []string
+		wantCreation time.Time
+		err          bool
+	}{
+		{
+			desc:     "Tag only",
+			options:  []FileOption{AutoDeleteAfter(24*time.Hour, 0)},
+			wantTags: []string{"drop-by:expires:2022-01-02T10:30:00Z"},
+		},
+		{
+			desc:     "Tags are kept whatever the order",
+			options:  []FileOption{AutoDeleteAfter(24*time.Hour, 0), Tags([]string{"a"})},
+			wantTags: []string{"a", "drop-by:expires:2022-01-02T10:30:00Z"},
+		},
+		{
+			desc:         "Creation time from the retention",
+			options:      []FileOption{AutoDeleteAfter(24*time.Hour, 30*24*time.Hour)},
+			wantTags:     []string{"drop-by:expires:2022-01-02T10:30:00Z"},
+			wantCreation: time.Date(2021, 12, 3, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			desc:    "Retention shorter than the duration",
+			options: []FileOption{AutoDeleteAfter(24*time.Hour, time.Hour)},
+			err:     true,
+		},
+		{
+			desc:    "Retention with a creation time",
+			options: []FileOption{SetCreationTime(created), AutoDeleteAfter(24*time.Hour, 30*24*time.Hour)},
+			err:     true,
+		},
+		{
+			desc:    "No duration",
+			options: []FileOption{AutoDeleteAfter(0, 0)},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := buildIngestionMessage(clock.NewFake(now), "db", "table", blob, test.options...)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantTags, msg.Additional.Tags)
+			assert.Equal(t, test.wantCreation, msg.Additional.CreationTime)
+		})
+	}
+}
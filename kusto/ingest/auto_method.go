@@ -0,0 +1,269 @@
+package ingest
+
+// auto_method.go holds the WithAutoIngestionMethod() option of Managed and the IngestionDecision of its results.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+const (
+	// autoWindow is the number of recent streaming attempts whose failure rate is tracked.
+	autoWindow = 10
+	// autoMinAttempts is the number of attempts in the window before the failure rate is used.
+	autoMinAttempts = 4
+	// autoMaxFailureRate is the failure rate at which streaming is paused.
+	autoMaxFailureRate = 0.5
+	// autoPause is how long streaming is paused for when it fails too often.
+	autoPause = time.Minute
+	// autoPolicyTTL is how long the streaming ingestion policy of a table is cached for.
+	autoPolicyTTL = 5 * time.Minute
+	// autoPolicyErrorTTL is how long a failure to read the streaming ingestion policy of a table is cached for, so that
+	// a principal that cannot read it does not send a failing command with every ingestion.
+	autoPolicyErrorTTL = 30 * time.Second
+)
+
+// streamingFormats are the formats the streaming ingestion endpoint accepts.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/api/rest/streaming-ingest
+var streamingFormats = map[DataFormat]bool{
+	DFUnknown: true, // Sent as CSV.
+	CSV:       true,
+	TSV:       true,
+	SCSV:      true,
+	SOHSV:     true,
+	PSV:       true,
+	JSON:      true,
+	MultiJSON: true,
+	AVRO:      true,
+}
+
+// IngestionMethod is the method an ingestion was sent with.
+type IngestionMethod int
+
+const (
+	// MethodUnknown is the method of results that were not returned by Managed.
+	MethodUnknown IngestionMethod = iota
+	// MethodStreaming means the data was sent with streaming ingestion.
+	MethodStreaming
+	// MethodQueued means the data was sent with queued ingestion.
+	MethodQueued
+)
+
+// String implements fmt.Stringer.
+func (m IngestionMethod) String() string {
+	switch m {
+	case MethodStreaming:
+		return "Streaming"
+	case MethodQueued:
+		return "Queued"
+	}
+	return "Unknown"
+}
+
+// IngestionDecision is the method Managed sent an ingestion with, and why.
+type IngestionDecision struct {
+	// Method is the method the data was sent with.
+	Method IngestionMethod
+	// Reason says why Method was used, for logs. Its text may change between versions.
+	Reason string
+}
+
+// Decision returns the method Managed sent the ingestion with, and why. The Method is MethodUnknown for ingestions of
+// Ingestion and Streaming, which only have one method.
+func (r *Result) Decision() IngestionDecision {
+	return r.decision
+}
+
+// WithAutoIngestionMethod makes Managed choose the method of each ingestion from more than the size of the payload.
+// Without it, Managed streams every payload that fits the limit of streaming ingestion and falls back to queued
+// ingestion when streaming fails with a transient error. With it, payloads are also sent queued when:
+//
+//   - their format is not accepted by the streaming endpoint, like Parquet or ORC.
+//   - an IngestionMapping() is passed, as streaming ingestion only supports mappings created on the table.
+//   - streaming ingestion is not enabled on the table or its database, by their streaming ingestion policy. The
+//     policies are read with a management command the first time a table is ingested into, and again after 5 minutes.
+//     If they cannot be read, the data is streamed, and they are read again after 30 seconds.
+//   - half or more of the recent streaming attempts of the client failed. Streaming is then paused for a minute, after
+//     which it is attempted again.
+//
+// Result.Decision() returns the method used for an ingestion and why. This option only applies to Managed, New()
+// returns an error for it.
+func WithAutoIngestionMethod() Option {
+	return func(s *Ingestion) {
+		s.autoMethod = true
+	}
+}
+
+// streamingPolicy is a cached streaming ingestion policy lookup.
+type streamingPolicy struct {
+	enabled bool
+	// err is the error of the lookup, if it failed.
+	err     error
+	expires time.Time
+}
+
+// autoMethod holds the state of WithAutoIngestionMethod() for a Managed.
+type autoMethod struct {
+	client QueryClient
+	clock  clock.Clock
+
+	mu          sync.Mutex
+	policies    map[string]streamingPolicy
+	attempts    []bool // The recent streaming attempts, true if they failed.
+	pausedUntil time.Time
+}
+
+func newAutoMethod(client QueryClient, c clock.Clock) *autoMethod {
+	return &autoMethod{
+		client:   client,
+		clock:    c,
+		policies: map[string]streamingPolicy{},
+	}
+}
+
+// decide returns if the data of props should be streamed, with the reason for the decision.
+func (a *autoMethod) decide(ctx context.Context, props properties.All) (bool, string) {
+	format := props.Ingestion.Additional.Format
+	if !streamingFormats[format] {
+		return false, fmt.Sprintf("format %s is not supported by streaming ingestion", format)
+	}
+	if props.Ingestion.Additional.IngestionMapping != "" {
+		return false, "an IngestionMapping() is not supported by streaming ingestion"
+	}
+
+	a.mu.Lock()
+	paused := a.clock.Now().Before(a.pausedUntil)
+	a.mu.Unlock()
+	if paused {
+		return false, "streaming is paused after too many recent streaming failures"
+	}
+
+	db, tableName := props.Ingestion.DatabaseName, props.Ingestion.TableName
+	enabled, err := a.streamingEnabled(ctx, db, tableName)
+	if err != nil {
+		return true, fmt.Sprintf("the streaming ingestion policy could not be read (%s), so streaming was attempted", err)
+	}
+	if !enabled {
+		return false, fmt.Sprintf("streaming ingestion is not enabled on table %q of database %q", tableName, db)
+	}
+	return true, "the payload fits the streaming limit and streaming ingestion is enabled on the table"
+}
+
+// record adds the outcome of a streaming attempt, pausing streaming if too many recent attempts failed.
+func (a *autoMethod) record(failed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.attempts = append(a.attempts, failed)
+	if len(a.attempts) > autoWindow {
+		a.attempts = a.attempts[len(a.attempts)-autoWindow:]
+	}
+	if len(a.attempts) < autoMinAttempts {
+		return
+	}
+
+	failures := 0
+	for _, f := range a.attempts {
+		if f {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(a.attempts)) >= autoMaxFailureRate {
+		a.pausedUntil = a.clock.Now().Add(autoPause)
+		a.attempts = nil
+	}
+}
+
+// streamingEnabled returns if streaming ingestion is enabled on tableName in db. The table policy is used if set,
+// otherwise the database policy is. Lookups are cached for autoPolicyTTL, failures for autoPolicyErrorTTL.
+func (a *autoMethod) streamingEnabled(ctx context.Context, db, tableName string) (bool, error) {
+	key := db + "." + tableName
+
+	a.mu.Lock()
+	p, ok := a.policies[key]
+	a.mu.Unlock()
+	if ok && a.clock.Now().Before(p.expires) {
+		return p.enabled, p.err
+	}
+
+	enabled, err := a.readStreamingPolicies(ctx, db, tableName)
+	p = streamingPolicy{enabled: enabled, err: err, expires: a.clock.Now().Add(autoPolicyTTL)}
+	if err != nil {
+		p.expires = a.clock.Now().Add(autoPolicyErrorTTL)
+	}
+
+	a.mu.Lock()
+	a.policies[key] = p
+	a.mu.Unlock()
+	return enabled, err
+}
+
+// readStreamingPolicies returns if streaming ingestion is enabled on tableName in db, by the table policy if it is set
+// or otherwise by the database policy.
+func (a *autoMethod) readStreamingPolicies(ctx context.Context, db, tableName string) (bool, error) {
+	tableStmt := kusto.NewStmt(".show table ", kusto.UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" policy streamingingestion")
+	enabled, found, err := a.readStreamingPolicy(ctx, db, tableStmt)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		dbStmt := kusto.NewStmt(".show database ", kusto.UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+			UnsafeAdd(kql.QuoteName(db)).Add(" policy streamingingestion")
+		enabled, _, err = a.readStreamingPolicy(ctx, db, dbStmt)
+		if err != nil {
+			return false, err
+		}
+	}
+	return enabled, nil
+}
+
+// readStreamingPolicy runs stmt, a ".show policy streamingingestion" command, and returns the IsEnabled of the policy.
+// found is false if the entity has no policy.
+func (a *autoMethod) readStreamingPolicy(ctx context.Context, db string, stmt kusto.Stmt) (enabled, found bool, err error) {
+	iter, err := a.client.Mgmt(ctx, db, stmt)
+	if err != nil {
+		return false, false, err
+	}
+	defer iter.Stop()
+
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		v, ok := r.Value("Policy")
+		if !ok {
+			return nil
+		}
+		s, ok := v.(value.String)
+		if !ok || !s.Valid || strings.TrimSpace(s.Value) == "" || strings.TrimSpace(s.Value) == "null" {
+			return nil
+		}
+
+		var policy struct {
+			IsEnabled bool
+		}
+		if err := json.Unmarshal([]byte(s.Value), &policy); err != nil {
+			return fmt.Errorf("could not decode the streaming ingestion policy: %w", err)
+		}
+		enabled, found = policy.IsEnabled, true
+		return nil
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return enabled, found, nil
+}
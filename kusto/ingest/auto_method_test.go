@@ -0,0 +1,298 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/resources"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tablePolicyCmd = ".show table ['table'] policy streamingingestion"
+	dbPolicyCmd    = ".show database ['db'] policy streamingingestion"
+)
+
+// policyClient returns a mockClient that answers streaming ingestion policy commands with policies, by command.
+// A command that is not in policies fails.
+func policyClient(t *testing.T, policies map[string]string, calls *int) mockClient {
+	return mockClient{
+		onMgmt: func(ctx context.Context, db string, query kusto.Stmt, options ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+			if query.String() == ".get ingestion resources" {
+				return resources.SuccessfulFakeResources().Mgmt(ctx, db, query, options...)
+			}
+			if query.String() == ".get kusto identity token" {
+				return nil, nil
+			}
+			if calls != nil {
+				*calls++
+			}
+			policy, ok := policies[query.String()]
+			if !ok {
+				return nil, fmt.Errorf("unexpected command %q", query.String())
+			}
+
+			mock, err := kusto.NewMockRows(table.Columns{{Name: "PolicyName", Type: types.String}, {Name: "Policy", Type: types.String}})
+			require.NoError(t, err)
+			require.NoError(t, mock.Row(value.Values{
+				value.String{Value: "StreamingIngestionPolicy", Valid: true},
+				value.String{Value: policy, Valid: true},
+			}))
+			iter := &kusto.RowIterator{}
+			require.NoError(t, iter.Mock(mock))
+			return iter, nil
+		},
+	}
+}
+
+func TestAutoMethodDecide(t *testing.T) {
+	t.Parallel()
+
+	enabled := `{"IsEnabled":true,"HintAllocatedRate":null}`
+	disabled := `{"IsEnabled":false,"HintAllocatedRate":null}`
+
+	tests := []struct {
+		desc       string
+		format     DataFormat
+		mapping    string
+		policies   map[string]string
+		wantStream bool
+	}{
+		{
+			desc:       "Enabled on the table",
+			format:     CSV,
+			policies:   map[string]string{tablePolicyCmd: enabled},
+			wantStream: true,
+		},
+		{
+			desc:     "Disabled on the table",
+			format:   JSON,
+			policies: map[string]string{tablePolicyCmd: disabled, dbPolicyCmd: enabled},
+		},
+		{
+			desc:       "Enabled on the database",
+			format:     CSV,
+			policies:   map[string]string{tablePolicyCmd: "null", dbPolicyCmd: enabled},
+			wantStream: true,
+		},
+		{
+			desc:     "Not enabled anywhere",
+			format:   CSV,
+			policies: map[string]string{tablePolicyCmd: "", dbPolicyCmd: "null"},
+		},
+		{
+			desc:       "Policy cannot be read",
+			format:     CSV,
+			wantStream: true,
+		},
+		{
+			desc:     "Unsupported format",
+			format:   Parquet,
+			policies: map[string]string{tablePolicyCmd: enabled},
+		},
+		{
+			desc:     "Inline mapping",
+			format:   JSON,
+			mapping:  `[{"column":"a","Properties":{"Path":"$.a"}}]`,
+			policies: map[string]string{tablePolicyCmd: enabled},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			a := newAutoMethod(policyClient(t, test.policies, nil), clock.Real{})
+			props := properties.All{}
+			props.Ingestion.DatabaseName = "db"
+			props.Ingestion.TableName = "table"
+			props.Ingestion.Additional.Format = test.format
+			props.Ingestion.Additional.IngestionMapping = test.mapping
+
+			stream, reason := a.decide(context.Background(), props)
+			assert.Equal(t, test.wantStream, stream)
+			assert.NotEmpty(t, reason)
+		})
+	}
+}
+
+func TestAutoMethodPolicyCache(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	a := newAutoMethod(policyClient(t, map[string]string{tablePolicyCmd: `{"IsEnabled":true}`}, &calls), fakeClock)
+
+	for i := 0; i < 2; i++ {
+		enabled, err := a.streamingEnabled(context.Background(), "db", "table")
+		require.NoError(t, err)
+		assert.True(t, enabled)
+	}
+	assert.Equal(t, 1, calls)
+
+	fakeClock.Advance(autoPolicyTTL)
+	_, err := a.streamingEnabled(context.Background(), "db", "table")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAutoMethodPolicyErrorCache(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	a := newAutoMethod(policyClient(t, map[string]string{}, &calls), fakeClock)
+
+	for i := 0; i < 2; i++ {
+		_, err := a.streamingEnabled(context.Background(), "db", "table")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, calls)
+
+	fakeClock.Advance(autoPolicyErrorTTL)
+	_, err := a.streamingEnabled(context.Background(), "db", "table")
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAutoMethodOnlyManaged(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(kusto.NewMockClient(), "db", "table", WithAutoIngestionMethod())
+	require.Error(t, err)
+	e, ok := errors.GetKustoError(err)
+	require.True(t, ok)
+	assert.Equal(t, errors.KClientArgs, e.Kind)
+
+	m, err := NewManaged(kusto.NewMockClient(), "db", "table", WithAutoIngestionMethod())
+	require.NoError(t, err)
+	defer m.Close()
+	assert.NotNil(t, m.auto)
+}
+
+func TestAutoMethodRecord(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	a := newAutoMethod(mockClient{}, fakeClock)
+
+	paused := func() bool { return fakeClock.Now().Before(a.pausedUntil) }
+
+	// A few failures among successes do not pause streaming.
+	for _, failed := range []bool{false, true, false, false, true, false, false, false} {
+		a.record(failed)
+	}
+	assert.False(t, paused())
+
+	for i := 0; i < autoWindow; i++ {
+		a.record(true)
+		if paused() {
+			break
+		}
+	}
+	assert.True(t, paused())
+
+	props := properties.All{}
+	props.Ingestion.Additional.Format = CSV
+	stream, _ := a.decide(context.Background(), props)
+	assert.False(t, stream)
+
+	fakeClock.Advance(autoPause)
+	assert.False(t, paused())
+}
+
+func TestManagedDecision(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		auto       bool
+		options    []FileOption
+		streamErr  error
+		wantMethod IngestionMethod
+		wantStream int
+	}{
+		{
+			desc:       "Streamed",
+			wantMethod: MethodStreaming,
+			wantStream: 1,
+		},
+		{
+			desc:       "Fallback after transient errors",
+			streamErr:  errors.E(errors.OpIngestStream, errors.KHTTPError, fmt.Errorf("error")),
+			wantMethod: MethodQueued,
+			wantStream: retryCount + 1,
+		},
+		{
+			desc:       "Auto with streaming disabled",
+			auto:       true,
+			wantMethod: MethodQueued,
+		},
+		{
+			desc:       "SkipIfTagExists is queued",
+			options:    []FileOption{SkipIfTagExists("x")},
+			wantMethod: MethodQueued,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := policyClient(t, map[string]string{
+				".show table ['defaultTable'] policy streamingingestion": `{"IsEnabled":false}`,
+				// Has no Tags column, so no extent has the tag.
+				".show table ['defaultTable'] extents where tags has 'ingest-by:x'": "",
+			}, nil)
+			queued, err := New(client, "defaultDb", "defaultTable")
+			require.NoError(t, err)
+			queued.fs = resources.FsMock{
+				OnReader: func(ctx context.Context, reader io.Reader, props properties.All) (string, error) {
+					return "", nil
+				},
+			}
+
+			streamed := 0
+			managed := Managed{
+				queued: queued,
+				streaming: &Streaming{
+					db:     "defaultDb",
+					table:  "defaultTable",
+					client: client,
+					streamConn: fakeStreamIngestor{
+						onStreamIngest: func(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) error {
+							streamed++
+							return test.streamErr
+						},
+					},
+				},
+			}
+			if test.auto {
+				managed.auto = newAutoMethod(client, clock.Real{})
+			}
+
+			off := backoff.NewExponentialBackOff()
+			off.InitialInterval = time.Millisecond
+			result, err := managed.FromReader(context.Background(), bytes.NewReader([]byte("a,b\n")), append(test.options, backOff(off))...)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMethod, result.Decision().Method)
+			assert.NotEmpty(t, result.Decision().Reason)
+			assert.Equal(t, test.wantStream, streamed)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package ingest
+
+// blob_cleanup.go holds the deletion of uploaded blobs used by the WithBlobCleanup() option.
+
+import (
+	"context"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// putUpload records upload in the result. If cleanup is set and the status of the ingestion is reported to a table,
+// the uploaded blob is deleted by Wait() once the status is final.
+func (r *Result) putUpload(upload properties.Upload, cleanup bool) {
+	r.uploadStats = upload.Stats
+	if cleanup && r.reportToTable {
+		r.deleteBlob = upload.Delete
+	}
+}
+
+// cleanup deletes the uploaded blob if it should be and the service reported that it is done with it.
+// A failure to delete the blob is kept for BlobCleanupError().
+func (r *Result) cleanup(ctx context.Context) {
+	if r.deleteBlob == nil {
+		return
+	}
+	switch r.record.Status {
+	case Succeeded, PartiallySucceeded, Failed, Skipped:
+	default:
+		return
+	}
+
+	deleteBlob := r.deleteBlob
+	r.deleteBlob = nil
+	r.cleanupErr = deleteBlob(ctx)
+}
+
+// BlobCleanupError returns the error of deleting the blob uploaded for the ingestion, if WithBlobCleanup() was set and
+// the deletion failed. The blob is deleted by Wait(), so this is only set once the channel returned by Wait() is closed.
+// The error does not fail the ingestion, as the data was ingested or rejected by then.
+func (r *Result) BlobCleanupError() error {
+	return r.cleanupErr
+}
@@ -0,0 +1,59 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobCleanup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc          string
+		cleanup       bool
+		reportToTable bool
+		status        StatusCode
+		deleteErr     error
+		wantDeletes   int
+	}{
+		{desc: "Succeeded", cleanup: true, reportToTable: true, status: Succeeded, wantDeletes: 1},
+		{desc: "Failed", cleanup: true, reportToTable: true, status: Failed, wantDeletes: 1},
+		{desc: "Delete error is not returned", cleanup: true, reportToTable: true, status: Succeeded, deleteErr: fmt.Errorf("forbidden"), wantDeletes: 1},
+		{desc: "Still pending", cleanup: true, reportToTable: true, status: Pending},
+		{desc: "Status not retrieved", cleanup: true, reportToTable: true, status: StatusRetrievalFailed},
+		{desc: "No status reporting", cleanup: true, status: Succeeded},
+		{desc: "Option not set", reportToTable: true, status: Succeeded},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			deletes := 0
+			upload := properties.Upload{
+				Stats: UploadStats{UploadedBytes: 10},
+				Delete: func(context.Context) error {
+					deletes++
+					return test.deleteErr
+				},
+			}
+
+			r := newResult()
+			r.reportToTable = test.reportToTable
+			r.putUpload(upload, test.cleanup)
+			assert.Equal(t, int64(10), r.UploadStats().UploadedBytes)
+
+			r.record.Status = test.status
+			r.cleanup(context.Background())
+			// A second call must not delete the blob again.
+			r.cleanup(context.Background())
+			assert.Equal(t, test.wantDeletes, deletes)
+			assert.Equal(t, test.deleteErr, r.BlobCleanupError())
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package ingest
+
+// bom.go holds the client side removal of UTF-8 byte order marks used by the StripBOM() option.
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	kgzip "github.com/Azure/azure-kusto-go/kusto/ingest/internal/gzip"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// applyStripBOM wraps reader to remove a UTF-8 BOM from the start of the data if the StripBOM() option was provided.
+// gzip data is decompressed to remove the BOM of its content and compressed again at level.
+func applyStripBOM(reader io.Reader, props *properties.All, level int) (io.Reader, error) {
+	if !props.Source.StripBOM {
+		return reader, nil
+	}
+	props.Source.StripBOM = false
+
+	br := bufio.NewReader(reader)
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, errors.E(errors.OpFileIngest, errors.KIO, err)
+	}
+	if !bytes.Equal(head, gzipMagic) {
+		return skipBOM(br)
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "StripBOM() was passed data with a gzip header that is not valid gzip: %s", err).SetNoRetry()
+	}
+	content, err := skipBOM(bufio.NewReader(zr))
+	if err != nil {
+		return nil, err
+	}
+	return kgzip.CompressLevel(content, level)
+}
+
+// skipBOM discards the UTF-8 BOM at the start of br, if there is one.
+func skipBOM(br *bufio.Reader) (io.Reader, error) {
+	head, err := br.Peek(len(utf8BOM))
+	if err != nil && err != io.EOF {
+		return nil, errors.E(errors.OpFileIngest, errors.KIO, err)
+	}
+	if bytes.Equal(head, utf8BOM) {
+		if _, err := br.Discard(len(utf8BOM)); err != nil {
+			return nil, errors.E(errors.OpFileIngest, errors.KIO, err)
+		}
+	}
+	return br, nil
+}
+
+// uploadConverted uploads the local file fPath converted by SourceEncoding() and StripBOM(), counting its records for
+// RecordCounter(), and queues it for ingestion.
+func (i *Ingestion) uploadConverted(ctx context.Context, fPath string, props properties.All) (properties.Upload, error) {
+	file, err := os.Open(fPath)
+	if err != nil {
+		return properties.Upload{}, errors.ES(errors.OpFileIngest, errors.KLocalFileSystem, "problem retrieving source file %q: %s", fPath, err).SetNoRetry()
+	}
+	defer file.Close()
+
+	reader, err := applySourceEncoding(file, &props, i.compressionLevel)
+	if err != nil {
+		return properties.Upload{}, err
+	}
+	reader, err = applyStripBOM(reader, &props, i.compressionLevel)
+	if err != nil {
+		return properties.Upload{}, err
+	}
+	if props.Source.RecordCounter != nil && props.Ingestion.Additional.Format == DFUnknown {
+		props.Ingestion.Additional.Format = properties.DataFormatDiscovery(fPath)
+	}
+	reader, reportCount, err := applyRecordCounter(reader, &props)
+	if err != nil {
+		return properties.Upload{}, err
+	}
+	_, upload, err := i.fs.Reader(ctx, reader, props)
+	if err != nil {
+		return properties.Upload{}, err
+	}
+	reportCount()
+	return upload, nil
+}
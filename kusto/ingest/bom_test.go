@@ -0,0 +1,91 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripBOM(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		input    []byte
+		strip    bool
+		want     string
+		wantGzip bool
+		// level is the compression level of gzip data, gzip.DefaultCompression if 0.
+		level int
+		err   bool
+	}{
+		{desc: "BOM", input: []byte("\xef\xbb\xbfa,b\n"), strip: true, want: "a,b\n"},
+		{desc: "No BOM", input: []byte("a,b\n"), strip: true, want: "a,b\n"},
+		{desc: "Shorter than a BOM", input: []byte("a"), strip: true, want: "a"},
+		{desc: "Empty", input: []byte{}, strip: true, want: ""},
+		{desc: "Option not set", input: []byte("\xef\xbb\xbfa,b\n"), want: "\xef\xbb\xbfa,b\n"},
+		{desc: "gzip with BOM", input: gzipped(t, []byte("\xef\xbb\xbf{\"a\":1}\n")), strip: true, want: "{\"a\":1}\n", wantGzip: true},
+		{desc: "gzip without BOM", input: gzipped(t, []byte("{\"a\":1}\n")), strip: true, want: "{\"a\":1}\n", wantGzip: true},
+		{desc: "gzip at a level", input: gzipped(t, []byte("\xef\xbb\xbf{\"a\":1}\n")), strip: true, level: gzip.BestSpeed, want: "{\"a\":1}\n", wantGzip: true},
+		{desc: "gzip at an invalid level", input: gzipped(t, []byte("{\"a\":1}\n")), strip: true, level: gzip.BestCompression + 1, err: true},
+		{desc: "Invalid gzip", input: []byte{0x1f, 0x8b, 0x00}, strip: true, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			props.Source.StripBOM = test.strip
+
+			level := test.level
+			if level == 0 {
+				level = gzip.DefaultCompression
+			}
+			reader, err := applyStripBOM(bytes.NewReader(test.input), &props, level)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, props.Source.StripBOM)
+
+			if test.wantGzip {
+				reader, err = gzip.NewReader(reader)
+				require.NoError(t, err)
+			}
+			got, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, string(got))
+		})
+	}
+}
+
+func TestStripBOMStreaming(t *testing.T) {
+	t.Parallel()
+
+	var got []byte
+	streaming := Streaming{
+		db:    "db",
+		table: "table",
+		streamConn: fakeStreamIngestor{
+			onStreamIngest: func(_ context.Context, _, _ string, payload io.Reader, _ properties.DataFormat, _ string, _ string) error {
+				var err error
+				got, err = io.ReadAll(payload)
+				return err
+			},
+		},
+	}
+
+	_, err := streaming.FromReader(context.Background(), strings.NewReader("\xef\xbb\xbfa,b\n"), StripBOM(), DontCompress())
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n", string(got))
+}
@@ -0,0 +1,83 @@
+package ingest
+
+// column_order.go holds the mapping generation and schema check of the ColumnOrder() option.
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// ordinalMapping is an entry of a CSV ingestion mapping that maps a field, by its position, to a column.
+type ordinalMapping struct {
+	Column     string
+	Properties struct {
+		Ordinal string
+	}
+}
+
+// columnOrderMapping returns the CSV ingestion mapping that maps the fields of a record to columns, in order.
+func columnOrderMapping(columns []string) (string, error) {
+	if len(columns) == 0 {
+		return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "ColumnOrder() requires at least one column").SetNoRetry()
+	}
+
+	seen := make(map[string]bool, len(columns))
+	mapping := make([]ordinalMapping, len(columns))
+	for i, c := range columns {
+		switch {
+		case strings.TrimSpace(c) == "":
+			return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "ColumnOrder() column %d has no name", i).SetNoRetry()
+		case seen[c]:
+			return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "ColumnOrder() has column %q more than once", c).SetNoRetry()
+		}
+		seen[c] = true
+		mapping[i].Column = c
+		mapping[i].Properties.Ordinal = strconv.Itoa(i)
+	}
+
+	b, err := json.Marshal(mapping)
+	if err != nil {
+		return "", errors.ES(errors.OpUnknown, errors.KInternal, "bug: the ColumnOrder() mapping would not JSON encode: %s", err).SetNoRetry()
+	}
+	return string(b), nil
+}
+
+// checkColumnOrder verifies that the columns of the ColumnOrder() option exist in the table, if it was provided.
+func (i *Ingestion) checkColumnOrder(ctx context.Context, props *properties.All) error {
+	columns := props.Source.ColumnOrder
+	if len(columns) == 0 {
+		return nil
+	}
+
+	if format := props.Ingestion.Additional.Format; format != DFUnknown && mappingKind(format) != CSV {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "ColumnOrder() requires a delimited format like CSV, but the format is %v", format).SetNoRetry()
+	}
+
+	tableName := props.Ingestion.TableName
+	schema, err := kusto.ShowTableSchema(ctx, i.client, props.Ingestion.DatabaseName, tableName)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(schema.OrderedColumns))
+	for _, c := range schema.OrderedColumns {
+		exists[c.Name] = true
+	}
+	var missing []string
+	for _, c := range columns {
+		if !exists[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "ColumnOrder() has columns that table %q does not have: %s", tableName, strings.Join(missing, ", ")).SetNoRetry()
+	}
+
+	props.Source.ColumnOrder = nil
+	return nil
+}
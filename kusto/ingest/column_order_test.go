@@ -0,0 +1,123 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnOrderOption(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		columns []string
+		want    string
+		err     bool
+	}{
+		{
+			desc:    "Success",
+			columns: []string{"b", "a"},
+			want:    `[{"Column":"b","Properties":{"Ordinal":"0"}},{"Column":"a","Properties":{"Ordinal":"1"}}]`,
+		},
+		{desc: "No columns", err: true},
+		{desc: "Empty column", columns: []string{"a", " "}, err: true},
+		{desc: "Duplicate column", columns: []string{"a", "b", "a"}, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			err := ColumnOrder(test.columns).Run(&props, QueuedClient, FromFile)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, props.Ingestion.Additional.IngestionMapping)
+			assert.Equal(t, CSV, props.Ingestion.Additional.IngestionMappingType)
+			assert.Equal(t, test.columns, props.Source.ColumnOrder)
+		})
+	}
+}
+
+func TestCheckColumnOrder(t *testing.T) {
+	t.Parallel()
+
+	const schema = `{"Name":"table","OrderedColumns":[{"Name":"a","CslType":"string"},{"Name":"b","CslType":"long"}]}`
+
+	tests := []struct {
+		desc    string
+		columns []string
+		format  DataFormat
+		schemas []string
+		mgmtErr error
+		err     bool
+	}{
+		{desc: "Columns exist", columns: []string{"b", "a"}, format: CSV, schemas: []string{schema}},
+		{desc: "Unknown format", columns: []string{"a"}, schemas: []string{schema}},
+		{desc: "Unknown column", columns: []string{"a", "c"}, format: CSV, schemas: []string{schema}, err: true},
+		{desc: "Table does not exist", columns: []string{"a"}, format: CSV, err: true},
+		{desc: "Not a delimited format", columns: []string{"a"}, format: JSON, schemas: []string{schema}, err: true},
+		{desc: "Service error", columns: []string{"a"}, format: CSV, mgmtErr: errors.ES(errors.OpMgmt, errors.KTimeout, "timeout"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := mockClient{
+				onMgmt: func(_ context.Context, db string, query kusto.Stmt, _ ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+					assert.Equal(t, "db", db)
+					assert.Equal(t, ".show table ['table'] schema as json", query.String())
+					if test.mgmtErr != nil {
+						return nil, test.mgmtErr
+					}
+
+					mock, err := kusto.NewMockRows(table.Columns{
+						{Name: "TableName", Type: types.String},
+						{Name: "Schema", Type: types.String},
+					})
+					require.NoError(t, err)
+					for _, s := range test.schemas {
+						require.NoError(t, mock.Row(value.Values{value.String{Value: "table", Valid: true}, value.String{Value: s, Valid: true}}))
+					}
+					iter := &kusto.RowIterator{}
+					require.NoError(t, iter.Mock(mock))
+					return iter, nil
+				},
+			}
+
+			props := properties.All{}
+			props.Ingestion.DatabaseName = "db"
+			props.Ingestion.TableName = "table"
+			props.Ingestion.Additional.Format = test.format
+			props.Source.ColumnOrder = test.columns
+
+			err := (&Ingestion{client: client}).checkColumnOrder(context.Background(), &props)
+			if test.err {
+				assert.Error(t, err)
+				if test.mgmtErr != nil {
+					// The error of the service keeps its kind, so transient errors can be retried.
+					assert.Equal(t, test.mgmtErr, err)
+					assert.True(t, errors.Retry(err))
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, props.Source.ColumnOrder)
+		})
+	}
+}
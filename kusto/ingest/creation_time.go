@@ -0,0 +1,79 @@
+package ingest
+
+// creation_time.go holds the validation and application of the CreationTimePattern() option.
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+var (
+	// creationTimeRequired are the named groups a CreationTimePattern() must have.
+	creationTimeRequired = []string{"year", "month", "day"}
+	// creationTimeGroups are all the named groups a CreationTimePattern() can have, in the order time.Date() takes them.
+	creationTimeGroups = []string{"year", "month", "day", "hour", "minute", "second"}
+)
+
+// compileCreationTimePattern compiles the regular expression of CreationTimePattern() and checks its named groups.
+func compileCreationTimePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "CreationTimePattern() was passed an invalid regular expression: %s", err).SetNoRetry()
+	}
+
+	for _, name := range creationTimeRequired {
+		if re.SubexpIndex(name) < 0 {
+			return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "CreationTimePattern() requires the named group %q, like (?P<%s>\\d+)", name, name).SetNoRetry()
+		}
+	}
+	return re, nil
+}
+
+// applyCreationTimePattern sets the creation time from blobURI if the CreationTimePattern() option was provided.
+func applyCreationTimePattern(props *properties.All, blobURI string) error {
+	re := props.Source.CreationTimePattern
+	if re == nil {
+		return nil
+	}
+
+	if !props.Ingestion.Additional.CreationTime.IsZero() {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "CreationTimePattern() cannot be combined with SetCreationTime()").SetNoRetry()
+	}
+
+	u, err := url.Parse(blobURI)
+	if err != nil {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "blob URI %q could not be parsed: %s", blobURI, err).SetNoRetry()
+	}
+
+	match := re.FindStringSubmatch(u.Path)
+	if match == nil {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "blob path %q does not match the CreationTimePattern() %q", u.Path, re.String()).SetNoRetry()
+	}
+
+	parts := make([]int, len(creationTimeGroups))
+	for i, name := range creationTimeGroups {
+		index := re.SubexpIndex(name)
+		if index < 0 || match[index] == "" {
+			continue
+		}
+		parts[i], err = strconv.Atoi(match[index])
+		if err != nil {
+			return errors.ES(errors.OpFileIngest, errors.KClientArgs, "the %s %q in blob path %q is not a number", name, match[index], u.Path).SetNoRetry()
+		}
+	}
+
+	t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.UTC)
+	// time.Date() normalizes values out of range (like month 13), which means the path did not hold a valid time.
+	if t.Year() != parts[0] || int(t.Month()) != parts[1] || t.Day() != parts[2] || t.Hour() != parts[3] || t.Minute() != parts[4] || t.Second() != parts[5] {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "blob path %q does not hold a valid time for the CreationTimePattern() %q", u.Path, re.String()).SetNoRetry()
+	}
+
+	props.Ingestion.Additional.CreationTime = t
+	props.Source.CreationTimePattern = nil
+	return nil
+}
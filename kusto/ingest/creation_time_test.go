@@ -0,0 +1,90 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreationTimePattern(t *testing.T) {
+	t.Parallel()
+
+	const (
+		datePattern = `/(?P<year>\d{4})/(?P<month>\d{2})/(?P<day>\d{2})/`
+		hourPattern = `/(?P<year>\d{4})/(?P<month>\d{2})/(?P<day>\d{2})/(?P<hour>\d{2})(?P<minute>\d{2})/`
+	)
+
+	tests := []struct {
+		desc    string
+		source  string
+		options []FileOption
+		want    time.Time
+		err     bool
+	}{
+		{
+			desc:    "Date",
+			source:  "https://account.blob.core.windows.net/landing/2024/01/15/file.json",
+			options: []FileOption{CreationTimePattern(datePattern)},
+			want:    time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:    "Date and time",
+			source:  "https://account.blob.core.windows.net/landing/2024/01/15/2330/file.json",
+			options: []FileOption{CreationTimePattern(hourPattern)},
+			want:    time.Date(2024, 1, 15, 23, 30, 0, 0, time.UTC),
+		},
+		{
+			desc:    "SAS is not matched",
+			source:  "https://account.blob.core.windows.net/landing/2024/01/15/file.json",
+			options: []FileOption{CreationTimePattern(`sig=(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})`), BlobSAS("sv=2020-08-04&sig=20240115")},
+			err:     true,
+		},
+		{
+			desc:    "Path does not match",
+			source:  "https://account.blob.core.windows.net/landing/file.json",
+			options: []FileOption{CreationTimePattern(datePattern)},
+			err:     true,
+		},
+		{
+			desc:    "Invalid date",
+			source:  "https://account.blob.core.windows.net/landing/2024/13/15/file.json",
+			options: []FileOption{CreationTimePattern(datePattern)},
+			err:     true,
+		},
+		{
+			desc:    "Invalid regular expression",
+			source:  "https://account.blob.core.windows.net/landing/2024/01/15/file.json",
+			options: []FileOption{CreationTimePattern(`(?P<year>\d{4}`)},
+			err:     true,
+		},
+		{
+			desc:    "Missing group",
+			source:  "https://account.blob.core.windows.net/landing/2024/01/15/file.json",
+			options: []FileOption{CreationTimePattern(`/(?P<year>\d{4})/(?P<month>\d{2})/`)},
+			err:     true,
+		},
+		{
+			desc:    "With SetCreationTime",
+			source:  "https://account.blob.core.windows.net/landing/2024/01/15/file.json",
+			options: []FileOption{CreationTimePattern(datePattern), SetCreationTime(time.Now())},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := BuildIngestionMessage("db", "table", test.source, test.options...)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, msg.Additional.CreationTime)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ingest
+
+// default_options.go holds the merging of the FileOptions set with WithDefaultFileOptions() and those passed to a call.
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// mappingOptions are the names of the FileOptions that set the ingestion mapping.
+var mappingOptions = map[string]bool{
+	"IngestionMapping":    true,
+	"IngestionMappingRef": true,
+	"ColumnOrder":         true,
+}
+
+// defaultOption is a FileOption set with WithDefaultFileOptions(). It does nothing for calls it is not valid for,
+// which is reported by Result.IgnoredOptions().
+type defaultOption struct {
+	FileOption
+}
+
+func (d defaultOption) Run(p *properties.All, clientType ClientScope, sourceType SourceScope) error {
+	if d.ClientScopes()&clientType == 0 {
+		p.Ignore(d.String(), fmt.Sprintf("the default option is not valid for client '%s'", clientType))
+		return nil
+	}
+	if d.SourceScopes()&sourceType == 0 {
+		p.Ignore(d.String(), fmt.Sprintf("the default option is not valid for ingestion source type '%s'", sourceType))
+		return nil
+	}
+	return d.FileOption.Run(p, clientType, sourceType)
+}
+
+// withDefaults returns the default FileOptions of the client followed by options, so that options are run last and
+// override the defaults. Default mapping options are left out if options set the mapping.
+func (i *Ingestion) withDefaults(options []FileOption) []FileOption {
+	if len(i.defaultOptions) == 0 {
+		return options
+	}
+
+	callMapping := false
+	for _, o := range options {
+		if mappingOptions[o.String()] {
+			callMapping = true
+			break
+		}
+	}
+
+	merged := make([]FileOption, 0, len(i.defaultOptions)+len(options))
+	for _, o := range i.defaultOptions {
+		if callMapping && mappingOptions[o.String()] {
+			continue
+		}
+		merged = append(merged, defaultOption{o})
+	}
+	return append(merged, options...)
+}
@@ -0,0 +1,78 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDefaultFileOptions(t *testing.T) {
+	t.Parallel()
+
+	defaults := []FileOption{FileFormat(JSON), IngestionMappingRef("defaultMapping", JSON), ClientRequestId("id")}
+
+	tests := []struct {
+		desc        string
+		options     []FileOption
+		source      SourceScope
+		wantFormat  DataFormat
+		wantRef     string
+		wantMapping string
+	}{
+		{
+			desc:       "Defaults only",
+			source:     FromFile,
+			wantFormat: JSON,
+			wantRef:    "defaultMapping",
+		},
+		{
+			desc:       "Call overrides format",
+			options:    []FileOption{FileFormat(CSV)},
+			source:     FromReader,
+			wantFormat: CSV,
+			wantRef:    "defaultMapping",
+		},
+		{
+			desc:       "Call overrides mapping ref",
+			options:    []FileOption{IngestionMappingRef("callMapping", JSON)},
+			source:     FromBlob,
+			wantFormat: JSON,
+			wantRef:    "callMapping",
+		},
+		{
+			desc:        "Call inline mapping replaces default mapping ref",
+			options:     []FileOption{IngestionMapping(`[{"Column":"a"}]`, JSON)},
+			source:      FromFile,
+			wantFormat:  JSON,
+			wantMapping: `[{"Column":"a"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			i := &Ingestion{}
+			WithDefaultFileOptions(defaults...)(i)
+
+			props := properties.All{}
+			for _, o := range i.withDefaults(test.options) {
+				// ClientRequestId() is not valid for the queued client, so the default is skipped.
+				require.NoError(t, o.Run(&props, QueuedClient, test.source))
+			}
+			assert.Equal(t, test.wantFormat, props.Ingestion.Additional.Format)
+			assert.Equal(t, test.wantRef, props.Ingestion.Additional.IngestionMappingRef)
+			assert.Equal(t, test.wantMapping, props.Ingestion.Additional.IngestionMapping)
+			assert.Empty(t, props.Streaming.ClientRequestId)
+		})
+	}
+
+	// Options passed to the call are still checked.
+	i := &Ingestion{}
+	WithDefaultFileOptions(defaults...)(i)
+	options := i.withDefaults([]FileOption{ClientRequestId("id")})
+	assert.Error(t, options[len(options)-1].Run(&properties.All{}, QueuedClient, FromFile))
+}
@@ -0,0 +1,155 @@
+package ingest
+
+// detect.go holds DetectFormat(), which sniffs the format and compression of a payload from its content.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// CompressionType is a payload's compression type.
+type CompressionType = properties.CompressionType
+
+//goland:noinspection GoUnusedConst - Part of the API
+const (
+	// CTUnknown indicates that that the compression type was unset.
+	CTUnknown CompressionType = properties.CTUnknown
+	// CTNone indicates that the payload is not compressed.
+	CTNone CompressionType = properties.CTNone
+	// GZIP indicates that the payload is GZIP compressed.
+	GZIP CompressionType = properties.GZIP
+	// ZIP indicates that the payload is ZIP compressed.
+	ZIP CompressionType = properties.ZIP
+)
+
+// sniffSize is the number of bytes DetectFormat() reads to detect the format, after decompression.
+const sniffSize = 4096
+
+var (
+	gzipMagic    = []byte{0x1f, 0x8b}
+	zipMagic     = []byte("PK\x03\x04")
+	zstdMagic    = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	parquetMagic = []byte("PAR1")
+	avroMagic    = []byte("Obj\x01")
+	orcMagic     = []byte("ORC")
+	utf8BOM      = []byte("\xef\xbb\xbf")
+)
+
+// DetectFormat detects the DataFormat and CompressionType of the payload in r from its content, for payloads such as
+// .export output whose file names do not have extensions. GZIP payloads are decompressed to detect the format of
+// their content. For ZIP payloads, the format is detected from the extension of the first file in the archive.
+//
+// Detection is a heuristic. Binary formats are detected by their magic numbers (Parquet, Avro, ORC), JSON by its
+// first character (JSON if the first line is a complete value, MultiJSON otherwise) and other text by the most
+// frequent separator on its first line (CSV, TSV, SCSV, PSV, SOHSV or TXT if there is none).
+// DFUnknown is returned if the format is not recognized. zstd payloads are not supported by Kusto ingestion
+// and return an error.
+func DetectFormat(r io.ReaderAt) (DataFormat, CompressionType, error) {
+	head, err := readAtMost(io.NewSectionReader(r, 0, math.MaxInt64), sniffSize)
+	if err != nil {
+		return DFUnknown, CTUnknown, errors.E(errors.OpFileIngest, errors.KIO, err)
+	}
+	if len(head) == 0 {
+		return DFUnknown, CTUnknown, errors.ES(errors.OpFileIngest, errors.KClientArgs, "cannot detect the format of an empty payload").SetNoRetry()
+	}
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		zr, err := gzip.NewReader(io.NewSectionReader(r, 0, math.MaxInt64))
+		if err != nil {
+			return DFUnknown, GZIP, errors.ES(errors.OpFileIngest, errors.KClientArgs, "payload has a gzip header but is not valid gzip: %s", err).SetNoRetry()
+		}
+		defer zr.Close()
+		content, err := readAtMost(zr, sniffSize)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return DFUnknown, GZIP, errors.ES(errors.OpFileIngest, errors.KClientArgs, "payload has a gzip header but could not be decompressed: %s", err).SetNoRetry()
+		}
+		return sniffFormat(content), GZIP, nil
+	case bytes.HasPrefix(head, zipMagic):
+		return zipFormat(head), ZIP, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		return DFUnknown, CTUnknown, errors.ES(errors.OpFileIngest, errors.KClientArgs, "payload is zstd compressed, which Kusto ingestion does not support").SetNoRetry()
+	}
+	return sniffFormat(head), CTNone, nil
+}
+
+// readAtMost reads up to n bytes from r. Reaching the end of r is not an error.
+func readAtMost(r io.Reader, n int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, n))
+	if err == io.EOF {
+		err = nil
+	}
+	return b, err
+}
+
+// zipFormat detects the format from the file name in the first local file header of a ZIP archive.
+func zipFormat(head []byte) DataFormat {
+	const nameOffset = 30
+	if len(head) < nameOffset {
+		return DFUnknown
+	}
+	nameLen := int(binary.LittleEndian.Uint16(head[26:28]))
+	if len(head) < nameOffset+nameLen {
+		return DFUnknown
+	}
+	return properties.DataFormatDiscovery(string(head[nameOffset : nameOffset+nameLen]))
+}
+
+// sniffFormat detects the format of uncompressed content from its first bytes.
+func sniffFormat(b []byte) DataFormat {
+	switch {
+	case bytes.HasPrefix(b, parquetMagic):
+		return Parquet
+	case bytes.HasPrefix(b, avroMagic):
+		return AVRO
+	case bytes.HasPrefix(b, orcMagic):
+		return ORC
+	case bytes.IndexByte(b, 0) != -1:
+		return DFUnknown
+	}
+
+	b = bytes.TrimLeft(bytes.TrimPrefix(b, utf8BOM), " \t\r\n")
+	if len(b) == 0 {
+		return DFUnknown
+	}
+
+	line := b
+	if i := bytes.IndexByte(b, '\n'); i != -1 {
+		line = b[:i]
+	}
+	line = bytes.TrimRight(line, "\r")
+
+	switch b[0] {
+	case '[':
+		return MultiJSON
+	case '{':
+		if json.Valid(line) {
+			return JSON
+		}
+		return MultiJSON
+	}
+
+	format, most := TXT, 0
+	for _, sep := range []struct {
+		char   byte
+		format DataFormat
+	}{
+		{',', CSV},
+		{'\t', TSV},
+		{';', SCSV},
+		{'|', PSV},
+		{'\x01', SOHSV},
+	} {
+		if n := bytes.Count(line, []byte{sep.char}); n > most {
+			format, most = sep.format, n
+		}
+	}
+	return format
+}
@@ -0,0 +1,82 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, b []byte) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	_, err := w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, name string, b []byte) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	f, err := w.Create(name)
+	require.NoError(t, err)
+	_, err = f.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	csv := []byte("a,b,c\n1,2,3\n")
+
+	tests := []struct {
+		desc            string
+		payload         []byte
+		wantFormat      DataFormat
+		wantCompression CompressionType
+		err             bool
+	}{
+		{desc: "CSV", payload: csv, wantFormat: CSV, wantCompression: CTNone},
+		{desc: "TSV", payload: []byte("a\tb\tc\n"), wantFormat: TSV, wantCompression: CTNone},
+		{desc: "SCSV", payload: []byte("a;b;c,d\n"), wantFormat: SCSV, wantCompression: CTNone},
+		{desc: "PSV", payload: []byte("a|b|c\n"), wantFormat: PSV, wantCompression: CTNone},
+		{desc: "SOHSV", payload: []byte("a\x01b\x01c\n"), wantFormat: SOHSV, wantCompression: CTNone},
+		{desc: "TXT", payload: []byte("hello world\n"), wantFormat: TXT, wantCompression: CTNone},
+		{desc: "JSON lines", payload: []byte("\xef\xbb\xbf{\"a\":1}\n{\"a\":2}\n"), wantFormat: JSON, wantCompression: CTNone},
+		{desc: "Multi line JSON", payload: []byte("{\n\"a\":1\n}\n"), wantFormat: MultiJSON, wantCompression: CTNone},
+		{desc: "JSON array", payload: []byte(" [{\"a\":1}]"), wantFormat: MultiJSON, wantCompression: CTNone},
+		{desc: "Parquet", payload: []byte("PAR1\x15\x04"), wantFormat: Parquet, wantCompression: CTNone},
+		{desc: "Avro", payload: []byte("Obj\x01\x04"), wantFormat: AVRO, wantCompression: CTNone},
+		{desc: "ORC", payload: []byte("ORC\x0a"), wantFormat: ORC, wantCompression: CTNone},
+		{desc: "Binary", payload: []byte{0x00, 0x01, 0x02}, wantFormat: DFUnknown, wantCompression: CTNone},
+		{desc: "GZIP CSV", payload: gzipped(t, csv), wantFormat: CSV, wantCompression: GZIP},
+		{desc: "GZIP larger than the sniffed size", payload: gzipped(t, bytes.Repeat(csv, 1000)), wantFormat: CSV, wantCompression: GZIP},
+		{desc: "ZIP JSON", payload: zipped(t, "export/data.json", []byte(`{"a":1}`)), wantFormat: JSON, wantCompression: ZIP},
+		{desc: "ZIP without extension", payload: zipped(t, "data", csv), wantFormat: DFUnknown, wantCompression: ZIP},
+		{desc: "Invalid GZIP", payload: []byte{0x1f, 0x8b, 0x00}, err: true},
+		{desc: "zstd", payload: []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}, err: true},
+		{desc: "Empty", payload: nil, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			format, compression, err := DetectFormat(bytes.NewReader(test.payload))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantFormat, format)
+			assert.Equal(t, test.wantCompression, compression)
+		})
+	}
+}
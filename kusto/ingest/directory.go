@@ -0,0 +1,116 @@
+package ingest
+
+// directory.go holds FromDirectory(), which ingests the files of a directory in parallel.
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// defaultParallelism is the number of files FromDirectory() ingests at the same time if WithParallelism() is not provided.
+const defaultParallelism = 4
+
+// DirectoryOption is an option for FromDirectory().
+type DirectoryOption func(d *directoryOptions)
+
+type directoryOptions struct {
+	parallelism int
+	fileOptions []FileOption
+}
+
+// WithParallelism sets the maximum number of files FromDirectory() ingests at the same time. The default is 4.
+// Values less than 1 are ignored.
+func WithParallelism(n int) DirectoryOption {
+	return func(d *directoryOptions) {
+		if n > 0 {
+			d.parallelism = n
+		}
+	}
+}
+
+// WithFileOptions sets the options FromDirectory() passes to FromFile() for every file.
+func WithFileOptions(options ...FileOption) DirectoryOption {
+	return func(d *directoryOptions) {
+		d.fileOptions = append(d.fileOptions, options...)
+	}
+}
+
+// FromDirectory ingests the files in dir that match glob using ingestor. glob is a pattern as used by filepath.Match()
+// that is relative to dir, like "*.csv" or "2024-*/*.json". Directories that match are skipped.
+// Files are ingested in lexical order with at most the number of calls set with WithParallelism() running at the same
+// time. The returned MultiResult holds the outcome of every matching file, with its path (dir joined with the name) as
+// the source. If any file failed, the error is MultiResult.Err(). When ctx is done, no more files are started and the
+// files that were not started fail with a KTimeout error.
+func FromDirectory(ctx context.Context, ingestor Ingestor, dir, glob string, options ...DirectoryOption) (*MultiResult, error) {
+	opts := directoryOptions{parallelism: defaultParallelism}
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if glob == "" {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FromDirectory() requires a glob, use \"*\" for all files").SetNoRetry()
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FromDirectory() could not read directory %q: %s", dir, err).SetNoRetry()
+	} else if !info.IsDir() {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FromDirectory() was passed %q, which is not a directory", dir).SetNoRetry()
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, glob))
+	if err != nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FromDirectory() was passed an invalid glob %q: %s", glob, err).SetNoRetry()
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results = &MultiResult{}
+		sem     = make(chan struct{}, opts.parallelism)
+		stopErr error
+	)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			results.addFailed(path, err)
+			continue
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		if stopErr == nil {
+			select {
+			case <-ctx.Done():
+			case sem <- struct{}{}:
+			}
+			// Both cases can be ready at the same time, so check ctx again to never start a file after it is done.
+			if ctx.Err() != nil {
+				stopErr = errors.ES(errors.OpFileIngest, errors.KTimeout, "FromDirectory() stopped before the file was started: %s", ctx.Err())
+			}
+		}
+		if stopErr != nil {
+			results.addFailed(path, stopErr)
+			continue
+		}
+
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := ingestor.FromFile(ctx, path, opts.fileOptions...)
+			if err != nil {
+				results.addFailed(path, err)
+				return
+			}
+			results.addSucceeded(path, result)
+		}(path)
+	}
+	wg.Wait()
+
+	return results, results.Err()
+}
@@ -0,0 +1,123 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.csv", "b.csv", "c.csv", "d.csv", "e.json"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("a,b\n"), 0600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.csv"), 0700))
+
+	tests := []struct {
+		desc      string
+		glob      string
+		options   []DirectoryOption
+		fail      string
+		cancel    bool
+		want      []string
+		wantMax   int32
+		wantErrs  int
+		argsError bool
+	}{
+		{
+			desc:    "All matching files",
+			glob:    "*.csv",
+			options: []DirectoryOption{WithParallelism(2)},
+			want:    []string{"a.csv", "b.csv", "c.csv", "d.csv"},
+			wantMax: 2,
+		},
+		{
+			desc:    "Sequential",
+			glob:    "*",
+			options: []DirectoryOption{WithParallelism(1)},
+			want:    []string{"a.csv", "b.csv", "c.csv", "d.csv", "e.json"},
+			wantMax: 1,
+		},
+		{
+			desc:     "Failed file",
+			glob:     "*.csv",
+			fail:     "b.csv",
+			want:     []string{"a.csv", "c.csv", "d.csv"},
+			wantErrs: 1,
+		},
+		{
+			desc:     "Cancelled",
+			glob:     "*.csv",
+			cancel:   true,
+			wantErrs: 4,
+		},
+		{
+			desc:      "Invalid glob",
+			glob:      "[",
+			argsError: true,
+		},
+		{
+			desc:      "No glob",
+			argsError: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+
+			ingestor := &recordingIngestor{
+				delay: 10 * time.Millisecond,
+				fail: func(path string) error {
+					if filepath.Base(path) == test.fail {
+						return fmt.Errorf("ingestion failed")
+					}
+					return nil
+				},
+			}
+			results, err := FromDirectory(ctx, ingestor, dir, test.glob, test.options...)
+			if test.argsError {
+				require.Error(t, err)
+				e, ok := errors.GetKustoError(err)
+				require.True(t, ok)
+				assert.Equal(t, errors.KClientArgs, e.Kind)
+				return
+			}
+
+			if test.wantErrs > 0 {
+				require.Error(t, err)
+				combined, ok := err.(*errors.CombinedError)
+				require.True(t, ok)
+				assert.Len(t, combined.Errors, test.wantErrs)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var got []string
+			for _, s := range results.Succeeded() {
+				got = append(got, filepath.Base(s.Source))
+			}
+			assert.Equal(t, test.want, got)
+			assert.Len(t, results.Failed(), test.wantErrs)
+			if test.wantMax > 0 {
+				assert.Equal(t, test.wantMax, ingestor.maxRunning)
+			}
+		})
+	}
+}
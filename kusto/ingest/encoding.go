@@ -0,0 +1,80 @@
+package ingest
+
+// encoding.go holds the client side conversion of data to UTF-8 used by the SourceEncoding() option.
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	kgzip "github.com/Azure/azure-kusto-go/kusto/ingest/internal/gzip"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// applySourceEncoding wraps reader to convert the data from the encoding set with SourceEncoding() to UTF-8. gzip data
+// is decompressed to convert its content and compressed again at level.
+func applySourceEncoding(reader io.Reader, props *properties.All, level int) (io.Reader, error) {
+	enc := props.Source.SourceEncoding
+	if enc == nil {
+		return reader, nil
+	}
+	props.Source.SourceEncoding = nil
+
+	switch format := props.Ingestion.Additional.Format; format {
+	case AVRO, ApacheAVRO, ORC, Parquet, SStream:
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "SourceEncoding() cannot be used with the binary format %s", format).SetNoRetry()
+	}
+
+	br := bufio.NewReader(reader)
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, errors.E(errors.OpFileIngest, errors.KIO, err)
+	}
+	if !bytes.Equal(head, gzipMagic) {
+		return newDecodeReader(br, enc), nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "SourceEncoding() was passed data with a gzip header that is not valid gzip: %s", err).SetNoRetry()
+	}
+	return kgzip.CompressLevel(newDecodeReader(zr, enc), level)
+}
+
+// decodeReader converts the data of src to UTF-8 with a decoder of an encoding. Errors of the decoder are returned as
+// KClientArgs errors, errors of src are returned as they are.
+type decodeReader struct {
+	src    *sourceReader
+	reader *transform.Reader
+}
+
+func newDecodeReader(src io.Reader, enc encoding.Encoding) *decodeReader {
+	sr := &sourceReader{reader: src}
+	return &decodeReader{src: sr, reader: transform.NewReader(sr, enc.NewDecoder())}
+}
+
+// Read implements io.Reader.
+func (d *decodeReader) Read(b []byte) (int, error) {
+	n, err := d.reader.Read(b)
+	if err == nil || err == io.EOF || err == d.src.err {
+		return n, err
+	}
+	return n, errors.ES(errors.OpFileIngest, errors.KClientArgs, "the data could not be converted from the encoding set with SourceEncoding() to UTF-8: %s", err).SetNoRetry()
+}
+
+// sourceReader records the last error of reader, so decodeReader can tell it apart from the errors of the decoder.
+type sourceReader struct {
+	reader io.Reader
+	err    error
+}
+
+// Read implements io.Reader.
+func (s *sourceReader) Read(b []byte) (int, error) {
+	n, err := s.reader.Read(b)
+	s.err = err
+	return n, err
+}
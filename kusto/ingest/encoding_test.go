@@ -0,0 +1,153 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// failingEncoding is an encoding whose decoder fails on any input.
+type failingEncoding struct {
+	encoding.Encoding
+}
+
+func (failingEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: failingTransformer{}}
+}
+
+type failingTransformer struct {
+	transform.NopResetter
+}
+
+func (failingTransformer) Transform(_, src []byte, _ bool) (int, int, error) {
+	if len(src) == 0 {
+		return 0, 0, nil
+	}
+	return 0, 0, fmt.Errorf("invalid input")
+}
+
+// failingReader returns err after its data.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (f *failingReader) Read(b []byte) (int, error) {
+	if len(f.data) == 0 {
+		return 0, f.err
+	}
+	n := copy(b, f.data)
+	f.data = f.data[n:]
+	return n, nil
+}
+
+func TestSourceEncoding(t *testing.T) {
+	t.Parallel()
+
+	sourceErr := fmt.Errorf("source error")
+
+	tests := []struct {
+		desc     string
+		input    io.Reader
+		enc      encoding.Encoding
+		format   properties.DataFormat
+		want     string
+		wantGzip bool
+		// level is the compression level of gzip data, gzip.DefaultCompression if 0.
+		level    int
+		err      bool
+		readErr  error
+		readKind errors.Kind
+	}{
+		{desc: "Windows-1252", input: bytes.NewReader([]byte("caf\xe9,\x80\n")), enc: charmap.Windows1252, want: "café,€\n"},
+		{desc: "Latin-1", input: bytes.NewReader([]byte("na\xefve\n")), enc: charmap.ISO8859_1, want: "naïve\n"},
+		{desc: "UTF-16 with BOM", input: bytes.NewReader([]byte("\xff\xfea\x00,\x00b\x00")), enc: unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), want: "a,b"},
+		{desc: "Empty", input: bytes.NewReader(nil), enc: charmap.Windows1252, want: ""},
+		{desc: "Option not set", input: bytes.NewReader([]byte("caf\xe9\n")), want: "caf\xe9\n"},
+		{desc: "gzip", input: bytes.NewReader(gzipped(t, []byte("caf\xe9\n"))), enc: charmap.Windows1252, want: "café\n", wantGzip: true},
+		{desc: "gzip at a level", input: bytes.NewReader(gzipped(t, []byte("caf\xe9\n"))), enc: charmap.Windows1252, level: gzip.BestSpeed, want: "café\n", wantGzip: true},
+		{desc: "gzip at an invalid level", input: bytes.NewReader(gzipped(t, []byte("caf\xe9\n"))), enc: charmap.Windows1252, level: gzip.BestCompression + 1, err: true},
+		{desc: "Invalid gzip", input: bytes.NewReader([]byte{0x1f, 0x8b, 0x00}), enc: charmap.Windows1252, err: true},
+		{desc: "Binary format", input: bytes.NewReader([]byte("PAR1")), enc: charmap.Windows1252, format: Parquet, err: true},
+		{desc: "Decode error", input: bytes.NewReader([]byte("a,b\n")), enc: failingEncoding{}, readKind: errors.KClientArgs},
+		{desc: "Source error", input: &failingReader{data: []byte("a,b\n"), err: sourceErr}, enc: charmap.Windows1252, readErr: sourceErr},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			props.Source.SourceEncoding = test.enc
+			props.Ingestion.Additional.Format = test.format
+
+			level := test.level
+			if level == 0 {
+				level = gzip.DefaultCompression
+			}
+			reader, err := applySourceEncoding(test.input, &props, level)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, props.Source.SourceEncoding)
+
+			if test.wantGzip {
+				reader, err = gzip.NewReader(reader)
+				require.NoError(t, err)
+			}
+			got, err := io.ReadAll(reader)
+			switch {
+			case test.readErr != nil:
+				assert.Equal(t, test.readErr, err)
+				return
+			case test.readKind != errors.KOther:
+				e, ok := errors.GetKustoError(err)
+				require.True(t, ok, "error %v is not a Kusto error", err)
+				assert.Equal(t, test.readKind, e.Kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, string(got))
+		})
+	}
+}
+
+func TestSourceEncodingOption(t *testing.T) {
+	t.Parallel()
+
+	var got []byte
+	streaming := Streaming{
+		db:    "db",
+		table: "table",
+		streamConn: fakeStreamIngestor{
+			onStreamIngest: func(_ context.Context, _, _ string, payload io.Reader, _ properties.DataFormat, _ string, _ string) error {
+				var err error
+				got, err = io.ReadAll(payload)
+				return err
+			},
+		},
+	}
+
+	_, err := streaming.FromReader(context.Background(), strings.NewReader("caf\xe9\n"), SourceEncoding(charmap.Windows1252), DontCompress())
+	require.NoError(t, err)
+	assert.Equal(t, "café\n", string(got))
+
+	_, err = streaming.FromReader(context.Background(), strings.NewReader("a\n"), SourceEncoding(nil))
+	assert.Error(t, err)
+}
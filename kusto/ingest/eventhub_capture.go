@@ -0,0 +1,33 @@
+package ingest
+
+// eventhub_capture.go holds EventHubCaptureMapping(), an ingestion mapping for the Avro files written by Azure Event Hubs Capture.
+
+// eventHubCaptureMapping maps every field of the Event Hubs Capture Avro schema to the column with the same name.
+const eventHubCaptureMapping = `[` +
+	`{"Column":"SequenceNumber","Properties":{"Path":"$.SequenceNumber"}},` +
+	`{"Column":"Offset","Properties":{"Path":"$.Offset"}},` +
+	`{"Column":"EnqueuedTimeUtc","Properties":{"Path":"$.EnqueuedTimeUtc"}},` +
+	`{"Column":"SystemProperties","Properties":{"Path":"$.SystemProperties"}},` +
+	`{"Column":"Properties","Properties":{"Path":"$.Properties"}},` +
+	`{"Column":"Body","Properties":{"Path":"$.Body"}}` +
+	`]`
+
+// EventHubCaptureMapping provides the ingestion mapping for Avro files written by Azure Event Hubs Capture, such as
+// blobs ingested with FromBlob(). Each field of a captured event is mapped to the table column with the same name,
+// so the table should have these columns:
+//
+//	SequenceNumber: long
+//	Offset: string
+//	EnqueuedTimeUtc: datetime
+//	SystemProperties: dynamic
+//	Properties: dynamic
+//	Body: string or dynamic, holding the event payload
+//
+// Columns of the table that are not listed here are left empty. The files must be ingested with the AVRO or ApacheAVRO
+// format, which is detected from the ".avro" extension of the captured blobs. This replaces IngestionMapping() and
+// IngestionMappingRef().
+func EventHubCaptureMapping() FileOption {
+	o := IngestionMapping(eventHubCaptureMapping, AVRO).(option)
+	o.name = "EventHubCaptureMapping"
+	return o
+}
@@ -0,0 +1,63 @@
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHubCaptureMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		source     string
+		options    []FileOption
+		wantFormat string
+	}{
+		{
+			desc:       "Format from extension",
+			source:     "https://account.blob.core.windows.net/capture/ns/hub/0/2022/01/01/00/00/00.avro",
+			options:    []FileOption{EventHubCaptureMapping()},
+			wantFormat: "avro",
+		},
+		{
+			desc:       "ApacheAVRO format",
+			source:     "https://account.blob.core.windows.net/capture/ns/hub/0/2022/01/01/00/00/00",
+			options:    []FileOption{FileFormat(ApacheAVRO), EventHubCaptureMapping()},
+			wantFormat: "apacheavro",
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := BuildIngestionMessage("db", "table", test.source, test.options...)
+			require.NoError(t, err)
+
+			b, err := json.Marshal(msg.Additional)
+			require.NoError(t, err)
+			var got map[string]interface{}
+			require.NoError(t, json.Unmarshal(b, &got))
+
+			assert.Equal(t, test.wantFormat, got["format"])
+			assert.Equal(t, "Avro", got["ingestionMappingType"])
+
+			var mapping []struct {
+				Column     string
+				Properties struct{ Path string }
+			}
+			require.NoError(t, json.Unmarshal([]byte(got["ingestionMapping"].(string)), &mapping))
+			var columns []string
+			for _, m := range mapping {
+				assert.Equal(t, "$."+m.Column, m.Properties.Path)
+				columns = append(columns, m.Column)
+			}
+			assert.Equal(t, []string{"SequenceNumber", "Offset", "EnqueuedTimeUtc", "SystemProperties", "Properties", "Body"}, columns)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package ingest
+
+// extra_columns.go holds the validation and application of the IgnoreExtraColumns() option.
+
+import (
+	"encoding/json"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// ignoreExtraColumnsPolicy is the validation policy used by IgnoreExtraColumns(). It turns off the check that
+// records have the same number of fields as the table and ignores records that fail validation.
+var ignoreExtraColumnsPolicy = ValPolicy{Options: VOUnknown, Implications: IgnoreFailures}
+
+// applyIgnoreExtraColumns sets the validation policy if the IgnoreExtraColumns() option was provided. It is called
+// after all options were applied, so it can check the mapping regardless of the order of the options.
+func applyIgnoreExtraColumns(props *properties.All) error {
+	if !props.Source.IgnoreExtraColumns {
+		return nil
+	}
+
+	additional := &props.Ingestion.Additional
+	switch {
+	case additional.IngestionMapping == "" && additional.IngestionMappingRef == "":
+		return errors.ES(
+			errors.OpFileIngest,
+			errors.KClientArgs,
+			"IgnoreExtraColumns() requires an IngestionMapping() or IngestionMappingRef() option, extra columns are only dropped for mapped formats",
+		).SetNoRetry()
+	case !additional.IngestionMappingType.IsValidMappingKind():
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IgnoreExtraColumns() does not support the mapping kind %v", additional.IngestionMappingType).SetNoRetry()
+	case additional.ValidationPolicy != "":
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IgnoreExtraColumns() cannot be combined with ValidationPolicy()").SetNoRetry()
+	}
+
+	b, err := json.Marshal(ignoreExtraColumnsPolicy)
+	if err != nil {
+		return errors.ES(errors.OpFileIngest, errors.KInternal, "bug: the IgnoreExtraColumns() validation policy would not JSON encode").SetNoRetry()
+	}
+	additional.ValidationPolicy = string(b)
+	props.Source.IgnoreExtraColumns = false
+	return nil
+}
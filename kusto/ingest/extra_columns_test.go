@@ -0,0 +1,70 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreExtraColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		options    []FileOption
+		wantPolicy string
+		err        bool
+	}{
+		{
+			desc:       "Not set",
+			options:    []FileOption{IngestionMappingRef("mapping", CSV)},
+			wantPolicy: "",
+		},
+		{
+			desc:       "Mapping reference",
+			options:    []FileOption{IgnoreExtraColumns(), IngestionMappingRef("mapping", CSV)},
+			wantPolicy: `{"ValidationOptions":0,"ValidationImplications":1}`,
+		},
+		{
+			desc:       "Mapping",
+			options:    []FileOption{IngestionMapping(`[{"column":"a","Properties":{"Ordinal":"0"}}]`, JSON), IgnoreExtraColumns()},
+			wantPolicy: `{"ValidationOptions":0,"ValidationImplications":1}`,
+		},
+		{
+			desc:    "No mapping",
+			options: []FileOption{IgnoreExtraColumns(), FileFormat(CSV)},
+			err:     true,
+		},
+		{
+			desc:    "With ValidationPolicy",
+			options: []FileOption{IgnoreExtraColumns(), IngestionMappingRef("mapping", CSV), ValidationPolicy(ValPolicy{Options: SameNumberOfFields})},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			for _, o := range test.options {
+				require.NoError(t, o.Run(&props, QueuedClient, FromReader))
+			}
+
+			err := applyIgnoreExtraColumns(&props)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantPolicy, props.Ingestion.Additional.ValidationPolicy)
+			assert.False(t, props.Source.IgnoreExtraColumns)
+
+			// Applying again, as the managed to queued fallback does, must not fail.
+			require.NoError(t, applyIgnoreExtraColumns(&props))
+		})
+	}
+}
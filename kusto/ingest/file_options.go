@@ -3,11 +3,14 @@ package ingest
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
 	"github.com/cenkalti/backoff/v4"
+	"golang.org/x/text/encoding"
 )
 
 type SourceScope uint
@@ -42,6 +45,8 @@ func (s ClientScope) String() string {
 		return "QueuedClient"
 	case StreamingClient:
 		return "StreamingClient"
+	case ManagedClient:
+		return "ManagedClient"
 	default:
 		panic(fmt.Sprintf("unknown ClientScope %d", s))
 	}
@@ -119,7 +124,11 @@ func Table(name string) FileOption {
 	}
 }
 
-// DontCompress sets whether to compress the data.
+// DontCompress sets whether to compress the data. It makes queued ingestion upload the data as it is, for data that
+// is already compressed. The format is still sent to the service, so set it with FileFormat() if it cannot be
+// detected from the file name. Data in Avro, ApacheAvro, ORC, Parquet and SStream format, and files with a .zip or .gz
+// extension, are never compressed by the client, as they are compressed already.
+// Use WithDefaultFileOptions(DontCompress()) to apply it to all ingestions of a client.
 func DontCompress() FileOption {
 	return option{
 		run: func(p *properties.All) error {
@@ -132,6 +141,144 @@ func DontCompress() FileOption {
 	}
 }
 
+// DropMalformedRows parses CSV data from an io.Reader on the client and drops any row whose number of fields
+// does not match the first row before the data is uploaded. The number of dropped rows is returned by
+// Result.DroppedRows() instead of being logged, as the SDK has no logger of its own and writing to the global logger
+// would force output on every application; log the count from the Result where it is needed.
+// This is best-effort preprocessing that costs CPU and changes the data being ingested, it has no effect
+// on formats other than CSV.
+func DropMalformedRows() FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			p.Source.DropMalformedRows = true
+			return nil
+		},
+		clientScopes: QueuedClient | StreamingClient | ManagedClient,
+		sourceScope:  FromReader,
+		name:         "DropMalformedRows",
+	}
+}
+
+// StripBOM removes a UTF-8 byte order mark (BOM) from the start of a local file or an io.Reader before upload, as the
+// service would otherwise read it as part of the first field or record. If the data is gzip compressed, the BOM is
+// removed from the decompressed content, which is then compressed again. Data that does not start with a BOM is
+// uploaded unchanged. For the queued client, a local file is streamed instead of uploaded in parallel blocks.
+func StripBOM() FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			p.Source.StripBOM = true
+			return nil
+		},
+		clientScopes: QueuedClient | StreamingClient | ManagedClient,
+		sourceScope:  FromFile | FromReader,
+		name:         "StripBOM",
+	}
+}
+
+// SourceEncoding converts data in enc, such as charmap.Windows1252 from golang.org/x/text/encoding/charmap, to the
+// UTF-8 the service expects before upload. It applies to local files and io.Readers. If the data is gzip compressed,
+// its decompressed content is converted and compressed again. It cannot be used with binary formats like Parquet.
+// For the queued client, a local file is streamed instead of uploaded in parallel blocks.
+func SourceEncoding(enc encoding.Encoding) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			if enc == nil {
+				return errors.ES(errors.OpFileIngest, errors.KClientArgs, "SourceEncoding() requires an encoding").SetNoRetry()
+			}
+			p.Source.SourceEncoding = enc
+			return nil
+		},
+		clientScopes: QueuedClient | StreamingClient | ManagedClient,
+		sourceScope:  FromFile | FromReader,
+		name:         "SourceEncoding",
+	}
+}
+
+// IgnoreExtraColumns makes ingestion drop source columns that are not in the ingestion mapping instead of failing,
+// so the ingestion is not broken by columns added upstream. It requires an IngestionMapping() or IngestionMappingRef()
+// option for a mapped format (CSV, JSON, AVRO, Parquet or ORC) and cannot be combined with ValidationPolicy(), as it
+// sets the validation policy itself.
+func IgnoreExtraColumns() FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			p.Source.IgnoreExtraColumns = true
+			return nil
+		},
+		clientScopes: QueuedClient | ManagedClient,
+		sourceScope:  FromFile | FromReader | FromBlob,
+		name:         "IgnoreExtraColumns",
+	}
+}
+
+// SampleRate keeps only a deterministic sample of the records from an io.Reader, selected on the client before
+// upload. fraction is the portion of records to keep and must be in the range (0, 1]. seed controls which records are
+// selected, the same seed, fraction and data will always produce the same sample. This is meant for validating
+// mappings against a large source quickly. Only CSV, JSON and MultiJSON data can be sampled.
+func SampleRate(fraction float64, seed int64) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			if fraction <= 0 || fraction > 1 {
+				return errors.ES(errors.OpFileIngest, errors.KClientArgs, "SampleRate() fraction must be in the range (0, 1], was %v", fraction).SetNoRetry()
+			}
+			p.Source.SampleRate = fraction
+			p.Source.SampleSeed = seed
+			return nil
+		},
+		clientScopes: QueuedClient | StreamingClient | ManagedClient,
+		sourceScope:  FromReader,
+		name:         "SampleRate",
+	}
+}
+
+// RecordCounter counts the records of a local file or an io.Reader as they are uploaded and calls f with the total
+// once all the data was read and uploaded or streamed, to reconcile the records submitted with the records ingested.
+// Records that are removed by DropMalformedRows() or SampleRate() are not counted. CSV records are lines, and newlines
+// in double quoted fields do not end a record. JSON records are lines and MultiJSON records are top level objects or
+// arrays. Empty lines are not counted. Only uncompressed CSV, JSON and MultiJSON data can be counted. f is not called
+// if reading, uploading or streaming the data fails. For the queued client, a local file is streamed instead of
+// uploaded in parallel blocks.
+func RecordCounter(f func(count int64)) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			if f == nil {
+				return errors.ES(errors.OpFileIngest, errors.KClientArgs, "RecordCounter() requires a function").SetNoRetry()
+			}
+			p.Source.RecordCounter = f
+			return nil
+		},
+		clientScopes: QueuedClient | StreamingClient | ManagedClient,
+		sourceScope:  FromFile | FromReader,
+		name:         "RecordCounter",
+	}
+}
+
+// BlobSAS provides the SAS token to access a blob passed to FromFile(). This allows the blob URI to be passed without
+// a SAS embedded in it, so the URI can be safely logged. The SAS is added to the URI only in the ingestion message sent
+// to the service, it is not part of the Result or of any error. The URI must not already contain a SAS.
+// sas is the query string of the SAS, with or without the leading '?', and must contain the "sv" and "sig" parameters.
+func BlobSAS(sas string) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			sas = strings.TrimPrefix(sas, "?")
+			v, err := url.ParseQuery(sas)
+			if err != nil {
+				// Don't include the error, as it can contain the secret.
+				return errors.ES(errors.OpFileIngest, errors.KClientArgs, "BlobSAS() was passed a SAS that is not a valid query string").SetNoRetry()
+			}
+			for _, param := range []string{"sv", "sig"} {
+				if v.Get(param) == "" {
+					return errors.ES(errors.OpFileIngest, errors.KClientArgs, "BlobSAS() was passed a SAS without the %q parameter", param).SetNoRetry()
+				}
+			}
+			p.Source.BlobSAS = sas
+			return nil
+		},
+		clientScopes: QueuedClient | ManagedClient,
+		sourceScope:  FromBlob,
+		name:         "BlobSAS",
+	}
+}
+
 func backOff(off *backoff.ExponentialBackOff) FileOption {
 	return option{
 		run: func(p *properties.All) error {
@@ -249,6 +396,30 @@ func IngestionMapping(mapping interface{}, mappingKind DataFormat) FileOption {
 	}
 }
 
+// ColumnOrder provides the table columns that the fields of CSV records are ingested into, in the order of the fields.
+// It generates an IngestionMapping() of kind CSV that maps the first field to columns[0] and so on, so it replaces
+// IngestionMapping() and IngestionMappingRef(). Table columns that are not listed are left empty. The format must be
+// CSV or another delimited format, like TSV. Before uploading, the columns are checked against the schema of the
+// table with a ".show table schema" command, which requires table viewer permissions.
+// Streaming ingestion does not support inline mappings, so this is only supported by the queued client.
+func ColumnOrder(columns []string) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			mapping, err := columnOrderMapping(columns)
+			if err != nil {
+				return err
+			}
+			p.Ingestion.Additional.IngestionMapping = mapping
+			p.Ingestion.Additional.IngestionMappingType = CSV
+			p.Source.ColumnOrder = columns
+			return nil
+		},
+		clientScopes: QueuedClient,
+		sourceScope:  FromFile | FromReader | FromBlob,
+		name:         "ColumnOrder",
+	}
+}
+
 // IngestionMappingRef provides the name of a pre-created mapping for the data being imported to the fields in the table.
 // mappingKind can only be: CSV, JSON, AVRO, Parquet or ORC.
 // For more details, see: https://docs.microsoft.com/en-us/azure/kusto/management/create-ingestion-mapping-command
@@ -309,6 +480,8 @@ func Tags(tags []string) FileOption {
 
 // IfNotExists provides a string value that, if specified, prevents ingestion from succeeding if the table already
 // has data tagged with an ingest-by: tag with the same value. This ensures idempotent data ingestion.
+// This option does not tag the ingested data, pass Tags() with "ingest-by:" + ingestByTag, or use SkipIfTagExists(),
+// for that. kusto.Client.ExtentsIngestedBy() returns the extents that have the tag.
 // For more information see: https://docs.microsoft.com/en-us/azure/kusto/management/extents-overview#ingest-by-extent-tags
 func IfNotExists(ingestByTag string) FileOption {
 	return option{
@@ -322,6 +495,61 @@ func IfNotExists(ingestByTag string) FileOption {
 	}
 }
 
+// SkipIfTagExists checks whether the table already has data tagged with the ingest-by: tag ingestByTag before any data
+// is uploaded, and if it does, skips the ingestion. The returned Result then reports Skipped(). Otherwise the data is
+// ingested with the ingest-by tag, so ingesting it again is skipped, and with IfNotExists(ingestByTag).
+// The check is a ".show table extents" command, so it requires table viewer permissions.
+// With Managed, the data is always ingested queued, as streaming ingestion does not tag the data it ingests.
+// The check and the ingestion are not atomic: data with the tag that is ingested between the check and the time the
+// service processes this ingestion, like by a concurrent job, is not found by the check. In that case the
+// IfNotExists() condition makes the service discard the data instead, after it was uploaded.
+func SkipIfTagExists(ingestByTag string) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			if ingestByTag == "" {
+				return errors.ES(errors.OpUnknown, errors.KClientArgs, "SkipIfTagExists() requires a tag").SetNoRetry()
+			}
+			p.Source.SkipIfTagExists = ingestByTag
+			return nil
+		},
+		sourceScope:  FromFile | FromReader | FromBlob,
+		clientScopes: QueuedClient | ManagedClient,
+		name:         "SkipIfTagExists",
+	}
+}
+
+// AdditionalProperty sets the ingestion property key to value, for properties of the service that have no option in
+// the SDK. value must be JSON encodable and is sent as its JSON encoding, so pass a string for properties the service
+// expects as a string. Setting a property that has an option in the SDK, like "format" or "tags", returns an error.
+// The SDK does not validate the property: using a name or a value the service does not understand can make the
+// ingestion fail, and for queued ingestion that is only reported once the service processes the data.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/ingestion-properties
+func AdditionalProperty(key string, value interface{}) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			if strings.TrimSpace(key) == "" {
+				return errors.ES(errors.OpUnknown, errors.KClientArgs, "AdditionalProperty() requires a key").SetNoRetry()
+			}
+			if properties.IsModeledAdditional(key) {
+				return errors.ES(errors.OpUnknown, errors.KClientArgs, "AdditionalProperty() cannot set %q, use the option for it instead", key).SetNoRetry()
+			}
+			b, err := json.Marshal(value)
+			if err != nil {
+				return errors.ES(errors.OpUnknown, errors.KClientArgs, "AdditionalProperty() value for %q could not be JSON encoded: %s", key, err).SetNoRetry()
+			}
+
+			if p.Ingestion.Additional.Extra == nil {
+				p.Ingestion.Additional.Extra = map[string]json.RawMessage{}
+			}
+			p.Ingestion.Additional.Extra[key] = b
+			return nil
+		},
+		sourceScope:  FromFile | FromReader | FromBlob,
+		clientScopes: QueuedClient | ManagedClient,
+		name:         "AdditionalProperty",
+	}
+}
+
 // ReportResultToTable option requests that the ingestion status will be tracked in an Azure table.
 // Note using Table status reporting is not recommended for high capacity ingestions, as it could slow down the ingestion.
 // In such cases, it's recommended to enable it temporarily for debugging failed ingestions.
@@ -339,7 +567,11 @@ func ReportResultToTable() FileOption {
 }
 
 // SetCreationTime option allows the user to override the data creation time the retention policies are considered against
-// If not set the data creation time is considered to be the time of ingestion
+// If not set the data creation time is considered to be the time of ingestion.
+// This sets the creation time of the extents holding the data, which the retention and caching policies are computed
+// from. For a backfill of historical data, set t to the time of the original events so they age out of retention as
+// if they had been ingested then. It does not change ingestion_time(): when the table's IngestionTime policy is enabled,
+// ingestion_time() is always the time the service ingested the data and cannot be overridden by an ingestion option.
 func SetCreationTime(t time.Time) FileOption {
 	return option{
 		run: func(p *properties.All) error {
@@ -352,6 +584,28 @@ func SetCreationTime(t time.Time) FileOption {
 	}
 }
 
+// CreationTimePattern sets the creation time of a blob's data (see SetCreationTime()) from its URI, for blobs whose path
+// encodes the date, like "https://account.blob.core.windows.net/landing/2024/01/15/file.json". pattern is a regular
+// expression matched against the path of the blob URI, which must have the named groups "year", "month" and "day"
+// and can have the named groups "hour", "minute" and "second". The time is in UTC.
+// For example: `/(?P<year>\d{4})/(?P<month>\d{2})/(?P<day>\d{2})/`.
+// Ingesting a blob whose path does not match the pattern returns an error. This cannot be combined with SetCreationTime().
+func CreationTimePattern(pattern string) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			re, err := compileCreationTimePattern(pattern)
+			if err != nil {
+				return err
+			}
+			p.Source.CreationTimePattern = re
+			return nil
+		},
+		sourceScope:  FromBlob,
+		clientScopes: QueuedClient | ManagedClient,
+		name:         "CreationTimePattern",
+	}
+}
+
 // ValidationOption is an an option for validating the ingestion input data.
 // These are defined as constants within this package.
 type ValidationOption int8
@@ -398,7 +652,7 @@ func ValidationPolicy(policy ValPolicy) FileOption {
 				return errors.ES(errors.OpUnknown, errors.KInternal, "bug: the ValPolicy provided would not JSON encode").SetNoRetry()
 			}
 
-			// You might be asking, what if we are just using blobstore? Well, then this option doesn't matter :)
+			// Streaming ingestion does not send the policy, Result.IgnoredOptions() reports it when Managed streams.
 			p.Ingestion.Additional.ValidationPolicy = string(b)
 			return nil
 		},
@@ -3,10 +3,12 @@ package ingest
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/Azure/azure-kusto-go/kusto"
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -108,6 +110,14 @@ func TestOptions(t *testing.T) {
 			op:       errors.OpIngestStream,
 			kind:     errors.KClientArgs,
 		},
+		{
+			desc:     "Invalid option for streaming ingestor from file",
+			option:   DropMalformedRows(),
+			ingestor: streamingClient,
+			from:     fromFile,
+			op:       errors.OpIngestStream,
+			kind:     errors.KClientArgs,
+		},
 		{
 			desc:     "Invalid option for managed ingestor from reader",
 			option:   DeleteSource(),
@@ -116,6 +126,30 @@ func TestOptions(t *testing.T) {
 			op:       errors.OpFileIngest,
 			kind:     errors.KClientArgs,
 		},
+		{
+			desc:     "Invalid SAS for queued ingestor from blob",
+			option:   BlobSAS("?sig=secret"),
+			ingestor: queuedClient,
+			from:     fromBlob,
+			op:       errors.OpFileIngest,
+			kind:     errors.KClientArgs,
+		},
+		{
+			desc:     "Invalid SAS option for queued ingestor from file",
+			option:   BlobSAS("?sv=2020-08-04&sig=secret"),
+			ingestor: queuedClient,
+			from:     fromFile,
+			op:       errors.OpFileIngest,
+			kind:     errors.KClientArgs,
+		},
+		{
+			desc:     "Invalid sample rate for streaming ingestor from reader",
+			option:   SampleRate(1.5, 0),
+			ingestor: streamingClient,
+			from:     fromReader,
+			op:       errors.OpFileIngest,
+			kind:     errors.KClientArgs,
+		},
 	}
 
 	for _, test := range tests {
@@ -144,3 +178,64 @@ func TestOptions(t *testing.T) {
 
 	}
 }
+
+func TestAdditionalProperty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		options []FileOption
+		want    string
+		err     bool
+	}{
+		{
+			desc:    "String and object",
+			options: []FileOption{FileFormat(CSV), AdditionalProperty("ignoreFirstRecord", "true"), AdditionalProperty("newProperty", map[string]int{"a": 1})},
+			want:    `{"format":"csv","ignoreFirstRecord":"true","newProperty":{"a":1}}`,
+		},
+		{
+			desc:    "Set twice",
+			options: []FileOption{FileFormat(CSV), AdditionalProperty("zipPattern", "*.csv"), AdditionalProperty("zipPattern", "*.tsv")},
+			want:    `{"format":"csv","zipPattern":"*.tsv"}`,
+		},
+		{
+			desc:    "Modeled property",
+			options: []FileOption{AdditionalProperty("Tags", []string{"a"})},
+			err:     true,
+		},
+		{
+			desc:    "Not JSON encodable",
+			options: []FileOption{AdditionalProperty("newProperty", func() {})},
+			err:     true,
+		},
+		{
+			desc:    "No key",
+			options: []FileOption{AdditionalProperty(" ", "value")},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			var err error
+			for _, o := range test.options {
+				if err = o.Run(&props, QueuedClient, FromFile); err != nil {
+					break
+				}
+			}
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			b, err := json.Marshal(props.Ingestion.Additional)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.want, string(b))
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package ingest
+
+// identity_token.go holds IdentityToken(), which exposes the Kusto identity token used by queued ingestion.
+
+import (
+	"context"
+)
+
+// IdentityToken returns the Kusto identity token, from the ".get kusto identity token" command, that queued
+// ingestion adds to the messages it puts on the ingestion queues. It is the token the ingestion service uses to check
+// that the sender is allowed to ingest, so it is for integrations that queue ingestion messages themselves, like a
+// proxy to the ingestion queues.
+// The token is a credential: whoever holds it can ingest data as the principal of the client until it expires. Do not
+// log it or send it to anyone who should not have the permissions of that principal. The token is short lived: it is
+// cached and refreshed after an hour, as used by this client, so call IdentityToken() every time a token is needed
+// instead of keeping the returned value. This method is thread-safe.
+func (i *Ingestion) IdentityToken(ctx context.Context) (string, error) {
+	return i.mgr.AuthContext(ctx)
+}
@@ -0,0 +1,53 @@
+package ingest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityToken(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	client := mockClient{
+		endpoint: "https://test.kusto.windows.net",
+		onMgmt: func(_ context.Context, _ string, query kusto.Stmt, _ ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+			if query.String() != ".get kusto identity token" {
+				return nil, nil
+			}
+			atomic.AddInt32(&calls, 1)
+
+			rows, err := kusto.NewMockRows(table.Columns{{Name: "AuthorizationContext", Type: types.String}})
+			if err != nil {
+				return nil, err
+			}
+			if err := rows.Row(value.Values{value.String{Valid: true, Value: "identity-token"}}); err != nil {
+				return nil, err
+			}
+			iter := &kusto.RowIterator{}
+			if err := iter.Mock(rows); err != nil {
+				return nil, err
+			}
+			return iter, nil
+		},
+	}
+
+	ingestion, err := New(client, "db", "table")
+	require.NoError(t, err)
+	defer ingestion.Close()
+
+	for i := 0; i < 2; i++ {
+		token, err := ingestion.IdentityToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "identity-token", token)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "the token should be cached")
+}
@@ -0,0 +1,86 @@
+package ingest
+
+// ignored_options.go holds IgnoredOption, the record of the options of an ingestion that did not take effect.
+
+import (
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// IgnoredOption is an option passed to an ingestion that did not take effect, because the client, the source or the
+// data it was used with does not support it.
+type IgnoredOption = properties.IgnoredOption
+
+// unstreamedReason is the reason of the options that streaming ingestion does not send.
+const unstreamedReason = "the data was ingested with streaming ingestion, which does not support the option"
+
+// IgnoredOptions returns the options of the ingestion that were accepted but did not take effect. Options that are
+// not valid for a client or a source return an error instead, this reports the cases that are not errors:
+//
+//   - options of WithDefaultFileOptions() that are not valid for the client or the source of the call.
+//   - DropMalformedRows() for data that is not CSV.
+//   - options of Managed that only apply to queued ingestion, like IngestionMapping(), ValidationPolicy(), Tags() and
+//     SetCreationTime(), when the data was streamed.
+//
+// Log them to find out why an ingestion did not behave as configured. They are not part of AppliedProperties().
+func (r *Result) IgnoredOptions() []IgnoredOption {
+	return r.ignored
+}
+
+// ignoreUnstreamedProps records the properties of props that streaming ingestion does not send as ignored options,
+// and clears them so they are not reported as applied.
+func ignoreUnstreamedProps(props *properties.All) {
+	in := &props.Ingestion
+	if in.Additional.IngestionMapping != "" {
+		name := "IngestionMapping"
+		if len(props.Source.ColumnOrder) > 0 {
+			name = "ColumnOrder"
+		}
+		props.Ignore(name, unstreamedReason+", only mappings created on the table can be used with IngestionMappingRef()")
+		in.Additional.IngestionMapping = ""
+		if in.Additional.IngestionMappingRef == "" {
+			in.Additional.IngestionMappingType = DFUnknown
+		}
+	}
+	if in.Additional.ValidationPolicy != "" {
+		name := "ValidationPolicy"
+		if props.Source.IgnoreExtraColumns {
+			name = "IgnoreExtraColumns"
+		}
+		props.Ignore(name, unstreamedReason)
+		in.Additional.ValidationPolicy = ""
+	}
+	if len(in.Additional.Tags) > 0 {
+		props.Ignore("Tags", unstreamedReason)
+		in.Additional.Tags = nil
+	}
+	if in.Additional.IngestIfNotExists != "" {
+		props.Ignore("IfNotExists", unstreamedReason)
+		in.Additional.IngestIfNotExists = ""
+	}
+	if !in.Additional.CreationTime.IsZero() {
+		name := "SetCreationTime"
+		if props.Source.AutoDeleteAfter > 0 {
+			name = "AutoDeleteAfter"
+		}
+		props.Ignore(name, unstreamedReason)
+		in.Additional.CreationTime = time.Time{}
+	}
+	if len(in.Additional.Extra) > 0 {
+		props.Ignore("AdditionalProperty", unstreamedReason)
+		in.Additional.Extra = nil
+	}
+	if in.FlushImmediately {
+		props.Ignore("FlushImmediately", unstreamedReason)
+		in.FlushImmediately = false
+	}
+	if in.IgnoreSizeLimit {
+		props.Ignore("IgnoreSizeLimit", unstreamedReason)
+		in.IgnoreSizeLimit = false
+	}
+	if in.ReportMethod == properties.ReportStatusToTable || in.ReportMethod == properties.ReportStatusToQueueAndTable {
+		props.Ignore("ReportResultToTable", unstreamedReason+", its status is known when the call returns")
+		in.ReportMethod = properties.ReportStatusToQueue
+	}
+}
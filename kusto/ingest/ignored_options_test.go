@@ -0,0 +1,100 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func optionNames(ignored []IgnoredOption) []string {
+	var names []string
+	for _, o := range ignored {
+		names = append(names, o.Option)
+	}
+	return names
+}
+
+func TestStreamedIgnoredOptions(t *testing.T) {
+	t.Parallel()
+
+	props := properties.All{}
+	for _, o := range []FileOption{
+		IngestionMapping(`[{"column":"a","Properties":{"Ordinal":"0"}}]`, CSV),
+		ValidationPolicy(ValPolicy{Options: SameNumberOfFields, Implications: FailIngestion}),
+		Tags([]string{"tag"}),
+		SetCreationTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		FlushImmediately(),
+		ReportResultToTable(),
+	} {
+		require.NoError(t, o.Run(&props, ManagedClient, FromReader))
+	}
+
+	streamIngestor := fakeStreamIngestor{
+		onStreamIngest: func(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) error {
+			return nil
+		},
+	}
+	result, err := streamImpl(streamIngestor, context.Background(), bytes.NewBufferString("1,2"), props)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"IngestionMapping", "ValidationPolicy", "Tags", "SetCreationTime", "FlushImmediately", "ReportResultToTable"}, optionNames(result.IgnoredOptions()))
+	for _, o := range result.IgnoredOptions() {
+		assert.NotEmpty(t, o.Reason)
+	}
+
+	applied := result.AppliedProperties()
+	assert.Empty(t, applied.IngestionMapping)
+	assert.Empty(t, applied.ValidationPolicy)
+	assert.Empty(t, applied.Tags)
+	assert.True(t, applied.CreationTime.IsZero())
+	assert.False(t, applied.FlushImmediately)
+	assert.False(t, applied.ReportToTable)
+}
+
+func TestStreamedNoIgnoredOptions(t *testing.T) {
+	t.Parallel()
+
+	props := properties.All{}
+	require.NoError(t, IngestionMappingRef("mapping", CSV).Run(&props, StreamingClient, FromReader))
+
+	streamIngestor := fakeStreamIngestor{
+		onStreamIngest: func(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) error {
+			assert.Equal(t, "mapping", mappingName)
+			return nil
+		},
+	}
+	result, err := streamImpl(streamIngestor, context.Background(), bytes.NewBufferString("1,2"), props)
+	require.NoError(t, err)
+	assert.Empty(t, result.IgnoredOptions())
+	assert.Equal(t, "mapping", result.AppliedProperties().IngestionMappingRef)
+}
+
+func TestDefaultOptionIgnored(t *testing.T) {
+	t.Parallel()
+
+	i := &Ingestion{}
+	WithDefaultFileOptions(ClientRequestId("id"), DeleteSource())(i)
+
+	props := properties.All{}
+	for _, o := range i.withDefaults(nil) {
+		require.NoError(t, o.Run(&props, QueuedClient, FromReader))
+	}
+	assert.Equal(t, []string{"ClientRequestId", "DeleteSource"}, optionNames(props.Source.IgnoredOptions))
+}
+
+func TestDropMalformedRowsIgnored(t *testing.T) {
+	t.Parallel()
+
+	props := properties.All{}
+	props.Source.DropMalformedRows = true
+	props.Ingestion.Additional.Format = JSON
+
+	applyMalformedRowsFilter(bytes.NewBufferString(`{"a":1}`), &props)
+	assert.Equal(t, []string{"DropMalformedRows"}, optionNames(props.Source.IgnoredOptions))
+}
@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/conn"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/gzip"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/queued"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/resources"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
 	"github.com/google/uuid"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 )
 
 type Ingestor interface {
@@ -36,6 +41,26 @@ type Ingestion struct {
 
 	bufferSize int
 	maxBuffers int
+	// uploadBufferSize is set by WithUploadBufferSize().
+	uploadBufferSize int
+
+	capture          io.Writer
+	storageCred      azcore.TokenCredential
+	compressionLevel int
+	blobCleanup      bool
+	defaultOptions   []FileOption
+	// resourceFetchTimeout is set by WithResourceFetchTimeout().
+	resourceFetchTimeout time.Duration
+
+	checkTable  bool
+	tablesMu    sync.Mutex
+	tablesFound map[string]bool
+
+	// autoMethod is set by WithAutoIngestionMethod().
+	autoMethod bool
+
+	// clock is set by withClock().
+	clock clock.Clock
 }
 
 // Option is an optional argument to New().
@@ -49,25 +74,175 @@ func WithStaticBuffer(bufferSize int, maxBuffers int) Option {
 	}
 }
 
+// WithUploadBufferSize limits the memory the ingest client uses to upload the data of FromReader(), and of local files
+// it compresses, to Blob Storage to about bytes, which must be at least 1MiB. Data is compressed and uploaded as it is
+// read, in blocks of up to 8MiB that are uploaded in parallel, so the memory used does not depend on the size of the
+// data but on the number of blocks in flight. By default the client allocates blocks as uploads need them and uploads
+// them with a single pool of 50 workers shared by all its uploads, so it holds about 400MiB of blocks in flight for
+// the whole client when busy, plus the block each waiting upload is filling, and keeps freed blocks for reuse until
+// the garbage collector reclaims them.
+// With this option the client preallocates bytes worth of blocks once and shares them between all its uploads, which
+// wait for a free block when they are all in use. Smaller sizes cap the memory of many concurrent ingestions, like on
+// small containers, at the cost of throughput, as fewer blocks are uploaded at a time, and sizes under 8MiB also make
+// the blocks smaller. Uncompressed local files are uploaded straight from the file and do not use these buffers.
+// This option cannot be used with WithStaticBuffer(), which sets the size and number of blocks directly.
+func WithUploadBufferSize(bytes int) Option {
+	return func(s *Ingestion) {
+		s.uploadBufferSize = bytes
+	}
+}
+
+// uploadBuffers returns the size and number of the blocks WithUploadBufferSize(bytes) uploads with.
+func uploadBuffers(bytes int) (bufferSize, maxBuffers int) {
+	bufferSize = queued.BlockSize
+	if bytes < bufferSize {
+		bufferSize = bytes
+	}
+	return bufferSize, bytes / bufferSize
+}
+
+// WithCapturePayload configures the ingest client to write a copy of the exact bytes it uploads to Blobstore to w,
+// after compression. This is meant for diagnosing format issues of a failed ingestion by looking at the payload the
+// service received. The copy is buffered, so it only slows the upload down as much as writing to w does.
+// While this option is set, uploads of the client are done one at a time so that each payload is written to w whole.
+// Streaming ingestion (including the streaming attempt of Managed) is not captured.
+func WithCapturePayload(w io.Writer) Option {
+	return func(s *Ingestion) {
+		s.capture = w
+	}
+}
+
+// WithCompressionLevel configures the ingest client to gzip the data it uploads at level, from gzip.HuffmanOnly (-2)
+// to gzip.BestCompression (9). Lower levels use less CPU for a larger upload, gzip.BestSpeed (1) suits CPU bound
+// hosts and levels around 6 suit network bound ones. New() returns an error for levels outside that range.
+// The default remains gzip.DefaultCompression. This also applies to the payloads Managed compresses for streaming.
+func WithCompressionLevel(level int) Option {
+	return func(s *Ingestion) {
+		s.compressionLevel = level
+	}
+}
+
+// WithStorageCredential configures the ingest client to authenticate uploads to Blob Storage and posts to the
+// ingestion queues with cred, instead of the SAS tokens the service returns with the ingestion resources. The
+// identity of cred needs write access to the containers and queues. Commands to the engine and the data management
+// service keep using the credential of the QueryClient passed to New().
+func WithStorageCredential(cred azcore.TokenCredential) Option {
+	return func(s *Ingestion) {
+		s.storageCred = cred
+	}
+}
+
+// WithBlobCleanup configures the ingest client to delete the blobs it uploads for local files and io.Readers once the
+// service is done with them, for storage accounts whose lifecycle policies do not delete them. The service reads a
+// blob some time after the ingestion is queued, so a blob is only deleted once its status is known to be final:
+// this requires the ReportResultToTable() option, and the blob is deleted by Result.Wait() after the status table
+// reports that the ingestion succeeded, failed or was skipped. Failures to delete a blob do not fail the ingestion and
+// are returned by Result.BlobCleanupError(). Blobs passed to FromFile() by URI are never deleted.
+func WithBlobCleanup(enabled bool) Option {
+	return func(s *Ingestion) {
+		s.blobCleanup = enabled
+	}
+}
+
+// WithDefaultFileOptions configures the ingest client to apply options to every ingestion, such as the FileFormat()
+// and IngestionMappingRef() of a client that always ingests the same kind of data. The defaults are applied before
+// the options passed to FromFile() or FromReader(), so an option passed to the call overrides the value a default set.
+// If the call is passed an IngestionMapping(), IngestionMappingRef() or ColumnOrder(), default mapping options are
+// not applied at all, so that a call can replace the mapping. Defaults that are not valid for the client or the
+// ingestion source of a call, like ClientRequestId() for an Ingestion or DeleteSource() for an io.Reader, are skipped for
+// that call instead of returning an error. Calling this more than once appends the options.
+func WithDefaultFileOptions(options ...FileOption) Option {
+	return func(s *Ingestion) {
+		s.defaultOptions = append(s.defaultOptions, options...)
+	}
+}
+
+// WithResourceFetchTimeout sets how long the ingest client waits for the ingestion service (DM) when it gets the
+// ingestion resources (".get ingestion resources") and the Kusto identity token (".get kusto identity token") it needs
+// to queue ingestions. These calls are made by New() and, after their cached values expire, by the first ingestion that
+// needs them. When the timeout expires, the call fails with a KTimeout error instead of holding back the ingestion,
+// whatever the deadline of the context of the ingestion. The default is 30 seconds. Values less than 1 are ignored.
+func WithResourceFetchTimeout(d time.Duration) Option {
+	return func(s *Ingestion) {
+		if d > 0 {
+			s.resourceFetchTimeout = d
+		}
+	}
+}
+
 // New is a constructor for Ingestion.
 func New(client QueryClient, db, table string, options ...Option) (*Ingestion, error) {
-	mgr, err := resources.New(client)
-	if err != nil {
-		return nil, err
+	return newIngestion(client, db, table, false, options)
+}
+
+// newIngestion creates an Ingestion for New() or, if managed is set, for NewManaged(), which accepts the options that
+// only apply to Managed.
+// withClock sets the clock of the ingest client and of its resource manager. This is for tests of time dependent
+// behavior, if not set the system time is used.
+func withClock(c clock.Clock) Option {
+	return func(s *Ingestion) {
+		s.clock = c
+	}
+}
+
+// getClock returns the clock set with withClock() or the system clock.
+func (i *Ingestion) getClock() clock.Clock {
+	if i.clock == nil {
+		return clock.Real{}
 	}
+	return i.clock
+}
 
+func newIngestion(client QueryClient, db, table string, managed bool, options []Option) (*Ingestion, error) {
 	i := &Ingestion{
-		client: client,
-		mgr:    mgr,
-		db:     db,
-		table:  table,
+		client:           client,
+		db:               db,
+		table:            table,
+		compressionLevel: gzip.DefaultCompression,
 	}
 
 	for _, option := range options {
 		option(i)
 	}
 
-	fs, err := queued.New(db, table, mgr, queued.WithStaticBuffer(i.bufferSize, i.maxBuffers))
+	if i.autoMethod && !managed {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "WithAutoIngestionMethod() only applies to NewManaged()").SetNoRetry()
+	}
+	if err := gzip.ValidateLevel(i.compressionLevel); err != nil {
+		return nil, errors.E(errors.OpFileIngest, errors.KClientArgs, err).SetNoRetry()
+	}
+	if i.uploadBufferSize != 0 {
+		if i.bufferSize != 0 || i.maxBuffers != 0 {
+			return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "WithUploadBufferSize() cannot be used with WithStaticBuffer()").SetNoRetry()
+		}
+		if i.uploadBufferSize < mb {
+			return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "WithUploadBufferSize() must be at least 1MiB, was %d", i.uploadBufferSize).SetNoRetry()
+		}
+		i.bufferSize, i.maxBuffers = uploadBuffers(i.uploadBufferSize)
+	}
+
+	var mgrOptions []resources.Option
+	if i.resourceFetchTimeout > 0 {
+		mgrOptions = append(mgrOptions, resources.WithFetchTimeout(i.resourceFetchTimeout))
+	}
+	if i.clock != nil {
+		mgrOptions = append(mgrOptions, resources.WithClock(i.clock))
+	}
+	mgr, err := resources.New(client, mgrOptions...)
+	if err != nil {
+		return nil, err
+	}
+	i.mgr = mgr
+
+	fs, err := queued.New(
+		db,
+		table,
+		mgr,
+		queued.WithStaticBuffer(i.bufferSize, i.maxBuffers),
+		queued.WithCapture(i.capture),
+		queued.WithStorageCredential(i.storageCred),
+		queued.WithCompressionLevel(i.compressionLevel),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +268,22 @@ func (i *Ingestion) prepForIngestion(ctx context.Context, options []FileOption,
 		}
 	}
 
+	if err := applyIgnoreExtraColumns(&props); err != nil {
+		return nil, properties.All{}, err
+	}
+
+	if err := applyAutoDelete(&props, i.getClock()); err != nil {
+		return nil, properties.All{}, err
+	}
+
+	if err := i.checkTableExists(ctx, props.Ingestion.DatabaseName, props.Ingestion.TableName); err != nil {
+		return nil, properties.All{}, err
+	}
+
+	if err := i.checkColumnOrder(ctx, &props); err != nil {
+		return nil, properties.All{}, err
+	}
+
 	if props.Ingestion.ReportLevel != properties.None {
 		if props.Source.ID == uuid.Nil {
 			props.Source.ID = uuid.New()
@@ -123,7 +314,7 @@ func (i *Ingestion) prepForIngestion(ctx context.Context, options []FileOption,
 // FromFile allows uploading a data file for Kusto from either a local path or a blobstore URI path.
 // This method is thread-safe.
 func (i *Ingestion) FromFile(ctx context.Context, fPath string, options ...FileOption) (*Result, error) {
-	return i.fromFile(ctx, fPath, options, i.newProp())
+	return i.fromFile(ctx, fPath, i.withDefaults(options), i.newProp())
 }
 
 // fromFile is an internal function to allow managed streaming to pass a properties object to the ingestion.
@@ -146,12 +337,34 @@ func (i *Ingestion) fromFile(ctx context.Context, fPath string, options []FileOp
 		return nil, err
 	}
 
-	result.record.IngestionSourcePath = fPath
+	if !local {
+		if err := applyCreationTimePattern(&props, fPath); err != nil {
+			return nil, err
+		}
+		result.putProps(props)
+	}
 
-	if local {
-		err = i.fs.Local(ctx, fPath, props)
-	} else {
+	skipped, err := i.skipIfTagExists(ctx, &props, result)
+	if err != nil {
+		return nil, err
+	}
+	if skipped {
+		result.record.IngestionSourcePath = fPath
+		return result, nil
+	}
 
+	result.record.IngestionSourcePath = fPath
+
+	switch {
+	case local && (props.Source.StripBOM || props.Source.SourceEncoding != nil || props.Source.RecordCounter != nil):
+		var upload properties.Upload
+		upload, err = i.uploadConverted(ctx, fPath, props)
+		result.putUpload(upload, i.blobCleanup)
+	case local:
+		var upload properties.Upload
+		upload, err = i.fs.Local(ctx, fPath, props)
+		result.putUpload(upload, i.blobCleanup)
+	default:
 		err = i.fs.Blob(ctx, fPath, 0, props)
 	}
 
@@ -167,7 +380,7 @@ func (i *Ingestion) fromFile(ctx context.Context, fPath string, options []FileOp
 // ingested after all data in the reader is processed. Content should not use compression as the content will be
 // compressed with gzip. This method is thread-safe.
 func (i *Ingestion) FromReader(ctx context.Context, reader io.Reader, options ...FileOption) (*Result, error) {
-	return i.fromReader(ctx, reader, options, i.newProp())
+	return i.fromReader(ctx, reader, i.withDefaults(options), i.newProp())
 }
 
 // fromReader is an internal function to allow managed streaming to pass a properties object to the ingestion.
@@ -177,16 +390,47 @@ func (i *Ingestion) fromReader(ctx context.Context, reader io.Reader, options []
 		return nil, err
 	}
 
+	skipped, err := i.skipIfTagExists(ctx, &props, result)
+	if err != nil {
+		return nil, err
+	}
+	if skipped {
+		return result, nil
+	}
+
 	if props.Ingestion.Additional.Format == DFUnknown {
 		props.Ingestion.Additional.Format = CSV
 	}
 
-	path, err := i.fs.Reader(ctx, reader, props)
+	// The apply*() helpers below and in prepForIngestion(), and checks like skipIfTagExists(), clear the option they
+	// handle from props. Managed falls back to queued ingestion with props it already handled, so that the data is
+	// not converted, filtered or checked a second time.
+	reader, err = applySourceEncoding(reader, &props, i.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	reader, err = applyStripBOM(reader, &props, i.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	reader = applyMalformedRowsFilter(reader, &props)
+	reader, err = applySampleRate(reader, &props)
+	if err != nil {
+		return nil, err
+	}
+	reader, reportCount, err := applyRecordCounter(reader, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	path, upload, err := i.fs.Reader(ctx, reader, props)
 	if err != nil {
 		return nil, err
 	}
+	reportCount()
 
 	result.record.IngestionSourcePath = path
+	result.putUpload(upload, i.blobCleanup)
 	result.putQueued(i.mgr)
 	return result, nil
 }
@@ -226,7 +470,7 @@ func (i *Ingestion) getStreamConn() (*conn.Conn, error) {
 		return i.streamConn, nil
 	}
 
-	sc, err := conn.New(i.client.Endpoint(), i.client.Auth(), i.client.HttpClient())
+	sc, err := newStreamConn(i.client)
 	if err != nil {
 		return nil, err
 	}
@@ -2,14 +2,23 @@ package ingest
 
 import (
 	"context"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/data/table"
 	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/resources"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockClient struct {
@@ -68,6 +77,88 @@ func (m mockClient) Mgmt(ctx context.Context, db string, query kusto.Stmt, optio
 	return iter, nil
 }
 
+// recordingIngestor is a fake Ingestor that records the calls to FromFile() and FromReader(), with the properties that
+// their FileOption(s) produce, and how many calls ran at the same time.
+type recordingIngestor struct {
+	// fail returns the error of a call for its path or data, if set.
+	fail func(arg string) error
+	// delay is how long every call takes.
+	delay time.Duration
+
+	mu         sync.Mutex
+	calls      []ingestorCall
+	running    int32
+	maxRunning int32
+	closed     bool
+}
+
+// ingestorCall is a call recorded by recordingIngestor.
+type ingestorCall struct {
+	// method is "FromFile" or "FromReader".
+	method string
+	// arg is the path passed to FromFile() or the data read from the reader passed to FromReader().
+	arg   string
+	props properties.All
+}
+
+func (r *recordingIngestor) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *recordingIngestor) FromFile(_ context.Context, fPath string, options ...FileOption) (*Result, error) {
+	return r.record("FromFile", FromFile, fPath, options)
+}
+
+func (r *recordingIngestor) FromReader(_ context.Context, reader io.Reader, options ...FileOption) (*Result, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return r.record("FromReader", FromReader, string(b), options)
+}
+
+func (r *recordingIngestor) record(method string, scope SourceScope, arg string, options []FileOption) (*Result, error) {
+	n := atomic.AddInt32(&r.running, 1)
+	defer atomic.AddInt32(&r.running, -1)
+
+	call := ingestorCall{method: method, arg: arg}
+	for _, o := range options {
+		if err := o.Run(&call.props, QueuedClient, scope); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	if n > r.maxRunning {
+		r.maxRunning = n
+	}
+	r.mu.Unlock()
+
+	time.Sleep(r.delay)
+	if r.fail != nil {
+		if err := r.fail(arg); err != nil {
+			return nil, err
+		}
+	}
+	return newResult(), nil
+}
+
+// args returns the path or data of every recorded call, in the order of the calls.
+func (r *recordingIngestor) args() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var args []string
+	for _, c := range r.calls {
+		args = append(args, c.arg)
+	}
+	return args
+}
+
 func TestIngestion(t *testing.T) {
 
 	firstMockClient := mockClient{
@@ -159,3 +250,89 @@ func TestIngestion(t *testing.T) {
 		})
 	}
 }
+
+func TestWithCompressionLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc  string
+		level int
+		err   bool
+	}{
+		{desc: "Best speed", level: 1},
+		{desc: "Huffman only", level: -2},
+		{desc: "Best compression", level: 9},
+		{desc: "Too low", level: -3, err: true},
+		{desc: "Too high", level: 10, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			in, err := New(kusto.NewMockClient(), "db", "table", WithCompressionLevel(test.level))
+			if test.err {
+				require.Error(t, err)
+				e, ok := errors.GetKustoError(err)
+				require.True(t, ok)
+				assert.Equal(t, errors.KClientArgs, e.Kind)
+				return
+			}
+			require.NoError(t, err)
+			defer in.Close()
+			assert.Equal(t, test.level, in.compressionLevel)
+		})
+	}
+}
+
+func TestWithUploadBufferSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc           string
+		options        []Option
+		wantBufferSize int
+		wantMaxBuffers int
+		err            bool
+	}{
+		{desc: "Default", options: nil},
+		{desc: "Minimum", options: []Option{WithUploadBufferSize(mb)}, wantBufferSize: mb, wantMaxBuffers: 1},
+		{desc: "Less than a block", options: []Option{WithUploadBufferSize(3 * mb)}, wantBufferSize: 3 * mb, wantMaxBuffers: 1},
+		{desc: "Several blocks", options: []Option{WithUploadBufferSize(20 * mb)}, wantBufferSize: 8 * mb, wantMaxBuffers: 2},
+		{desc: "Too small", options: []Option{WithUploadBufferSize(mb - 1)}, err: true},
+		{desc: "Negative", options: []Option{WithUploadBufferSize(-1)}, err: true},
+		{desc: "With WithStaticBuffer", options: []Option{WithStaticBuffer(mb, 2), WithUploadBufferSize(mb)}, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			in, err := New(kusto.NewMockClient(), "db", "table", test.options...)
+			if test.err {
+				require.Error(t, err)
+				e, ok := errors.GetKustoError(err)
+				require.True(t, ok)
+				assert.Equal(t, errors.KClientArgs, e.Kind)
+				return
+			}
+			require.NoError(t, err)
+			defer in.Close()
+			assert.Equal(t, test.wantBufferSize, in.bufferSize)
+			assert.Equal(t, test.wantMaxBuffers, in.maxBuffers)
+		})
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	in, err := New(kusto.NewMockClient(), "db", "table", withClock(fakeClock))
+	require.NoError(t, err)
+	defer in.Close()
+
+	assert.Equal(t, fakeClock, in.mgr.Clock(), "the resource manager should use the clock of the client")
+}
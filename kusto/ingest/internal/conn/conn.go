@@ -46,6 +46,12 @@ type Conn struct {
 
 // New returns a new Conn object.
 func New(endpoint string, auth kusto.Authorization, client *http.Client) (*Conn, error) {
+	return NewResolved(endpoint, endpoint, auth, client)
+}
+
+// NewResolved returns a new Conn object that authenticates for endpoint and sends its requests to address, the
+// endpoint resolved by kusto.WithEndpointResolver().
+func NewResolved(endpoint, address string, auth kusto.Authorization, client *http.Client) (*Conn, error) {
 	if !validURL.MatchString(endpoint) {
 		return nil, errors.ES(
 			errors.OpServConn,
@@ -53,11 +59,18 @@ func New(endpoint string, auth kusto.Authorization, client *http.Client) (*Conn,
 			"endpoint is not valid(%s) for Kusto streaming ingestion", endpoint,
 		).SetNoRetry()
 	}
+	if !validURL.MatchString(address) {
+		return nil, errors.ES(
+			errors.OpServConn,
+			errors.KClientArgs,
+			"resolved endpoint is not valid(%s) for Kusto streaming ingestion", address,
+		).SetNoRetry()
+	}
 	if err := auth.Validate(endpoint); err != nil {
 		return nil, err
 	}
 
-	return newWithoutValidation(endpoint, auth, client)
+	return newWithoutValidation(address, auth, client)
 }
 
 func newWithoutValidation(endpoint string, auth kusto.Authorization, client *http.Client) (*Conn, error) {
@@ -98,9 +111,19 @@ func newWithoutValidation(endpoint string, auth kusto.Authorization, client *htt
 
 var writeOp = errors.OpIngestStream
 
+// maxStreamResponseSize is the most of the body of a successful streaming ingestion response that is read.
+const maxStreamResponseSize = 1 << 20
+
 // StreamIngest ingests into database "db", table "table" what is stored in "payload" which should be encoded in "format" and
 // have a server side data mapping reference named "mappingName".  "mappingName" can be nil.
 func (c *Conn) StreamIngest(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) error {
+	_, err := c.StreamIngestResponse(ctx, db, table, payload, format, mappingName, clientRequestId)
+	return err
+}
+
+// StreamIngestResponse is StreamIngest() that also returns the body of the successful response of the service, which
+// is empty if the service did not return one.
+func (c *Conn) StreamIngestResponse(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) ([]byte, error) {
 	defer func() {
 		if buf, ok := payload.(*bytes.Buffer); ok {
 			buf.Reset()
@@ -161,23 +184,34 @@ func (c *Conn) StreamIngest(ctx context.Context, db, table string, payload io.Re
 		prep := c.auth.Authorizer.WithAuthorization()
 		req, err = prep(autorest.CreatePreparer()).Prepare(req)
 		if err != nil {
-			return errors.E(writeOp, errors.KInternal, err)
+			return nil, errors.E(writeOp, errors.KInternal, err)
 		}
 	}
 
 	resp, err := c.client.Do(req.WithContext(ctx))
 	if err != nil {
-		return errors.E(writeOp, errors.KHTTPError, err)
+		return nil, errors.E(writeOp, errors.KHTTPError, err)
 	}
 
-	if resp.StatusCode != 200 {
-		body, err := response.TranslateBody(resp, writeOp)
-		if err != nil {
-			return err
+	body, err := response.TranslateBody(resp, writeOp)
+	if err != nil {
+		if resp.StatusCode == 200 {
+			// The data was ingested, so a response that cannot be decoded is treated as an empty one.
+			resp.Body.Close()
+			return nil, nil
 		}
-		return errors.HTTP(writeOp, resp.Status, resp.StatusCode, body, "streaming ingest issue")
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		e := errors.HTTP(writeOp, resp.Status, resp.StatusCode, body, "streaming ingest issue")
+		e.SetResponseHeaders(resp.Header)
+		return nil, e
 	}
-	return nil
+	defer body.Close()
+
+	// The data was ingested, so an error reading the response is not an ingestion error.
+	raw, _ := ioutil.ReadAll(io.LimitReader(body, maxStreamResponseSize))
+	return raw, nil
 }
 
 func copyHeaders(header http.Header) http.Header {
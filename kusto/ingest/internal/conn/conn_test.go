@@ -17,6 +17,7 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto"
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -96,6 +97,15 @@ func (f *fakeStreamService) handleStream(res http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if strings.Contains(r.URL.Path, "badEncoding") {
+		res.Header().Set("Content-Encoding", "gzip")
+		res.WriteHeader(200)
+		if _, err := res.Write([]byte("not gzip")); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	res.WriteHeader(200)
 }
 
@@ -118,6 +128,7 @@ func TestStream(t *testing.T) {
 		err         error
 		httpError   bool
 		gzip        bool
+		badEncoding bool
 	}{
 		{
 			desc:        "AVRO without mappingName",
@@ -150,6 +161,12 @@ func TestStream(t *testing.T) {
 			httpError:   true,
 			gzip:        true,
 		},
+		{
+			desc:        "Success with a response that cannot be decoded",
+			payload:     fakeContent{Name: "Doak", ID: 25},
+			mappingName: "jsonMap",
+			badEncoding: true,
+		},
 	}
 	for _, test := range tests {
 		test := test // capture
@@ -192,6 +209,9 @@ func TestStream(t *testing.T) {
 			if test.gzip {
 				db += ".gzip"
 			}
+			if test.badEncoding {
+				db = "badEncoding"
+			}
 
 			err = conn.StreamIngest(ctx, db, "table", &payload, properties.JSON, test.mappingName, "")
 
@@ -220,3 +240,37 @@ func TestStream(t *testing.T) {
 		})
 	}
 }
+
+func TestNewResolved(t *testing.T) {
+	t.Parallel()
+
+	auth := kusto.Authorization{Authorizer: autorest.NewBasicAuthorizer("", "")}
+
+	tests := []struct {
+		desc     string
+		endpoint string
+		address  string
+		wantHost string
+		err      bool
+	}{
+		{desc: "Same address", endpoint: "https://cluster.kusto.windows.net", address: "https://cluster.kusto.windows.net", wantHost: "cluster.kusto.windows.net"},
+		{desc: "Resolved address", endpoint: "https://cluster.kusto.windows.net", address: "https://cluster.privatelink.kusto.windows.net", wantHost: "cluster.privatelink.kusto.windows.net"},
+		{desc: "Invalid address", endpoint: "https://cluster.kusto.windows.net", address: "cluster", err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewResolved(test.endpoint, test.address, auth, &http.Client{})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer c.Close()
+			assert.Equal(t, test.wantHost, c.baseURL.Host)
+		})
+	}
+}
@@ -4,16 +4,35 @@ package gzip
 
 import (
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"sync"
 	"sync/atomic"
 )
 
-var compressPool = &sync.Pool{
-	New: func() interface{} {
-		return gzip.NewWriter(nil)
-	},
+// DefaultCompression is the compression level used by New() and Compress().
+const DefaultCompression = gzip.DefaultCompression
+
+// compressPools holds a pool of *gzip.Writer for every valid compression level, indexed by level-gzip.HuffmanOnly.
+var compressPools [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+func init() {
+	for i := range compressPools {
+		level := i + gzip.HuffmanOnly
+		compressPools[i].New = func() interface{} {
+			zw, _ := gzip.NewWriterLevel(nil, level)
+			return zw
+		}
+	}
+}
+
+// ValidateLevel returns an error if level is not a compression level supported by compress/gzip.
+func ValidateLevel(level int) error {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return fmt.Errorf("gzip compression level must be between %d and %d, was %d", gzip.HuffmanOnly, gzip.BestCompression, level)
+	}
+	return nil
 }
 
 // Streamer implements an io.ReadCloser that converts data from a non-compressed stream to a compressed stream.
@@ -21,13 +40,24 @@ type Streamer struct {
 	userInput   io.ReadCloser
 	outputRead  *io.PipeReader
 	outputWrite *io.PipeWriter
-	size        int64
+	inSize      int64
+	outSize     int64
+	level       int
 	err         atomic.Value // holds error
 }
 
-// New creates a new streamer object. Use Reset() to initialize it.
+// New creates a new streamer object that compresses at DefaultCompression. Use Reset() to initialize it.
 func New() *Streamer {
-	return &Streamer{}
+	return &Streamer{level: DefaultCompression}
+}
+
+// NewLevel creates a new streamer object that compresses at level, which must be valid for ValidateLevel().
+// Use Reset() to initialize it.
+func NewLevel(level int) (*Streamer, error) {
+	if err := ValidateLevel(level); err != nil {
+		return nil, err
+	}
+	return &Streamer{level: level}, nil
 }
 
 // Reset resets the streamer object to defaults and accepts the io.ReadCloser.
@@ -35,16 +65,23 @@ func New() *Streamer {
 func (s *Streamer) Reset(reader io.ReadCloser) {
 	s.userInput = reader
 	s.outputRead, s.outputWrite = io.Pipe()
-	s.size = 0
+	s.inSize = 0
+	s.outSize = 0
 	s.err = atomic.Value{}
 
 	s.run()
 }
 
-// InputSize returns the amount of data that the Streamer streamed. This will only be accurate for
+// InputSize returns the amount of uncompressed data that the Streamer read from its input. This will only be accurate for
 // the full stream after Read() has returned io.EOF and not before.
 func (s *Streamer) InputSize() int64 {
-	return atomic.LoadInt64(&s.size)
+	return atomic.LoadInt64(&s.inSize)
+}
+
+// OutputSize returns the amount of compressed data that the Streamer streamed. This will only be accurate for
+// the full stream after Read() has returned io.EOF and not before.
+func (s *Streamer) OutputSize() int64 {
+	return atomic.LoadInt64(&s.outSize)
 }
 
 func Compress(payload io.Reader) io.Reader {
@@ -59,28 +96,46 @@ func Compress(payload io.Reader) io.Reader {
 	return zw
 }
 
+// CompressLevel is the same as Compress(), but compresses at level, which must be valid for ValidateLevel().
+func CompressLevel(payload io.Reader, level int) (io.Reader, error) {
+	var closer io.ReadCloser
+	var ok bool
+	if closer, ok = payload.(io.ReadCloser); !ok {
+		closer = ioutil.NopCloser(payload)
+	}
+	zw, err := NewLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	zw.Reset(closer)
+
+	return zw, nil
+}
+
 // run copies the file into a buffer that we stream back via our Read() call.
 func (s *Streamer) run() {
-	zw := compressPool.Get().(*gzip.Writer)
+	pool := &compressPools[s.level-gzip.HuffmanOnly]
+	zw := pool.Get().(*gzip.Writer)
 	zw.Reset(s.outputWrite)
 
 	go func() {
-		defer compressPool.Put(zw)
+		defer pool.Put(zw)
 		defer s.outputWrite.Close()
 		defer zw.Close()
 		defer zw.Flush()
 
-		_, err := io.Copy(zw, s.userInput)
+		n, err := io.Copy(zw, s.userInput)
 		if err != nil {
 			s.err.Store(err)
 		}
+		atomic.StoreInt64(&s.inSize, n)
 	}()
 }
 
 // Read implements io.Reader.
 func (s *Streamer) Read(b []byte) (int, error) {
 	amount, err := s.outputRead.Read(b)
-	atomic.AddInt64(&s.size, int64(amount))
+	atomic.AddInt64(&s.outSize, int64(amount))
 	return amount, err
 }
 
@@ -48,6 +48,13 @@ func TestStreamer(t *testing.T) {
 		t.Fatalf("TestStreamer: got err == %s, want err == nil", err)
 	}
 
+	if streamer.InputSize() != int64(len(str)) {
+		t.Fatalf("TestStreamer(InputSize): got %d, want %d", streamer.InputSize(), len(str))
+	}
+	if streamer.OutputSize() != int64(compressedBuf.Len()) {
+		t.Fatalf("TestStreamer(OutputSize): got %d, want %d", streamer.OutputSize(), compressedBuf.Len())
+	}
+
 	gzipReader, err := gzip.NewReader(&compressedBuf)
 	if err != nil {
 		t.Fatalf("TestStreamer(gzip.NewReader(compressedBuf)): got err == %s, want err == nil", err)
@@ -62,3 +69,51 @@ func TestStreamer(t *testing.T) {
 		t.Fatalf("TestStreamer(input/output comparison): after compression/decompression the data was not the same")
 	}
 }
+
+func TestStreamerLevel(t *testing.T) {
+	t.Parallel()
+
+	input := bytes.Repeat([]byte("some repetitive content that compresses well "), 10000)
+
+	compress := func(t *testing.T, level int) []byte {
+		streamer, err := NewLevel(level)
+		if err != nil {
+			t.Fatalf("TestStreamerLevel(NewLevel(%d)): got err == %s, want err == nil", level, err)
+		}
+		streamer.Reset(ioutil.NopCloser(bytes.NewReader(input)))
+
+		compressed, err := ioutil.ReadAll(streamer)
+		if err != nil {
+			t.Fatalf("TestStreamerLevel(level %d): got err == %s, want err == nil", level, err)
+		}
+
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("TestStreamerLevel(level %d, gzip.NewReader): got err == %s, want err == nil", level, err)
+		}
+		got, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("TestStreamerLevel(level %d, decompress): got err == %s, want err == nil", level, err)
+		}
+		if !bytes.Equal(got, input) {
+			t.Fatalf("TestStreamerLevel(level %d): decompressed content does not match the input", level)
+		}
+		return compressed
+	}
+
+	none := compress(t, gzip.NoCompression)
+	best := compress(t, gzip.BestCompression)
+	compress(t, gzip.HuffmanOnly)
+	compress(t, gzip.BestSpeed)
+	compress(t, DefaultCompression)
+
+	if len(none) <= len(best) {
+		t.Errorf("TestStreamerLevel: NoCompression output was %d bytes, want more than the BestCompression output of %d bytes", len(none), len(best))
+	}
+
+	for _, level := range []int{gzip.HuffmanOnly - 1, gzip.BestCompression + 1} {
+		if _, err := NewLevel(level); err == nil {
+			t.Errorf("TestStreamerLevel(NewLevel(%d)): got err == nil, want err != nil", level)
+		}
+	}
+}
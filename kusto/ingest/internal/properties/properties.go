@@ -3,18 +3,23 @@
 package properties
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/google/uuid"
+	"golang.org/x/text/encoding"
 )
 
 // CompressionType is a file's compression type.
@@ -104,27 +109,29 @@ type dfDescriptor struct {
 	jsonName         string
 	detectableExt    string
 	validMappingKind bool
+	// compressible is false for binary formats that are compressed internally, which gzip does not make smaller.
+	compressible bool
 }
 
 var dfDescriptions = []dfDescriptor{
-	{"", "", "", false},
-	{"Avro", "avro", ".avro", true},
-	{"ApacheAvro", "avro", "", false},
-	{"Csv", "csv", ".csv", true},
-	{"Json", "json", ".json", true},
-	{"MultiJson", "multijson", "", false},
-	{"Orc", "orc", ".orc", true},
-	{"Parquet", "parquet", ".parquet", true},
-	{"Psv", "psv", ".psv", false},
-	{"Raw", "raw", ".raw", false},
-	{"Scsv", "scsv", ".scsv", false},
-	{"Sohsv", "sohsv", ".sohsv", false},
-	{"SStream", "sstream", ".ss", false},
-	{"Tsv", "tsv", ".tsv", false},
-	{"Tsve", "tsve", ".tsve", false},
-	{"Txt", "txt", ".txt", false},
-	{"W3cLogFile", "w3clogfile", ".w3clogfile", false},
-	{"SingleJson", "singlejson", "", false},
+	{"", "", "", false, true},
+	{"Avro", "avro", ".avro", true, false},
+	{"ApacheAvro", "apacheavro", "", false, false},
+	{"Csv", "csv", ".csv", true, true},
+	{"Json", "json", ".json", true, true},
+	{"MultiJson", "multijson", "", false, true},
+	{"Orc", "orc", ".orc", true, false},
+	{"Parquet", "parquet", ".parquet", true, false},
+	{"Psv", "psv", ".psv", false, true},
+	{"Raw", "raw", ".raw", false, true},
+	{"Scsv", "scsv", ".scsv", false, true},
+	{"Sohsv", "sohsv", ".sohsv", false, true},
+	{"SStream", "sstream", ".ss", false, false},
+	{"Tsv", "tsv", ".tsv", false, true},
+	{"Tsve", "tsve", ".tsve", false, true},
+	{"Txt", "txt", ".txt", false, true},
+	{"W3cLogFile", "w3clogfile", ".w3clogfile", false, true},
+	{"SingleJson", "singlejson", "", false, true},
 }
 
 // IngestionReportLevel defines which ingestion statuses are reported by the DM.
@@ -192,6 +199,16 @@ func (d DataFormat) IsValidMappingKind() bool {
 	return false
 }
 
+// ShouldCompress returns false for binary formats that are compressed internally, like Parquet, Avro and ORC, which
+// are uploaded without gzip compression. DFUnknown returns true.
+func (d DataFormat) ShouldCompress() bool {
+	if d > 0 && int(d) < len(dfDescriptions) {
+		return dfDescriptions[d].compressible
+	}
+
+	return true
+}
+
 // DataFormatDiscovery looks at the file name and tries to discern what the file format is.
 func DataFormatDiscovery(fName string) DataFormat {
 	name := fName
@@ -252,8 +269,98 @@ type SourceOptions struct {
 	// DontCompress indicates to not compress the file.
 	DontCompress bool
 
+	// DropMalformedRows indicates to drop CSV rows that do not have the expected number of fields before upload.
+	DropMalformedRows bool
+	// DroppedRows is set to the number of rows DropMalformedRows removed, once the data was read. It is a pointer so
+	// that copies of the properties made before the data was read see the count.
+	DroppedRows *int64
+
+	// StripBOM indicates to remove a UTF-8 byte order mark from the start of the data before upload.
+	StripBOM bool
+
+	// SourceEncoding is the encoding the data is converted from to UTF-8 before upload. nil means the data is UTF-8.
+	SourceEncoding encoding.Encoding
+
+	// IgnoreExtraColumns indicates to ingest with a validation policy that ignores source columns not in the mapping.
+	IgnoreExtraColumns bool
+
+	// ColumnOrder are the table columns the fields of CSV records go to, in order. The ingestion mapping is generated
+	// from it, it is kept to check the columns against the table schema.
+	ColumnOrder []string
+
+	// SkipIfTagExists is the ingest-by tag to look for before uploading. Empty means no check is done.
+	SkipIfTagExists string
+
+	// CreationTimePattern extracts the creation time of the data from the blob URI. nil means it is not used.
+	CreationTimePattern *regexp.Regexp
+
+	// AutoDeleteAfter is how long after the ingestion the data expires. 0 means it does not.
+	AutoDeleteAfter time.Duration
+	// AutoDeleteRetention is the soft delete period of the retention policy of the table that AutoDeleteAfter sets the
+	// creation time for. 0 means the creation time is not set.
+	AutoDeleteRetention time.Duration
+
+	// SampleRate is the fraction of records to keep when sampling the data before upload. 0 means no sampling.
+	SampleRate float64
+	// SampleSeed is the seed used to select the records when SampleRate is set.
+	SampleSeed int64
+
+	// RecordCounter is called with the number of records uploaded once the data was read. nil means records are not
+	// counted.
+	RecordCounter func(count int64)
+
+	// BlobSAS is a SAS token that is appended to the blob URI given to FromFile(). It is kept separate from the URI so
+	// that it is only part of the message sent to the service.
+	BlobSAS string
+
 	// OriginalSource is the path to the original source file, used for deletion.
 	OriginalSource string
+
+	// IgnoredOptions are the options of the ingestion that did not take effect.
+	IgnoredOptions []IgnoredOption
+}
+
+// IgnoredOption is an option passed to an ingestion that did not take effect, because the client, the source or the
+// data it was used with does not support it.
+type IgnoredOption struct {
+	// Option is the name of the option, like "ValidationPolicy".
+	Option string
+	// Reason says why the option did not take effect.
+	Reason string
+}
+
+// Ignore records that option did not take effect for reason.
+func (p *All) Ignore(option, reason string) {
+	p.Source.IgnoredOptions = append(p.Source.IgnoredOptions, IgnoredOption{Option: option, Reason: reason})
+}
+
+// UploadStats holds metrics about the upload of data to Blobstore.
+type UploadStats struct {
+	// UncompressedBytes is the amount of data read from the source. If the source was already compressed,
+	// this is the compressed size.
+	UncompressedBytes int64
+	// UploadedBytes is the amount of data uploaded to Blobstore. This is the compressed size if the client compressed the data.
+	UploadedBytes int64
+	// Compressed indicates that the client compressed the data before uploading it.
+	Compressed bool
+	// Duration is how long the upload to Blobstore took.
+	Duration time.Duration
+}
+
+// Upload is a blob uploaded by the queued client for an ingestion.
+type Upload struct {
+	// Stats are metrics about the upload.
+	Stats UploadStats
+	// Delete deletes the blob. It must only be called once the service no longer needs the blob.
+	Delete func(ctx context.Context) error
+}
+
+// CompressionRatio returns UncompressedBytes divided by UploadedBytes. It returns 0 if nothing was uploaded.
+func (u UploadStats) CompressionRatio() float64 {
+	if u.UploadedBytes == 0 {
+		return 0
+	}
+	return float64(u.UncompressedBytes) / float64(u.UploadedBytes)
 }
 
 // Ingestion is a JSON serializable set of options that must be provided to the service.
@@ -311,7 +418,24 @@ type Additional struct {
 	// has data tagged with an ingest-by: tag with the same value. This ensures idempotent data ingestion.
 	IngestIfNotExists string `json:"ingestIfNotExists,omitempty"`
 	// CreationTime is used to override the time considered for retantion policies, which by default is the time of ingestion.
+	// It is not sent if it is the zero value.
 	CreationTime time.Time `json:"creationTime,omitempty"`
+	// Extra holds additional properties that are not modeled by the SDK, keyed by their name. They are added to the
+	// JSON as given.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// IsModeledAdditional returns true if key is the name of a property that Additional has a field for. The service
+// does not distinguish the case of property names, so neither does this.
+func IsModeledAdditional(key string) bool {
+	t := reflect.TypeOf(Additional{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" && strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
 }
 
 // StatusTableDescription is a reference to the table status entry used for this ingestion command.
@@ -358,12 +482,23 @@ func (a Additional) MarshalJSON() ([]byte, error) {
 		m["ingestionMappingType"] = a.IngestionMappingType.CamelCase()
 	}
 
+	// omitempty has no effect on a time.Time, so a zero CreationTime would be sent as year 1 instead of letting the
+	// service use the time of ingestion.
+	if a.CreationTime.IsZero() {
+		delete(m, "creationTime")
+	}
+
+	for k, v := range a.Extra {
+		m[k] = v
+	}
+
 	return json.Marshal(m)
 }
 
-// MarshalJSONString will marshal Ingestion into a base64 encoded string.
-func (i Ingestion) MarshalJSONString() (base64String string, err error) {
-	i = i.defaults()
+// MarshalJSONString will marshal Ingestion into a base64 encoded string. c provides the default
+// SourceMessageCreationTime.
+func (i Ingestion) MarshalJSONString(c clock.Clock) (base64String string, err error) {
+	i = i.defaults(c)
 	if err := i.validate(); err != nil {
 		return "", err
 	}
@@ -377,13 +512,13 @@ func (i Ingestion) MarshalJSONString() (base64String string, err error) {
 }
 
 // defaults sets default values that can be auto-generated if not set. This is used inside our MarshalJSONString().
-func (i Ingestion) defaults() Ingestion {
+func (i Ingestion) defaults(c clock.Clock) Ingestion {
 	if uuidIsZero(i.ID) {
 		i.ID = uuid.New()
 	}
 
 	if i.SourceMessageCreationTime.IsZero() {
-		i.SourceMessageCreationTime = time.Now()
+		i.SourceMessageCreationTime = c.Now()
 	}
 
 	return i
@@ -0,0 +1,43 @@
+package properties
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaults(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-time.Hour)
+
+	tests := []struct {
+		desc        string
+		ingestion   Ingestion
+		wantCreated time.Time
+	}{
+		{
+			desc:        "Creation time from the clock",
+			wantCreated: now,
+		},
+		{
+			desc:        "Creation time already set",
+			ingestion:   Ingestion{SourceMessageCreationTime: created},
+			wantCreated: created,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := test.ingestion.defaults(clock.NewFake(now))
+			assert.Equal(t, test.wantCreated, got.SourceMessageCreationTime)
+			assert.False(t, uuidIsZero(got.ID))
+		})
+	}
+}
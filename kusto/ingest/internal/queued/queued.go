@@ -3,9 +3,11 @@
 package queued
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/url"
@@ -13,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
@@ -21,6 +24,8 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/resources"
 	"github.com/google/uuid"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-storage-queue-go/azqueue"
 )
@@ -35,16 +40,33 @@ const (
 
 	BlockSize   = 8 * _1MiB
 	Concurrency = 50
+
+	// captureBufferSize is the size of the buffer between an upload and the writer set with WithCapture(), so that
+	// writing the capture does not slow down the upload with many small writes.
+	captureBufferSize = _1MiB
 )
 
 // Queued provides methods for taking data from various sources and ingesting it into Kusto using queued ingestion.
 type Queued interface {
 	io.Closer
-	Local(ctx context.Context, from string, props properties.All) error
-	Reader(ctx context.Context, reader io.Reader, props properties.All) (string, error)
+	Local(ctx context.Context, from string, props properties.All) (properties.Upload, error)
+	Reader(ctx context.Context, reader io.Reader, props properties.All) (string, properties.Upload, error)
 	Blob(ctx context.Context, from string, fileSize int64, props properties.All) error
 }
 
+// countingReader is an io.Reader that counts the bytes read through it.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+// Read implements io.Reader.
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.Reader.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
 // uploadStream provides a type that mimics azblob.UploadStreamToBlockBlob to allow fakes for testing.
 type uploadStream func(context.Context, io.Reader, azblob.BlockBlobClient, azblob.UploadStreamToBlockBlobOptions) (azblob.BlockBlobCommitBlockListResponse, error)
 
@@ -64,6 +86,19 @@ type Ingestion struct {
 
 	bufferSize int
 	maxBuffers int
+
+	// capture receives a copy of every uploaded payload if set with WithCapture(). captureMu serializes uploads
+	// so that payloads are written one after the other.
+	capture   io.Writer
+	captureMu sync.Mutex
+
+	// storageCred is used for Blob Storage and queues instead of the SAS provided by the service if set with
+	// WithStorageCredential(). queueCred is storageCred adapted to the queue client.
+	storageCred azcore.TokenCredential
+	queueCred   azqueue.Credential
+
+	// compressionLevel is the gzip level used to compress uploads, set with WithCompressionLevel().
+	compressionLevel int
 }
 
 // Option is an optional argument to New().
@@ -77,12 +112,35 @@ func WithStaticBuffer(bufferSize int, maxBuffers int) Option {
 	}
 }
 
+// WithCapture copies the exact bytes of every upload to Blobstore into w. A nil w disables the capture.
+func WithCapture(w io.Writer) Option {
+	return func(s *Ingestion) {
+		s.capture = w
+	}
+}
+
+// WithCompressionLevel sets the gzip level used to compress uploads. The level must be valid for gzip.ValidateLevel().
+func WithCompressionLevel(level int) Option {
+	return func(s *Ingestion) {
+		s.compressionLevel = level
+	}
+}
+
+// WithStorageCredential makes uploads to Blob Storage and posts to the ingestion queues authenticate with cred instead
+// of the SAS tokens provided by the service.
+func WithStorageCredential(cred azcore.TokenCredential) Option {
+	return func(s *Ingestion) {
+		s.storageCred = cred
+	}
+}
+
 // New is the constructor for Ingestion.
 func New(db, table string, mgr *resources.Manager, options ...Option) (*Ingestion, error) {
 	i := &Ingestion{
-		db:    db,
-		table: table,
-		mgr:   mgr,
+		db:               db,
+		table:            table,
+		mgr:              mgr,
+		compressionLevel: gzip.DefaultCompression,
 		uploadStream: func(ctx context.Context, reader io.Reader, client azblob.BlockBlobClient, options azblob.UploadStreamToBlockBlobOptions) (azblob.BlockBlobCommitBlockListResponse, error) {
 			return client.UploadStreamToBlockBlob(ctx, reader, options)
 		},
@@ -110,63 +168,93 @@ func New(db, table string, mgr *resources.Manager, options ...Option) (*Ingestio
 	}
 	i.transferManager = transferManager
 
+	if i.storageCred != nil {
+		i.queueCred = queueCredential(i.storageCred)
+	}
+
 	return i, nil
 }
 
+// storageScope is the OAuth scope of tokens for Azure Storage.
+const storageScope = "https://storage.azure.com/.default"
+
+// queueCredential adapts cred to the queue client, which needs the token to be refreshed before it expires.
+func queueCredential(cred azcore.TokenCredential) azqueue.Credential {
+	return azqueue.NewTokenCredential("", func(tc azqueue.TokenCredential) time.Duration {
+		const retry = 10 * time.Second
+		const refreshBefore = 2 * time.Minute
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{storageScope}})
+		if err != nil {
+			// Queue posts fail with an authentication error until a token is retrieved.
+			return retry
+		}
+		tc.SetToken(token.Token)
+
+		if d := time.Until(token.ExpiresOn) - refreshBefore; d > retry {
+			return d
+		}
+		return retry
+	})
+}
+
 // Local ingests a local file into Kusto.
-func (i *Ingestion) Local(ctx context.Context, from string, props properties.All) error {
+func (i *Ingestion) Local(ctx context.Context, from string, props properties.All) (properties.Upload, error) {
 	container, err := i.upstreamContainer()
 	if err != nil {
-		return err
+		return properties.Upload{}, err
 	}
 
 	mgrResources, err := i.mgr.Resources()
 	if err != nil {
-		return err
+		return properties.Upload{}, err
 	}
 
 	// We want to check the queue size here so so we don't upload a file and then find we don't have a Kusto queue to stick
 	// it in. If we don't have a container, that is handled by containerQueue().
 	if len(mgrResources.Queues) == 0 {
-		return errors.ES(errors.OpFileIngest, errors.KBlobstore, "no Kusto queue resources are defined, there is no queue to upload to").SetNoRetry()
+		return properties.Upload{}, errors.ES(errors.OpFileIngest, errors.KBlobstore, "no Kusto queue resources are defined, there is no queue to upload to").SetNoRetry()
 	}
 
-	blobURL, size, err := i.localToBlob(ctx, from, container, &props)
+	blobURL, stats, err := i.localToBlob(ctx, from, container, &props)
 	if err != nil {
-		return err
+		return properties.Upload{}, err
 	}
 
-	if err := i.Blob(ctx, blobURL, size, props); err != nil {
-		return err
+	if err := i.Blob(ctx, blobURL, stats.UncompressedBytes, props); err != nil {
+		return properties.Upload{}, err
 	}
 
-	return nil
+	return properties.Upload{Stats: stats, Delete: i.blobDeleter(blobURL)}, nil
 }
 
 // Reader uploads a file via an io.Reader.
-// If the function succeeds, it returns the path of the created blob.
-func (i *Ingestion) Reader(ctx context.Context, reader io.Reader, props properties.All) (string, error) {
+// If the function succeeds, it returns the path of the created blob and the Upload.
+func (i *Ingestion) Reader(ctx context.Context, reader io.Reader, props properties.All) (string, properties.Upload, error) {
 	to, err := i.upstreamContainer()
 	if err != nil {
-		return "", err
+		return "", properties.Upload{}, err
 	}
 
 	mgrResources, err := i.mgr.Resources()
 	if err != nil {
-		return "", err
+		return "", properties.Upload{}, err
 	}
 
 	// We want to check the queue size here so so we don't upload a file and then find we don't have a Kusto queue to stick
 	// it in. If we don't have a container, that is handled by containerQueue().
 	if len(mgrResources.Queues) == 0 {
-		return "", errors.ES(errors.OpFileIngest, errors.KBlobstore, "no Kusto queue resources are defined, there is no queue to upload to").SetNoRetry()
+		return "", properties.Upload{}, errors.ES(errors.OpFileIngest, errors.KBlobstore, "no Kusto queue resources are defined, there is no queue to upload to").SetNoRetry()
 	}
 
 	shouldCompress := true
 	if props.Source.OriginalSource != "" {
 		shouldCompress = CompressionDiscovery(props.Source.OriginalSource) == properties.CTNone
 	}
-	if props.Source.DontCompress {
+	if props.Source.DontCompress || !props.Ingestion.Additional.Format.ShouldCompress() {
 		shouldCompress = false
 	}
 
@@ -186,30 +274,36 @@ func (i *Ingestion) Reader(ctx context.Context, reader io.Reader, props properti
 
 	size := int64(0)
 
+	counter := &countingReader{Reader: reader}
+	var upload io.Reader = counter
+	var gz *gzip.Streamer
 	if shouldCompress {
-		reader = gzip.Compress(reader)
+		gz, err = gzip.NewLevel(i.compressionLevel)
+		if err != nil {
+			return "", properties.Upload{}, errors.E(errors.OpFileIngest, errors.KClientArgs, err).SetNoRetry()
+		}
+		gz.Reset(ioutil.NopCloser(counter))
+		upload = gz
 	}
 
-	_, err = i.uploadStream(
-		ctx,
-		reader,
-		blobClient,
-		azblob.UploadStreamToBlockBlobOptions{TransferManager: i.transferManager},
-	)
-
+	start := nower()
+	err = i.stream(ctx, upload, blobClient)
 	if err != nil {
-		return blobName, errors.ES(errors.OpFileIngest, errors.KBlobstore, "problem uploading to Blob Storage: %s", err)
+		return blobName, properties.Upload{}, errors.ES(errors.OpFileIngest, errors.KBlobstore, "problem uploading to Blob Storage: %s", err)
 	}
 
-	if gz, ok := reader.(*gzip.Streamer); ok {
+	stats := properties.UploadStats{UncompressedBytes: counter.n, UploadedBytes: counter.n, Duration: nower().Sub(start)}
+	if gz != nil {
 		size = gz.InputSize()
+		stats.UploadedBytes = gz.OutputSize()
+		stats.Compressed = true
 	}
 
 	if err := i.Blob(ctx, blobClient.URL(), size, props); err != nil {
-		return blobName, err
+		return blobName, properties.Upload{}, err
 	}
 
-	return blobName, nil
+	return blobName, properties.Upload{Stats: stats, Delete: i.blobDeleter(blobClient.URL())}, nil
 }
 
 // Blob ingests a file from Azure Blob Storage into Kusto.
@@ -224,19 +318,12 @@ func (i *Ingestion) Blob(ctx context.Context, from string, fileSize int64, props
 		return err
 	}
 
-	props.Ingestion.BlobPath = from
-	if fileSize != 0 {
-		props.Ingestion.RawDataSize = fileSize
-	}
-
-	props.Ingestion.RetainBlobOnSuccess = !props.Source.DeleteLocalSource
-
-	err = CompleteFormatFromFileName(&props, from)
+	msg, err := Message(from, fileSize, props)
 	if err != nil {
 		return err
 	}
 
-	j, err := props.Ingestion.MarshalJSONString()
+	j, err := msg.MarshalJSONString(i.mgr.Clock())
 	if err != nil {
 		return errors.ES(errors.OpFileIngest, errors.KInternal, "could not marshal the ingestion blob info: %s", err).SetNoRetry()
 	}
@@ -253,6 +340,46 @@ func (i *Ingestion) Blob(ctx context.Context, from string, fileSize int64, props
 	return nil
 }
 
+// Message returns the ingestion message that Blob() posts to the queue for the blob at from.
+func Message(from string, fileSize int64, props properties.All) (properties.Ingestion, error) {
+	if props.Source.BlobSAS != "" {
+		var err error
+		from, err = addSAS(from, props.Source.BlobSAS)
+		if err != nil {
+			return properties.Ingestion{}, err
+		}
+	}
+
+	props.Ingestion.BlobPath = from
+	if fileSize != 0 {
+		props.Ingestion.RawDataSize = fileSize
+	}
+
+	props.Ingestion.RetainBlobOnSuccess = !props.Source.DeleteLocalSource
+
+	if err := CompleteFormatFromFileName(&props, from); err != nil {
+		return properties.Ingestion{}, err
+	}
+	return props.Ingestion, nil
+}
+
+// addSAS adds the sas query string to the blob URI. The URI must not already contain a SAS.
+func addSAS(uri, sas string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", errors.ES(errors.OpFileIngest, errors.KClientArgs, "blob URI could not be parsed: %s", err).SetNoRetry()
+	}
+	if u.Query().Get("sig") != "" {
+		return "", errors.ES(errors.OpFileIngest, errors.KClientArgs, "BlobSAS() option cannot be used with a blob URI that already has a SAS").SetNoRetry()
+	}
+	if u.RawQuery == "" {
+		u.RawQuery = sas
+	} else {
+		u.RawQuery += "&" + sas
+	}
+	return u.String(), nil
+}
+
 func CompleteFormatFromFileName(props *properties.All, from string) error {
 	// If they did not tell us how the file was encoded, try to discover it from the file extension.
 	if props.Ingestion.Additional.Format != properties.DFUnknown {
@@ -285,9 +412,14 @@ func (i *Ingestion) upstreamContainer() (azblob.ContainerClient, error) {
 	}
 
 	storageURI := mgrResources.Containers[rand.Intn(len(mgrResources.Containers))]
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net?%s", storageURI.Account(), storageURI.SAS().Encode())
 
-	service, err := azblob.NewServiceClientWithNoCredential(serviceURL, nil)
+	var service azblob.ServiceClient
+	if i.storageCred != nil {
+		service, err = azblob.NewServiceClient(fmt.Sprintf("https://%s.blob.core.windows.net", storageURI.Account()), i.storageCred, nil)
+	} else {
+		serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net?%s", storageURI.Account(), storageURI.SAS().Encode())
+		service, err = azblob.NewServiceClientWithNoCredential(serviceURL, nil)
+	}
 	if err != nil {
 		return azblob.ContainerClient{}, errors.E(errors.OpFileIngest, errors.KBlobstore, err)
 	}
@@ -310,9 +442,14 @@ func (i *Ingestion) upstreamQueue() (azqueue.MessagesURL, error) {
 	}
 
 	queue := mgrResources.Queues[rand.Intn(len(mgrResources.Queues))]
+
+	var creds azqueue.Credential = azqueue.NewAnonymousCredential()
 	service, _ := url.Parse(fmt.Sprintf("https://%s.queue.core.windows.net?%s", queue.Account(), queue.SAS().Encode()))
+	if i.queueCred != nil {
+		creds = i.queueCred
+		service, _ = url.Parse(fmt.Sprintf("https://%s.queue.core.windows.net", queue.Account()))
+	}
 
-	creds := azqueue.NewAnonymousCredential()
 	p := azqueue.NewPipeline(creds, azqueue.PipelineOptions{})
 
 	return azqueue.NewServiceURL(*service, p).NewQueueURL(queue.ObjectName()).NewMessagesURL(), nil
@@ -320,12 +457,18 @@ func (i *Ingestion) upstreamQueue() (azqueue.MessagesURL, error) {
 
 var nower = time.Now
 
-// localToBlob copies from a local to to an Azure Blobstore blob. It returns the URL of the Blob, the local file info and an
+// localToBlob copies from a local to to an Azure Blobstore blob. It returns the URL of the Blob, metrics about the upload and an
 // error if there was one.
-func (i *Ingestion) localToBlob(ctx context.Context, from string, container azblob.ContainerClient, props *properties.All) (string, int64, error) {
-	compression := CompressionDiscovery(from)
+func (i *Ingestion) localToBlob(ctx context.Context, from string, container azblob.ContainerClient, props *properties.All) (string, properties.UploadStats, error) {
+	format := props.Ingestion.Additional.Format
+	if format == properties.DFUnknown {
+		format = properties.DataFormatDiscovery(from)
+	}
+	// Files that are already compressed, in an archive or in a compressed binary format are uploaded as they are.
+	shouldCompress := CompressionDiscovery(from) == properties.CTNone && !props.Source.DontCompress && format.ShouldCompress()
+
 	blobName := fmt.Sprintf("%s_%s_%s_%s_%s", i.db, i.table, nower(), filepath.Base(uuid.New().String()), filepath.Base(from))
-	if compression == properties.CTNone {
+	if shouldCompress {
 		blobName = blobName + ".gz"
 	}
 
@@ -334,7 +477,7 @@ func (i *Ingestion) localToBlob(ctx context.Context, from string, container azbl
 
 	file, err := os.Open(from)
 	if err != nil {
-		return "", 0, errors.ES(
+		return "", properties.UploadStats{}, errors.ES(
 			errors.OpFileIngest,
 			errors.KLocalFileSystem,
 			"problem retrieving source file %q: %s", from, err,
@@ -343,47 +486,93 @@ func (i *Ingestion) localToBlob(ctx context.Context, from string, container azbl
 
 	stat, err := file.Stat()
 	if err != nil {
-		return "", 0, errors.ES(
+		return "", properties.UploadStats{}, errors.ES(
 			errors.OpFileIngest,
 			errors.KLocalFileSystem,
 			"could not Stat the file(%s): %s", from, err,
 		).SetNoRetry()
 	}
 
-	if compression == properties.CTNone && !props.Source.DontCompress {
-		gstream := gzip.New()
+	start := nower()
+	var stats properties.UploadStats
+	switch {
+	case shouldCompress:
+		var gstream *gzip.Streamer
+		gstream, err = gzip.NewLevel(i.compressionLevel)
+		if err != nil {
+			return "", properties.UploadStats{}, errors.E(errors.OpFileIngest, errors.KClientArgs, err).SetNoRetry()
+		}
 		gstream.Reset(file)
 
-		_, err = i.uploadStream(
+		err = i.stream(ctx, gstream, blobClient)
+		stats = properties.UploadStats{UncompressedBytes: gstream.InputSize(), UploadedBytes: gstream.OutputSize(), Compressed: true}
+	case i.capture != nil:
+		// UploadFileToBlockBlob() reads the file in parallel chunks, which cannot be captured in order, so stream it instead.
+		err = i.stream(ctx, file, blobClient)
+		stats = properties.UploadStats{UncompressedBytes: stat.Size(), UploadedBytes: stat.Size()}
+	default:
+		// The high-level API UploadFileToBlockBlob function uploads blocks in parallel for optimal performance, and can handle large files as well.
+		// This function calls StageBlock/CommitBlockList for files larger 256 MBs, and calls Upload for any file smaller
+		_, err = i.uploadBlob(
 			ctx,
-			gstream,
+			file,
 			blobClient,
-			azblob.UploadStreamToBlockBlobOptions{TransferManager: i.transferManager},
+			azblob.HighLevelUploadToBlockBlobOption{
+				BlockSize:   BlockSize,
+				Parallelism: Concurrency,
+			},
 		)
+		stats = properties.UploadStats{UncompressedBytes: stat.Size(), UploadedBytes: stat.Size()}
+	}
 
+	if err != nil {
+		return "", properties.UploadStats{}, errors.ES(errors.OpFileIngest, errors.KBlobstore, "problem uploading to Blob Storage: %s", err)
+	}
+
+	stats.Duration = nower().Sub(start)
+	return blobClient.URL(), stats, nil
+}
+
+// blobDeleter returns a function that deletes the blob at blobURL, which was uploaded by this client.
+func (i *Ingestion) blobDeleter(blobURL string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var (
+			blob azblob.BlobClient
+			err  error
+		)
+		if i.storageCred != nil {
+			blob, err = azblob.NewBlobClient(blobURL, i.storageCred, nil)
+		} else {
+			blob, err = azblob.NewBlobClientWithNoCredential(blobURL, nil)
+		}
+		if err == nil {
+			_, err = blob.Delete(ctx, nil)
+		}
 		if err != nil {
-			return "", 0, errors.ES(errors.OpFileIngest, errors.KBlobstore, "problem uploading to Blob Storage: %s", err)
+			return errors.ES(errors.OpFileIngest, errors.KBlobstore, "problem deleting the uploaded blob: %s", err)
 		}
-		return blobClient.URL(), gstream.InputSize(), nil
-	}
-
-	// The high-level API UploadFileToBlockBlob function uploads blocks in parallel for optimal performance, and can handle large files as well.
-	// This function calls StageBlock/CommitBlockList for files larger 256 MBs, and calls Upload for any file smaller
-	_, err = i.uploadBlob(
-		ctx,
-		file,
-		blobClient,
-		azblob.HighLevelUploadToBlockBlobOption{
-			BlockSize:   BlockSize,
-			Parallelism: Concurrency,
-		},
-	)
+		return nil
+	}
+}
 
-	if err != nil {
-		return "", 0, errors.ES(errors.OpFileIngest, errors.KBlobstore, "problem uploading to Blob Storage: %s", err)
+// stream uploads reader to blobClient. If WithCapture() was set, the uploaded bytes are also written to the capture.
+// The capture is written even if the upload fails, as that is when it is most useful.
+func (i *Ingestion) stream(ctx context.Context, reader io.Reader, blobClient azblob.BlockBlobClient) error {
+	options := azblob.UploadStreamToBlockBlobOptions{TransferManager: i.transferManager}
+	if i.capture == nil {
+		_, err := i.uploadStream(ctx, reader, blobClient, options)
+		return err
 	}
 
-	return blobClient.URL(), stat.Size(), nil
+	i.captureMu.Lock()
+	defer i.captureMu.Unlock()
+
+	w := bufio.NewWriterSize(i.capture, captureBufferSize)
+	_, err := i.uploadStream(ctx, io.TeeReader(reader, w), blobClient, options)
+	if flushErr := w.Flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("could not write the captured payload: %w", flushErr)
+	}
+	return err
 }
 
 // CompressionDiscovery looks at the file extension. If it is one we support, we return that
@@ -9,13 +9,20 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-storage-queue-go/azqueue"
 )
 
 func TestFormatDiscovery(t *testing.T) {
@@ -199,7 +206,8 @@ func TestLocalToBlob(t *testing.T) {
 			uploadBlob:   fbs.uploadBlobFile,
 		}
 
-		_, _, err := in.localToBlob(context.Background(), test.from, to, &properties.All{})
+		uploadedBytes := 0
+		_, stats, err := in.localToBlob(context.Background(), test.from, to, &properties.All{})
 		switch {
 		case err == nil && test.err:
 			t.Errorf("TestLocalToBlob(%s): got err == nil, want err != nil", test.desc)
@@ -211,6 +219,7 @@ func TestLocalToBlob(t *testing.T) {
 			continue
 		}
 
+		uploadedBytes = fbs.out.Len()
 		gotBuf := &bytes.Buffer{}
 		zr, err := gzip.NewReader(fbs.out)
 		if err != nil {
@@ -224,6 +233,75 @@ func TestLocalToBlob(t *testing.T) {
 		if gotBuf.String() != content {
 			t.Errorf("TestLocalToBlob(%s): got %q, want %q", test.desc, gotBuf.String(), content)
 		}
+
+		wantUncompressed := int64(len(content))
+		if !stats.Compressed {
+			wantUncompressed = int64(uploadedBytes)
+		}
+		if stats.UncompressedBytes != wantUncompressed {
+			t.Errorf("TestLocalToBlob(%s): got UncompressedBytes == %d, want %d", test.desc, stats.UncompressedBytes, wantUncompressed)
+		}
+		if stats.UploadedBytes != int64(uploadedBytes) {
+			t.Errorf("TestLocalToBlob(%s): got UploadedBytes == %d, want %d", test.desc, stats.UploadedBytes, uploadedBytes)
+		}
+	}
+}
+
+func TestLocalToBlobCompressedFormat(t *testing.T) {
+	t.Parallel()
+
+	content := "PAR1 not really parquet"
+	to, err := azblob.NewContainerClientWithNoCredential("https://account.windows.net", nil)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	parquet := filepath.Join(dir, "data.parquet")
+	require.NoError(t, ioutil.WriteFile(parquet, []byte(content), 0770))
+	noExt := filepath.Join(dir, "data")
+	require.NoError(t, ioutil.WriteFile(noExt, []byte(content), 0770))
+
+	tests := []struct {
+		desc         string
+		from         string
+		props        properties.All
+		wantCompress bool
+	}{
+		{desc: "Format from the file name", from: parquet},
+		{
+			desc:  "Format from the properties",
+			from:  noExt,
+			props: properties.All{Ingestion: properties.Ingestion{Additional: properties.Additional{Format: properties.AVRO}}},
+		},
+		{
+			desc:  "DontCompress",
+			from:  noExt,
+			props: properties.All{Source: properties.SourceOptions{DontCompress: true}},
+		},
+		{desc: "Text format is compressed", from: noExt, wantCompress: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			fbs := &fakeBlobstore{out: &bytes.Buffer{}}
+			in := &Ingestion{
+				db:           "database",
+				table:        "table",
+				uploadStream: fbs.uploadBlobStream,
+				uploadBlob:   fbs.uploadBlobFile,
+			}
+
+			blobURL, stats, err := in.localToBlob(context.Background(), test.from, to, &test.props)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantCompress, stats.Compressed)
+			assert.Equal(t, test.wantCompress, strings.HasSuffix(blobURL, ".gz"))
+			if !test.wantCompress {
+				assert.Equal(t, content, fbs.out.String())
+			}
+		})
 	}
 }
 
@@ -303,3 +381,161 @@ func TestIsLocalPath(t *testing.T) {
 		})
 	}
 }
+
+func TestAddSAS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		uri  string
+		sas  string
+		want string
+		err  bool
+	}{
+		{
+			desc: "URI without a query",
+			uri:  "https://account.blob.core.windows.net/container/file.csv",
+			sas:  "sv=2020-08-04&sig=secret",
+			want: "https://account.blob.core.windows.net/container/file.csv?sv=2020-08-04&sig=secret",
+		},
+		{
+			desc: "URI with a query",
+			uri:  "https://account.blob.core.windows.net/container/file.csv?snapshot=1",
+			sas:  "sv=2020-08-04&sig=secret",
+			want: "https://account.blob.core.windows.net/container/file.csv?snapshot=1&sv=2020-08-04&sig=secret",
+		},
+		{
+			desc: "URI already has a SAS",
+			uri:  "https://account.blob.core.windows.net/container/file.csv?sv=2020-08-04&sig=other",
+			sas:  "sv=2020-08-04&sig=secret",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := addSAS(test.uri, test.sas)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCapture(t *testing.T) {
+	t.Parallel()
+
+	to, err := azblob.NewContainerClientWithNoCredential("https://account.windows.net", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	plain := filepath.Join(dir, "capture.csv")
+	if err := ioutil.WriteFile(plain, []byte("a,b\nc,d\n"), 0644); err != nil {
+		panic(err)
+	}
+	compressed := filepath.Join(dir, "capture.csv.gz")
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, _ = zw.Write([]byte("a,b\nc,d\n"))
+	_ = zw.Close()
+	if err := ioutil.WriteFile(compressed, buf.Bytes(), 0644); err != nil {
+		panic(err)
+	}
+
+	tests := []struct {
+		desc      string
+		from      string
+		uploadErr bool
+	}{
+		{desc: "Compressed by the client", from: plain},
+		{desc: "Already compressed", from: compressed},
+		{desc: "Upload fails", from: plain, uploadErr: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			fbs := &fakeBlobstore{shouldErr: test.uploadErr, out: &bytes.Buffer{}}
+			captured := &bytes.Buffer{}
+			in := &Ingestion{
+				db:           "database",
+				table:        "table",
+				uploadStream: fbs.uploadBlobStream,
+				uploadBlob: func(context.Context, *os.File, azblob.BlockBlobClient, azblob.HighLevelUploadToBlockBlobOption) (*http.Response, error) {
+					panic("a captured upload must be streamed")
+				},
+			}
+			WithCapture(captured)(in)
+
+			_, _, err := in.localToBlob(context.Background(), test.from, to, &properties.All{})
+			if test.uploadErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotZero(t, fbs.out.Len())
+			assert.Equal(t, fbs.out.Bytes(), captured.Bytes())
+		})
+	}
+}
+
+type fakeTokenCredential struct {
+	token string
+	err   error
+}
+
+func (f fakeTokenCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (*azcore.AccessToken, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if len(options.Scopes) != 1 || options.Scopes[0] != storageScope {
+		return nil, fmt.Errorf("unexpected scopes %v", options.Scopes)
+	}
+	return &azcore.AccessToken{Token: f.token, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestQueueCredential(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		cred fakeTokenCredential
+		want string
+	}{
+		{desc: "Token is set", cred: fakeTokenCredential{token: "token"}, want: "token"},
+		{desc: "Token retrieval fails", cred: fakeTokenCredential{err: fmt.Errorf("no token")}, want: ""},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			in, err := New("database", "table", nil, WithStorageCredential(test.cred))
+			assert.NoError(t, err)
+
+			tc, ok := in.queueCred.(azqueue.TokenCredential)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, test.want, tc.Token())
+		})
+	}
+}
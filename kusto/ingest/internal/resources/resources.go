@@ -15,6 +15,7 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto"
 	kustoErrors "github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
 	"github.com/cenkalti/backoff/v4"
 )
 
@@ -22,6 +23,11 @@ const (
 	defaultInitialInterval = 1 * time.Second
 	defaultMultiplier      = 2
 	retryCount             = 4
+	refreshInterval        = 1 * time.Hour
+	fetchRetryInterval     = 10 * time.Second
+	// defaultFetchTimeout is how long fetching the resources or the Kusto identity token can take if WithFetchTimeout()
+	// is not provided.
+	defaultFetchTimeout = 30 * time.Second
 )
 
 // mgmter is a private interface that allows us to write hermetic tests against the kusto.Client.Mgmt() method.
@@ -142,16 +148,44 @@ type Manager struct {
 	kustoTokenCacheExpiration time.Time
 	authLock                  sync.Mutex
 	fetchLock                 sync.Mutex
+	clock                     clock.Clock
+	fetchTimeout              time.Duration
+}
+
+// Option is an optional argument to New().
+type Option func(m *Manager)
+
+// WithClock sets the clock used for resource refreshes, token cache expiration and retry backoffs.
+// This is mostly useful for tests. If not set the system time is used.
+func WithClock(c clock.Clock) Option {
+	return func(m *Manager) {
+		m.clock = c
+	}
+}
+
+// WithFetchTimeout sets how long fetching the ingestion resources or the Kusto identity token from the service can
+// take, including retries, before it fails with a KTimeout error. The default is 30 seconds. Values less than 1 are
+// ignored.
+func WithFetchTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.fetchTimeout = d
+		}
+	}
 }
 
 // New is the constructor for Manager.
-func New(client mgmter) (*Manager, error) {
+func New(client mgmter, options ...Option) (*Manager, error) {
 	m := &Manager{client: client, done: make(chan struct{})}
+	for _, o := range options {
+		o(m)
+	}
+
 	if err := m.fetch(context.Background()); err != nil {
 		return nil, err
 	}
 
-	m.kustoTokenCacheExpiration = time.Now().UTC()
+	m.kustoTokenCacheExpiration = m.now().UTC()
 	go m.renewResources()
 
 	m.authLock = sync.Mutex{}
@@ -173,14 +207,32 @@ func (m *Manager) Close() {
 	}
 }
 
+// Clock returns the clock set with WithClock() or the system clock.
+func (m *Manager) Clock() clock.Clock {
+	if m.clock == nil {
+		return clock.Real{}
+	}
+	return m.clock
+}
+
+// getFetchTimeout returns the timeout set with WithFetchTimeout() or the default one.
+func (m *Manager) getFetchTimeout() time.Duration {
+	if m.fetchTimeout <= 0 {
+		return defaultFetchTimeout
+	}
+	return m.fetchTimeout
+}
+
+func (m *Manager) now() time.Time {
+	return m.Clock().Now()
+}
+
 func (m *Manager) renewResources() {
-	tick := time.NewTicker(1 * time.Hour)
 	for {
 		select {
-		case <-tick.C:
+		case <-m.Clock().After(refreshInterval):
 			m.fetchRetry(context.Background())
 		case <-m.done:
-			tick.Stop()
 			return
 		}
 	}
@@ -191,13 +243,17 @@ func (m *Manager) renewResources() {
 func (m *Manager) AuthContext(ctx context.Context) (string, error) {
 	m.authLock.Lock()
 	defer m.authLock.Unlock()
-	if m.kustoTokenCacheExpiration.After(time.Now().UTC()) {
+	if m.kustoTokenCacheExpiration.After(m.now().UTC()) {
 		return m.kustoToken.AuthContext, nil
 	}
 
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, m.getFetchTimeout())
+	defer cancel()
+
 	var rows *kusto.RowIterator
-	retryCtx := backoff.WithContext(InitBackoff(), ctx)
-	err := backoff.Retry(func() error {
+	retryCtx := backoff.WithContext(m.initBackoff(), ctx)
+	err := backoff.RetryNotifyWithTimer(func() error {
 		var err error
 		rows, err = m.client.Mgmt(ctx, "NetDefaultDB", kusto.NewStmt(".get kusto identity token"), kusto.IngestionEndpoint())
 		if err == nil {
@@ -210,9 +266,12 @@ func (m *Manager) AuthContext(ctx context.Context) (string, error) {
 			}
 		}
 		return backoff.Permanent(err)
-	}, retryCtx)
+	}, retryCtx, nil, &clockTimer{clock: m.Clock()})
 
 	if err != nil {
+		if tErr := m.timeoutError(ctx, parent, "getting the Kusto identity token"); tErr != nil {
+			return "", tErr
+		}
 		return "", fmt.Errorf("problem getting authorization context from Kusto via Mgmt: %s", err)
 	}
 
@@ -228,11 +287,14 @@ func (m *Manager) AuthContext(ctx context.Context) (string, error) {
 		},
 	)
 	if err != nil {
+		if tErr := m.timeoutError(ctx, parent, "getting the Kusto identity token"); tErr != nil {
+			return "", tErr
+		}
 		return "", err
 	}
 
 	m.kustoToken = token
-	m.kustoTokenCacheExpiration = time.Now().UTC().Add(time.Hour)
+	m.kustoTokenCacheExpiration = m.now().UTC().Add(time.Hour)
 	return token.AuthContext, nil
 }
 
@@ -280,9 +342,13 @@ func (m *Manager) fetch(ctx context.Context) error {
 	m.fetchLock.Lock()
 	defer m.fetchLock.Unlock()
 
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, m.getFetchTimeout())
+	defer cancel()
+
 	var rows *kusto.RowIterator
-	retryCtx := backoff.WithContext(InitBackoff(), ctx)
-	err := backoff.Retry(func() error {
+	retryCtx := backoff.WithContext(m.initBackoff(), ctx)
+	err := backoff.RetryNotifyWithTimer(func() error {
 		var err error
 		rows, err = m.client.Mgmt(ctx, "NetDefaultDB", kusto.NewStmt(".get ingestion resources"), kusto.IngestionEndpoint())
 		if err == nil {
@@ -295,9 +361,12 @@ func (m *Manager) fetch(ctx context.Context) error {
 			}
 		}
 		return backoff.Permanent(err)
-	}, retryCtx)
+	}, retryCtx, nil, &clockTimer{clock: m.Clock()})
 
 	if err != nil {
+		if tErr := m.timeoutError(ctx, parent, "getting the ingestion resources"); tErr != nil {
+			return tErr
+		}
 		return fmt.Errorf("problem getting ingestion resources from Kusto: %s", err)
 	}
 
@@ -315,6 +384,9 @@ func (m *Manager) fetch(ctx context.Context) error {
 		},
 	)
 	if err != nil {
+		if tErr := m.timeoutError(ctx, parent, "getting the ingestion resources"); tErr != nil {
+			return tErr
+		}
 		return fmt.Errorf("problem reading ingestion resources from Kusto: %s", err)
 	}
 
@@ -332,19 +404,34 @@ func (m *Manager) fetchRetry(ctx context.Context) {
 		default:
 		}
 
-		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		err := m.fetch(ctx)
-		cancel()
-		if err != nil {
+		if err := m.fetch(ctx); err != nil {
 			attempts++
 			//log.Printf("problem fetching the resources from Kusto Mgmt(attempt %d): %s", attempts, err)
-			time.Sleep(10 * time.Second)
+			select {
+			case <-m.done:
+				return
+			case <-m.Clock().After(fetchRetryInterval):
+			}
 			continue
 		}
 		return
 	}
 }
 
+// timeoutError returns a KTimeout error if ctx, the context of a call doing what, expired because of the timeout set
+// with WithFetchTimeout() rather than because parent, the context the call was made with, is done. Otherwise it
+// returns nil.
+func (m *Manager) timeoutError(ctx, parent context.Context, what string) error {
+	if ctx.Err() != context.DeadlineExceeded || parent.Err() != nil {
+		return nil
+	}
+	return kustoErrors.ES(
+		kustoErrors.OpMgmt,
+		kustoErrors.KTimeout,
+		"%s from the ingestion service timed out after %s, the service may be slow or unreachable", what, m.getFetchTimeout(),
+	)
+}
+
 // Resources returns information about the ingestion resources. This will used cached information instead
 // of fetching from source.
 func (m *Manager) Resources() (Ingestion, error) {
@@ -356,8 +443,34 @@ func (m *Manager) Resources() (Ingestion, error) {
 }
 
 func InitBackoff() backoff.BackOff {
+	return initBackoff(clock.Real{})
+}
+
+func (m *Manager) initBackoff() backoff.BackOff {
+	return initBackoff(m.Clock())
+}
+
+func initBackoff(c clock.Clock) backoff.BackOff {
 	exp := backoff.NewExponentialBackOff()
 	exp.InitialInterval = defaultInitialInterval
 	exp.Multiplier = defaultMultiplier
+	exp.Clock = c
+	exp.Reset()
 	return backoff.WithMaxRetries(exp, retryCount)
 }
+
+// clockTimer implements backoff.Timer using a clock.Clock, so that retry waits follow the Manager's clock.
+type clockTimer struct {
+	clock clock.Clock
+	c     <-chan time.Time
+}
+
+func (t *clockTimer) Start(d time.Duration) {
+	t.c = t.clock.After(d)
+}
+
+func (t *clockTimer) Stop() {}
+
+func (t *clockTimer) C() <-chan time.Time {
+	return t.c
+}
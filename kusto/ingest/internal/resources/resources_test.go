@@ -2,13 +2,20 @@ package resources
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/Azure/azure-kusto-go/kusto"
+	kustoErrors "github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/data/table"
 	"github.com/Azure/azure-kusto-go/kusto/data/types"
 	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
 )
 
 func TestParse(t *testing.T) {
@@ -264,3 +271,142 @@ func TestResources(t *testing.T) {
 		}
 	}
 }
+
+// countingMgmt counts the number of Mgmt() calls made on a FakeMgmt.
+type countingMgmt struct {
+	*FakeMgmt
+	calls int32
+}
+
+func (c *countingMgmt) Mgmt(ctx context.Context, db string, query kusto.Stmt, options ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.FakeMgmt.Mgmt(ctx, db, query, options...)
+}
+
+func TestRenewResourcesWithClock(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	mgmt := &countingMgmt{FakeMgmt: SuccessfulFakeResources()}
+
+	manager, err := New(mgmt, WithClock(fakeClock))
+	require.NoError(t, err)
+	defer manager.Close()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mgmt.calls))
+
+	// Wait for the renewal goroutine to block on the clock, then move past the refresh interval.
+	require.Eventually(t, func() bool { return fakeClock.Waiters() == 1 }, 5*time.Second, time.Millisecond)
+	fakeClock.Advance(refreshInterval - time.Minute)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mgmt.calls))
+
+	fakeClock.Advance(time.Minute)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&mgmt.calls) == 2 }, 5*time.Second, time.Millisecond)
+}
+
+func TestAuthContextCacheWithClock(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	mgmt := &countingMgmt{
+		FakeMgmt: FakeAuthContext(
+			[]value.Values{
+				{
+					value.String{
+						Valid: true,
+						Value: "authtoken",
+					},
+				},
+			},
+			false,
+		),
+	}
+	manager := &Manager{client: mgmt, clock: fakeClock}
+
+	got, err := manager.AuthContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "authtoken", got)
+
+	fakeClock.Advance(30 * time.Minute)
+	got, err = manager.AuthContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "authtoken", got)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mgmt.calls))
+
+	// Past the cache expiration, we should go back to the service.
+	fakeClock.Advance(31 * time.Minute)
+	_, _ = manager.AuthContext(context.Background())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&mgmt.calls))
+}
+
+// slowMgmt is a mgmter that does not answer until the context of the call is done.
+type slowMgmt struct{}
+
+func (slowMgmt) Mgmt(ctx context.Context, _ string, _ kusto.Stmt, _ ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFetchTimeout(t *testing.T) {
+	t.Parallel()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		desc        string
+		ctx         context.Context
+		call        func(ctx context.Context, m *Manager) error
+		wantTimeout bool
+	}{
+		{
+			desc:        "Resources time out",
+			ctx:         context.Background(),
+			call:        func(ctx context.Context, m *Manager) error { return m.fetch(ctx) },
+			wantTimeout: true,
+		},
+		{
+			desc: "Identity token times out",
+			ctx:  context.Background(),
+			call: func(ctx context.Context, m *Manager) error {
+				_, err := m.AuthContext(ctx)
+				return err
+			},
+			wantTimeout: true,
+		},
+		{
+			desc: "Canceled context is not a timeout",
+			ctx:  canceled,
+			call: func(ctx context.Context, m *Manager) error { return m.fetch(ctx) },
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			m := &Manager{client: slowMgmt{}}
+			WithFetchTimeout(10 * time.Millisecond)(m)
+
+			start := time.Now()
+			err := test.call(test.ctx, m)
+			require.Error(t, err)
+			assert.Less(t, int64(time.Since(start)), int64(10*time.Second))
+
+			e, ok := kustoErrors.GetKustoError(err)
+			assert.Equal(t, test.wantTimeout, ok && e.Kind == kustoErrors.KTimeout, "got error: %s", err)
+		})
+	}
+}
+
+func TestFetchTimeoutDefault(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{}
+	assert.Equal(t, defaultFetchTimeout, m.getFetchTimeout())
+	WithFetchTimeout(-1)(m)
+	assert.Equal(t, defaultFetchTimeout, m.getFetchTimeout())
+	WithFetchTimeout(time.Minute)(m)
+	assert.Equal(t, time.Minute, m.getFetchTimeout())
+}
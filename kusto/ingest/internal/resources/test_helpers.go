@@ -123,24 +123,36 @@ type FsMock struct {
 	OnLocal  func(ctx context.Context, from string, props properties.All) error
 	OnReader func(ctx context.Context, reader io.Reader, props properties.All) (string, error)
 	OnBlob   func(ctx context.Context, from string, fileSize int64, props properties.All) error
+	OnDelete func(ctx context.Context, from string) error
 }
 
 func (f FsMock) Close() error {
 	return nil
 }
 
-func (f FsMock) Local(ctx context.Context, from string, props properties.All) error {
+func (f FsMock) Local(ctx context.Context, from string, props properties.All) (properties.Upload, error) {
 	if f.OnLocal != nil {
-		return f.OnLocal(ctx, from, props)
+		return properties.Upload{Delete: f.deleter(from)}, f.OnLocal(ctx, from, props)
 	}
-	return nil
+	return properties.Upload{Delete: f.deleter(from)}, nil
 }
 
-func (f FsMock) Reader(ctx context.Context, reader io.Reader, props properties.All) (string, error) {
+func (f FsMock) Reader(ctx context.Context, reader io.Reader, props properties.All) (string, properties.Upload, error) {
 	if f.OnReader != nil {
-		return f.OnReader(ctx, reader, props)
+		path, err := f.OnReader(ctx, reader, props)
+		return path, properties.Upload{Delete: f.deleter(path)}, err
+	}
+	return "", properties.Upload{Delete: f.deleter("")}, nil
+}
+
+// deleter returns the properties.Upload.Delete function of the upload of from, which calls OnDelete.
+func (f FsMock) deleter(from string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if f.OnDelete != nil {
+			return f.OnDelete(ctx, from)
+		}
+		return nil
 	}
-	return "", nil
 }
 
 func (f FsMock) Blob(ctx context.Context, from string, fileSize int64, props properties.All) error {
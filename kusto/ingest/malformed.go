@@ -0,0 +1,69 @@
+package ingest
+
+// malformed.go holds the client side CSV preprocessing used by the DropMalformedRows() option.
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// applyMalformedRowsFilter wraps reader with a CSV filter if the DropMalformedRows() option was provided and the
+// data is CSV.
+func applyMalformedRowsFilter(reader io.Reader, props *properties.All) io.Reader {
+	if !props.Source.DropMalformedRows {
+		return reader
+	}
+	props.Source.DropMalformedRows = false
+
+	switch props.Ingestion.Additional.Format {
+	case DFUnknown, CSV:
+		props.Source.DroppedRows = new(int64)
+		return dropMalformedRows(reader, props.Source.DroppedRows)
+	}
+	props.Ignore("DropMalformedRows", fmt.Sprintf("the format is %s, only CSV rows are checked", props.Ingestion.Additional.Format))
+	return reader
+}
+
+// dropMalformedRows returns an io.Reader that outputs the CSV content of reader with all rows removed that do
+// not have the same number of fields as the first row. The number of dropped rows is stored in dropped once the input
+// has been fully read, before the returned reader reports io.EOF. Any other CSV parsing error is returned from the
+// reader's Read() call.
+func dropMalformedRows(reader io.Reader, dropped *int64) io.Reader {
+	pr, pw := io.Pipe()
+
+	go func() {
+		in := csv.NewReader(reader)
+		in.ReuseRecord = true
+		out := csv.NewWriter(pw)
+
+		var count int64
+		for {
+			rec, err := in.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if errors.Is(err, csv.ErrFieldCount) {
+					count++
+					continue
+				}
+				pw.CloseWithError(err)
+				return
+			}
+			if err := out.Write(rec); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		out.Flush()
+
+		*dropped = count
+		pw.CloseWithError(out.Error())
+	}()
+
+	return pr
+}
@@ -0,0 +1,78 @@
+package ingest
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropMalformedRows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		format      properties.DataFormat
+		input       string
+		want        string
+		wantDropped int64
+	}{
+		{
+			desc:   "No malformed rows",
+			format: CSV,
+			input:  "a,b,c\n1,2,3\n",
+			want:   "a,b,c\n1,2,3\n",
+		},
+		{
+			desc:        "Drops rows with too few and too many fields",
+			format:      CSV,
+			input:       "a,b,c\n1,2\n1,2,3\n1,2,3,4\n4,5,6\n",
+			want:        "a,b,c\n1,2,3\n4,5,6\n",
+			wantDropped: 2,
+		},
+		{
+			desc:        "Unknown format is treated as CSV",
+			format:      DFUnknown,
+			input:       "a,b\n1\n2,3\n",
+			want:        "a,b\n2,3\n",
+			wantDropped: 1,
+		},
+		{
+			desc:   "Other formats are not touched",
+			format: JSON,
+			input:  "{\"a\": 1}\n{\"a\": 1, \"b\": 2}\n",
+			want:   "{\"a\": 1}\n{\"a\": 1, \"b\": 2}\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			props.Source.DropMalformedRows = true
+			props.Ingestion.Additional.Format = test.format
+
+			got, err := ioutil.ReadAll(applyMalformedRowsFilter(strings.NewReader(test.input), &props))
+			require.NoError(t, err)
+			assert.Equal(t, test.want, string(got))
+			assert.False(t, props.Source.DropMalformedRows)
+
+			// The count is only known once the data was read, like when the result of the ingestion is created.
+			result := newResult()
+			result.putProps(props)
+			assert.Equal(t, test.wantDropped, result.DroppedRows())
+		})
+	}
+}
+
+func TestDropMalformedRowsParseError(t *testing.T) {
+	t.Parallel()
+
+	_, err := ioutil.ReadAll(dropMalformedRows(strings.NewReader("a,b\n\"1,2\n"), new(int64)))
+	assert.Error(t, err)
+}
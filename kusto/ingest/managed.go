@@ -1,21 +1,22 @@
 package ingest
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
-	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/gzip"
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/google/uuid"
 )
 
 const (
-	mb                     = 1024 * 1024
+	mb = 1024 * 1024
+	// maxStreamingSize is the limit of the data of a streaming ingestion request, which the service documents as 4MB
+	// for every cluster. No command returns the limit of a cluster, so it is not discovered at runtime.
+	// See: https://docs.microsoft.com/en-us/azure/data-explorer/ingest-data-streaming#limitations
 	maxStreamingSize       = 4 * mb
 	defaultInitialInterval = 1 * time.Second
 	defaultMultiplier      = 2
@@ -25,11 +26,13 @@ const (
 type Managed struct {
 	queued    *Ingestion
 	streaming *Streaming
+	// auto is set by WithAutoIngestionMethod().
+	auto *autoMethod
 }
 
 // NewManaged is a constructor for Managed.
 func NewManaged(client QueryClient, db, table string, options ...Option) (*Managed, error) {
-	queued, err := New(client, db, table, options...)
+	queued, err := newIngestion(client, db, table, true, options)
 	if err != nil {
 		return nil, err
 	}
@@ -38,61 +41,129 @@ func NewManaged(client QueryClient, db, table string, options ...Option) (*Manag
 		return nil, err
 	}
 
-	return &Managed{
+	m := &Managed{
 		queued:    queued,
 		streaming: streaming,
-	}, nil
+	}
+	if queued.autoMethod {
+		m.auto = newAutoMethod(client, queued.getClock())
+	}
+	return m, nil
 }
 
 func (m *Managed) FromFile(ctx context.Context, fPath string, options ...FileOption) (*Result, error) {
 	props := m.newProp()
-	file, err := prepFileAndProps(fPath, &props, options, ManagedClient)
+	file, err := prepFileAndProps(fPath, &props, m.queued.withDefaults(options), ManagedClient)
 
 	if err == FileIsBlobErr { // Non-local file - fallback to queued
-		return m.queued.fromFile(ctx, fPath, []FileOption{}, props)
+		result, err := m.queued.fromFile(ctx, fPath, []FileOption{}, props)
+		return withDecision(result, err, MethodQueued, "blobs are always ingested queued")
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	return m.managedStreamImpl(ctx, file, props)
+	payload, err := applySourceEncoding(file, &props, m.queued.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	payload, err = applyStripBOM(payload, &props, m.queued.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	payload, reportCount, err := applyRecordCounter(payload, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportOnSuccess(reportCount)(m.managedStreamImpl(ctx, payload, props))
 }
 
 func (m *Managed) FromReader(ctx context.Context, reader io.Reader, options ...FileOption) (*Result, error) {
 	props := m.newProp()
 
-	for _, prop := range options {
+	for _, prop := range m.queued.withDefaults(options) {
 		err := prop.Run(&props, ManagedClient, FromReader)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return m.managedStreamImpl(ctx, reader, props)
+	if err := applyIgnoreExtraColumns(&props); err != nil {
+		return nil, err
+	}
+
+	reader, err := applySourceEncoding(reader, &props, m.queued.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	reader, err = applyStripBOM(reader, &props, m.queued.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	reader = applyMalformedRowsFilter(reader, &props)
+	reader, err = applySampleRate(reader, &props)
+	if err != nil {
+		return nil, err
+	}
+	reader, reportCount, err := applyRecordCounter(reader, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportOnSuccess(reportCount)(m.managedStreamImpl(ctx, reader, props))
 }
 
 func (m *Managed) managedStreamImpl(ctx context.Context, payload io.Reader, props properties.All) (*Result, error) {
-	compress := !props.Source.DontCompress
-	if compress {
-		payload = gzip.Compress(payload)
-		props.Source.DontCompress = true
+	if err := m.queued.checkTableExists(ctx, props.Ingestion.DatabaseName, props.Ingestion.TableName); err != nil {
+		return nil, err
 	}
-	maxSize := maxStreamingSize
 
-	buf, err := io.ReadAll(io.LimitReader(payload, int64(maxSize+1)))
+	// Streaming ingestion does not tag the data, so data checked with SkipIfTagExists() is queued to be tagged.
+	tagged := props.Source.SkipIfTagExists != ""
+	skipResult := newResult()
+	skipped, err := m.queued.skipIfTagExists(ctx, &props, skipResult)
 	if err != nil {
 		return nil, err
 	}
+	if skipped {
+		return skipResult, nil
+	}
+
+	var sp streamPayload
+	if ra, ok := payload.(readSeekerAt); ok {
+		sp, err = seekableStreamPayload(ra, props, m.queued.compressionLevel)
+	} else {
+		sp, err = bufferedStreamPayload(payload, props, m.queued.compressionLevel)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// If the payload is larger than the max size for streaming, we fall back to queued.
+	if !sp.fits {
+		result, err := m.queuedFromPayload(ctx, sp)
+		return withDecision(result, err, MethodQueued, "the payload is larger than the streaming limit")
+	}
+	if tagged {
+		result, err := m.queuedFromPayload(ctx, sp)
+		return withDecision(result, err, MethodQueued, "SkipIfTagExists() requires the ingest-by tag, which streaming ingestion does not set")
+	}
 
-	// If the payload is larger than the max size for streaming, we fall back to queued by combining what we read with the rest of the payload
-	if len(buf) > maxSize {
-		combinedBuf := io.MultiReader(bytes.NewReader(buf), payload)
-		return m.queued.fromReader(ctx, combinedBuf, []FileOption{}, props)
+	reason := "the payload fits the streaming limit"
+	if m.auto != nil {
+		var stream bool
+		stream, reason = m.auto.decide(ctx, sp.props)
+		if !stream {
+			result, err := m.queuedFromPayload(ctx, sp)
+			return withDecision(result, err, MethodQueued, reason)
+		}
 	}
 
 	var result *Result
 
+	props = sp.props
 	hasCustomId := props.Streaming.ClientRequestId != ""
 	i := 0
 	managedUuid := uuid.New().String()
@@ -103,7 +174,12 @@ func (m *Managed) managedStreamImpl(ctx context.Context, payload io.Reader, prop
 		if !hasCustomId {
 			props.Streaming.ClientRequestId = fmt.Sprintf("KGC.executeManagedStreamingIngest;%s;%d", managedUuid, i)
 		}
-		result, err = streamImpl(m.streaming.streamConn, ctx, bytes.NewReader(buf), props)
+		payload, err := sp.open()
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		result, err = streamImpl(m.streaming.streamConn, ctx, payload, props)
+		closePayload(payload)
 		i++
 		if err != nil {
 			if e, ok := err.(*errors.Error); ok {
@@ -119,18 +195,41 @@ func (m *Managed) managedStreamImpl(ctx context.Context, payload io.Reader, prop
 		return nil
 	}, actualBackoff)
 
+	if m.auto != nil && (err == nil || errors.Retry(err)) {
+		m.auto.record(err != nil)
+	}
+
 	if err == nil {
-		return result, nil
+		return withDecision(result, nil, MethodStreaming, reason)
 	}
 
 	// Fallback to queued
 	if errors.Retry(err) {
-		return m.queued.fromReader(ctx, bytes.NewReader(buf), []FileOption{}, props)
+		result, qErr := m.queuedFromPayload(ctx, sp)
+		return withDecision(result, qErr, MethodQueued, fmt.Sprintf("streaming failed with a transient error: %s", err))
 	}
 
 	return nil, err
 }
 
+// queuedFromPayload ingests sp with the queued client.
+func (m *Managed) queuedFromPayload(ctx context.Context, sp streamPayload) (*Result, error) {
+	payload, err := sp.open()
+	if err != nil {
+		return nil, err
+	}
+	defer closePayload(payload)
+	return m.queued.fromReader(ctx, payload, []FileOption{}, sp.props)
+}
+
+// withDecision sets the decision of result, if there is one, to method for reason.
+func withDecision(result *Result, err error, method IngestionMethod, reason string) (*Result, error) {
+	if result != nil {
+		result.decision = IngestionDecision{Method: method, Reason: reason}
+	}
+	return result, err
+}
+
 func (m *Managed) newProp() properties.All {
 	exp := backoff.NewExponentialBackOff()
 	exp.InitialInterval = defaultInitialInterval
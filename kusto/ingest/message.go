@@ -0,0 +1,64 @@
+package ingest
+
+// message.go holds BuildIngestionMessage(), which shows the queued ingestion message a set of FileOption(s) produces.
+
+import (
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/queued"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+)
+
+// IngestionMessage is the message posted to the Kusto ingestion queue by queued ingestion.
+// This is for inspection and testing only, its fields can change with the service's message format.
+// The message format has no version to select: the ingestion service defines a single format, which older fields
+// were never removed from, and the optional fields the SDK sets are left out of the message unless an option sets
+// them. BuildIngestionMessage() shows the message a cluster receives for a set of options.
+type IngestionMessage = properties.Ingestion
+
+// BuildIngestionMessage returns the message that Ingestion.FromFile() would post to the ingestion queue for source,
+// with options applied, without any network calls. source is a blob URI or a local file path, like for FromFile().
+// Fields that are only known when ingesting are left as zero values: the ID, SourceMessageCreationTime, the
+// authorization context and the status table reference. For a local file, BlobPath and RawDataSize are also not set,
+// as they describe the blob the file is uploaded to.
+func BuildIngestionMessage(db, table, source string, options ...FileOption) (IngestionMessage, error) {
+	return buildIngestionMessage(clock.Real{}, db, table, source, options...)
+}
+
+// buildIngestionMessage implements BuildIngestionMessage() with c as the clock of the options that depend on the time.
+func buildIngestionMessage(c clock.Clock, db, table, source string, options ...FileOption) (IngestionMessage, error) {
+	local, err := queued.IsLocalPath(source)
+	if err != nil {
+		return IngestionMessage{}, err
+	}
+
+	scope := FromBlob
+	if local {
+		scope = FromFile
+	}
+
+	props := properties.All{Ingestion: properties.Ingestion{DatabaseName: db, TableName: table}}
+	for _, o := range options {
+		if err := o.Run(&props, QueuedClient, scope); err != nil {
+			return IngestionMessage{}, err
+		}
+	}
+	if err := applyIgnoreExtraColumns(&props); err != nil {
+		return IngestionMessage{}, err
+	}
+	if err := applyAutoDelete(&props, c); err != nil {
+		return IngestionMessage{}, err
+	}
+
+	if !local {
+		if err := applyCreationTimePattern(&props, source); err != nil {
+			return IngestionMessage{}, err
+		}
+		return queued.Message(source, 0, props)
+	}
+
+	if err := queued.CompleteFormatFromFileName(&props, source); err != nil {
+		return IngestionMessage{}, err
+	}
+	props.Ingestion.RetainBlobOnSuccess = !props.Source.DeleteLocalSource
+	return props.Ingestion, nil
+}
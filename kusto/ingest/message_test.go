@@ -0,0 +1,115 @@
+package ingest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildIngestionMessage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "data.json")
+	require.NoError(t, os.WriteFile(local, []byte(`{"a":1}`), 0600))
+
+	tests := []struct {
+		desc    string
+		source  string
+		options []FileOption
+		want    IngestionMessage
+		err     bool
+	}{
+		{
+			desc:   "Blob",
+			source: "https://account.blob.core.windows.net/container/data.csv",
+			want: IngestionMessage{
+				BlobPath:            "https://account.blob.core.windows.net/container/data.csv",
+				DatabaseName:        "db",
+				TableName:           "table",
+				RetainBlobOnSuccess: true,
+				Additional:          properties.Additional{Format: CSV},
+			},
+		},
+		{
+			desc:    "Blob with options",
+			source:  "https://account.blob.core.windows.net/container/data",
+			options: []FileOption{BlobSAS("sv=2020-08-04&sig=secret"), FileFormat(JSON), IngestionMappingRef("mapping", JSON), Tags([]string{"tag"}), FlushImmediately()},
+			want: IngestionMessage{
+				BlobPath:            "https://account.blob.core.windows.net/container/data?sv=2020-08-04&sig=secret",
+				DatabaseName:        "db",
+				TableName:           "table",
+				RetainBlobOnSuccess: true,
+				FlushImmediately:    true,
+				Additional: properties.Additional{
+					Format:               JSON,
+					IngestionMappingRef:  "mapping",
+					IngestionMappingType: JSON,
+					Tags:                 []string{"tag"},
+				},
+			},
+		},
+		{
+			desc:    "Local file",
+			source:  local,
+			options: []FileOption{DeleteSource()},
+			want: IngestionMessage{
+				DatabaseName: "db",
+				TableName:    "table",
+				Additional:   properties.Additional{Format: JSON},
+			},
+		},
+		{
+			desc:    "Option not supported for the source",
+			source:  local,
+			options: []FileOption{BlobSAS("sig=secret")},
+			err:     true,
+		},
+		{
+			desc:    "Invalid option combination",
+			source:  "https://account.blob.core.windows.net/container/data.csv",
+			options: []FileOption{IgnoreExtraColumns()},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := BuildIngestionMessage("db", "table", test.source, test.options...)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestBuildIngestionMessageCreationTime(t *testing.T) {
+	t.Parallel()
+
+	const source = "https://account.blob.core.windows.net/container/data.csv"
+
+	msg, err := BuildIngestionMessage("db", "table", source)
+	require.NoError(t, err)
+	b, err := json.Marshal(msg.Additional)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "creationTime")
+
+	created := time.Date(2015, 3, 4, 5, 6, 7, 0, time.UTC)
+	msg, err = BuildIngestionMessage("db", "table", source, SetCreationTime(created))
+	require.NoError(t, err)
+	b, err = json.Marshal(msg.Additional)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"creationTime":"2015-03-04T05:06:07Z"`)
+}
@@ -0,0 +1,92 @@
+package ingest
+
+// multi_result.go holds MultiResult, the outcome of the ingestion of many sources by a batch API like FromDirectory().
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// SourceResult is the Result of a source that was ingested successfully.
+type SourceResult struct {
+	// Source identifies the source, like the path of a file.
+	Source string
+	// Result is the Result of the ingestion of the source.
+	Result *Result
+}
+
+// FailedResult is a source that could not be ingested. It implements error.
+type FailedResult struct {
+	// Source identifies the source, like the path of a file.
+	Source string
+	// Err is the error the ingestion of the source failed with.
+	Err error
+}
+
+// Error implements error.
+func (f FailedResult) Error() string {
+	return fmt.Sprintf("%s: %s", f.Source, f.Err)
+}
+
+// Unwrap returns the error the ingestion of the source failed with.
+func (f FailedResult) Unwrap() error {
+	return f.Err
+}
+
+// MultiResult is the outcome of the ingestion of many sources. It is safe for concurrent use.
+type MultiResult struct {
+	mu        sync.Mutex
+	succeeded []SourceResult
+	failed    []FailedResult
+}
+
+// Succeeded returns the sources that were ingested successfully, sorted by source.
+func (m *MultiResult) Succeeded() []SourceResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := append([]SourceResult(nil), m.succeeded...)
+	sort.Slice(s, func(i, j int) bool { return s[i].Source < s[j].Source })
+	return s
+}
+
+// Failed returns the sources that could not be ingested, sorted by source.
+func (m *MultiResult) Failed() []FailedResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := append([]FailedResult(nil), m.failed...)
+	sort.Slice(f, func(i, j int) bool { return f[i].Source < f[j].Source })
+	return f
+}
+
+// Err returns nil if all sources were ingested. Otherwise it returns an *errors.CombinedError holding a FailedResult
+// for every source that failed. Its Is() and As() methods check each of these, so errors.Is() and errors.As() match
+// the errors of any source.
+func (m *MultiResult) Err() error {
+	failed := m.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(failed))
+	for i, f := range failed {
+		errs[i] = f
+	}
+	return errors.GetCombinedError(errs...)
+}
+
+func (m *MultiResult) addSucceeded(source string, result *Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.succeeded = append(m.succeeded, SourceResult{Source: source, Result: result})
+}
+
+func (m *MultiResult) addFailed(source string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed = append(m.failed, FailedResult{Source: source, Err: err})
+}
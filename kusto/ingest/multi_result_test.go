@@ -0,0 +1,41 @@
+package ingest
+
+import (
+	goErrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiResult(t *testing.T) {
+	t.Parallel()
+
+	errB := fmt.Errorf("b failed")
+	errC := errors.ES(errors.OpFileIngest, errors.KTimeout, "c timed out")
+
+	m := &MultiResult{}
+	require.NoError(t, m.Err())
+
+	a := &Result{}
+	m.addFailed("c", errC)
+	m.addSucceeded("a", a)
+	m.addFailed("b", errB)
+
+	assert.Equal(t, []SourceResult{{Source: "a", Result: a}}, m.Succeeded())
+	assert.Equal(t, []FailedResult{{Source: "b", Err: errB}, {Source: "c", Err: errC}}, m.Failed())
+
+	err := m.Err()
+	require.Error(t, err)
+	combined, ok := err.(*errors.CombinedError)
+	require.True(t, ok)
+	assert.Len(t, combined.Errors, 2)
+	assert.Equal(t, "b: b failed", combined.Errors[0].Error())
+
+	assert.True(t, goErrors.Is(err, errB))
+	var failed FailedResult
+	require.True(t, goErrors.As(err, &failed))
+	assert.Equal(t, "b", failed.Source)
+}
@@ -0,0 +1,81 @@
+package ingest
+
+// poll.go holds the options that set how Result.Wait() polls the status table.
+
+import (
+	"time"
+)
+
+const (
+	// defaultPollInterval is the time before the first poll of the status table if WithPollInterval() is not provided.
+	defaultPollInterval = 5 * time.Second
+	// defaultPollBackoff is the factor between the intervals of two polls if WithBackoff() is not provided.
+	defaultPollBackoff = 1.5
+	// maxPollInterval is the longest interval between two polls, unless WithPollInterval() sets a longer one.
+	maxPollInterval = time.Minute
+)
+
+// WaitOption is an optional argument to Result.Wait().
+type WaitOption func(w *waitOptions)
+
+type waitOptions struct {
+	interval time.Duration
+	timeout  time.Duration
+	backoff  float64
+}
+
+// WithPollInterval sets the time before the first poll of the status table. Following polls wait longer, as set with
+// WithBackoff(), up to a minute or interval if it is longer. The default is 5 seconds. Short intervals suit small
+// ingestions that complete fast, long ones suit ingestions that are batched by the service and take minutes.
+// Values less than 1 are ignored.
+func WithPollInterval(interval time.Duration) WaitOption {
+	return func(w *waitOptions) {
+		if interval > 0 {
+			w.interval = interval
+		}
+	}
+}
+
+// WithPollTimeout makes Wait() stop polling after timeout. The ingestion then has the StatusRetrievalCanceled status,
+// as when the context passed to Wait() is done, and may still complete on the service. By default, Wait() polls until
+// the ingestion completes or its context is done. Values less than 1 are ignored.
+func WithPollTimeout(timeout time.Duration) WaitOption {
+	return func(w *waitOptions) {
+		if timeout > 0 {
+			w.timeout = timeout
+		}
+	}
+}
+
+// WithBackoff sets the factor each interval between two polls is multiplied by to get the next one. The default is 1.5.
+// Pass 1 to poll at a fixed interval. Values less than 1 are ignored.
+func WithBackoff(factor float64) WaitOption {
+	return func(w *waitOptions) {
+		if factor >= 1 {
+			w.backoff = factor
+		}
+	}
+}
+
+// newWaitOptions returns the waitOptions set by options.
+func newWaitOptions(options []WaitOption) waitOptions {
+	w := waitOptions{interval: defaultPollInterval, backoff: defaultPollBackoff}
+	for _, o := range options {
+		o(&w)
+	}
+	return w
+}
+
+// nextInterval returns the interval to wait after a poll that was done interval after the previous one.
+func (w waitOptions) nextInterval(interval time.Duration) time.Duration {
+	limit := maxPollInterval
+	if w.interval > limit {
+		limit = w.interval
+	}
+
+	next := time.Duration(float64(interval) * w.backoff)
+	if next > limit || next < interval {
+		return limit
+	}
+	return next
+}
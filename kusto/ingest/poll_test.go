@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatusTable is a statusReader that returns statuses, one per read, and then the last one again.
+type fakeStatusTable struct {
+	mu       sync.Mutex
+	statuses []StatusCode
+	reads    []time.Time
+}
+
+func (f *fakeStatusTable) Read(string) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reads = append(f.reads, time.Now())
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	return map[string]interface{}{"Status": string(status)}, nil
+}
+
+func TestWaitOptionsNextInterval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		options []WaitOption
+		want    []time.Duration
+	}{
+		{
+			desc: "Default",
+			want: []time.Duration{5 * time.Second, 7500 * time.Millisecond, 11250 * time.Millisecond},
+		},
+		{
+			desc:    "Default is capped",
+			options: []WaitOption{WithPollInterval(40 * time.Second)},
+			want:    []time.Duration{40 * time.Second, time.Minute, time.Minute},
+		},
+		{
+			desc:    "Fixed interval",
+			options: []WaitOption{WithPollInterval(time.Second), WithBackoff(1)},
+			want:    []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			desc:    "Interval longer than the cap",
+			options: []WaitOption{WithPollInterval(2 * time.Minute), WithBackoff(2)},
+			want:    []time.Duration{2 * time.Minute, 2 * time.Minute},
+		},
+		{
+			desc:    "Invalid values are ignored",
+			options: []WaitOption{WithPollInterval(-1), WithBackoff(0.5), WithPollTimeout(0)},
+			want:    []time.Duration{5 * time.Second, 7500 * time.Millisecond},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			opts := newWaitOptions(test.options)
+			assert.Zero(t, opts.timeout)
+
+			var got []time.Duration
+			for interval := opts.interval; len(got) < len(test.want); interval = opts.nextInterval(interval) {
+				got = append(got, interval)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestResultWait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		statuses   []StatusCode
+		options    []WaitOption
+		wantStatus StatusCode
+		wantReads  int
+	}{
+		{
+			desc:       "Success",
+			statuses:   []StatusCode{Pending, Pending, Succeeded},
+			options:    []WaitOption{WithPollInterval(time.Millisecond), WithBackoff(1)},
+			wantStatus: Succeeded,
+			wantReads:  3,
+		},
+		{
+			desc:       "Failure",
+			statuses:   []StatusCode{Pending, Failed},
+			options:    []WaitOption{WithPollInterval(time.Millisecond)},
+			wantStatus: Failed,
+			wantReads:  2,
+		},
+		{
+			desc:       "Timeout",
+			statuses:   []StatusCode{Pending},
+			options:    []WaitOption{WithPollInterval(time.Hour), WithPollTimeout(10 * time.Millisecond)},
+			wantStatus: StatusRetrievalCanceled,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			table := &fakeStatusTable{statuses: test.statuses}
+			r := newResult()
+			r.record.Status = Pending
+			r.reportToTable = true
+			r.tableClient = table
+
+			err := <-r.Wait(context.Background(), test.options...)
+			assert.Equal(t, test.wantStatus, r.record.Status)
+			assert.Len(t, table.reads, test.wantReads)
+			if test.wantStatus.IsSuccess() {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, IsStatusRecord(err))
+		})
+	}
+}
+
+func TestResultWaitCanceled(t *testing.T) {
+	t.Parallel()
+
+	r := newResult()
+	r.record.Status = Pending
+	r.reportToTable = true
+	r.tableClient = &fakeStatusTable{statuses: []StatusCode{Pending}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := r.Wait(ctx, WithPollInterval(time.Hour))
+	cancel()
+
+	select {
+	case err := <-ch:
+		code, statusErr := GetIngestionStatus(err)
+		require.NoError(t, statusErr)
+		assert.Equal(t, StatusRetrievalCanceled, code)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Wait() did not return after its context was canceled")
+	}
+}
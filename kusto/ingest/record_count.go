@@ -0,0 +1,153 @@
+package ingest
+
+// record_count.go holds the client side record counting used by the RecordCounter() option.
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// applyRecordCounter wraps reader to count the records that are read from it if the RecordCounter() option was
+// provided. It must be applied after all the options that change the records. report must be called once the data
+// was ingested, and calls the function of the option with the count if reader was read to the end. It returns an
+// error if the data format cannot be counted or the data is gzip compressed.
+func applyRecordCounter(reader io.Reader, props *properties.All) (_ io.Reader, report func(), err error) {
+	done := props.Source.RecordCounter
+	if done == nil {
+		return reader, func() {}, nil
+	}
+	props.Source.RecordCounter = nil
+
+	format := props.Ingestion.Additional.Format
+	switch format {
+	case DFUnknown, CSV, JSON, MultiJSON:
+	default:
+		return nil, nil, errors.ES(
+			errors.OpFileIngest,
+			errors.KClientArgs,
+			"RecordCounter() option does not support format %s, only CSV, JSON and MultiJSON", format,
+		).SetNoRetry()
+	}
+
+	br := bufio.NewReader(reader)
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, errors.E(errors.OpFileIngest, errors.KIO, err)
+	}
+	if bytes.Equal(head, gzipMagic) {
+		return nil, nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "RecordCounter() cannot count the records of gzip compressed data").SetNoRetry()
+	}
+
+	counter := &recordCounter{reader: br, multiJSON: format == MultiJSON, quotes: format != JSON && format != MultiJSON, done: done}
+	return counter, counter.report, nil
+}
+
+// reportOnSuccess returns a function that calls report if err is nil and returns result and err, to wrap the call that
+// ingests data counted by applyRecordCounter().
+func reportOnSuccess(report func()) func(result *Result, err error) (*Result, error) {
+	return func(result *Result, err error) (*Result, error) {
+		if err == nil {
+			report()
+		}
+		return result, err
+	}
+}
+
+// recordCounter counts the records of the data read through it. report() calls done with the count if the data was read
+// to the end. Records of CSV and JSON are non empty lines, where a CSV line ends at a newline outside of double
+// quotes. Records of MultiJSON are top level JSON objects and arrays, which can span multiple lines.
+type recordCounter struct {
+	reader    io.Reader
+	multiJSON bool
+	quotes    bool
+	done      func(count int64)
+
+	count    int64
+	eof      bool
+	reported bool
+
+	// inQuotes and lineData are the state of CSV and JSON lines.
+	inQuotes bool
+	lineData bool
+
+	// depth, inString and escaped are the state of MultiJSON values.
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// Read implements io.Reader.
+func (r *recordCounter) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	if r.multiJSON {
+		r.scanMultiJSON(b[:n])
+	} else {
+		r.scanLines(b[:n])
+	}
+
+	if err == io.EOF && !r.eof {
+		r.eof = true
+		if r.lineData {
+			r.count++
+		}
+	}
+	return n, err
+}
+
+// report calls done with the count if the data was read to the end, once.
+func (r *recordCounter) report() {
+	if r.eof && !r.reported {
+		r.reported = true
+		r.done(r.count)
+	}
+}
+
+func (r *recordCounter) scanLines(b []byte) {
+	for _, c := range b {
+		switch {
+		case c == '"' && r.quotes:
+			// An escaped quote is two quotes, which toggle the state twice.
+			r.inQuotes = !r.inQuotes
+			r.lineData = true
+		case c == '\n' && !r.inQuotes:
+			if r.lineData {
+				r.count++
+			}
+			r.lineData = false
+		case c != '\r':
+			r.lineData = true
+		}
+	}
+}
+
+func (r *recordCounter) scanMultiJSON(b []byte) {
+	for _, c := range b {
+		if r.inString {
+			switch {
+			case r.escaped:
+				r.escaped = false
+			case c == '\\':
+				r.escaped = true
+			case c == '"':
+				r.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			r.inString = true
+		case '{', '[':
+			r.depth++
+		case '}', ']':
+			r.depth--
+			if r.depth == 0 {
+				r.count++
+			}
+		}
+	}
+}
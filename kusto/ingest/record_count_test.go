@@ -0,0 +1,110 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCounter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc   string
+		input  []byte
+		format properties.DataFormat
+		want   int64
+		err    bool
+	}{
+		{desc: "CSV", input: []byte("a,b\nc,d\n"), format: CSV, want: 2},
+		{desc: "CSV without trailing newline", input: []byte("a,b\r\nc,d"), format: CSV, want: 2},
+		{desc: "CSV with quoted newline", input: []byte("a,\"multi\nline\"\n\"x\"\"\ny\",z\nc,d\n"), format: CSV, want: 3},
+		{desc: "CSV with empty lines", input: []byte("a,b\n\n\r\nc,d\n\n"), format: CSV, want: 2},
+		{desc: "Unknown format is CSV", input: []byte("\"a\nb\"\n"), want: 1},
+		{desc: "Empty", input: []byte{}, format: CSV, want: 0},
+		{desc: "JSON", input: []byte("{\"a\":\"\\\"\"}\n{\"a\":2}\n"), format: JSON, want: 2},
+		{desc: "MultiJSON", input: []byte("{\n\"a\": \"}\",\n\"b\": [1, {\"c\": 2}]\n}\n{\"a\": 2}[1]"), format: MultiJSON, want: 3},
+		{desc: "Unsupported format", input: []byte("a\n"), format: Parquet, err: true},
+		{desc: "gzip", input: gzipped(t, []byte("a,b\n")), format: CSV, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var got []int64
+			props := properties.All{}
+			props.Ingestion.Additional.Format = test.format
+			props.Source.RecordCounter = func(count int64) { got = append(got, count) }
+
+			// OneByteReader splits records and quoted fields across reads.
+			reader, report, err := applyRecordCounter(iotest.OneByteReader(bytes.NewReader(test.input)), &props)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, props.Source.RecordCounter)
+
+			out, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, test.input, out)
+			// The count is only reported once the data was ingested.
+			assert.Empty(t, got)
+			report()
+			// Reporting again does not call RecordCounter again.
+			report()
+			assert.Equal(t, []int64{test.want}, got)
+		})
+	}
+}
+
+func TestRecordCounterStreaming(t *testing.T) {
+	t.Parallel()
+
+	streaming := Streaming{
+		db:    "db",
+		table: "table",
+		streamConn: fakeStreamIngestor{
+			onStreamIngest: func(_ context.Context, _, _ string, payload io.Reader, _ properties.DataFormat, _ string, _ string) error {
+				_, err := ioutil.ReadAll(payload)
+				return err
+			},
+		},
+	}
+
+	var got int64
+	_, err := streaming.FromReader(context.Background(), strings.NewReader("a,b\nc,d\ne,f\n"), RecordCounter(func(count int64) { got = count }), DontCompress())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, got)
+
+	failing := Streaming{
+		db:    "db",
+		table: "table",
+		streamConn: fakeStreamIngestor{
+			onStreamIngest: func(_ context.Context, _, _ string, payload io.Reader, _ properties.DataFormat, _ string, _ string) error {
+				if _, err := ioutil.ReadAll(payload); err != nil {
+					return err
+				}
+				return errors.ES(errors.OpIngestStream, errors.KHTTPError, "stream failed").SetNoRetry()
+			},
+		},
+	}
+	called := false
+	_, err = failing.FromReader(context.Background(), strings.NewReader("a,b\n"), RecordCounter(func(int64) { called = true }), DontCompress())
+	assert.Error(t, err)
+	assert.False(t, called, "RecordCounter must not be called when the ingestion fails")
+
+	_, err = streaming.FromReader(context.Background(), strings.NewReader("a\n"), RecordCounter(nil))
+	assert.Error(t, err)
+}
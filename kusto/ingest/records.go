@@ -0,0 +1,348 @@
+package ingest
+
+// records.go holds FromRecords(), which encodes a slice of records with a RecordEncoder and ingests the result.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// RecordEncoder encodes records into a data format that Kusto can ingest. Register a RecordEncoder for a record type
+// with RegisterRecordEncoder() to replace the default encoder, like with one generated for a protobuf message.
+type RecordEncoder interface {
+	// Format is the DataFormat Encode() writes, like CSV or JSON.
+	Format() DataFormat
+	// Encode writes records, which is a slice of the registered type or of pointers to it, to w.
+	Encode(w io.Writer, records interface{}) error
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[reflect.Type]RecordEncoder{}
+)
+
+// RegisterRecordEncoder registers enc as the RecordEncoder FromRecords() uses for slices of the type of record, which
+// should be the zero value of the type, like MyEvent{}. Records of a pointer type use the encoder of the type they
+// point to. Registering a type again replaces the encoder, registering a nil encoder removes it.
+// This is usually called in an init() function.
+func RegisterRecordEncoder(record interface{}, enc RecordEncoder) {
+	t := recordType(reflect.TypeOf(record))
+
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	if enc == nil {
+		delete(encoders, t)
+		return
+	}
+	encoders[t] = enc
+}
+
+// FromRecords ingests records, which must be a slice, using ingestor. The records are encoded with the RecordEncoder
+// registered for their type or, if none was registered, as JSON with one object per record. The default encoder uses
+// the exported fields of a struct, named by their `kusto:"name"` tag or their field name, and skips fields tagged
+// `kusto:"-"`. value.Kusto fields are encoded as their value, or null if not valid.
+// Decimals, from value.Decimal, big.Int, big.Float and big.Rat fields or pointers to them, are encoded as JSON strings of
+// their decimal digits, never through a float64, so a decimal column receives them exactly. A big.Float is written with
+// the fewest digits that identify it at its precision, so its precision must be high enough for the digits it was
+// parsed from. A big.Rat must have a finite decimal expansion, like 1/8, as a value like 1/3 cannot be written exactly.
+// Nil pointers are encoded as null.
+// No ingestion mapping is generated by default: the service maps the properties of the JSON objects to the columns of
+// the same name, so the names must be the names of the columns. Pass RecordMapping() to send a mapping that also types
+// the decimal fields, or IngestionMappingRef() to map them otherwise.
+// The encoded data is streamed to FromReader() with the FileFormat() of the encoder, followed by options.
+func FromRecords(ctx context.Context, ingestor Ingestor, records interface{}, options ...FileOption) (*Result, error) {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FromRecords() requires a slice of records, got %T", records).SetNoRetry()
+	}
+
+	t := recordType(v.Type().Elem())
+	encodersMu.RLock()
+	enc, ok := encoders[t]
+	encodersMu.RUnlock()
+	if !ok {
+		if t.Kind() != reflect.Struct {
+			return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FromRecords() has no RecordEncoder registered for %v and the default encoder only supports structs", t).SetNoRetry()
+		}
+		enc = jsonRecordEncoder{}
+	}
+
+	if enc.Format() == DFUnknown {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "the RecordEncoder for %v did not provide a Format()", t).SetNoRetry()
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(enc.Encode(pw, records))
+	}()
+	defer pr.Close()
+
+	return ingestor.FromReader(ctx, pr, append([]FileOption{FileFormat(enc.Format())}, options...)...)
+}
+
+// RecordMapping provides an inline JSON IngestionMapping() for the records of FromRecords() encoded by the default
+// encoder, with record being the zero value of their type, like MyEvent{}. Every field is mapped to the column of its
+// name, and decimal fields, from value.Decimal, big.Int, big.Float and big.Rat, are given the decimal data type, so the
+// strings of digits the encoder writes for them are read as decimals.
+// It returns an error if a RecordEncoder is registered for the type, as the mapping only describes the default encoder.
+// Streaming ingestion does not support inline mappings, so this is not supported by the streaming client.
+func RecordMapping(record interface{}) FileOption {
+	return option{
+		run: func(p *properties.All) error {
+			mapping, err := recordMapping(reflect.TypeOf(record))
+			if err != nil {
+				return err
+			}
+			p.Ingestion.Additional.IngestionMapping = mapping
+			p.Ingestion.Additional.IngestionMappingType = JSON
+			return nil
+		},
+		clientScopes: QueuedClient | ManagedClient,
+		sourceScope:  FromReader,
+		name:         "RecordMapping",
+	}
+}
+
+// pathMapping is an entry of a JSON ingestion mapping that maps a property, by its path, to a column.
+type pathMapping struct {
+	Column     string
+	DataType   string `json:",omitempty"`
+	Properties struct {
+		Path string
+	}
+}
+
+// recordMapping returns the JSON ingestion mapping of the records of type t encoded by jsonRecordEncoder.
+func recordMapping(t reflect.Type) (string, error) {
+	if t == nil {
+		return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "RecordMapping() requires a record, got nil").SetNoRetry()
+	}
+	t = recordType(t)
+
+	encodersMu.RLock()
+	_, registered := encoders[t]
+	encodersMu.RUnlock()
+	switch {
+	case registered:
+		return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "RecordMapping() only describes the default encoder, but a RecordEncoder is registered for %v", t).SetNoRetry()
+	case t.Kind() != reflect.Struct:
+		return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "RecordMapping() requires a struct, got %v", t).SetNoRetry()
+	}
+
+	fields := recordFields(t)
+	if len(fields) == 0 {
+		return "", errors.ES(errors.OpUnknown, errors.KClientArgs, "RecordMapping() found no exported fields in %v", t).SetNoRetry()
+	}
+	mapping := make([]pathMapping, len(fields))
+	for i, f := range fields {
+		mapping[i].Column = f.name
+		mapping[i].Properties.Path = "$['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(f.name) + "']"
+		if isDecimalType(t.Field(f.index).Type) {
+			mapping[i].DataType = "decimal"
+		}
+	}
+
+	b, err := json.Marshal(mapping)
+	if err != nil {
+		return "", errors.ES(errors.OpUnknown, errors.KInternal, "bug: the RecordMapping() mapping would not JSON encode: %s", err).SetNoRetry()
+	}
+	return string(b), nil
+}
+
+// isDecimalType reports if the default encoder writes fields of type t as decimals.
+func isDecimalType(t reflect.Type) bool {
+	switch recordType(t) {
+	case reflect.TypeOf(value.Decimal{}), reflect.TypeOf(big.Int{}), reflect.TypeOf(big.Float{}), reflect.TypeOf(big.Rat{}):
+		return true
+	}
+	return false
+}
+
+// recordField is a field of a struct that jsonRecordEncoder encodes.
+type recordField struct {
+	index int
+	name  string
+}
+
+// recordFields returns the fields of the struct t that jsonRecordEncoder encodes, in their order.
+func recordFields(t reflect.Type) []recordField {
+	var fields []recordField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // Unexported.
+			continue
+		}
+		name := f.Name
+		if tag := strings.TrimSpace(f.Tag.Get("kusto")); tag != "" {
+			name = tag
+		}
+		if name == "-" {
+			continue
+		}
+		fields = append(fields, recordField{index: i, name: name})
+	}
+	return fields
+}
+
+// recordType returns the type a RecordEncoder is registered for, which is t without pointers.
+func recordType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonRecordEncoder is the default RecordEncoder. It uses reflection to encode structs as JSON objects, one per line.
+type jsonRecordEncoder struct{}
+
+// Format implements RecordEncoder.Format().
+func (jsonRecordEncoder) Format() DataFormat {
+	return JSON
+}
+
+// Encode implements RecordEncoder.Encode().
+func (jsonRecordEncoder) Encode(w io.Writer, records interface{}) error {
+	v := reflect.ValueOf(records)
+	fields := recordFields(recordType(v.Type().Elem()))
+
+	enc := json.NewEncoder(w)
+	record := make(map[string]interface{}, len(fields))
+	for i := 0; i < v.Len(); i++ {
+		rv := v.Index(i)
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return fmt.Errorf("FromRecords() record %d is nil", i)
+			}
+			rv = rv.Elem()
+		}
+
+		for _, f := range fields {
+			val, err := recordJSONValue(rv.Field(f.index).Interface())
+			if err != nil {
+				return fmt.Errorf("FromRecords() record %d field %s: %w", i, f.name, err)
+			}
+			record[f.name] = val
+		}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("FromRecords() record %d could not be JSON encoded: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// recordJSONValue returns the value that represents v in the JSON sent to Kusto.
+func recordJSONValue(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case time.Duration:
+		return value.Timespan{Value: t, Valid: true}.Marshal(), nil
+	case big.Int:
+		return recordJSONValue(&t)
+	case *big.Int:
+		if t == nil {
+			return nil, nil
+		}
+		return t.String(), nil
+	case big.Float:
+		return recordJSONValue(&t)
+	case *big.Float:
+		if t == nil {
+			return nil, nil
+		}
+		if t.IsInf() {
+			return nil, fmt.Errorf("decimal value %s is not a number", t)
+		}
+		return t.Text('f', -1), nil
+	case big.Rat:
+		return recordJSONValue(&t)
+	case *big.Rat:
+		if t == nil {
+			return nil, nil
+		}
+		return ratDecimal(t)
+	case value.Bool:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Int:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Long:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Real:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Decimal:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.String:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.DateTime:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Timespan:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Marshal(), nil
+	case value.GUID:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Dynamic:
+		if !t.Valid {
+			return nil, nil
+		}
+		return json.RawMessage(t.Value), nil
+	}
+	return v, nil
+}
+
+// ratDecimal returns r as an exact decimal number. It returns an error if r has no finite decimal expansion, which is
+// when its denominator has prime factors other than 2 and 5.
+func ratDecimal(r *big.Rat) (string, error) {
+	denom := new(big.Int).Set(r.Denom())
+	two, five, rem := big.NewInt(2), big.NewInt(5), new(big.Int)
+	var twos, fives int
+	for ; rem.Mod(denom, two).Sign() == 0; twos++ {
+		denom.Quo(denom, two)
+	}
+	for ; rem.Mod(denom, five).Sign() == 0; fives++ {
+		denom.Quo(denom, five)
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("decimal value %s has no exact decimal representation", r.RatString())
+	}
+
+	decimals := twos
+	if fives > decimals {
+		decimals = fives
+	}
+	return r.FloatString(decimals), nil
+}
@@ -0,0 +1,234 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordsEvent struct {
+	ID       int64 `kusto:"id"`
+	Name     string
+	Duration time.Duration
+	Score    value.Real
+	Data     value.Dynamic
+	Skipped  string `kusto:"-"`
+	internal string
+}
+
+type decimalEvent struct {
+	Decimal value.Decimal
+	Int     *big.Int
+	Float   *big.Float
+	Rat     *big.Rat
+}
+
+// valueDecimalEvent holds the decimal types FromRecords() supports as values instead of pointers.
+type valueDecimalEvent struct {
+	Int   big.Int
+	Float big.Float
+	Rat   big.Rat
+}
+
+// bigDecimal returns the value of s, a decimal number with 30 or more digits, as every decimal type FromRecords()
+// supports.
+func bigDecimal(t *testing.T, s string) decimalEvent {
+	i, ok := new(big.Int).SetString(strings.Split(s, ".")[0], 10)
+	require.True(t, ok)
+	f, ok := new(big.Float).SetPrec(256).SetString(s)
+	require.True(t, ok)
+	r, ok := new(big.Rat).SetString(s)
+	require.True(t, ok)
+	return decimalEvent{Decimal: value.Decimal{Value: s, Valid: true}, Int: i, Float: f, Rat: r}
+}
+
+// csvEvent has a RecordEncoder registered in TestFromRecords.
+type csvEvent struct {
+	ID   int64
+	Name string
+}
+
+type csvEventEncoder struct{}
+
+func (csvEventEncoder) Format() DataFormat {
+	return CSV
+}
+
+func (csvEventEncoder) Encode(w io.Writer, records interface{}) error {
+	for _, e := range records.([]csvEvent) {
+		if _, err := fmt.Fprintf(w, "%d,%s\n", e.ID, e.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFromRecords(t *testing.T) {
+	t.Parallel()
+
+	RegisterRecordEncoder(csvEvent{}, csvEventEncoder{})
+
+	tests := []struct {
+		desc        string
+		records     interface{}
+		options     []FileOption
+		wantPayload string
+		wantFormat  DataFormat
+		err         bool
+	}{
+		{
+			desc: "Default encoder",
+			records: []recordsEvent{
+				{ID: 1, Name: "a", Duration: 90 * time.Second, Score: value.Real{Value: 0.5, Valid: true}, Data: value.Dynamic{Value: []byte(`{"k":1}`), Valid: true}, Skipped: "x", internal: "y"},
+				{ID: 2, Name: "b"},
+			},
+			wantPayload: `{"Data":{"k":1},"Duration":"00:01:30","Name":"a","Score":0.5,"id":1}` + "\n" +
+				`{"Data":null,"Duration":"00:00:00","Name":"b","Score":null,"id":2}` + "\n",
+			wantFormat: JSON,
+		},
+		{
+			desc:        "Pointers",
+			records:     []*recordsEvent{{ID: 1, Name: "a"}},
+			wantPayload: `{"Data":null,"Duration":"00:00:00","Name":"a","Score":null,"id":1}` + "\n",
+			wantFormat:  JSON,
+		},
+		{
+			desc:        "Registered encoder",
+			records:     []csvEvent{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}},
+			options:     []FileOption{FlushImmediately()},
+			wantPayload: "1,a\n2,b\n",
+			wantFormat:  CSV,
+		},
+		{
+			desc:    "Decimals",
+			records: []decimalEvent{bigDecimal(t, "123456789012345678901234567890.123456789"), {}},
+			wantPayload: `{"Decimal":"123456789012345678901234567890.123456789","Float":"123456789012345678901234567890.123456789",` +
+				`"Int":"123456789012345678901234567890","Rat":"123456789012345678901234567890.123456789"}` + "\n" +
+				`{"Decimal":null,"Float":null,"Int":null,"Rat":null}` + "\n",
+			wantFormat: JSON,
+		},
+		{
+			desc: "Decimal values",
+			records: func() []valueDecimalEvent {
+				d := bigDecimal(t, "123456789012345678901234567890.125")
+				return []valueDecimalEvent{{Int: *d.Int, Float: *d.Float, Rat: *d.Rat}, {}}
+			}(),
+			wantPayload: `{"Float":"123456789012345678901234567890.125","Int":"123456789012345678901234567890","Rat":"123456789012345678901234567890.125"}` + "\n" +
+				`{"Float":"0","Int":"0","Rat":"0"}` + "\n",
+			wantFormat: JSON,
+		},
+		{
+			desc:    "Rational value without a decimal representation",
+			records: []valueDecimalEvent{{Rat: *big.NewRat(1, 3)}},
+			err:     true,
+		},
+		{
+			desc:    "Rational without a decimal representation",
+			records: []decimalEvent{{Rat: big.NewRat(1, 3)}},
+			err:     true,
+		},
+		{
+			desc:    "Nil record",
+			records: []*recordsEvent{nil},
+			err:     true,
+		},
+		{
+			desc:    "Not a slice",
+			records: recordsEvent{},
+			err:     true,
+		},
+		{
+			desc:    "Not a struct",
+			records: []string{"a"},
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ingestor := &recordingIngestor{}
+			_, err := FromRecords(context.Background(), ingestor, test.records, test.options...)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, ingestor.calls, 1)
+			assert.Equal(t, "FromReader", ingestor.calls[0].method)
+			assert.Equal(t, test.wantPayload, ingestor.calls[0].arg)
+			assert.Equal(t, test.wantFormat, ingestor.calls[0].props.Ingestion.Additional.Format)
+		})
+	}
+}
+
+func TestRecordMapping(t *testing.T) {
+	t.Parallel()
+
+	RegisterRecordEncoder(csvEvent{}, csvEventEncoder{})
+
+	tests := []struct {
+		desc        string
+		record      interface{}
+		wantMapping string
+		err         bool
+	}{
+		{
+			desc:   "Decimal fields",
+			record: decimalEvent{},
+			wantMapping: `[{"Column":"Decimal","DataType":"decimal","Properties":{"Path":"$['Decimal']"}},` +
+				`{"Column":"Int","DataType":"decimal","Properties":{"Path":"$['Int']"}},` +
+				`{"Column":"Float","DataType":"decimal","Properties":{"Path":"$['Float']"}},` +
+				`{"Column":"Rat","DataType":"decimal","Properties":{"Path":"$['Rat']"}}]`,
+		},
+		{
+			desc:   "Tagged and skipped fields",
+			record: &recordsEvent{},
+			wantMapping: `[{"Column":"id","Properties":{"Path":"$['id']"}},{"Column":"Name","Properties":{"Path":"$['Name']"}},` +
+				`{"Column":"Duration","Properties":{"Path":"$['Duration']"}},{"Column":"Score","Properties":{"Path":"$['Score']"}},` +
+				`{"Column":"Data","Properties":{"Path":"$['Data']"}}]`,
+		},
+		{
+			desc:   "Registered encoder",
+			record: csvEvent{},
+			err:    true,
+		},
+		{
+			desc:   "Not a struct",
+			record: "a",
+			err:    true,
+		},
+		{
+			desc: "Nil record",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			props := properties.All{}
+			err := RecordMapping(test.record).Run(&props, QueuedClient, FromReader)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMapping, props.Ingestion.Additional.IngestionMapping)
+			assert.Equal(t, JSON, props.Ingestion.Additional.IngestionMappingType)
+		})
+	}
+}
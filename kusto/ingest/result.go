@@ -11,12 +11,34 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/status"
 )
 
+// UploadStats holds metrics about the upload of data to Blobstore done by queued ingestion.
+type UploadStats = properties.UploadStats
+
 // Result provides a way for users track the state of ingestion jobs.
 type Result struct {
 	record        statusRecord
-	tableClient   *status.TableClient
+	tableClient   statusReader
 	reportToTable bool
 	reportToQueue bool
+	uploadStats   UploadStats
+	applied       AppliedProperties
+	// streamingResponse is the response of the service to a streaming ingestion.
+	streamingResponse StreamingResponse
+	// decision is the method Managed sent the ingestion with.
+	decision IngestionDecision
+	// ignored are the options of the ingestion that did not take effect.
+	ignored []IgnoredOption
+	// deleteBlob deletes the uploaded blob if WithBlobCleanup() was set and the status is reported to a table.
+	deleteBlob func(ctx context.Context) error
+	// cleanupErr is the error of deleteBlob.
+	cleanupErr error
+	// droppedRows is the number of rows DropMalformedRows() removed.
+	droppedRows int64
+}
+
+// statusReader reads the status records of ingestions from the status table.
+type statusReader interface {
+	Read(ingestionSourceID string) (map[string]interface{}, error)
 }
 
 // newResult creates an initial ingestion status record.
@@ -31,6 +53,17 @@ func newResult() *Result {
 func (r *Result) putProps(props properties.All) {
 	r.reportToTable = props.Ingestion.ReportMethod == properties.ReportStatusToTable || props.Ingestion.ReportMethod == properties.ReportStatusToQueueAndTable
 	r.record.FromProps(props)
+	r.applied = newAppliedProperties(props)
+	r.ignored = append([]IgnoredOption(nil), props.Source.IgnoredOptions...)
+	if props.Source.DroppedRows != nil {
+		r.droppedRows = *props.Source.DroppedRows
+	}
+}
+
+// DroppedRows returns the number of CSV rows that the DropMalformedRows() option removed from the data before it was
+// uploaded, because they did not have the number of fields of the first row. It is 0 if the option was not set.
+func (r *Result) DroppedRows() int64 {
+	return r.droppedRows
 }
 
 // putQueued sets the initial success status depending on status reporting state
@@ -79,9 +112,20 @@ func (r *Result) putQueued(mgr *resources.Manager) {
 	r.tableClient = client
 }
 
+// UploadStats returns metrics about the upload of the data to Blobstore, such as the amount of data read from the
+// source, the amount uploaded after compression and how long the upload took. These are only set for local files and
+// io.Readers ingested with queued ingestion, including managed streaming ingestion falling back to queued.
+// Otherwise the zero value is returned.
+func (r *Result) UploadStats() UploadStats {
+	return r.uploadStats
+}
+
 // Wait returns a channel that can be checked for ingestion results.
 // In order to check actual status please use the ReportResultToTable option when ingesting data.
-func (r *Result) Wait(ctx context.Context) chan error {
+// The status table is polled until the ingestion completes, ctx is done or the WithPollTimeout() option expires.
+// By default, the first poll is done after 5 seconds and every following one waits 1.5 times as long as the one
+// before, up to a minute, see WithPollInterval() and WithBackoff().
+func (r *Result) Wait(ctx context.Context, options ...WaitOption) chan error {
 	ch := make(chan error, 1)
 
 	if r.record.Status.IsFinal() || !r.reportToTable {
@@ -89,10 +133,12 @@ func (r *Result) Wait(ctx context.Context) chan error {
 		return ch
 	}
 
+	opts := newWaitOptions(options)
 	go func() {
 		defer close(ch)
 
-		r.poll(ctx)
+		r.poll(ctx, opts)
+		r.cleanup(ctx)
 		if !r.record.Status.IsSuccess() {
 			ch <- r.record
 		}
@@ -101,45 +147,53 @@ func (r *Result) Wait(ctx context.Context) chan error {
 	return ch
 }
 
-func (r *Result) poll(ctx context.Context) {
-	const pollInterval = 10 * time.Second
+func (r *Result) poll(ctx context.Context, opts waitOptions) {
 	attempts := 3
 	delay := [3]int{120, 60, 10} // attempts are counted backwards
 
-	// create a table client
-	if r.tableClient != nil {
-		// Create a ticker to poll the table in 10 second intervals.
-		timer := time.NewTimer(pollInterval)
-		defer timer.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				r.record.Status = StatusRetrievalCanceled
-				r.record.FailureStatus = Transient
-				return
+	if r.tableClient == nil {
+		return
+	}
+
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
 
-			case <-timer.C:
-				smap, err := r.tableClient.Read(r.record.IngestionSourceID.String())
-				if err != nil {
-					if attempts == 0 {
-						r.record.Status = StatusRetrievalFailed
-						r.record.FailureStatus = Transient
-						r.record.Details = "Failed reading from Status Table: " + err.Error()
-						return
-					}
-
-					attempts = attempts - 1
-					time.Sleep(time.Duration(delay[attempts]+rand.Intn(5)) * time.Second)
-				} else {
-					r.record.FromMap(smap)
-					if r.record.Status.IsFinal() {
-						return
-					}
+	interval := opts.interval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.record.Status = StatusRetrievalCanceled
+			r.record.FailureStatus = Transient
+			return
+
+		case <-timer.C:
+			smap, err := r.tableClient.Read(r.record.IngestionSourceID.String())
+			if err != nil {
+				if attempts == 0 {
+					r.record.Status = StatusRetrievalFailed
+					r.record.FailureStatus = Transient
+					r.record.Details = "Failed reading from Status Table: " + err.Error()
+					return
 				}
 
-				timer.Reset(pollInterval)
+				attempts = attempts - 1
+				timer.Reset(time.Duration(delay[attempts]+rand.Intn(5)) * time.Second)
+				continue
 			}
+
+			r.record.FromMap(smap)
+			if r.record.Status.IsFinal() {
+				return
+			}
+
+			interval = opts.nextInterval(interval)
+			timer.Reset(interval)
 		}
 	}
 }
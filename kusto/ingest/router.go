@@ -0,0 +1,184 @@
+package ingest
+
+// router.go holds Router, which ingests the records of one reader into the tables they are routed to.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// routerBatchSize is the size at which the records of a table are ingested, so that a batch can be streamed.
+const routerBatchSize = maxStreamingSize
+
+// RouteFunc returns the table a record is ingested into. Records for which ok is false are dropped. record is the
+// record without its line ending and must not be kept after RouteFunc returns.
+type RouteFunc func(record []byte) (table string, ok bool)
+
+// Router ingests the records of a reader into the tables of a database, picking the table of each record with a
+// RouteFunc, to split a stream of mixed records (like a topic of events of several kinds) into a table per kind.
+// Each table is ingested into with its own Managed client, which streams small batches and queues large ones.
+// Router is thread-safe.
+type Router struct {
+	route RouteFunc
+	// newIngestor returns the Ingestor of table.
+	newIngestor func(table string) (Ingestor, error)
+
+	mu        sync.Mutex
+	ingestors map[string]Ingestor
+}
+
+// NewRouter creates a Router that ingests into the tables of db that route returns. The options are used for the
+// Managed clients of the tables, which are created the first time a record is routed to a table.
+func NewRouter(client QueryClient, db string, route RouteFunc, options ...Option) (*Router, error) {
+	if route == nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "NewRouter() requires a RouteFunc").SetNoRetry()
+	}
+	if db == "" {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "NewRouter() requires a database").SetNoRetry()
+	}
+
+	return &Router{
+		route: route,
+		newIngestor: func(table string) (Ingestor, error) {
+			return NewManaged(client, db, table, options...)
+		},
+		ingestors: map[string]Ingestor{},
+	}, nil
+}
+
+// FromReader reads the records of reader, one per line, and ingests each into the table route returns for it.
+// The records of a table are batched, and a batch is ingested when it reaches the 4MB streaming limit and once reader
+// is done. Empty lines are skipped. The format, set with FileFormat(), must be one whose records are lines: CSV (without
+// a header), TSV, TSVE, PSV, SCSV, SOHSV, TXT, JSON or MultiJSON with one object per line. The options are used for
+// every batch, except Database() and Table() which are not supported.
+// The returned MultiResult holds the outcome of every batch, with the table as the source. If any batch failed, the
+// error is MultiResult.Err(). If reader fails, or ctx is done, the batches that were not ingested yet fail with that
+// error, which is returned if there were none, and batches that were already ingested are not undone.
+func (r *Router) FromReader(ctx context.Context, reader io.Reader, options ...FileOption) (*MultiResult, error) {
+	props := properties.All{}
+	for _, o := range options {
+		if err := o.Run(&props, ManagedClient, FromReader); err != nil {
+			return nil, err
+		}
+	}
+	if props.Ingestion.DatabaseName != "" || props.Ingestion.TableName != "" {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "Router does not support Database() or Table(), the table of a record comes from the RouteFunc").SetNoRetry()
+	}
+	format := props.Ingestion.Additional.Format
+	if format == DFUnknown {
+		format = CSV
+	}
+	if !batchableFormat(format) {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "Router does not support format %s, its records are not lines", format).SetNoRetry()
+	}
+
+	var (
+		results = &MultiResult{}
+		batches = map[string]*bytes.Buffer{}
+		stopErr error
+	)
+
+	ingest := func(table string, batch *bytes.Buffer) {
+		ingestor, err := r.ingestor(table)
+		if err != nil {
+			results.addFailed(table, err)
+			return
+		}
+		result, err := ingestor.FromReader(ctx, bytes.NewReader(batch.Bytes()), options...)
+		if err != nil {
+			results.addFailed(table, err)
+			return
+		}
+		results.addSucceeded(table, result)
+	}
+
+	br := bufio.NewReader(reader)
+	for stopErr == nil {
+		line, err := br.ReadBytes('\n')
+		if record := bytes.TrimRight(line, "\r\n"); len(record) > 0 {
+			if table, ok := r.route(record); ok {
+				if table == "" {
+					stopErr = errors.ES(errors.OpFileIngest, errors.KClientArgs, "the RouteFunc of the Router returned an empty table").SetNoRetry()
+					break
+				}
+				batch := batches[table]
+				if batch != nil && batch.Len()+len(record)+1 > routerBatchSize {
+					ingest(table, batch)
+					batch = nil
+				}
+				if batch == nil {
+					batch = &bytes.Buffer{}
+					batches[table] = batch
+				}
+				batch.Write(record)
+				batch.WriteByte('\n')
+			}
+		}
+
+		switch {
+		case err == io.EOF:
+			tables := make([]string, 0, len(batches))
+			for table := range batches {
+				tables = append(tables, table)
+			}
+			sort.Strings(tables)
+			for _, table := range tables {
+				ingest(table, batches[table])
+			}
+			return results, results.Err()
+		case err != nil:
+			stopErr = errors.ES(errors.OpFileIngest, errors.KIO, "the reader of the Router failed: %s", err)
+		case ctx.Err() != nil:
+			stopErr = errors.ES(errors.OpFileIngest, errors.KTimeout, "the Router stopped before the reader was done: %s", ctx.Err())
+		}
+	}
+
+	if len(batches) == 0 {
+		return results, stopErr
+	}
+	for table := range batches {
+		results.addFailed(table, stopErr)
+	}
+	return results, results.Err()
+}
+
+// ingestor returns the Ingestor of table, creating it if needed.
+func (r *Router) ingestor(table string) (Ingestor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ingestor, ok := r.ingestors[table]; ok {
+		return ingestor, nil
+	}
+	ingestor, err := r.newIngestor(table)
+	if err != nil {
+		return nil, err
+	}
+	r.ingestors[table] = ingestor
+	return ingestor, nil
+}
+
+// Close closes the clients of the tables.
+func (r *Router) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for table, ingestor := range r.ingestors {
+		if err := ingestor.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(r.ingestors, table)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.GetCombinedError(errs...)
+}
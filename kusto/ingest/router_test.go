@@ -0,0 +1,153 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, fmt.Errorf("read error")
+}
+
+// testRouter returns a Router that routes JSON records by their "type" field to fake ingestors.
+func testRouter(t *testing.T, failing string) (*Router, map[string]*recordingIngestor) {
+	ingestors := map[string]*recordingIngestor{}
+	route := func(record []byte) (string, bool) {
+		switch {
+		case bytes.Contains(record, []byte(`"type":"a"`)):
+			return "TableA", true
+		case bytes.Contains(record, []byte(`"type":"b"`)):
+			return "TableB", true
+		}
+		return "", false
+	}
+	r, err := NewRouter(mockClient{}, "db", route)
+	require.NoError(t, err)
+	r.newIngestor = func(table string) (Ingestor, error) {
+		f := &recordingIngestor{}
+		if table == failing {
+			f.fail = func(string) error { return fmt.Errorf("ingestion failed") }
+		}
+		ingestors[table] = f
+		return f, nil
+	}
+	return r, ingestors
+}
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+
+	r, ingestors := testRouter(t, "")
+	input := `{"type":"a","v":1}` + "\n" +
+		`{"type":"b","v":2}` + "\r\n" +
+		"\n" +
+		`{"type":"c","v":3}` + "\n" +
+		`{"type":"a","v":4}`
+
+	results, err := r.FromReader(context.Background(), strings.NewReader(input), FileFormat(JSON))
+	require.NoError(t, err)
+
+	succeeded := results.Succeeded()
+	require.Len(t, succeeded, 2)
+	assert.Equal(t, "TableA", succeeded[0].Source)
+	assert.Equal(t, "TableB", succeeded[1].Source)
+	assert.Equal(t, []string{`{"type":"a","v":1}` + "\n" + `{"type":"a","v":4}` + "\n"}, ingestors["TableA"].args())
+	assert.Equal(t, []string{`{"type":"b","v":2}` + "\n"}, ingestors["TableB"].args())
+
+	require.NoError(t, r.Close())
+	assert.True(t, ingestors["TableA"].closed)
+	assert.True(t, ingestors["TableB"].closed)
+}
+
+func TestRouterBatchSize(t *testing.T) {
+	t.Parallel()
+
+	r, ingestors := testRouter(t, "")
+	record := `{"type":"a","v":"` + strings.Repeat("x", mb) + `"}` + "\n"
+	input := strings.Repeat(record, 6)
+
+	results, err := r.FromReader(context.Background(), strings.NewReader(input), FileFormat(JSON))
+	require.NoError(t, err)
+	assert.Len(t, results.Succeeded(), 2)
+
+	payloads := ingestors["TableA"].args()
+	require.Len(t, payloads, 2)
+	for _, p := range payloads {
+		assert.LessOrEqual(t, len(p), routerBatchSize)
+	}
+	assert.Equal(t, input, payloads[0]+payloads[1])
+}
+
+func TestRouterErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		reader     io.Reader
+		options    []FileOption
+		failing    string
+		wantFailed []string
+		wantResult bool
+	}{
+		{
+			desc:    "Unsupported format",
+			reader:  strings.NewReader(`{"type":"a"}`),
+			options: []FileOption{FileFormat(Parquet)},
+		},
+		{
+			desc:    "Table option",
+			reader:  strings.NewReader(`{"type":"a"}`),
+			options: []FileOption{Table("other")},
+		},
+		{
+			desc:       "Table ingestion fails",
+			reader:     strings.NewReader(`{"type":"a"}` + "\n" + `{"type":"b"}`),
+			options:    []FileOption{FileFormat(JSON)},
+			failing:    "TableB",
+			wantFailed: []string{"TableB"},
+			wantResult: true,
+		},
+		{
+			desc:       "Reader fails",
+			reader:     io.MultiReader(strings.NewReader(`{"type":"a"}`+"\n"), errReader{}),
+			options:    []FileOption{FileFormat(JSON)},
+			wantFailed: []string{"TableA"},
+			wantResult: true,
+		},
+		{
+			desc:       "Reader fails without records",
+			reader:     errReader{},
+			options:    []FileOption{FileFormat(JSON)},
+			wantResult: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r, _ := testRouter(t, test.failing)
+			results, err := r.FromReader(context.Background(), test.reader, test.options...)
+			assert.Error(t, err)
+			if !test.wantResult {
+				assert.Nil(t, results)
+				return
+			}
+			var failed []string
+			for _, f := range results.Failed() {
+				failed = append(failed, f.Source)
+			}
+			assert.Equal(t, test.wantFailed, failed)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package ingest
+
+// sample.go holds the client side record sampling used by the SampleRate() option.
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/rand"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// applySampleRate wraps reader with a sampler if the SampleRate() option was provided. It returns an error if the
+// data format cannot be sampled.
+func applySampleRate(reader io.Reader, props *properties.All) (io.Reader, error) {
+	fraction := props.Source.SampleRate
+	if fraction == 0 {
+		return reader, nil
+	}
+	props.Source.SampleRate = 0
+
+	keep := sampler(fraction, props.Source.SampleSeed)
+	switch props.Ingestion.Additional.Format {
+	case DFUnknown, CSV:
+		return sampleCSV(reader, keep), nil
+	case JSON, MultiJSON:
+		return sampleJSON(reader, keep), nil
+	}
+	return nil, errors.ES(
+		errors.OpFileIngest,
+		errors.KClientArgs,
+		"SampleRate() option does not support format %s, only CSV, JSON and MultiJSON", props.Ingestion.Additional.Format,
+	).SetNoRetry()
+}
+
+// sampler returns a function that decides if the next record is kept. The decisions are deterministic for a seed.
+func sampler(fraction float64, seed int64) func() bool {
+	r := rand.New(rand.NewSource(seed))
+	return func() bool {
+		return r.Float64() < fraction
+	}
+}
+
+// sampleCSV returns an io.Reader that outputs the CSV records of reader for which keep() returned true.
+func sampleCSV(reader io.Reader, keep func() bool) io.Reader {
+	pr, pw := io.Pipe()
+
+	go func() {
+		in := csv.NewReader(reader)
+		in.FieldsPerRecord = -1
+		in.ReuseRecord = true
+		out := csv.NewWriter(pw)
+
+		for {
+			rec, err := in.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if !keep() {
+				continue
+			}
+			if err := out.Write(rec); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		out.Flush()
+		pw.CloseWithError(out.Error())
+	}()
+
+	return pr
+}
+
+// sampleJSON returns an io.Reader that outputs the JSON records of reader for which keep() returned true, one per line.
+// reader may hold one record per line (JSON) or records spanning multiple lines (MultiJSON), which are compacted to a
+// line each.
+func sampleJSON(reader io.Reader, keep func() bool) io.Reader {
+	pr, pw := io.Pipe()
+
+	go func() {
+		dec := json.NewDecoder(reader)
+		line := &bytes.Buffer{}
+
+		for {
+			var rec json.RawMessage
+			err := dec.Decode(&rec)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if !keep() {
+				continue
+			}
+			line.Reset()
+			if err := json.Compact(line, rec); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			line.WriteByte('\n')
+			if _, err := pw.Write(line.Bytes()); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	return pr
+}
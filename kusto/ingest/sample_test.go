@@ -0,0 +1,121 @@
+package ingest
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleRate(t *testing.T) {
+	t.Parallel()
+
+	var csvInput, jsonInput, multiJSONInput strings.Builder
+	for i := 0; i < 1000; i++ {
+		csvInput.WriteString("a,b,c\n")
+		jsonInput.WriteString("{\"a\": 1}\n")
+		multiJSONInput.WriteString("{\n  \"a\": 1\n}\n")
+	}
+
+	tests := []struct {
+		desc     string
+		format   properties.DataFormat
+		input    string
+		fraction float64
+		record   string
+		err      bool
+	}{
+		{
+			desc:     "CSV",
+			format:   CSV,
+			input:    csvInput.String(),
+			fraction: 0.1,
+			record:   "a,b,c\n",
+		},
+		{
+			desc:     "Unknown format is treated as CSV",
+			format:   DFUnknown,
+			input:    csvInput.String(),
+			fraction: 0.5,
+			record:   "a,b,c\n",
+		},
+		{
+			desc:     "JSON",
+			format:   JSON,
+			input:    jsonInput.String(),
+			fraction: 0.1,
+			record:   "{\"a\":1}\n",
+		},
+		{
+			desc:     "MultiJSON records are written one per line",
+			format:   MultiJSON,
+			input:    multiJSONInput.String(),
+			fraction: 0.1,
+			record:   "{\"a\":1}\n",
+		},
+		{
+			desc:     "Fraction of 1 keeps everything",
+			format:   CSV,
+			input:    csvInput.String(),
+			fraction: 1,
+			record:   "a,b,c\n",
+		},
+		{
+			desc:     "Unsupported format",
+			format:   Parquet,
+			input:    "",
+			fraction: 0.1,
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			sample := func(seed int64) string {
+				props := properties.All{}
+				props.Source.SampleRate = test.fraction
+				props.Source.SampleSeed = seed
+				props.Ingestion.Additional.Format = test.format
+
+				reader, err := applySampleRate(strings.NewReader(test.input), &props)
+				if test.err {
+					assert.Error(t, err)
+					return ""
+				}
+				require.NoError(t, err)
+				assert.Zero(t, props.Source.SampleRate)
+
+				got, err := ioutil.ReadAll(reader)
+				require.NoError(t, err)
+				return string(got)
+			}
+
+			got := sample(1)
+			if test.err {
+				return
+			}
+
+			// The same seed must produce the same sample.
+			assert.Equal(t, got, sample(1))
+
+			records := strings.Count(got, test.record)
+			want := int(test.fraction * 1000)
+			assert.InDelta(t, want, records, float64(want)/5+1)
+		})
+	}
+}
+
+func TestSampleRateNotSet(t *testing.T) {
+	t.Parallel()
+
+	input := strings.NewReader("a,b,c\n")
+	got, err := applySampleRate(input, &properties.All{})
+	require.NoError(t, err)
+	assert.Same(t, input, got)
+}
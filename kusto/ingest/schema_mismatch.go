@@ -0,0 +1,93 @@
+package ingest
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// SchemaMismatchError is returned by streaming ingestion when the service reports that a value could not be
+// converted to the type of the column it was mapped to. Use errors.As() to detect it. The original service
+// error is available with Unwrap().
+type SchemaMismatchError struct {
+	// Column is the name of the column the value was going to be stored in.
+	Column string
+	// ExpectedType is the Kusto type of Column, if reported by the service.
+	ExpectedType string
+	// GotValue is the value that could not be converted, if reported by the service.
+	GotValue string
+
+	// Err is the error returned by the service.
+	Err *errors.Error
+}
+
+// Error implements error.
+func (s *SchemaMismatchError) Error() string {
+	msg := fmt.Sprintf("schema mismatch on column %q", s.Column)
+	if s.ExpectedType != "" {
+		msg += fmt.Sprintf(": expected type %s", s.ExpectedType)
+	}
+	if s.GotValue != "" {
+		msg += fmt.Sprintf(", got value %q", s.GotValue)
+	}
+	return fmt.Sprintf("%s:\n%s", msg, s.Err)
+}
+
+// Unwrap implements "interface {Unwrap() error}" as defined internally by the go stdlib errors package.
+func (s *SchemaMismatchError) Unwrap() error {
+	return s.Err
+}
+
+// schemaMismatchREs are the message formats the service uses when reporting a value that could not be coerced into
+// a column. Every expression must have a "column" group, "type" and "value" groups are optional.
+var schemaMismatchREs = []*regexp.Regexp{
+	// Column 'Timestamp' of type 'datetime' could not be assigned value 'abc'
+	regexp.MustCompile(`(?i)column\s+'(?P<column>[^']+)'\s*(?:\(|of\s+)?type\s+'?(?P<type>[\w.]+)'?\)?.*?value\s+'(?P<value>[^']*)'`),
+	// Failed to convert value 'abc' to type 'datetime' for column 'Timestamp'
+	regexp.MustCompile(`(?i)value\s+'(?P<value>[^']*)'\s+(?:to|as)\s+(?:type\s+)?'?(?P<type>[\w.]+)'?.*?column\s+'(?P<column>[^']+)'`),
+	// Invalid value for column 'Timestamp' (expected type 'datetime')
+	regexp.MustCompile(`(?i)invalid\s+value\s+for\s+column\s+'(?P<column>[^']+)'(?:.*?type\s+'?(?P<type>[\w.]+)'?)?`),
+}
+
+// schemaMismatch returns a *SchemaMismatchError if the service error in e describes a schema mismatch that names the
+// offending column. Otherwise it returns nil.
+func schemaMismatch(e *errors.Error) *SchemaMismatchError {
+	m := e.UnmarshalREST()
+	if m == nil {
+		return nil
+	}
+	errMap, ok := m["error"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	// "@message" holds the detailed engine message, "message" is usually generic, but check both.
+	for _, key := range []string{"@message", "message"} {
+		msg, ok := errMap[key].(string)
+		if !ok {
+			continue
+		}
+		for _, re := range schemaMismatchREs {
+			match := re.FindStringSubmatch(msg)
+			if match == nil {
+				continue
+			}
+			sm := &SchemaMismatchError{Err: e}
+			for i, name := range re.SubexpNames() {
+				switch name {
+				case "column":
+					sm.Column = match[i]
+				case "type":
+					sm.ExpectedType = match[i]
+				case "value":
+					sm.GotValue = match[i]
+				}
+			}
+			// The data will never match the schema on a retry.
+			e.SetNoRetry()
+			return sm
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	goErrors "errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamingHTTPError(body string) error {
+	return errors.HTTP(errors.OpIngestStream, "400 Bad Request", http.StatusBadRequest, ioutil.NopCloser(bytes.NewBufferString(body)), "streaming ingest issue")
+}
+
+func TestSchemaMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		body string
+		want *SchemaMismatchError
+	}{
+		{
+			desc: "Not JSON",
+			body: "some error",
+		},
+		{
+			desc: "No column details",
+			body: `{"error": {"code": "BadRequest", "message": "Request is invalid and cannot be executed.", "@message": "Bad streaming ingestion request"}}`,
+		},
+		{
+			desc: "Column, type and value",
+			body: `{"error": {"code": "BadRequest", "message": "Request is invalid and cannot be executed.", ` +
+				`"@message": "Bad streaming ingestion request: Column 'Timestamp' of type 'datetime' could not be assigned value 'abc'"}}`,
+			want: &SchemaMismatchError{Column: "Timestamp", ExpectedType: "datetime", GotValue: "abc"},
+		},
+		{
+			desc: "Value, type and column",
+			body: `{"error": {"code": "BadRequest", "@message": "Failed to convert value 'ten' to type 'long' for column 'Count'"}}`,
+			want: &SchemaMismatchError{Column: "Count", ExpectedType: "long", GotValue: "ten"},
+		},
+		{
+			desc: "Column only",
+			body: `{"error": {"code": "BadRequest", "message": "Invalid value for column 'Count'"}}`,
+			want: &SchemaMismatchError{Column: "Count"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			streamIngestor := fakeStreamIngestor{
+				onStreamIngest: func(context.Context, string, string, io.Reader, properties.DataFormat, string, string) error {
+					return streamingHTTPError(test.body)
+				},
+			}
+
+			_, err := streamImpl(streamIngestor, context.Background(), bytes.NewBufferString("1,2"), properties.All{})
+			require.Error(t, err)
+
+			var got *SchemaMismatchError
+			if test.want == nil {
+				assert.False(t, goErrors.As(err, &got))
+				return
+			}
+
+			require.True(t, goErrors.As(err, &got))
+			assert.Equal(t, test.want.Column, got.Column)
+			assert.Equal(t, test.want.ExpectedType, got.ExpectedType)
+			assert.Equal(t, test.want.GotValue, got.GotValue)
+			assert.False(t, errors.Retry(err))
+			assert.Contains(t, err.Error(), test.want.Column)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package ingest
+
+// skip_tag.go holds the check used by the SkipIfTagExists() option.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// skipIfTagExists returns true if the SkipIfTagExists() option was provided and the table has data with its tag, in
+// which case result is marked as skipped. If the data is not skipped, props are set to ingest it with the tag.
+func (i *Ingestion) skipIfTagExists(ctx context.Context, props *properties.All, result *Result) (bool, error) {
+	tag := props.Source.SkipIfTagExists
+	if tag == "" {
+		return false, nil
+	}
+	props.Source.SkipIfTagExists = ""
+
+	db, tableName := props.Ingestion.DatabaseName, props.Ingestion.TableName
+	stmt := kusto.NewStmt(".show table ", kusto.UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" extents where tags has ").UnsafeAdd(kql.QuoteString(kql.IngestByPrefix + tag))
+
+	rows := 0
+	iter, err := i.client.Mgmt(ctx, db, stmt)
+	if err == nil {
+		defer iter.Stop()
+		err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+			if e != nil {
+				return e
+			}
+			// "has" matches terms of the tags, so only count the extents that have the exact tag.
+			if hasTag(r, kql.IngestByPrefix+tag) {
+				rows++
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return false, errors.E(errors.OpFileIngest, errors.KOther, fmt.Errorf("could not check whether table %q in database %q has data tagged %q: %w", tableName, db, kql.IngestByPrefix+tag, err))
+	}
+
+	if rows > 0 {
+		result.putProps(*props)
+		result.record.Status = Skipped
+		result.record.Details = "the table already has data tagged " + kql.IngestByPrefix + tag
+		return true, nil
+	}
+
+	additional := &props.Ingestion.Additional
+	additional.IngestIfNotExists = tag
+	hasTag := false
+	for _, t := range additional.Tags {
+		if t == kql.IngestByPrefix+tag {
+			hasTag = true
+			break
+		}
+	}
+	if !hasTag {
+		additional.Tags = append(append([]string(nil), additional.Tags...), kql.IngestByPrefix+tag)
+	}
+	return false, nil
+}
+
+// hasTag reports if r, a row of ".show table extents", has tag in its Tags column, which holds one tag per line.
+func hasTag(r *table.Row, tag string) bool {
+	v, ok := r.Value("Tags")
+	if !ok {
+		return false
+	}
+	tags, ok := v.(value.String)
+	if !ok {
+		return false
+	}
+	for _, t := range kql.SplitTags(tags.Value) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Skipped returns true if the ingestion was skipped without uploading any data, like when the table already had data
+// with the tag passed to SkipIfTagExists().
+func (r *Result) Skipped() bool {
+	return r.record.Status == Skipped
+}
@@ -0,0 +1,121 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipIfTagExists(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		options     []FileOption
+		rows        []value.Values
+		mgmtErr     bool
+		wantSkipped bool
+		wantTags    []string
+		wantCalls   int
+		err         bool
+	}{
+		{
+			desc:    "Not set",
+			options: []FileOption{Tags([]string{"a"})},
+		},
+		{
+			desc:        "Tag exists",
+			options:     []FileOption{SkipIfTagExists("batch'1")},
+			rows:        []value.Values{{value.String{Value: "extent", Valid: true}, value.String{Value: "drop-by:a\r\ningest-by:batch'1", Valid: true}}},
+			wantSkipped: true,
+			wantCalls:   1,
+		},
+		{
+			desc:      "Only a tag with the same terms exists",
+			options:   []FileOption{SkipIfTagExists("batch'1")},
+			rows:      []value.Values{{value.String{Value: "extent", Valid: true}, value.String{Value: "ingest-by:batch'1-2", Valid: true}}},
+			wantTags:  []string{"ingest-by:batch'1"},
+			wantCalls: 1,
+		},
+		{
+			desc:      "Tag does not exist",
+			options:   []FileOption{Tags([]string{"a"}), SkipIfTagExists("batch'1")},
+			wantTags:  []string{"a", "ingest-by:batch'1"},
+			wantCalls: 1,
+		},
+		{
+			desc:      "Tag already set",
+			options:   []FileOption{SkipIfTagExists("batch'1"), Tags([]string{"ingest-by:batch'1"})},
+			wantTags:  []string{"ingest-by:batch'1"},
+			wantCalls: 1,
+		},
+		{
+			desc:      "Mgmt error",
+			options:   []FileOption{SkipIfTagExists("batch'1")},
+			mgmtErr:   true,
+			wantCalls: 1,
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			client := mockClient{
+				onMgmt: func(_ context.Context, db string, query kusto.Stmt, _ ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+					calls++
+					assert.Equal(t, "db", db)
+					assert.Equal(t, `.show table ['table'] extents where tags has 'ingest-by:batch\'1'`, query.String())
+					if test.mgmtErr {
+						return nil, fmt.Errorf("some mgmt error")
+					}
+
+					mock, err := kusto.NewMockRows(table.Columns{{Name: "ExtentId", Type: types.String}, {Name: "Tags", Type: types.String}})
+					require.NoError(t, err)
+					for _, row := range test.rows {
+						require.NoError(t, mock.Row(row))
+					}
+					iter := &kusto.RowIterator{}
+					require.NoError(t, iter.Mock(mock))
+					return iter, nil
+				},
+			}
+
+			i := &Ingestion{client: client}
+			props := properties.All{Ingestion: properties.Ingestion{DatabaseName: "db", TableName: "table"}}
+			for _, o := range test.options {
+				require.NoError(t, o.Run(&props, QueuedClient, FromFile))
+			}
+
+			result := newResult()
+			for n := 0; n < 2; n++ { // The second call must not check again.
+				skipped, err := i.skipIfTagExists(context.Background(), &props, result)
+				if test.err {
+					assert.Error(t, err)
+					break
+				}
+				require.NoError(t, err)
+				if n == 0 {
+					assert.Equal(t, test.wantSkipped, skipped)
+				}
+			}
+			assert.Equal(t, test.wantCalls, calls)
+			assert.Equal(t, test.wantSkipped, result.Skipped())
+			if test.wantTags != nil {
+				assert.Equal(t, test.wantTags, props.Ingestion.Additional.Tags)
+				assert.Equal(t, "batch'1", props.Ingestion.Additional.IngestIfNotExists)
+			}
+		})
+	}
+}
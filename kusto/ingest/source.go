@@ -0,0 +1,92 @@
+package ingest
+
+// source.go holds Source, which allows handling the sources FromFile() and FromReader() accept as values.
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/queued"
+)
+
+// Source is data to ingest with Ingest(). It is one of FileSource, ReaderSource or BlobSource, which allows code that
+// ingests data from different sources to hold them together, like in a []Source.
+type Source interface {
+	// ingest ingests the source with ingestor.
+	ingest(ctx context.Context, ingestor Ingestor, options []FileOption) (*Result, error)
+}
+
+// FileSource is a local file to ingest, as passed to FromFile().
+type FileSource struct {
+	// Path is the path of the file.
+	Path string
+}
+
+func (s FileSource) ingest(ctx context.Context, ingestor Ingestor, options []FileOption) (*Result, error) {
+	if local, err := queued.IsLocalPath(s.Path); err != nil || !local {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "FileSource.Path(%s) is not a local file, use BlobSource for blobstore URIs", withoutQuery(s.Path)).SetNoRetry()
+	}
+	return ingestor.FromFile(ctx, s.Path, options...)
+}
+
+// ReaderSource is data to read from an io.Reader, as passed to FromReader().
+type ReaderSource struct {
+	// Reader is read to its end.
+	Reader io.Reader
+}
+
+func (s ReaderSource) ingest(ctx context.Context, ingestor Ingestor, options []FileOption) (*Result, error) {
+	if s.Reader == nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "ReaderSource.Reader must be set").SetNoRetry()
+	}
+	return ingestor.FromReader(ctx, s.Reader, options...)
+}
+
+// BlobSource is a blob in Azure Blob Storage to ingest, as passed to FromFile(). The streaming client does not
+// support blobs.
+type BlobSource struct {
+	// URI is the URI of the blob, including a SAS token or other credentials the service can use to read it.
+	URI string
+}
+
+func (s BlobSource) ingest(ctx context.Context, ingestor Ingestor, options []FileOption) (*Result, error) {
+	if local, err := queued.IsLocalPath(s.URI); err != nil || local {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "BlobSource.URI(%s) is not a blobstore URI, use FileSource for local files", withoutQuery(s.URI)).SetNoRetry()
+	}
+	return ingestor.FromFile(ctx, s.URI, options...)
+}
+
+// Ingest ingests src, calling FromFile() or FromReader() depending on its type.
+// This method is thread-safe.
+func (i *Ingestion) Ingest(ctx context.Context, src Source, options ...FileOption) (*Result, error) {
+	return ingestSource(ctx, i, src, options)
+}
+
+// Ingest ingests src, calling FromFile() or FromReader() depending on its type. BlobSource is not supported.
+func (i *Streaming) Ingest(ctx context.Context, src Source, options ...FileOption) (*Result, error) {
+	return ingestSource(ctx, i, src, options)
+}
+
+// Ingest ingests src, calling FromFile() or FromReader() depending on its type.
+func (m *Managed) Ingest(ctx context.Context, src Source, options ...FileOption) (*Result, error) {
+	return ingestSource(ctx, m, src, options)
+}
+
+// ingestSource ingests src with ingestor.
+func ingestSource(ctx context.Context, ingestor Ingestor, src Source, options []FileOption) (*Result, error) {
+	if src == nil {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "Ingest() requires a Source").SetNoRetry()
+	}
+	return src.ingest(ctx, ingestor, options)
+}
+
+// withoutQuery returns uri without its query string, which holds the SAS token of a blob URI, so it can be part of an
+// error.
+func withoutQuery(uri string) string {
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		return uri[:i]
+	}
+	return uri
+}
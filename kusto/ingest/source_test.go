@@ -0,0 +1,57 @@
+package ingest
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestSource(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data.csv")
+	require.NoError(t, ioutil.WriteFile(path, []byte("1,2\n"), 0644))
+	const blob = "https://account.blob.core.windows.net/container/data.csv?sig=secret"
+
+	tests := []struct {
+		desc       string
+		src        Source
+		wantMethod string
+		wantArg    string
+		err        bool
+	}{
+		{desc: "File", src: FileSource{Path: path}, wantMethod: "FromFile", wantArg: path},
+		{desc: "Reader", src: ReaderSource{Reader: strings.NewReader("1,2\n")}, wantMethod: "FromReader", wantArg: "1,2\n"},
+		{desc: "Blob", src: BlobSource{URI: blob}, wantMethod: "FromFile", wantArg: blob},
+		{desc: "File with a blob URI", src: FileSource{Path: blob}, err: true},
+		{desc: "Missing file", src: FileSource{Path: filepath.Join(t.TempDir(), "missing.csv")}, err: true},
+		{desc: "Blob with a local path", src: BlobSource{URI: path}, err: true},
+		{desc: "Reader without reader", src: ReaderSource{}, err: true},
+		{desc: "No source", err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ingestor := &recordingIngestor{}
+			_, err := ingestSource(context.Background(), ingestor, test.src, nil)
+			if test.err {
+				require.Error(t, err)
+				assert.Empty(t, ingestor.calls)
+				assert.NotContains(t, err.Error(), "secret")
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, ingestor.calls, 1)
+			assert.Equal(t, test.wantMethod, ingestor.calls[0].method)
+			assert.Equal(t, test.wantArg, ingestor.calls[0].arg)
+		})
+	}
+}
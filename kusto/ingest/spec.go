@@ -0,0 +1,129 @@
+package ingest
+
+// spec.go holds IngestionSpec, which allows driving file ingestion from a declarative config instead of FileOption(s).
+
+import (
+	"context"
+	"os"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/queued"
+)
+
+// IngestionSpec describes a single file ingestion. It bundles the settings that would otherwise be passed as
+// FileOption(s) to Ingestor.FromFile().
+type IngestionSpec struct {
+	// Path is the local file path or blobstore URI to ingest. Required.
+	Path string
+	// Database overrides the database the Ingestor was created with. If empty, the Ingestor's database is used.
+	Database string
+	// Table overrides the table the Ingestor was created with. If empty, the Ingestor's table is used.
+	Table string
+	// Format is the format of the data. If DFUnknown, the format is discovered from the extension of Path.
+	Format DataFormat
+	// MappingRef is the name of a pre-created ingestion mapping on the table. Optional.
+	MappingRef string
+	// Tags are tags to be associated with the ingested data. Optional. Not supported by the streaming client.
+	Tags []string
+}
+
+// Validate checks that the IngestionSpec is complete and consistent. For local paths, this checks that the file
+// exists. Validate does not contact the service.
+func (s IngestionSpec) Validate() error {
+	if s.Path == "" {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IngestionSpec.Path must be set").SetNoRetry()
+	}
+
+	local, err := queued.IsLocalPath(s.Path)
+	if err != nil {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IngestionSpec.Path(%s) is not valid: %s", s.Path, err).SetNoRetry()
+	}
+	if local {
+		stat, err := os.Stat(s.Path)
+		if err != nil {
+			return errors.ES(errors.OpFileIngest, errors.KLocalFileSystem, "IngestionSpec.Path(%s) could not be found: %s", s.Path, err).SetNoRetry()
+		}
+		if stat.IsDir() {
+			return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IngestionSpec.Path(%s) is a directory", s.Path).SetNoRetry()
+		}
+	}
+
+	format := s.format()
+	if format == DFUnknown {
+		return errors.ES(
+			errors.OpFileIngest,
+			errors.KClientArgs,
+			"IngestionSpec.Format was not set and could not be discovered from the extension of Path(%s)", s.Path,
+		).SetNoRetry()
+	}
+
+	if s.MappingRef != "" && mappingKind(format) == DFUnknown {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IngestionSpec.MappingRef cannot be used with format %s", format).SetNoRetry()
+	}
+
+	for _, tag := range s.Tags {
+		if tag == "" {
+			return errors.ES(errors.OpFileIngest, errors.KClientArgs, "IngestionSpec.Tags cannot contain an empty tag").SetNoRetry()
+		}
+	}
+	return nil
+}
+
+// format returns the format of the data, discovering it from the Path if not set.
+func (s IngestionSpec) format() DataFormat {
+	if s.Format != DFUnknown {
+		return s.Format
+	}
+	return properties.DataFormatDiscovery(s.Path)
+}
+
+// options converts the IngestionSpec into FileOption(s). The spec must have been validated.
+func (s IngestionSpec) options() []FileOption {
+	format := s.format()
+
+	options := []FileOption{FileFormat(format)}
+	if s.Database != "" {
+		options = append(options, Database(s.Database))
+	}
+	if s.Table != "" {
+		options = append(options, Table(s.Table))
+	}
+	if s.MappingRef != "" {
+		options = append(options, IngestionMappingRef(s.MappingRef, mappingKind(format)))
+	}
+	if len(s.Tags) > 0 {
+		options = append(options, Tags(s.Tags))
+	}
+	return options
+}
+
+// mappingKind returns the ingestion mapping kind that is used for a data format or DFUnknown if the format
+// does not support mappings.
+func mappingKind(format DataFormat) DataFormat {
+	switch format {
+	case CSV, TSV, TSVE, PSV, SCSV, SOHSV, TXT:
+		return CSV
+	case JSON, MultiJSON, SingleJSON:
+		return JSON
+	case AVRO, ApacheAVRO:
+		return AVRO
+	case Parquet, ORC:
+		return format
+	}
+	return DFUnknown
+}
+
+// FromFileWithSpec ingests the file described by spec using ingestor. The spec is fully validated before any
+// data is read or any call to the service is made.
+func FromFileWithSpec(ctx context.Context, ingestor Ingestor, spec IngestionSpec) (*Result, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := ingestor.(*Streaming); ok && len(spec.Tags) > 0 {
+		return nil, errors.ES(errors.OpFileIngest, errors.KClientArgs, "IngestionSpec.Tags is not supported by the streaming client").SetNoRetry()
+	}
+
+	return ingestor.FromFile(ctx, spec.Path, spec.options()...)
+}
@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestionSpec(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "data.csv")
+	noExtPath := filepath.Join(dir, "data")
+	for _, p := range []string{csvPath, noExtPath} {
+		require.NoError(t, ioutil.WriteFile(p, []byte("1,2\n"), 0644))
+	}
+
+	tests := []struct {
+		desc string
+		spec IngestionSpec
+		err  bool
+		want properties.Ingestion
+	}{
+		{
+			desc: "Missing path",
+			spec: IngestionSpec{},
+			err:  true,
+		},
+		{
+			desc: "File does not exist",
+			spec: IngestionSpec{Path: filepath.Join(dir, "missing.csv")},
+			err:  true,
+		},
+		{
+			desc: "Path is a directory",
+			spec: IngestionSpec{Path: dir, Format: CSV},
+			err:  true,
+		},
+		{
+			desc: "Format cannot be discovered",
+			spec: IngestionSpec{Path: noExtPath},
+			err:  true,
+		},
+		{
+			desc: "Mapping with a format that does not support mappings",
+			spec: IngestionSpec{Path: noExtPath, Format: Raw, MappingRef: "mapping"},
+			err:  true,
+		},
+		{
+			desc: "Empty tag",
+			spec: IngestionSpec{Path: csvPath, Tags: []string{""}},
+			err:  true,
+		},
+		{
+			desc: "Success with discovered format",
+			spec: IngestionSpec{Path: csvPath},
+			want: properties.Ingestion{
+				Additional: properties.Additional{Format: CSV},
+			},
+		},
+		{
+			desc: "Success with everything set",
+			spec: IngestionSpec{
+				Path:       noExtPath,
+				Database:   "db",
+				Table:      "table",
+				Format:     TSV,
+				MappingRef: "mapping",
+				Tags:       []string{"tag"},
+			},
+			want: properties.Ingestion{
+				DatabaseName: "db",
+				TableName:    "table",
+				Additional: properties.Additional{
+					Format:               TSV,
+					IngestionMappingRef:  "mapping",
+					IngestionMappingType: CSV,
+					Tags:                 []string{"tag"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ingestor := &recordingIngestor{}
+			_, err := FromFileWithSpec(context.Background(), ingestor, test.spec)
+			if test.err {
+				assert.Error(t, err)
+				assert.Empty(t, ingestor.calls, "FromFile() should not have been called")
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, ingestor.calls, 1)
+			assert.Equal(t, "FromFile", ingestor.calls[0].method)
+			assert.Equal(t, test.spec.Path, ingestor.calls[0].arg)
+			assert.Equal(t, test.want, ingestor.calls[0].props.Ingestion)
+		})
+	}
+}
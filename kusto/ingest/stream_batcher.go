@@ -0,0 +1,272 @@
+package ingest
+
+// stream_batcher.go holds StreamBatcher, which combines small payloads into one streaming ingestion request.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/gzip"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/google/uuid"
+)
+
+// batchKey identifies the submissions of a StreamBatcher that can be sent in the same request. It holds all the
+// properties a streaming ingestion request is sent with, other than its id.
+type batchKey struct {
+	db      string
+	table   string
+	format  DataFormat
+	mapping string
+}
+
+// streamSubmission is a payload submitted to a StreamBatcher.
+type streamSubmission struct {
+	props  properties.All
+	future *StreamFuture
+	// reportCount is the report of the RecordCounter() option, called once the submission was ingested.
+	reportCount func()
+}
+
+// streamBatch is the set of submissions a StreamBatcher sends in one request.
+type streamBatch struct {
+	key         batchKey
+	payload     bytes.Buffer
+	submissions []streamSubmission
+	timer       *time.Timer
+}
+
+// StreamFuture is the result of a payload submitted with StreamBatcher.Submit().
+type StreamFuture struct {
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+// Wait blocks until the request holding the payload finished and returns its result. The error is the error of the
+// request, so it is the same for all payloads that were sent together. If ctx is done before that, a KTimeout error
+// is returned, but the payload is still sent and Wait can be called again.
+func (f *StreamFuture) Wait(ctx context.Context) (*Result, error) {
+	select {
+	case <-ctx.Done():
+		return nil, errors.ES(errors.OpIngestStream, errors.KTimeout, "context was done before the streaming ingestion finished: %s", ctx.Err())
+	case <-f.done:
+		return f.result, f.err
+	}
+}
+
+// StreamBatcher combines small payloads submitted within a short window into one streaming ingestion request, to
+// reduce the per request overhead of streaming ingestion for high frequency small payloads.
+// Payloads are combined if they go to the same database and table with the same format and IngestionMappingRef(),
+// which are the only options a streaming ingestion request is sent with. The other options change the data, like
+// SourceEncoding(), and are applied to each payload before it is combined, so payloads with different options can be
+// sent together.
+// A batch is sent when the window after its first payload passed, when adding a payload would make it larger than
+// the 4MB streaming limit, or when Close() is called.
+// Only formats whose records are separated by newlines can be combined: CSV, TSV, TSVE, PSV, SCSV, SOHSV, TXT, JSON
+// and MultiJSON. Payloads are concatenated, with a newline added to the end of a payload that does not end in one, so
+// a CSV payload must not have a header. A failure of a request, such as a record that does not match the schema of
+// the table, fails all the payloads that were sent in it.
+// StreamBatcher is thread-safe.
+type StreamBatcher struct {
+	streaming *Streaming
+	window    time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	batches map[batchKey]*streamBatch
+}
+
+// NewStreamBatcher creates a StreamBatcher that sends its requests with streaming. A batch is sent at most window after
+// its first payload was submitted. The StreamBatcher does not close streaming.
+func NewStreamBatcher(streaming *Streaming, window time.Duration) (*StreamBatcher, error) {
+	if window <= 0 {
+		return nil, errors.ES(errors.OpIngestStream, errors.KClientArgs, "NewStreamBatcher() window must be positive, was %v", window).SetNoRetry()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StreamBatcher{
+		streaming: streaming,
+		window:    window,
+		ctx:       ctx,
+		cancel:    cancel,
+		batches:   map[batchKey]*streamBatch{},
+	}, nil
+}
+
+// Submit adds payload to a batch and returns a StreamFuture to receive the result of the request it is sent in.
+// payload must not be compressed and is not modified. The options are the same as for Streaming.FromReader(), except
+// that ClientRequestId() and DontCompress() are not supported. Errors in the options or the payload are returned
+// immediately.
+func (b *StreamBatcher) Submit(payload []byte, options ...FileOption) (*StreamFuture, error) {
+	props := b.streaming.newProp()
+	defaultID := props.Streaming.ClientRequestId
+	for _, o := range options {
+		if err := o.Run(&props, StreamingClient, FromReader); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case props.Streaming.ClientRequestId != defaultID:
+		return nil, errors.ES(errors.OpIngestStream, errors.KClientArgs, "StreamBatcher does not support ClientRequestId(), payloads share the id of their request").SetNoRetry()
+	case props.Source.DontCompress:
+		return nil, errors.ES(errors.OpIngestStream, errors.KClientArgs, "StreamBatcher does not support DontCompress(), payloads must not be compressed").SetNoRetry()
+	}
+
+	if props.Ingestion.Additional.Format == DFUnknown {
+		props.Ingestion.Additional.Format = CSV
+	}
+	if !batchableFormat(props.Ingestion.Additional.Format) {
+		return nil, errors.ES(errors.OpIngestStream, errors.KClientArgs, "StreamBatcher does not support format %s, its records cannot be concatenated", props.Ingestion.Additional.Format).SetNoRetry()
+	}
+
+	payload, reportCount, err := b.preparePayload(payload, &props)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxStreamingSize {
+		return nil, errors.ES(errors.OpIngestStream, errors.KClientArgs, "payload of %d bytes is larger than the streaming limit of %d bytes, use queued ingestion", len(payload), maxStreamingSize).SetNoRetry()
+	}
+
+	key := batchKey{
+		db:      props.Ingestion.DatabaseName,
+		table:   props.Ingestion.TableName,
+		format:  props.Ingestion.Additional.Format,
+		mapping: props.Ingestion.Additional.IngestionMappingRef,
+	}
+	future := &StreamFuture{done: make(chan struct{})}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, errors.ES(errors.OpIngestStream, errors.KClientArgs, "StreamBatcher.Submit() called after Close()").SetNoRetry()
+	}
+
+	batch := b.batches[key]
+	if batch != nil && batch.payload.Len()+len(payload) > maxStreamingSize {
+		b.sendLocked(batch)
+		batch = nil
+	}
+	if batch == nil {
+		batch = &streamBatch{key: key}
+		batch.timer = time.AfterFunc(b.window, func() { b.flush(batch) })
+		b.batches[key] = batch
+	}
+
+	batch.payload.Write(payload)
+	batch.submissions = append(batch.submissions, streamSubmission{props: props, future: future, reportCount: reportCount})
+	return future, nil
+}
+
+// Close sends the batches that were not sent yet and waits for all requests to finish.
+func (b *StreamBatcher) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	for _, batch := range b.batches {
+		b.sendLocked(batch)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	b.cancel()
+	return nil
+}
+
+// preparePayload applies the FromReader() options to payload and makes sure it ends with a newline. reportCount is
+// the report of the RecordCounter() option.
+func (b *StreamBatcher) preparePayload(payload []byte, props *properties.All) (_ []byte, reportCount func(), err error) {
+	reader, err := applySourceEncoding(bytes.NewReader(payload), props, gzip.DefaultCompression)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err = applyStripBOM(reader, props, gzip.DefaultCompression)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader = applyMalformedRowsFilter(reader, props)
+	reader, err = applySampleRate(reader, props)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, reportCount, err = applyRecordCounter(reader, props)
+	if err != nil {
+		return nil, nil, err
+	}
+	prepared, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, errors.E(errors.OpIngestStream, errors.KClientArgs, err).SetNoRetry()
+	}
+
+	if len(prepared) > 0 && prepared[len(prepared)-1] != '\n' {
+		prepared = append(prepared, '\n')
+	}
+	return prepared, reportCount, nil
+}
+
+// flush sends batch when its window passed, unless it was already sent.
+func (b *StreamBatcher) flush(batch *streamBatch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.batches[batch.key] == batch {
+		b.sendLocked(batch)
+	}
+}
+
+// sendLocked removes batch from the pending batches and sends it in the background. b.mu must be held.
+func (b *StreamBatcher) sendLocked(batch *streamBatch) {
+	batch.timer.Stop()
+	delete(b.batches, batch.key)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.send(batch)
+	}()
+}
+
+// send sends batch in one streaming ingestion request and completes the futures of its submissions.
+func (b *StreamBatcher) send(batch *streamBatch) {
+	props := properties.All{
+		Ingestion: properties.Ingestion{DatabaseName: batch.key.db, TableName: batch.key.table},
+		Streaming: properties.Streaming{ClientRequestId: fmt.Sprintf("KGC.executeStreamingBatch;%s", uuid.New().String())},
+	}
+	props.Ingestion.Additional.Format = batch.key.format
+	props.Ingestion.Additional.IngestionMappingRef = batch.key.mapping
+
+	_, err := streamImpl(b.streaming.streamConn, b.ctx, bytes.NewReader(batch.payload.Bytes()), props)
+
+	for _, s := range batch.submissions {
+		if err != nil {
+			s.future.err = err
+		} else {
+			s.reportCount()
+			s.props.Streaming.ClientRequestId = props.Streaming.ClientRequestId
+			result := newResult()
+			result.putProps(s.props)
+			result.record.Status = "Success"
+			s.future.result = result
+		}
+		close(s.future.done)
+	}
+}
+
+// batchableFormat reports if the records of format are separated by newlines, so payloads can be concatenated.
+func batchableFormat(format DataFormat) bool {
+	switch format {
+	case CSV, TSV, TSVE, PSV, SCSV, SOHSV, TXT, JSON, MultiJSON:
+		return true
+	}
+	return false
+}
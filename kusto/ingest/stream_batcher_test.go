@@ -0,0 +1,173 @@
+package ingest
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamRequest is a request received by a fakeStreamIngestor.
+type streamRequest struct {
+	table   string
+	format  properties.DataFormat
+	payload string
+}
+
+// recordingStreaming returns a Streaming that records its requests and fails those holding "fail".
+func recordingStreaming(t *testing.T) (*Streaming, func() []streamRequest) {
+	var mu sync.Mutex
+	var requests []streamRequest
+
+	streaming := &Streaming{
+		db:    "db",
+		table: "table",
+		streamConn: fakeStreamIngestor{
+			onStreamIngest: func(_ context.Context, _, table string, payload io.Reader, format properties.DataFormat, _ string, _ string) error {
+				zr, err := gzip.NewReader(payload)
+				require.NoError(t, err)
+				b, err := io.ReadAll(zr)
+				require.NoError(t, err)
+
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, streamRequest{table: table, format: format, payload: string(b)})
+				if strings.Contains(string(b), "fail") {
+					return fmt.Errorf("request failed")
+				}
+				return nil
+			},
+		},
+	}
+
+	return streaming, func() []streamRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]streamRequest(nil), requests...)
+	}
+}
+
+func TestStreamBatcher(t *testing.T) {
+	t.Parallel()
+
+	streaming, requests := recordingStreaming(t)
+	batcher, err := NewStreamBatcher(streaming, time.Hour)
+	require.NoError(t, err)
+
+	first, err := batcher.Submit([]byte("a,1"))
+	require.NoError(t, err)
+	second, err := batcher.Submit([]byte("b,2\nc\n"), DropMalformedRows())
+	require.NoError(t, err)
+	other, err := batcher.Submit([]byte(`{"a":1}`), Table("other"), FileFormat(JSON))
+	require.NoError(t, err)
+
+	require.NoError(t, batcher.Close())
+
+	for _, f := range []*StreamFuture{first, second, other} {
+		result, err := f.Wait(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, StatusCode("Success"), result.record.Status)
+	}
+	// The options of each payload are applied to it alone and reported in its own result.
+	result, err := first.Wait(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, result.DroppedRows())
+	result, err = second.Wait(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.DroppedRows())
+
+	got := requests()
+	require.Len(t, got, 2)
+	if got[0].table != "table" {
+		got[0], got[1] = got[1], got[0]
+	}
+	assert.Equal(t, streamRequest{table: "table", format: CSV, payload: "a,1\nb,2\n"}, got[0])
+	assert.Equal(t, streamRequest{table: "other", format: JSON, payload: "{\"a\":1}\n"}, got[1])
+
+	_, err = batcher.Submit([]byte("c,3"))
+	assert.Error(t, err)
+}
+
+func TestStreamBatcherWindow(t *testing.T) {
+	t.Parallel()
+
+	streaming, requests := recordingStreaming(t)
+	batcher, err := NewStreamBatcher(streaming, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer batcher.Close()
+
+	ok, err := batcher.Submit([]byte("a,1"))
+	require.NoError(t, err)
+	_, err = ok.Wait(context.Background())
+	require.NoError(t, err)
+
+	failed, err := batcher.Submit([]byte("fail,2"))
+	require.NoError(t, err)
+	also, err := batcher.Submit([]byte("b,3"))
+	require.NoError(t, err)
+	_, err = failed.Wait(context.Background())
+	assert.Error(t, err)
+	_, err = also.Wait(context.Background())
+	assert.Error(t, err)
+
+	assert.Len(t, requests(), 2)
+}
+
+func TestStreamBatcherSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	streaming, requests := recordingStreaming(t)
+	batcher, err := NewStreamBatcher(streaming, time.Hour)
+	require.NoError(t, err)
+
+	// Each payload is half of the limit once the newline is added, so two fit in a request.
+	half := strings.Repeat("a", maxStreamingSize/2-1)
+	for i := 0; i < 3; i++ {
+		_, err := batcher.Submit([]byte(half))
+		require.NoError(t, err)
+	}
+	require.NoError(t, batcher.Close())
+	assert.Len(t, requests(), 2)
+}
+
+func TestStreamBatcherSubmitErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		payload string
+		options []FileOption
+	}{
+		{desc: "Unsupported format", payload: "a", options: []FileOption{FileFormat(Parquet)}},
+		{desc: "ClientRequestId", payload: "a", options: []FileOption{ClientRequestId("id")}},
+		{desc: "DontCompress", payload: "a", options: []FileOption{DontCompress()}},
+		{desc: "Too large", payload: strings.Repeat("a", maxStreamingSize+1)},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			streaming, requests := recordingStreaming(t)
+			batcher, err := NewStreamBatcher(streaming, time.Hour)
+			require.NoError(t, err)
+
+			_, err = batcher.Submit([]byte(test.payload), test.options...)
+			assert.Error(t, err)
+			require.NoError(t, batcher.Close())
+			assert.Empty(t, requests())
+		})
+	}
+
+	_, err := NewStreamBatcher(&Streaming{}, 0)
+	assert.Error(t, err)
+}
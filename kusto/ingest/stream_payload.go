@@ -0,0 +1,140 @@
+package ingest
+
+// stream_payload.go holds streamPayload, which lets Managed read a payload again for every streaming attempt.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	kgzip "github.com/Azure/azure-kusto-go/kusto/ingest/internal/gzip"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// readSeekerAt is a payload that can be read again from any offset, like an *os.File or a *bytes.Reader.
+type readSeekerAt interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// streamPayload is a payload for Managed that can be read from its start for every streaming attempt and for the
+// fallback to queued ingestion.
+type streamPayload struct {
+	// open returns the payload to ingest with props, compressed if needed, from its start.
+	open func() (io.Reader, error)
+	// props are the properties to ingest the payload returned by open() with.
+	props properties.All
+	// fits is false if the payload is larger than can be streamed. Then open() can only be called once, to ingest
+	// the payload with the queued client.
+	fits bool
+}
+
+// bufferedStreamPayload returns the streamPayload of a payload that cannot be read again. The payload is compressed
+// if needed and kept in memory, up to the max size for streaming.
+func bufferedStreamPayload(payload io.Reader, props properties.All, level int) (streamPayload, error) {
+	if !props.Source.DontCompress {
+		var err error
+		payload, err = kgzip.CompressLevel(payload, level)
+		if err != nil {
+			return streamPayload{}, err
+		}
+		props.Source.DontCompress = true
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(payload, int64(maxStreamingSize+1)))
+	if err != nil {
+		return streamPayload{}, err
+	}
+
+	// If the payload is too large, what was read is combined with the rest of the payload for the queued client.
+	if len(buf) > maxStreamingSize {
+		combined := io.MultiReader(bytes.NewReader(buf), payload)
+		return streamPayload{open: func() (io.Reader, error) { return combined, nil }, props: props}, nil
+	}
+
+	return streamPayload{
+		open:  func() (io.Reader, error) { return bytes.NewReader(buf), nil },
+		props: props,
+		fits:  true,
+	}, nil
+}
+
+// seekableStreamPayload returns the streamPayload of a payload that can be read again. Every attempt reads the payload
+// from its current offset (and compresses it if needed) instead of keeping it in memory. To find out if a payload that
+// is compressed on the client fits, it is compressed once without keeping the output.
+func seekableStreamPayload(payload readSeekerAt, props properties.All, level int) (streamPayload, error) {
+	start, err := payload.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return streamPayload{}, errors.E(errors.OpIngestStream, errors.KIO, err)
+	}
+	end, err := payload.Seek(0, io.SeekEnd)
+	if err != nil {
+		return streamPayload{}, errors.E(errors.OpIngestStream, errors.KIO, err)
+	}
+	if _, err := payload.Seek(start, io.SeekStart); err != nil {
+		return streamPayload{}, errors.E(errors.OpIngestStream, errors.KIO, err)
+	}
+
+	// Every reader gets its own offset, so a read left running by an earlier attempt does not affect the next one.
+	section := func() io.Reader { return io.NewSectionReader(payload, start, end-start) }
+
+	sp := streamPayload{props: props}
+	if props.Source.DontCompress {
+		sp.open = func() (io.Reader, error) { return section(), nil }
+		sp.fits = end-start <= maxStreamingSize
+		return sp, nil
+	}
+
+	sp.props.Source.DontCompress = true
+	sp.open = func() (io.Reader, error) { return kgzip.CompressLevel(section(), level) }
+
+	size, err := compressedSize(section(), level, maxStreamingSize+1)
+	if err != nil {
+		return streamPayload{}, err
+	}
+	sp.fits = size <= maxStreamingSize
+	return sp, nil
+}
+
+// compressedSize returns the size of the gzip compression of r at level, or a size above limit if it is larger.
+func compressedSize(r io.Reader, level int, limit int64) (int64, error) {
+	counter := &limitCounter{limit: limit}
+	zw, err := gzip.NewWriterLevel(counter, level)
+	if err != nil {
+		return 0, errors.E(errors.OpIngestStream, errors.KClientArgs, err).SetNoRetry()
+	}
+	_, err = io.Copy(zw, r)
+	if err == nil {
+		err = zw.Close()
+	}
+	switch {
+	case err == errLimitReached:
+		return counter.n, nil
+	case err != nil:
+		return 0, errors.E(errors.OpIngestStream, errors.KIO, err)
+	}
+	return counter.n, nil
+}
+
+var errLimitReached = errors.ES(errors.OpIngestStream, errors.KInternal, "limit reached")
+
+// limitCounter is an io.Writer that counts the bytes written to it and fails once more than limit were written.
+type limitCounter struct {
+	n, limit int64
+}
+
+func (l *limitCounter) Write(b []byte) (int, error) {
+	l.n += int64(len(b))
+	if l.n > l.limit {
+		return 0, errLimitReached
+	}
+	return len(b), nil
+}
+
+// closePayload closes payload if it is an io.Closer, which stops the compression of a payload that was not read fully.
+func closePayload(payload io.Reader) {
+	if c, ok := payload.(io.Closer); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeekableStreamPayload(t *testing.T) {
+	t.Parallel()
+
+	random := make([]byte, maxStreamingSize+1)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	tests := []struct {
+		desc         string
+		data         []byte
+		offset       int64
+		dontCompress bool
+		wantFits     bool
+	}{
+		{desc: "Small", data: []byte("a,b\nc,d\n"), wantFits: true},
+		{desc: "Read from current offset", data: []byte("skip,me\na,b\n"), offset: 8, wantFits: true},
+		{desc: "Compressible", data: []byte(strings.Repeat("a,b\n", maxStreamingSize)), wantFits: true},
+		{desc: "Too large when compressed", data: random},
+		{desc: "Not compressed", data: []byte("a,b\n"), dontCompress: true, wantFits: true},
+		{desc: "Too large, not compressed", data: []byte(strings.Repeat("a,b\n", maxStreamingSize)), dontCompress: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			reader := bytes.NewReader(test.data)
+			_, err := reader.Seek(test.offset, io.SeekStart)
+			require.NoError(t, err)
+
+			props := properties.All{}
+			props.Source.DontCompress = test.dontCompress
+
+			sp, err := seekableStreamPayload(reader, props, gzip.DefaultCompression)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantFits, sp.fits)
+			assert.True(t, sp.props.Source.DontCompress)
+
+			// Every call to open() must return the whole payload, even if an earlier one was not read fully.
+			for i := 0; i < 3; i++ {
+				payload, err := sp.open()
+				require.NoError(t, err)
+				if i == 0 {
+					_, err := payload.Read(make([]byte, 1))
+					require.NoError(t, err)
+					closePayload(payload)
+					continue
+				}
+
+				if !test.dontCompress {
+					payload, err = gzip.NewReader(payload)
+					require.NoError(t, err)
+				}
+				got, err := io.ReadAll(payload)
+				require.NoError(t, err)
+				assert.Equal(t, test.data[test.offset:], got)
+			}
+		})
+	}
+}
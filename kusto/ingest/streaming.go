@@ -19,6 +19,8 @@ type streamIngestor interface {
 }
 
 // Streaming provides data ingestion from external sources into Kusto.
+// The payload of every streaming request is sent gzip compressed, with a "Content-Encoding: gzip" header. Data that is
+// already gzip compressed, like a file with a .gz extension or a reader passed with DontCompress(), is sent as it is.
 type Streaming struct {
 	db         string
 	table      string
@@ -32,7 +34,7 @@ var FileIsBlobErr = errors.ES(errors.OpIngestStream, errors.KClientArgs, "blobst
 // More information can be found here:
 // https://docs.microsoft.com/en-us/azure/kusto/management/create-ingestion-mapping-command
 func NewStreaming(client QueryClient, db, table string) (*Streaming, error) {
-	streamConn, err := conn.New(client.Endpoint(), client.Auth(), client.HttpClient())
+	streamConn, err := newStreamConn(client)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +58,20 @@ func (i *Streaming) FromFile(ctx context.Context, fPath string, options ...FileO
 		return nil, err
 	}
 
-	return streamImpl(i.streamConn, ctx, file, props)
+	payload, err := applySourceEncoding(file, &props, gzip.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	payload, err = applyStripBOM(payload, &props, gzip.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	payload, reportCount, err := applyRecordCounter(payload, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportOnSuccess(reportCount)(streamImpl(i.streamConn, ctx, payload, props))
 }
 
 func prepFileAndProps(fPath string, props *properties.All, options []FileOption, client ClientScope) (*os.File, error) {
@@ -72,6 +87,10 @@ func prepFileAndProps(fPath string, props *properties.All, options []FileOption,
 		}
 	}
 
+	if err := applyIgnoreExtraColumns(props); err != nil {
+		return nil, err
+	}
+
 	if !local {
 		return nil, FileIsBlobErr
 	}
@@ -108,7 +127,25 @@ func (i *Streaming) FromReader(ctx context.Context, reader io.Reader, options ..
 		}
 	}
 
-	return streamImpl(i.streamConn, ctx, reader, props)
+	reader, err := applySourceEncoding(reader, &props, gzip.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	reader, err = applyStripBOM(reader, &props, gzip.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	reader = applyMalformedRowsFilter(reader, &props)
+	reader, err = applySampleRate(reader, &props)
+	if err != nil {
+		return nil, err
+	}
+	reader, reportCount, err := applyRecordCounter(reader, &props)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportOnSuccess(reportCount)(streamImpl(i.streamConn, ctx, reader, props))
 }
 
 func streamImpl(c streamIngestor, ctx context.Context, payload io.Reader, props properties.All) (*Result, error) {
@@ -121,12 +158,25 @@ func streamImpl(c streamIngestor, ctx context.Context, payload io.Reader, props
 		props.Ingestion.Additional.Format = CSV
 	}
 
-	err := c.StreamIngest(ctx, props.Ingestion.DatabaseName, props.Ingestion.TableName, payload, props.Ingestion.Additional.Format,
-		props.Ingestion.Additional.IngestionMappingRef,
-		props.Streaming.ClientRequestId)
+	var (
+		raw []byte
+		err error
+	)
+	if r, ok := c.(streamResponder); ok {
+		raw, err = r.StreamIngestResponse(ctx, props.Ingestion.DatabaseName, props.Ingestion.TableName, payload, props.Ingestion.Additional.Format,
+			props.Ingestion.Additional.IngestionMappingRef,
+			props.Streaming.ClientRequestId)
+	} else {
+		err = c.StreamIngest(ctx, props.Ingestion.DatabaseName, props.Ingestion.TableName, payload, props.Ingestion.Additional.Format,
+			props.Ingestion.Additional.IngestionMappingRef,
+			props.Streaming.ClientRequestId)
+	}
 
 	if err != nil {
 		if e, ok := errors.GetKustoError(err); ok {
+			if sm := schemaMismatch(e); sm != nil {
+				return nil, sm
+			}
 			return nil, e
 		}
 		return nil, errors.E(errors.OpIngestStream, errors.KClientArgs, err)
@@ -137,9 +187,13 @@ func streamImpl(c streamIngestor, ctx context.Context, payload io.Reader, props
 		return nil, err
 	}
 
+	ignoreUnstreamedProps(&props)
 	result := newResult()
 	result.putProps(props)
 	result.record.Status = "Success"
+	if len(raw) > 0 {
+		result.streamingResponse = newStreamingResponse(raw)
+	}
 
 	return result, nil
 }
@@ -159,3 +213,12 @@ func (i *Streaming) newProp() properties.All {
 func (i *Streaming) Close() error {
 	return i.streamConn.Close()
 }
+
+// newStreamConn returns the streaming ingestion connection of client, which is sent to the endpoint resolved by
+// kusto.WithEndpointResolver() if client has one.
+func newStreamConn(client QueryClient) (*conn.Conn, error) {
+	if r, ok := client.(interface{ ResolvedEndpoint() string }); ok {
+		return conn.NewResolved(client.Endpoint(), r.ResolvedEndpoint(), client.Auth(), client.HttpClient())
+	}
+	return conn.New(client.Endpoint(), client.Auth(), client.HttpClient())
+}
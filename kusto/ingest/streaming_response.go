@@ -0,0 +1,72 @@
+package ingest
+
+// streaming_response.go holds StreamingResponse, the response of the service to a streaming ingestion.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+)
+
+// StreamingResponse is the response of the service to a successful streaming ingestion, as returned by
+// Result.StreamingResponse().
+//
+// The service only returns success once the data was committed to the table, so the data is queryable when
+// FromFile(), FromReader() or Ingest() return without an error; there is no accepted but not yet committed state for
+// streaming ingestion. The response does not say more than that, but it may have details such as the status of the
+// update policies of the table, which are kept here as the service returned them.
+type StreamingResponse struct {
+	// Raw is the body of the response, which is empty if the service did not return one.
+	Raw []byte
+	// Properties are the values of the first row of the first table of the response by column name, formatted as
+	// strings, like "ConsumedRecordsCount" and "UpdatePolicyStatus". It is nil if Raw is not such a table.
+	Properties map[string]string
+}
+
+// streamResponder is implemented by streamIngestors that return the response of the service.
+type streamResponder interface {
+	StreamIngestResponse(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) ([]byte, error)
+}
+
+// newStreamingResponse returns the StreamingResponse of raw, a response body in the REST v1 format.
+func newStreamingResponse(raw []byte) StreamingResponse {
+	resp := StreamingResponse{Raw: raw}
+
+	var v1 struct {
+		Tables []struct {
+			Columns []struct {
+				ColumnName string
+			}
+			Rows [][]interface{}
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v1); err != nil || len(v1.Tables) == 0 || len(v1.Tables[0].Rows) == 0 {
+		return resp
+	}
+
+	table := v1.Tables[0]
+	resp.Properties = make(map[string]string, len(table.Columns))
+	for i, col := range table.Columns {
+		if i >= len(table.Rows[0]) {
+			break
+		}
+		if v := table.Rows[0][i]; v != nil {
+			resp.Properties[col.ColumnName] = fmt.Sprint(v)
+		} else {
+			resp.Properties[col.ColumnName] = ""
+		}
+	}
+	return resp
+}
+
+// StreamingResponse returns the response of the service to the streaming ingestion of the Result, including when the
+// managed client used streaming ingestion. It is the zero value for queued ingestions.
+func (r *Result) StreamingResponse() StreamingResponse {
+	return r.streamingResponse
+}
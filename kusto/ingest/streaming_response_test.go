@@ -0,0 +1,85 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/ingest/internal/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStreamResponder is a streamIngestor that returns response as the response of the service.
+type fakeStreamResponder struct {
+	fakeStreamIngestor
+	response string
+}
+
+func (f fakeStreamResponder) StreamIngestResponse(ctx context.Context, db, table string, payload io.Reader, format properties.DataFormat, mappingName string, clientRequestId string) ([]byte, error) {
+	if _, err := ioutil.ReadAll(payload); err != nil {
+		return nil, err
+	}
+	return []byte(f.response), nil
+}
+
+func TestStreamingResponse(t *testing.T) {
+	t.Parallel()
+
+	const v1 = `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"ConsumedRecordsCount","DataType":"Int64"},
+{"ColumnName":"UpdatePolicyStatus","DataType":"String"},
+{"ColumnName":"UpdatePolicyFailureReason","DataType":"String"}],
+"Rows":[[2,"Succeeded",null]]}]}`
+
+	tests := []struct {
+		desc     string
+		streamer streamIngestor
+		want     StreamingResponse
+	}{
+		{
+			desc:     "Table response",
+			streamer: fakeStreamResponder{response: v1},
+			want: StreamingResponse{
+				Raw:        []byte(v1),
+				Properties: map[string]string{"ConsumedRecordsCount": "2", "UpdatePolicyStatus": "Succeeded", "UpdatePolicyFailureReason": ""},
+			},
+		},
+		{
+			desc:     "Other response",
+			streamer: fakeStreamResponder{response: "ok"},
+			want:     StreamingResponse{Raw: []byte("ok")},
+		},
+		{
+			desc:     "Empty response",
+			streamer: fakeStreamResponder{},
+		},
+		{
+			desc: "No response",
+			streamer: fakeStreamIngestor{onStreamIngest: func(context.Context, string, string, io.Reader, properties.DataFormat, string, string) error {
+				return nil
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			streaming := Streaming{
+				db:         "db",
+				table:      "table",
+				client:     mockClient{endpoint: "https://test.kusto.windows.net", auth: kusto.Authorization{}},
+				streamConn: test.streamer,
+			}
+
+			result, err := streaming.FromReader(context.Background(), strings.NewReader("a,b\n"))
+			require.NoError(t, err)
+			assert.Equal(t, test.want, result.StreamingResponse())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ingest
+
+// table_check.go holds the table existence check used by the WithTableExistenceCheck() option.
+
+import (
+	"context"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// WithTableExistenceCheck makes the client verify that the target table exists before the first ingestion into it,
+// using a ".show table schema" command. Ingesting into a table that does not exist otherwise only fails once the
+// service processes the data, which for queued ingestion can be a long time after the call returned.
+// A successful check is cached for the lifetime of the client, so a table is only checked once.
+// Failed checks are not cached.
+func WithTableExistenceCheck() Option {
+	return func(s *Ingestion) {
+		s.checkTable = true
+		s.tablesFound = map[string]bool{}
+	}
+}
+
+// checkTableExists verifies that table exists in db if WithTableExistenceCheck() was passed.
+func (i *Ingestion) checkTableExists(ctx context.Context, db, tableName string) error {
+	if !i.checkTable {
+		return nil
+	}
+
+	key := db + "." + tableName
+
+	i.tablesMu.Lock()
+	found := i.tablesFound[key]
+	i.tablesMu.Unlock()
+	if found {
+		return nil
+	}
+
+	stmt := kusto.NewStmt(".show table ", kusto.UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" schema")
+
+	rows := 0
+	iter, err := i.client.Mgmt(ctx, db, stmt)
+	if err == nil {
+		defer iter.Stop()
+		err = iter.DoOnRowOrError(func(_ *table.Row, e *errors.Error) error {
+			if e != nil {
+				return e
+			}
+			rows++
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ES(errors.OpFileIngest, errors.KClientArgs, "table %q does not exist in database %q", tableName, db).SetNoRetry()
+	}
+
+	i.tablesMu.Lock()
+	i.tablesFound[key] = true
+	i.tablesMu.Unlock()
+	return nil
+}
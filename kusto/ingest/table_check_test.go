@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTableExists(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		rows      []value.Values
+		mgmtErr   error
+		err       bool
+		wantCalls int
+	}{
+		{
+			desc:      "Table exists",
+			rows:      []value.Values{{value.String{Value: "table", Valid: true}}},
+			wantCalls: 1,
+		},
+		{
+			desc:      "Table does not exist",
+			err:       true,
+			wantCalls: 2,
+		},
+		{
+			desc:      "Mgmt error",
+			mgmtErr:   errors.ES(errors.OpMgmt, errors.KHTTPError, "service unavailable"),
+			err:       true,
+			wantCalls: 2,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			client := mockClient{
+				onMgmt: func(_ context.Context, db string, query kusto.Stmt, _ ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+					calls++
+					assert.Equal(t, "db", db)
+					assert.Equal(t, `.show table ['ta\'ble'] schema`, query.String())
+					if test.mgmtErr != nil {
+						return nil, test.mgmtErr
+					}
+
+					mock, err := kusto.NewMockRows(table.Columns{{Name: "TableName", Type: types.String}})
+					require.NoError(t, err)
+					for _, row := range test.rows {
+						require.NoError(t, mock.Row(row))
+					}
+					iter := &kusto.RowIterator{}
+					require.NoError(t, iter.Mock(mock))
+					return iter, nil
+				},
+			}
+
+			i := &Ingestion{client: client}
+			WithTableExistenceCheck()(i)
+
+			// The second call should use the cache if the first call succeeded.
+			for n := 0; n < 2; n++ {
+				err := i.checkTableExists(context.Background(), "db", "ta'ble")
+				if test.mgmtErr != nil {
+					// The service error is returned as is, keeping its kind and whether it can be retried.
+					assert.Equal(t, test.mgmtErr, err)
+				} else if test.err {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+			assert.Equal(t, test.wantCalls, calls)
+		})
+	}
+}
+
+func TestCheckTableExistsDisabled(t *testing.T) {
+	t.Parallel()
+
+	client := mockClient{
+		onMgmt: func(context.Context, string, kusto.Stmt, ...kusto.MgmtOption) (*kusto.RowIterator, error) {
+			panic("Mgmt() should not be called")
+		},
+	}
+
+	i := &Ingestion{client: client}
+	assert.NoError(t, i.checkTableExists(context.Background(), "db", "table"))
+}
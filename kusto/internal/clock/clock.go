@@ -0,0 +1,96 @@
+// Package clock provides an abstraction over the system time so that time dependent behavior, such as resource
+// refreshes and retry backoffs, can be tested deterministically.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timer channels.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real implements Clock using the system time.
+type Real struct{}
+
+// Now implements Clock.Now().
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// After implements Clock.After().
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type waiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// Fake implements Clock with a time that only moves when Advance() is called. This is for use in tests.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+// NewFake is the constructor for Fake. The clock will start at "now".
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now implements Clock.Now().
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After implements Clock.After(). The channel fires once Advance() has moved the clock past d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, waiter{until: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d, firing any channels returned by After() that have expired.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+
+	sort.Slice(f.waiters, func(i, j int) bool { return f.waiters[i].until.Before(f.waiters[j].until) })
+
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.until.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	f.waiters = remaining
+}
+
+// Waiters returns the number of channels returned by After() that have not fired yet. Tests can use this to know
+// when code under test is blocked waiting on the clock.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	assert.Equal(t, start, f.Now())
+
+	short := f.After(time.Second)
+	long := f.After(time.Minute)
+	assert.Equal(t, 2, f.Waiters())
+
+	f.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(30*time.Second), f.Now())
+	assert.Equal(t, 1, f.Waiters())
+
+	select {
+	case got := <-short:
+		assert.Equal(t, start.Add(30*time.Second), got)
+	default:
+		t.Fatalf("TestFake: After(1s) did not fire after advancing 30s")
+	}
+
+	select {
+	case <-long:
+		t.Fatalf("TestFake: After(1m) fired after advancing 30s")
+	default:
+	}
+
+	f.Advance(30 * time.Second)
+	assert.Equal(t, 0, f.Waiters())
+	<-long
+
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatalf("TestFake: After(0) did not fire immediately")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
 )
 
 const (
@@ -57,6 +59,7 @@ const (
 	QueryTraceLog              TableKind = "QueryTraceLog"
 	QueryPerfLog               TableKind = "QueryPerfLog"
 	QueryResult                TableKind = "QueryResult"
+	QueryStatus                TableKind = "QueryStatus"
 	TableOfContents            TableKind = "TableOfContents"
 	QueryPlan                  TableKind = "QueryPlan"
 	ExtendedProperties         TableKind = "@ExtendedProperties"
@@ -96,3 +99,56 @@ func Errorf(ctx context.Context, ch chan Frame, s string, a ...interface{}) {
 	case ch <- Error{Msg: fmt.Sprintf(s, a...)}:
 	}
 }
+
+// DuplicateColumns is how a Decoder handles the columns of a table that have the same name.
+type DuplicateColumns int
+
+const (
+	// DuplicateColumnsKeep keeps the names of the columns as they are.
+	DuplicateColumnsKeep DuplicateColumns = iota
+	// DuplicateColumnsError fails the decoding of a table that has columns with the same name.
+	DuplicateColumnsError
+	// DuplicateColumnsRename adds the lowest number that makes the name unique to the name of every column after the
+	// first one with the same name, like "Name", "Name1" and "Name2".
+	DuplicateColumnsRename
+)
+
+// Apply returns columns handled as set by d. columns is not changed.
+func (d DuplicateColumns) Apply(columns table.Columns) (table.Columns, error) {
+	if d == DuplicateColumnsKeep {
+		return columns, nil
+	}
+
+	names := make(map[string]bool, len(columns))
+	dup := false
+	for _, col := range columns {
+		if names[col.Name] {
+			if d == DuplicateColumnsError {
+				return nil, fmt.Errorf("the result has more than one column named %q", col.Name)
+			}
+			dup = true
+		}
+		names[col.Name] = true
+	}
+	if !dup {
+		return columns, nil
+	}
+
+	renamed := make(table.Columns, len(columns))
+	seen := make(map[string]bool, len(columns))
+	for i, col := range columns {
+		if seen[col.Name] {
+			for n := 1; ; n++ {
+				name := col.Name + strconv.Itoa(n)
+				if !names[name] {
+					col.Name = name
+					names[name] = true
+					break
+				}
+			}
+		}
+		seen[col.Name] = true
+		renamed[i] = col
+	}
+	return renamed, nil
+}
@@ -30,6 +30,16 @@ func PutRows(rows []interface{}) {
 
 // Rows unmarshals a slice of a slice that represents a set of rows and translates them into a set of []value.Values.
 func Rows(columns table.Columns, interRows []interface{}, op errors.Op) ([]value.Values, []errors.Error, error) {
+	return decodeRows(columns, interRows, op, false)
+}
+
+// RowsBadAsErrors is like Rows(), but a row with a value that cannot be decoded into the type of its column is
+// returned as a row error instead of failing the decoding of all the rows.
+func RowsBadAsErrors(columns table.Columns, interRows []interface{}, op errors.Op) ([]value.Values, []errors.Error, error) {
+	return decodeRows(columns, interRows, op, true)
+}
+
+func decodeRows(columns table.Columns, interRows []interface{}, op errors.Op, badAsErrors bool) ([]value.Values, []errors.Error, error) {
 	rows := make([]value.Values, 0, len(interRows))
 	var errorRows []errors.Error
 
@@ -46,74 +56,90 @@ func Rows(columns table.Columns, interRows []interface{}, op errors.Op) ([]value
 			continue
 		}
 
-		row := make(value.Values, len(columns))
-		for i, col := range columns {
-			switch col.Type {
-			case types.Bool:
-				v := value.Bool{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Bool value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.DateTime:
-				v := value.DateTime{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a DateTime value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.Decimal:
-				v := value.Decimal{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Decimal value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.Dynamic:
-				v := value.Dynamic{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Dynamic value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.GUID:
-				v := value.GUID{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a GUID value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.Int:
-				v := value.Int{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Int value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.Long:
-				v := value.Long{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Long value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.Real:
-				v := value.Real{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Real value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.String:
-				v := value.String{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a String value: %s", col.Name, err)
-				}
-				row[i] = v
-			case types.Timespan:
-				v := value.Timespan{}
-				if err := v.Unmarshal(interRow[i]); err != nil {
-					return nil, nil, fmt.Errorf("unable to unmarshal column %s into a Timespan value: %s", col.Name, err)
-				}
-				row[i] = v
-			default:
-				return nil, nil, fmt.Errorf("DataTable had column of type %s, which was unknown", col.Type)
+		row, err := decodeRow(columns, interRow)
+		if err != nil {
+			if !badAsErrors {
+				return nil, nil, err
 			}
+			errorRows = append(errorRows, *errors.ES(op, errors.KInternal, "row could not be decoded: %s", err))
+			continue
 		}
 		rows = append(rows, row)
 	}
 	return rows, errorRows, nil
 }
+
+// decodeRow decodes interRow into the types of columns.
+func decodeRow(columns table.Columns, interRow []interface{}) (value.Values, error) {
+	if len(interRow) < len(columns) {
+		return nil, fmt.Errorf("row has %d values, want %d", len(interRow), len(columns))
+	}
+	row := make(value.Values, len(columns))
+	for i, col := range columns {
+		switch col.Type {
+		case types.Bool:
+			v := value.Bool{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Bool value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.DateTime:
+			v := value.DateTime{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a DateTime value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.Decimal:
+			v := value.Decimal{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Decimal value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.Dynamic:
+			v := value.Dynamic{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Dynamic value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.GUID:
+			v := value.GUID{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a GUID value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.Int:
+			v := value.Int{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Int value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.Long:
+			v := value.Long{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Long value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.Real:
+			v := value.Real{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Real value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.String:
+			v := value.String{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a String value: %s", col.Name, err)
+			}
+			row[i] = v
+		case types.Timespan:
+			v := value.Timespan{}
+			if err := v.Unmarshal(interRow[i]); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal column %s into a Timespan value: %s", col.Name, err)
+			}
+			row[i] = v
+		default:
+			return nil, fmt.Errorf("DataTable had column of type %s, which was unknown", col.Type)
+		}
+	}
+	return row, nil
+}
@@ -61,3 +61,26 @@ func TestUnmarshalRows(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalRowsBadAsErrors(t *testing.T) {
+	t.Parallel()
+
+	columns := table.Columns{table.Column{Name: "count", Type: types.Long}}
+	interRows := []interface{}{[]interface{}{1}, []interface{}{"not a long"}, []interface{}{}, []interface{}{3}}
+
+	if _, _, err := Rows(columns, interRows, errors.OpUnknown); err == nil {
+		t.Errorf("TestUnmarshalRowsBadAsErrors: Rows() got err == nil, want err != nil")
+	}
+
+	rows, rowErrors, err := RowsBadAsErrors(columns, interRows, errors.OpUnknown)
+	if err != nil {
+		t.Fatalf("TestUnmarshalRowsBadAsErrors: got err == %s, want err == nil", err)
+	}
+	want := []value.Values{{value.Long{1, true}}, {value.Long{3, true}}}
+	if diff := pretty.Compare(want, rows); diff != "" {
+		t.Errorf("TestUnmarshalRowsBadAsErrors: -want/+got:\n%s", diff)
+	}
+	if len(rowErrors) != 2 {
+		t.Errorf("TestUnmarshalRowsBadAsErrors: got %d row errors, want 2", len(rowErrors))
+	}
+}
@@ -24,12 +24,16 @@ type DataSet struct {
 type Decoder struct {
 	dec *json.Decoder
 	op  errors.Op
+
+	// BadRowsAsErrors makes rows with values that cannot be decoded row errors instead of failing the decoding.
+	BadRowsAsErrors bool
 }
 
 // Decode implements frames.Decoder.Decode(). This is not thread safe.
 func (d *Decoder) Decode(ctx context.Context, r io.ReadCloser, op errors.Op) chan frames.Frame {
 	ch := make(chan frames.Frame, 1) // Channel is sized to 1. We read from the channel faster than we put on the channel.
 	d.dec = json.NewDecoder(r)
+	d.dec.UseNumber() // Keeps longs above 2^53 and dynamic numbers from being rounded through float64.
 	d.op = op
 
 	go func() {
@@ -121,7 +125,11 @@ func (d *Decoder) processTables(ctx context.Context, ch chan frames.Frame) error
 			return err
 		}
 
-		dt.KustoRows, dt.RowErrors, err = unmarshal.Rows(columns, dt.Rows, d.op)
+		if d.BadRowsAsErrors {
+			dt.KustoRows, dt.RowErrors, err = unmarshal.RowsBadAsErrors(columns, dt.Rows, d.op)
+		} else {
+			dt.KustoRows, dt.RowErrors, err = unmarshal.Rows(columns, dt.Rows, d.op)
+		}
 		if err != nil {
 			return err
 		}
@@ -334,6 +334,58 @@ func TestErrorDecode(t *testing.T) {
 	}
 }
 
+func TestNumberPrecisionDecode(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// 9007199254740993 is 2^53 + 1, which a float64 cannot hold.
+	jsonStr := `{
+		"Tables": [
+			{
+				"TableName":"Table_0",
+				"Columns":[
+					{"ColumnName":"Long", "ColumnType":"long"},
+					{"ColumnName":"Real", "ColumnType":"real"},
+					{"ColumnName":"Dynamic", "ColumnType":"dynamic"}
+				],
+				"Rows":[
+					[9007199254740993, 0.1, {"id":9007199254740993}],
+					[-9223372036854775808, 1.7976931348623157e308, [9223372036854775807]]
+				]
+			}
+		]
+	}`
+
+	want := DataTable{
+		TableName: "Table_0",
+		DataTypes: DataTypes{
+			{ColumnName: "Long", ColumnType: "long"},
+			{ColumnName: "Real", ColumnType: "real"},
+			{ColumnName: "Dynamic", ColumnType: "dynamic"},
+		},
+		KustoRows: []value.Values{
+			{
+				value.Long{Value: 9007199254740993, Valid: true},
+				value.Real{Value: 0.1, Valid: true},
+				value.Dynamic{Value: []byte(`{"id":9007199254740993}`), Valid: true},
+			},
+			{
+				value.Long{Value: -9223372036854775808, Valid: true},
+				value.Real{Value: 1.7976931348623157e308, Valid: true},
+				value.Dynamic{Value: []byte(`[9223372036854775807]`), Valid: true},
+			},
+		},
+		Op: errors.OpMgmt,
+	}
+
+	dec := Decoder{}
+	ch := dec.Decode(ctx, ioutil.NopCloser(strings.NewReader(jsonStr)), errors.OpMgmt)
+
+	got := <-ch
+	require.EqualValues(t, want, got)
+}
+
 func timeMustParse(layout string, p string) time.Time {
 	t, err := time.Parse(layout, p)
 	if err != nil {
@@ -18,6 +18,11 @@ type Decoder struct {
 	dec     *json.Decoder
 	op      errors.Op
 
+	// BadRowsAsErrors makes rows with values that cannot be decoded row errors instead of failing the decoding.
+	BadRowsAsErrors bool
+	// DuplicateColumns is how the columns of a table with the same name are handled.
+	DuplicateColumns frames.DuplicateColumns
+
 	frameRaw json.RawMessage
 }
 
@@ -113,11 +118,16 @@ func (d *Decoder) decode(ctx context.Context, ch chan frames.Frame) error {
 
 	switch {
 	case bytes.Equal(ft, ftDataTable):
-		dt := DataTable{}
+		dt := DataTable{BadRowsAsErrors: d.BadRowsAsErrors}
 		if err := dt.UnmarshalRaw(d.frameRaw); err != nil {
 			return err
 		}
 		dt.Op = d.op
+		columns, err := d.DuplicateColumns.Apply(dt.Columns)
+		if err != nil {
+			return err
+		}
+		dt.Columns = columns
 		ch <- dt
 	case bytes.Equal(ft, ftDataSetCompletion):
 		dc := DataSetCompletion{}
@@ -132,10 +142,15 @@ func (d *Decoder) decode(ctx context.Context, ch chan frames.Frame) error {
 			return err
 		}
 		th.Op = d.op
+		columns, err := d.DuplicateColumns.Apply(th.Columns)
+		if err != nil {
+			return err
+		}
+		th.Columns = columns
 		d.columns = th.Columns
 		ch <- th
 	case bytes.Equal(ft, ftTableFragment):
-		tf := TableFragment{Columns: d.columns}
+		tf := TableFragment{Columns: d.columns, BadRowsAsErrors: d.BadRowsAsErrors}
 		if err := tf.UnmarshalRaw(d.frameRaw); err != nil {
 			return err
 		}
@@ -163,7 +178,7 @@ func (d *Decoder) decode(ctx context.Context, ch chan frames.Frame) error {
 }
 
 var (
-	frameType = []byte(fmt.Sprintf("%q:", frames.FieldFrameType))
+	frameType = []byte(fmt.Sprintf("%q", frames.FieldFrameType))
 	comma     = []byte(`,`)
 	semicolon = []byte(`:`)
 )
@@ -188,19 +203,28 @@ func getFrameType(message json.RawMessage) ([]byte, error) {
 	message = bytes.TrimLeft(message, "{")
 	message = bytes.TrimSpace(message)
 
-	for {
+	// Captured responses may have been re-encoded, so FrameType may not be the first field and there may be
+	// whitespace around the ':'.
+	for len(message) > 0 {
+		var search []byte
 		index := bytes.Index(message, comma)
 		if index == -1 {
-			return nil, fmt.Errorf("FrameType was not present in a frame")
+			search, message = bytes.TrimRight(message, "}"), nil
+		} else {
+			search, message = message[:index], message[index+1:]
 		}
-		search := bytes.TrimSpace(message[:index])
+		search = bytes.TrimSpace(search)
 		if bytes.HasPrefix(search, frameType) {
-			typeIndex := bytes.Index(search, semicolon)
-			if typeIndex == -1 {
+			search = bytes.TrimSpace(search[len(frameType):])
+			if !bytes.HasPrefix(search, semicolon) {
+				return nil, fmt.Errorf("problem finding expected value FrameType in frame")
+			}
+			search = bytes.TrimSpace(search[1:])
+			if len(search) < 2 || search[0] != '"' || search[len(search)-1] != '"' {
 				return nil, fmt.Errorf("problem finding expected value FrameType in frame")
 			}
-			search = search[typeIndex:]
-			return search[2 : len(search)-1], nil // Removes :"" around :"<frameType>"
+			return search[1 : len(search)-1], nil // Removes "" around "<frameType>"
 		}
 	}
+	return nil, fmt.Errorf("FrameType was not present in a frame")
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/data/table"
 	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/frames/unmarshal/json"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/google/uuid"
@@ -464,3 +466,36 @@ func timeMustParse(layout string, p string) time.Time {
 	}
 	return t
 }
+
+func TestGetFrameType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc  string
+		frame string
+		want  string
+		err   bool
+	}{
+		{desc: "Compact", frame: `{"FrameType":"DataTable","TableId":0}`, want: "DataTable"},
+		{desc: "Whitespace", frame: `{ "FrameType" : "DataTable", "TableId": 0 }`, want: "DataTable"},
+		{desc: "Not the first field", frame: `{"Cancelled":false,"FrameType":"DataSetCompletion","HasErrors":false}`, want: "DataSetCompletion"},
+		{desc: "Last field", frame: `{"HasErrors":false,"FrameType":"DataSetCompletion"}`, want: "DataSetCompletion"},
+		{desc: "Missing", frame: `{"TableId":0}`, err: true},
+		{desc: "Not a string", frame: `{"FrameType":1}`, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := getFrameType(json.RawMessage(test.frame))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(got))
+		})
+	}
+}
@@ -47,6 +47,9 @@ type DataTable struct {
 	KustoRows []value.Values
 	RowErrors []errors.Error
 
+	// BadRowsAsErrors makes rows with values that cannot be decoded RowErrors instead of failing the frame.
+	BadRowsAsErrors bool `json:"-"`
+
 	Op errors.Op `json:"-"`
 }
 
@@ -65,7 +68,7 @@ func (d *DataTable) UnmarshalRaw(raw json.RawMessage) error {
 		return err
 	}
 
-	v, rowErrors, err := unmarshal.Rows(d.Columns, d.Rows, d.Op)
+	v, rowErrors, err := decodeRows(d.Columns, d.Rows, d.Op, d.BadRowsAsErrors)
 	if err != nil {
 		return err
 	}
@@ -84,8 +87,8 @@ type DataSetCompletion struct {
 	HasErrors bool
 	// Cancelled indicates that the request was cancelled.
 	Cancelled bool
-	// OneAPIErrors is a list of errors encountered.
-	OneAPIErrors []string `json:"OneApiErrors"`
+	// OneAPIErrors is a list of errors encountered. Each entry is a decoded OneApiError JSON object.
+	OneAPIErrors []interface{} `json:"OneApiErrors"`
 
 	Op errors.Op `json:"-"`
 }
@@ -138,6 +141,8 @@ type TableFragment struct {
 	RowErrors []errors.Error
 
 	Columns table.Columns `json:"-"` // Needed for decoding values.
+	// BadRowsAsErrors makes rows with values that cannot be decoded RowErrors instead of failing the frame.
+	BadRowsAsErrors bool `json:"-"`
 
 	Op errors.Op `json:"-"`
 }
@@ -160,7 +165,7 @@ func (t *TableFragment) UnmarshalRaw(raw json.RawMessage) error {
 		return err
 	}
 
-	v, rowErrors, err := unmarshal.Rows(t.Columns, t.Rows, t.Op)
+	v, rowErrors, err := decodeRows(t.Columns, t.Rows, t.Op, t.BadRowsAsErrors)
 	if err != nil {
 		return err
 	}
@@ -225,3 +230,11 @@ func RawToOneAPIErr(raw json.RawMessage, op errors.Op) error {
 	}
 	return nil
 }
+
+// decodeRows decodes rows with unmarshal.Rows(), or unmarshal.RowsBadAsErrors() if badAsErrors is set.
+func decodeRows(columns table.Columns, rows []interface{}, op errors.Op, badAsErrors bool) ([]value.Values, []errors.Error, error) {
+	if badAsErrors {
+		return unmarshal.RowsBadAsErrors(columns, rows, op)
+	}
+	return unmarshal.Rows(columns, rows, op)
+}
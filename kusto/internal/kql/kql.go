@@ -0,0 +1,26 @@
+// Package kql holds the helpers the kusto and ingest packages use to build the text of queries and management
+// commands, and to read the values they return.
+package kql
+
+import "strings"
+
+// IngestByPrefix is the prefix of the extent tags set by ingestions with an ingest-by tag.
+const IngestByPrefix = "ingest-by:"
+
+var escaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// QuoteName quotes an entity name, such as a table or column name, so it can be safely used in a query or a
+// management command.
+func QuoteName(name string) string {
+	return "['" + escaper.Replace(name) + "']"
+}
+
+// QuoteString quotes s so it can be safely used as a string literal in a query or a management command.
+func QuoteString(s string) string {
+	return "'" + escaper.Replace(s) + "'"
+}
+
+// SplitTags splits the Tags column of an extent, which holds one tag per line.
+func SplitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool { return r == '\r' || r == '\n' })
+}
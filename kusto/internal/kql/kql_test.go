@@ -0,0 +1,39 @@
+package kql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		s          string
+		wantName   string
+		wantString string
+	}{
+		{desc: "Plain", s: "table", wantName: "['table']", wantString: "'table'"},
+		{desc: "Quote", s: "it's", wantName: `['it\'s']`, wantString: `'it\'s'`},
+		{desc: "Backslash", s: `a\'; drop`, wantName: `['a\\\'; drop']`, wantString: `'a\\\'; drop'`},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.wantName, QuoteName(test.s))
+			assert.Equal(t, test.wantString, QuoteString(test.s))
+		})
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"ingest-by:a", "drop-by:b"}, SplitTags("ingest-by:a\r\ndrop-by:b\n"))
+	assert.Empty(t, SplitTags(""))
+}
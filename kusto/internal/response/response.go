@@ -11,21 +11,42 @@ import (
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 )
 
+// TranslateBody returns the body of resp, decompressed according to its Content-Encoding header. Closing the returned
+// body closes the body of resp.
 func TranslateBody(resp *http.Response, op errors.Op) (io.ReadCloser, error) {
 	body := resp.Body
 	switch enc := strings.ToLower(resp.Header.Get("Content-Encoding")); enc {
 	case "":
 		return body, nil
 	case "gzip":
-		var err error
-		body, err = gzip.NewReader(resp.Body)
+		zr, err := gzip.NewReader(resp.Body)
 		if err != nil {
+			resp.Body.Close()
 			return nil, errors.E(op, errors.KInternal, fmt.Errorf("gzip reader error: %w", err))
 		}
+		body = decompressedBody{Reader: zr, decompressor: zr, body: resp.Body}
 	case "deflate":
-		body = flate.NewReader(resp.Body)
+		fr := flate.NewReader(resp.Body)
+		body = decompressedBody{Reader: fr, decompressor: fr, body: resp.Body}
 	default:
 		return nil, errors.ES(op, errors.KInternal, "Content-Encoding was unrecognized: %s", enc)
 	}
 	return body, nil
 }
+
+// decompressedBody reads a decompressed response body. Close closes both the decompressor and the response body, as
+// gzip and flate readers do not close the reader they read from.
+type decompressedBody struct {
+	io.Reader
+	decompressor io.Closer
+	body         io.Closer
+}
+
+// Close implements io.Closer.
+func (d decompressedBody) Close() error {
+	err := d.decompressor.Close()
+	if bErr := d.body.Close(); err == nil {
+		err = bErr
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+// Package kql provides helpers for working with the text of KQL queries.
+package kql
+
+import (
+	"strings"
+)
+
+// continuationIndent is the indentation of the line that continues an operator after a "//" comment.
+const continuationIndent = "  "
+
+// Format returns query with light normalization for logging and debugging, so that queries that were built
+// differently read the same way and can be diffed across runs:
+//
+//	StormEvents
+//	| where State == "TEXAS"
+//	| take 10
+//
+// Each top level "|" starts a new line, followed by a single space and the operator. Runs of whitespace outside of
+// string literals and comments are replaced by a single space and leading and trailing whitespace is removed.
+// Statements ending in ";", like let statements, are put on their own lines. Pipes inside parentheses, brackets
+// or braces, such as in the subquery of a join, are left in place. "//" comments are kept and the text that
+// followed them is continued on the next line.
+// Format does not parse the query: string literals, including verbatim (@"...") and multi-line (```...```) ones,
+// are copied unchanged, but the query is otherwise not validated and invalid queries are normalized the same way.
+// Formatting the result again returns it unchanged.
+func Format(query string) string {
+	f := &formatter{query: query}
+	f.run()
+	return strings.Join(f.stmts, "\n")
+}
+
+// formatter holds the state of Format().
+type formatter struct {
+	query string
+	pos   int
+	depth int
+
+	stmts []string
+	segs  []string
+	cur   strings.Builder
+
+	// pendingSpace is set by whitespace, so a single space is written before the next content.
+	pendingSpace bool
+	// needNewline is set by a comment, so the next content is written on a new line.
+	needNewline bool
+	// onlyComments is set while the current segment holds nothing but comments.
+	onlyComments bool
+}
+
+func (f *formatter) run() {
+	f.onlyComments = true
+	for f.pos < len(f.query) {
+		c := f.query[f.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			f.pendingSpace = true
+			f.pos++
+		case c == '/' && strings.HasPrefix(f.query[f.pos:], "//"):
+			end := strings.IndexByte(f.query[f.pos:], '\n')
+			if end < 0 {
+				end = len(f.query) - f.pos
+			}
+			f.write(strings.TrimRight(f.query[f.pos:f.pos+end], " \t\r"), true)
+			f.needNewline = true
+			f.pos += end
+		case strings.HasPrefix(f.query[f.pos:], "```") || strings.HasPrefix(f.query[f.pos:], "~~~"):
+			f.write(f.multiLineString(), false)
+		case c == '\'' || c == '"':
+			verbatim := f.pos > 0 && f.query[f.pos-1] == '@'
+			f.write(f.stringLiteral(verbatim), false)
+		case c == '(' || c == '[' || c == '{':
+			f.depth++
+			f.write(string(c), false)
+			f.pos++
+		case c == ')' || c == ']' || c == '}':
+			if f.depth > 0 {
+				f.depth--
+			}
+			f.write(string(c), false)
+			f.pos++
+		case c == '|' && f.depth == 0:
+			f.endSegment()
+			f.write("|", false)
+			f.pendingSpace = true
+			f.pos++
+		case c == ';' && f.depth == 0:
+			f.write(";", false)
+			f.endStatement()
+			f.pos++
+		default:
+			f.write(string(c), false)
+			f.pos++
+		}
+	}
+	f.endStatement()
+}
+
+// write adds content to the current segment, after the separator the preceding whitespace or comment calls for.
+func (f *formatter) write(s string, comment bool) {
+	switch {
+	case f.needNewline && f.onlyComments:
+		f.cur.WriteString("\n")
+	case f.needNewline:
+		f.cur.WriteString("\n" + continuationIndent)
+	case f.pendingSpace && f.cur.Len() > 0:
+		f.cur.WriteString(" ")
+	}
+	f.cur.WriteString(s)
+	f.pendingSpace = false
+	f.needNewline = false
+	f.onlyComments = f.onlyComments && comment
+}
+
+// endSegment ends the current segment. The next segment is written on a new line.
+func (f *formatter) endSegment() {
+	if f.cur.Len() > 0 {
+		f.segs = append(f.segs, f.cur.String())
+	}
+	f.cur.Reset()
+	f.pendingSpace = false
+	f.needNewline = false
+	f.onlyComments = true
+}
+
+// endStatement ends the current statement. The next statement is written on a new line.
+func (f *formatter) endStatement() {
+	f.endSegment()
+	if len(f.segs) > 0 {
+		f.stmts = append(f.stmts, strings.Join(f.segs, "\n"))
+	}
+	f.segs = nil
+}
+
+// stringLiteral returns the string literal starting at the current position and moves past it. Verbatim literals
+// escape their quote by doubling it, others by a backslash. An unterminated literal runs to the end of the query.
+func (f *formatter) stringLiteral(verbatim bool) string {
+	start := f.pos
+	quote := f.query[f.pos]
+	f.pos++
+	for f.pos < len(f.query) {
+		c := f.query[f.pos]
+		switch {
+		case !verbatim && c == '\\':
+			f.pos += 2
+			continue
+		case c == quote && verbatim && f.pos+1 < len(f.query) && f.query[f.pos+1] == quote:
+			f.pos += 2
+			continue
+		case c == quote:
+			f.pos++
+			return f.query[start:f.pos]
+		}
+		f.pos++
+	}
+	f.pos = len(f.query)
+	return f.query[start:]
+}
+
+// multiLineString returns the multi-line string literal starting at the current position and moves past it.
+// An unterminated literal runs to the end of the query.
+func (f *formatter) multiLineString() string {
+	start := f.pos
+	delim := f.query[f.pos : f.pos+3]
+	end := strings.Index(f.query[f.pos+3:], delim)
+	if end < 0 {
+		f.pos = len(f.query)
+		return f.query[start:]
+	}
+	f.pos += 3 + end + 3
+	return f.query[start:f.pos]
+}
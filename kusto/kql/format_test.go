@@ -0,0 +1,44 @@
+package kql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc  string
+		query string
+		want  string
+	}{
+		{desc: "Empty", query: "  \n ", want: ""},
+		{desc: "Single line", query: "  StormEvents | where State == 'TEXAS'|take 10  ", want: "StormEvents\n| where State == 'TEXAS'\n| take 10"},
+		{
+			desc:  "Multiple lines",
+			query: "StormEvents\n\t|   where State == 'TEXAS'\n    and\tEventType == 'Flood'\n|  take 10\n",
+			want:  "StormEvents\n| where State == 'TEXAS' and EventType == 'Flood'\n| take 10",
+		},
+		{desc: "Pipes in strings", query: `T | where a == "x | y" or b == 'p|q' or c == @"c:\|"`, want: "T\n| where a == \"x | y\" or b == 'p|q' or c == @\"c:\\|\""},
+		{desc: "Escaped quotes", query: `T | where a == "x\" | y" | where b == @'it''s | z'`, want: "T\n| where a == \"x\\\" | y\"\n| where b == @'it''s | z'"},
+		{desc: "Whitespace in strings", query: "T | where a == 'x   y'", want: "T\n| where a == 'x   y'"},
+		{desc: "Multi-line string", query: "print ```a |\n  b``` | take 1", want: "print ```a |\n  b```\n| take 1"},
+		{desc: "Subquery", query: "T | join (U | where x > 1) on a", want: "T\n| join (U | where x > 1) on a"},
+		{desc: "Statements", query: "let x = 1;  let f = () { T | take 1; };\nf() | count", want: "let x = 1;\nlet f = () { T | take 1; };\nf()\n| count"},
+		{desc: "Comments", query: "// header\nT // source\n| where a > 1 // filter | not a pipe\nand b < 2", want: "// header\nT // source\n| where a > 1 // filter | not a pipe\n  and b < 2"},
+		{desc: "Unterminated string", query: "T | where a == 'x | y", want: "T\n| where a == 'x | y"},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := Format(test.query)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, got, Format(got))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
 	"github.com/Azure/azure-kusto-go/kusto/internal/frames"
 	v2 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v2"
 
@@ -31,7 +32,8 @@ type Authorization struct {
 	// Authorizer provides an authorizer to use when talking to Kusto. If this is set, the
 	// Authorizer must have its Resource (also called Resource ID) set to the endpoint passed
 	// to the New() constructor. This will be something like "https://somename.westus.kusto.windows.net".
-	// This package will try to set that automatically for you.
+	// This package will try to set that automatically for you. NewTokenAuthorizer() and NewTokenProviderAuthorizer()
+	// create an Authorizer from bearer tokens acquired outside of this package.
 	Authorizer autorest.Authorizer
 	// Config provides the authorizer's config that can create the authorizer. We recommending setting
 	// this instead of Authorizer, as we will automatically set the Resource ID with the endpoint passed.
@@ -113,6 +115,27 @@ type Client struct {
 	auth             Authorization
 	mu               sync.Mutex
 	http             *http.Client
+
+	// asyncConcurrency is set by WithAsyncConcurrency(). asyncLimit is the semaphore used by QueryAsync(), created
+	// on first use.
+	asyncConcurrency int
+	asyncOnce        sync.Once
+	asyncLimit       chan struct{}
+
+	// tokenRetry is set by WithTokenAcquisitionRetry().
+	tokenRetry tokenRetry
+	// noAutoRequestID is set by WithoutAutoClientRequestID().
+	noAutoRequestID bool
+	// noResponseCompression is set by WithResponseCompression().
+	noResponseCompression bool
+	// partialFailureAttempts and partialFailureDelay are set by WithRetryOnPartialFailure().
+	partialFailureAttempts int
+	partialFailureDelay    time.Duration
+	// endpointResolver is set by WithEndpointResolver(). resolvedEndpoint is the endpoint it returned for endpoint.
+	endpointResolver func(cluster string) (string, error)
+	resolvedEndpoint string
+	// clock is set by withClock().
+	clock clock.Clock
 }
 
 // Option is an optional argument type for New().
@@ -146,10 +169,19 @@ func New(endpoint string, auth Authorization, options ...Option) (*Client, error
 		client.http = &http.Client{}
 	}
 
-	conn, err := newConn(endpoint, auth, client.http)
+	client.resolvedEndpoint, err = client.resolveEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := newConn(client.resolvedEndpoint, auth, client.http)
 	if err != nil {
 		return nil, err
 	}
+	conn.tokenRetry = client.tokenRetry
+	conn.tokenRetry.clock = client.getClock()
+	conn.noAutoRequestID = client.noAutoRequestID
+	conn.noResponseCompression = client.noResponseCompression
 	client.conn = conn
 
 	return client, nil
@@ -161,6 +193,22 @@ func WithHttpClient(client *http.Client) Option {
 	}
 }
 
+// withClock sets the clock the client waits between retries with. This is for tests of time dependent behavior, if
+// not set the system time is used.
+func withClock(c clock.Clock) Option {
+	return func(client *Client) {
+		client.clock = c
+	}
+}
+
+// getClock returns the clock set with withClock() or the system clock.
+func (c *Client) getClock() clock.Clock {
+	if c.clock == nil {
+		return clock.Real{}
+	}
+	return c.clock
+}
+
 // QueryOption is an option type for a call to Query().
 type QueryOption func(q *queryOptions) error
 
@@ -194,6 +242,14 @@ const (
 // Note that the server has a timeout of 4 minutes for a query by default unless the context deadline is set. Queries can
 // take a maximum of 1 hour.
 func (c *Client) Query(ctx context.Context, db string, query Stmt, options ...QueryOption) (*RowIterator, error) {
+	if c.partialFailureAttempts > 1 {
+		return c.queryRetryPartialFailure(ctx, db, query, options)
+	}
+	return c.query(ctx, db, query, options)
+}
+
+// query sends a single Query() call.
+func (c *Client) query(ctx context.Context, db string, query Stmt, options []QueryOption) (*RowIterator, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -218,6 +274,21 @@ func (c *Client) Query(ctx context.Context, db string, query Stmt, options ...Qu
 		return nil, err
 	}
 
+	iter, err := newV2RowIterator(ctx, cancel, execResp)
+	if err != nil {
+		return nil, err
+	}
+	if n, ok := opts.requestProperties.Options[takeMaxRecordsOption].(int64); ok {
+		iter.maxRecords = n
+	}
+	iter.verifyRowCount = opts.verifyRowCount
+
+	return iter, nil
+}
+
+// newV2RowIterator creates a RowIterator that reads the v2 frames in execResp. cancel is called when the RowIterator
+// has Stop() called.
+func newV2RowIterator(ctx context.Context, cancel context.CancelFunc, execResp execResp) (*RowIterator, error) {
 	var header v2.DataSetHeader
 
 	ff := <-execResp.frameCh
@@ -269,12 +340,12 @@ func (c *Client) Mgmt(ctx context.Context, db string, query Stmt, options ...Mgm
 		return nil, errors.ES(errors.OpMgmt, errors.KClientArgs, "a Mgmt() call cannot accept a Stmt object that has Definitions or Parameters attached")
 	}
 
-	ctx, cancel, err := c.contextSetup(ctx, true) // Note: cancel is called when *RowIterator has Stop() called.
+	callCtx, cancel, err := c.contextSetup(ctx, true) // Note: cancel is called when *RowIterator has Stop() called.
 	if err != nil {
 		return nil, err
 	}
 
-	opts, err := c.setMgmtOptions(ctx, errors.OpMgmt, query, options...)
+	opts, err := c.setMgmtOptions(callCtx, errors.OpMgmt, query, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -284,6 +355,24 @@ func (c *Client) Mgmt(ctx context.Context, db string, query Stmt, options ...Mgm
 		return nil, err
 	}
 
+	iter, err := mgmtIter(callCtx, cancel, conn, db, query, opts)
+	if err != nil || !opts.retryWithoutTruncation || !iter.Truncated() {
+		return iter, err
+	}
+
+	// The v1 result is complete once the columns are ready, so no row of the truncated result was returned yet.
+	iter.Stop()
+	opts.requestProperties.Options["notruncation"] = true
+	callCtx, cancel, err = c.contextSetup(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return mgmtIter(callCtx, cancel, conn, db, query, opts)
+}
+
+// mgmtIter sends a Mgmt() call on conn and returns a RowIterator reading its v1 result. cancel is called when the
+// RowIterator has Stop() called.
+func mgmtIter(ctx context.Context, cancel context.CancelFunc, conn queryer, db string, query Stmt, opts *mgmtOptions) (*RowIterator, error) {
 	execResp, err := conn.mgmt(ctx, db, query, opts)
 	if err != nil {
 		cancel()
@@ -291,6 +380,7 @@ func (c *Client) Mgmt(ctx context.Context, db string, query Stmt, options ...Mgm
 	}
 
 	iter, columnsReady := newRowIterator(ctx, cancel, execResp, v2.DataSetHeader{}, errors.OpMgmt)
+	iter.truncationError = opts.truncationError
 	sm := &v1SM{
 		op:   errors.OpQuery,
 		iter: iter,
@@ -312,6 +402,8 @@ func (c *Client) setQueryOptions(ctx context.Context, op errors.Op, query Stmt,
 		return nil, errors.ES(op, errors.KClientArgs, "QueryValues in the the Stmt were incorrect: %s", err).SetNoRetry()
 	}
 
+	options = withContextQueryOptions(ctx, options)
+
 	// Match our server deadline to our context.Deadline. This should be set from withing kusto.Query() to always have a value.
 	deadline, ok := ctx.Deadline()
 	if ok {
@@ -347,6 +439,8 @@ func (c *Client) setMgmtOptions(ctx context.Context, op errors.Op, query Stmt, o
 		return nil, errors.ES(op, errors.KClientArgs, "QueryValues in the the Stmt were incorrect: %s", err).SetNoRetry()
 	}
 
+	options = withContextMgmtOptions(ctx, options)
+
 	// Match our server deadline to our context.Deadline. This should be set from withing kusto.Query() to always have a value.
 	deadline, ok := ctx.Deadline()
 	if ok {
@@ -393,10 +487,17 @@ func (c *Client) getConn(callType callType, options connOptions) (queryer, error
 			if err := auth.Validate(u.String()); err != nil {
 				return nil, err
 			}
-			iconn, err := newConn(u.String(), auth, c.http)
+			address, err := c.resolveEndpoint(u.String())
+			if err != nil {
+				return nil, err
+			}
+			iconn, err := newConn(address, auth, c.http)
 			if err != nil {
 				return nil, err
 			}
+			iconn.tokenRetry = c.tokenRetry
+			iconn.noAutoRequestID = c.noAutoRequestID
+			iconn.noResponseCompression = c.noResponseCompression
 			c.ingestConn = iconn
 
 			return iconn, nil
@@ -0,0 +1,135 @@
+package kusto
+
+// mapping.go holds ShowIngestionMapping(), which reads an ingestion mapping stored on a table.
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// mappingKinds are the kinds of ingestion mappings that can be stored on a table.
+var mappingKinds = map[string]bool{
+	"csv":        true,
+	"json":       true,
+	"avro":       true,
+	"parquet":    true,
+	"orc":        true,
+	"w3clogfile": true,
+}
+
+// MappingColumn is the mapping of source data to one column of a table.
+type MappingColumn struct {
+	// Column is the name of the table column.
+	Column string
+	// DataType is the type of the column, it is empty if the mapping does not set it.
+	DataType string
+	// Properties describe where the value comes from, such as "Ordinal" for CSV or "Path" for JSON mappings.
+	// Values that are not strings in the mapping hold their JSON text.
+	Properties map[string]string
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *MappingColumn) UnmarshalJSON(data []byte) error {
+	j := struct {
+		Column     string
+		DataType   string
+		Properties map[string]json.RawMessage
+	}{}
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*m = MappingColumn{Column: j.Column, DataType: j.DataType}
+	if len(j.Properties) > 0 {
+		m.Properties = make(map[string]string, len(j.Properties))
+	}
+	for k, raw := range j.Properties {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw)
+		}
+		m.Properties[k] = s
+	}
+	return nil
+}
+
+// Mapping is an ingestion mapping stored on a table, as referenced with ingest.IngestionMappingRef().
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/mappings
+type Mapping struct {
+	// Name is the name of the mapping.
+	Name string
+	// Kind is the kind of the mapping, such as "csv" or "json".
+	Kind string
+	// Columns are the column mappings, in the order of the mapping.
+	Columns []MappingColumn
+	// LastUpdatedOn is when the mapping was last changed.
+	LastUpdatedOn time.Time
+	// Raw is the mapping as stored by the service, for logging.
+	Raw string
+}
+
+// ShowIngestionMapping returns the ingestion mapping called name of kind stored on tableName in db. kind is the
+// mapping kind, such as "csv" or "json". This allows a mapping passed by name to ingest.IngestionMappingRef() to be
+// checked before ingesting. This requires table viewer permissions. The error is returned by the service if the
+// mapping does not exist.
+func (c *Client) ShowIngestionMapping(ctx context.Context, db, tableName, name, kind string) (Mapping, error) {
+	kind = strings.ToLower(kind)
+	switch {
+	case tableName == "":
+		return Mapping{}, errors.ES(errors.OpMgmt, errors.KClientArgs, "ShowIngestionMapping() requires a table name").SetNoRetry()
+	case name == "":
+		return Mapping{}, errors.ES(errors.OpMgmt, errors.KClientArgs, "ShowIngestionMapping() requires a mapping name").SetNoRetry()
+	case !mappingKinds[kind]:
+		return Mapping{}, errors.ES(errors.OpMgmt, errors.KClientArgs, "ShowIngestionMapping() does not support mapping kind %q", kind).SetNoRetry()
+	}
+
+	// kind was checked against mappingKinds, so it is safe to add.
+	stmt := NewStmt(".show table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" ingestion ").UnsafeAdd(kind).Add(" mapping ").UnsafeAdd(kql.QuoteString(name))
+
+	iter, err := c.Mgmt(ctx, db, stmt)
+	if err != nil {
+		return Mapping{}, err
+	}
+	defer iter.Stop()
+
+	var (
+		mapping Mapping
+		found   bool
+	)
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		row := struct {
+			Name          string    `kusto:"Name"`
+			Kind          string    `kusto:"Kind"`
+			Mapping       string    `kusto:"Mapping"`
+			LastUpdatedOn time.Time `kusto:"LastUpdatedOn"`
+		}{}
+		if err := r.ToStruct(&row); err != nil {
+			return err
+		}
+		mapping = Mapping{Name: row.Name, Kind: row.Kind, LastUpdatedOn: row.LastUpdatedOn, Raw: row.Mapping}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return Mapping{}, err
+	}
+	if !found {
+		return Mapping{}, errors.ES(errors.OpMgmt, errors.KClientArgs, "table %q in database %q has no %s mapping %q", tableName, db, kind, name).SetNoRetry()
+	}
+
+	if err := json.Unmarshal([]byte(mapping.Raw), &mapping.Columns); err != nil {
+		return Mapping{}, errors.ES(errors.OpMgmt, errors.KInternal, "could not parse the ingestion mapping %q: %s", mapping.Raw, err)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,78 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mappingResponse returns a v1 response body of a ".show ingestion mapping" command holding mapping.
+func mappingResponse(mapping string) string {
+	return fmt.Sprintf(`{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"Name","ColumnType":"string"},
+{"ColumnName":"Kind","ColumnType":"string"},
+{"ColumnName":"Mapping","ColumnType":"string"},
+{"ColumnName":"LastUpdatedOn","ColumnType":"datetime"},
+{"ColumnName":"Database","ColumnType":"string"},
+{"ColumnName":"Table","ColumnType":"string"}],
+"Rows":[["map'ping","Json",%s,"2022-03-01T10:00:00Z","db","table"]]}]}`, strconv.Quote(mapping))
+}
+
+func TestShowIngestionMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		kind      string
+		mapping   string
+		wantQuery string
+		want      Mapping
+		err       bool
+	}{
+		{
+			desc:      "Success",
+			kind:      "JSON",
+			mapping:   `[{"column":"a","datatype":"string","Properties":{"Path":"$.a"}},{"Column":"b","Properties":{"Path":"$.b","Ordinal":1}}]`,
+			wantQuery: `.show table ['table'] ingestion json mapping 'map\'ping'`,
+			want: Mapping{
+				Name: "map'ping",
+				Kind: "Json",
+				Columns: []MappingColumn{
+					{Column: "a", DataType: "string", Properties: map[string]string{"Path": "$.a"}},
+					{Column: "b", Properties: map[string]string{"Path": "$.b", "Ordinal": "1"}},
+				},
+				LastUpdatedOn: time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{desc: "Unknown kind", kind: "json;.drop table", err: true},
+		{desc: "Bad mapping", kind: "json", mapping: `{"Column":`, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				if test.wantQuery != "" {
+					assert.Equal(t, test.wantQuery, query)
+				}
+				return mappingResponse(test.mapping), nil
+			}}}
+
+			got, err := client.ShowIngestionMapping(context.Background(), "db", "table", "map'ping", test.kind)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			test.want.Raw = test.mapping
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
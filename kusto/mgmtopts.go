@@ -11,6 +11,11 @@ type mgmtOptions struct {
 	requestProperties *requestProperties
 	canWrite          bool
 	queryIngestion    bool
+
+	// truncationError and retryWithoutTruncation are client side only settings used by MgmtTruncationError() and
+	// MgmtRetryWithoutTruncation().
+	truncationError        bool
+	retryWithoutTruncation bool
 }
 
 // AllowWrite allows a query that attempts to modify data in a table.
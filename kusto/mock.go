@@ -49,6 +49,7 @@ func (c columnMap) set(name string) error {
 // This allows for creating hermetic tests from mock data or creating mock data from a real data fetch.
 type MockRows struct {
 	columns table.Columns
+	index   table.ColumnIndex
 	// playback is the list of data we are going to return to the RowIterator.
 	// Note: In the future, we may want to  allow adding other table data playback.
 	playback []interface{}
@@ -62,7 +63,7 @@ func NewMockRows(columns table.Columns) (*MockRows, error) {
 		return nil, err
 	}
 
-	return &MockRows{columns: columns}, nil
+	return &MockRows{columns: columns, index: columns.Index()}, nil
 }
 
 func (m *MockRows) nextRow() (*table.Row, error) {
@@ -79,11 +80,9 @@ func (m *MockRows) nextRow() (*table.Row, error) {
 	v := m.playback[m.position]
 	switch t := v.(type) {
 	case value.Values:
-		return &table.Row{
-			ColumnTypes: m.columns,
-			Values:      value.Values(t),
-			Op:          errors.OpQuery,
-		}, nil
+		row := table.NewRow(m.columns, m.index, value.Values(t))
+		row.Op = errors.OpQuery
+		return row, nil
 	case error:
 		m.err = t
 		return nil, t
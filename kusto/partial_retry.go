@@ -0,0 +1,207 @@
+package kusto
+
+// partial_retry.go holds the re-execution of queries with partial failures configured with WithRetryOnPartialFailure().
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/frames"
+	v2 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v2"
+)
+
+// WithRetryOnPartialFailure makes Query() run a query again, up to maxAttempts times in total, when the service
+// returns rows but reports errors for it in the DataSetCompletion or inline with the rows, such as when a node fails
+// while the query runs. Errors that would happen again, like going over a query limit or errors the service marks as
+// permanent, are not retried. The result of the last attempt is returned, partial failures included.
+//
+// Attempts are spaced out: the first retry waits a second and every following retry waits twice as long as the one
+// before. If the context of the query is done while waiting, Query() returns its error.
+//
+// Every attempt re-executes the whole query on the service, with the cost that comes with it, so only use this for
+// queries that are cheap enough to run more than once. To know if an attempt failed, Query() reads all of its rows
+// before returning, so the rows of the result are held in memory and are only available once the query completed.
+// Values less than 2 are ignored.
+func WithRetryOnPartialFailure(maxAttempts int) Option {
+	return func(c *Client) {
+		if maxAttempts > 1 {
+			c.partialFailureAttempts = maxAttempts
+			c.partialFailureDelay = partialRetryBaseDelay
+		}
+	}
+}
+
+// partialRetryBaseDelay is how long the first retry of WithRetryOnPartialFailure() waits.
+const partialRetryBaseDelay = time.Second
+
+// queryRetryPartialFailure runs Query() calls until one does not have a retryable partial failure or the attempts
+// set with WithRetryOnPartialFailure() are used up and returns a RowIterator over the result of the last one.
+// c.mu is only held while each attempt is sent, not while its result is read or between attempts.
+func (c *Client) queryRetryPartialFailure(ctx context.Context, db string, query Stmt, options []QueryOption) (*RowIterator, error) {
+	delay := c.partialFailureDelay
+	for attempt := 1; ; attempt++ {
+		iter, err := c.query(ctx, db, query, options)
+		if err != nil {
+			return nil, err
+		}
+		result := bufferResult(iter)
+		iter.Stop()
+
+		if attempt >= c.partialFailureAttempts || !result.retryable() || ctx.Err() != nil {
+			return result.iterator(ctx, iter), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.ES(errors.OpQuery, errors.KTimeout, "the query was not retried after a partial failure: %s", ctx.Err())
+		case <-c.getClock().After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// bufferedResult is the result of a query that was read to the end.
+type bufferedResult struct {
+	rows          []Row
+	rowErrors     []errors.Error
+	nonPrimary    map[frames.TableKind]v2.DataTable
+	completion    v2.DataSetCompletion
+	hasCompletion bool
+	// err is the final error of the RowIterator, nil if it reached io.EOF.
+	err error
+}
+
+// bufferResult reads all the rows of iter and the tables that follow them.
+func bufferResult(iter *RowIterator) *bufferedResult {
+	result := &bufferedResult{}
+	for {
+		row, inlineErr, err := iter.NextRowOrError()
+		if err != nil {
+			if err != io.EOF {
+				result.err = err
+			}
+			break
+		}
+		if inlineErr != nil {
+			result.rowErrors = append(result.rowErrors, *inlineErr)
+			result.rows = append(result.rows, Row{Error: inlineErr})
+			continue
+		}
+		result.rows = append(result.rows, Row{Values: row.Values, Replace: row.Replace})
+	}
+
+	iter.mu.Lock()
+	defer iter.mu.Unlock()
+	result.nonPrimary = make(map[frames.TableKind]v2.DataTable, len(iter.nonPrimary))
+	for k, v := range iter.nonPrimary {
+		result.nonPrimary[k] = v
+	}
+	result.completion, result.hasCompletion = iter.dsCompletion, iter.hasCompletion
+	return result
+}
+
+// retryable reports if the query completed with a partial failure that may not happen again. Limit errors and errors
+// marked as permanent by the service are not retryable, nor are queries that failed or were cancelled.
+func (b *bufferedResult) retryable() bool {
+	if b.err != nil || !b.hasCompletion || b.completion.Cancelled {
+		return false
+	}
+	if !b.completion.HasErrors && len(b.completion.OneAPIErrors) == 0 && len(b.rowErrors) == 0 {
+		return false
+	}
+
+	for _, e := range b.rowErrors {
+		if e.Kind == errors.KLimitsExceeded {
+			return false
+		}
+	}
+	for _, oneErr := range b.completion.OneAPIErrors {
+		m, ok := oneErr.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		errMap, ok := m["error"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if permanent, ok := errMap["@permanent"].(bool); ok && permanent {
+			return false
+		}
+		if code, ok := errMap["code"].(string); ok && code == "LimitsExceeded" {
+			return false
+		}
+	}
+	return true
+}
+
+// iterator returns a RowIterator that returns the buffered result as orig, the RowIterator it was read from, did.
+func (b *bufferedResult) iterator(ctx context.Context, orig *RowIterator) *RowIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	iter, columnsReady := newRowIterator(
+		ctx,
+		cancel,
+		execResp{reqHeader: orig.RequestHeader, respHeader: orig.ResponseHeader, sent: orig.sent},
+		v2.DataSetHeader{IsProgressive: orig.progressive},
+		errors.OpQuery,
+	)
+	iter.maxRecords = orig.maxRecords
+
+	iter.mu.Lock()
+	for k, v := range b.nonPrimary {
+		iter.nonPrimary[k] = v
+	}
+	iter.dsCompletion, iter.hasCompletion = b.completion, b.hasCompletion
+	iter.mu.Unlock()
+
+	go b.feed(ctx, iter, orig.columns)
+	select {
+	case <-ctx.Done():
+	case <-columnsReady:
+	}
+	return iter
+}
+
+// feed sends the buffered rows into iter, followed by the final error if there was one.
+func (b *bufferedResult) feed(ctx context.Context, iter *RowIterator, columns table.Columns) {
+	select {
+	case <-ctx.Done():
+		return
+	case iter.inColumns <- send{inColumns: columns}:
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, row := range b.rows {
+		s := send{wg: wg}
+		switch {
+		case row.Error != nil:
+			s.inRowErrors = []errors.Error{*row.Error}
+		case row.Replace:
+			s.inRows, s.inTableFragmentType = []value.Values{row.Values}, "DataReplace"
+		default:
+			s.inRows = []value.Values{row.Values}
+		}
+
+		wg.Add(1)
+		select {
+		case <-ctx.Done():
+			return
+		case iter.inRows <- s:
+		}
+	}
+
+	if b.err == nil {
+		close(iter.inRows)
+		return
+	}
+	// The rows must be handled before the error, which ends the RowIterator.
+	wg.Wait()
+	select {
+	case <-ctx.Done():
+	case iter.inErr <- send{inErr: b.err}:
+	}
+}
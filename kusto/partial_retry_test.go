@@ -0,0 +1,180 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// partialResponse returns a v2 response body with a single "Attempt" long column holding attempt. If oneAPIError is
+// set, the DataSetCompletion has it as an error.
+func partialResponse(attempt int, oneAPIError string) string {
+	completion := `{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}`
+	if oneAPIError != "" {
+		completion = fmt.Sprintf(`{"FrameType":"DataSetCompletion","HasErrors":true,"Cancelled":false,"OneApiErrors":[%s]}`, oneAPIError)
+	}
+	return fmt.Sprintf(`[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Attempt","ColumnType":"long"}],"Rows":[[%d]]},
+%s
+]`, attempt, completion)
+}
+
+// advanceWhenWaiting advances c by step whenever code is waiting on it, until done is closed.
+func advanceWhenWaiting(c *clock.Fake, step time.Duration, done <-chan struct{}) {
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+			if c.Waiters() > 0 {
+				c.Advance(step)
+			}
+		}
+	}()
+}
+
+func TestWithRetryOnPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	const (
+		transient = `{"error":{"code":"Other","message":"a shard failed"}}`
+		permanent = `{"error":{"code":"Other","message":"bad query","@permanent":true}}`
+		limits    = `{"error":{"code":"LimitsExceeded","message":"too many records"}}`
+	)
+
+	tests := []struct {
+		desc        string
+		maxAttempts int
+		errors      []string
+		// timeout is the timeout of the query, if set, in which case the clock does not advance.
+		timeout      time.Duration
+		wantAttempts int
+		// wantWait is the time waited between the attempts.
+		wantWait     time.Duration
+		wantFailures bool
+		wantErr      bool
+	}{
+		{
+			desc:         "Success is not retried",
+			maxAttempts:  3,
+			errors:       []string{""},
+			wantAttempts: 1,
+		},
+		{
+			desc:         "Transient partial failure is retried",
+			maxAttempts:  3,
+			errors:       []string{transient, transient, ""},
+			wantAttempts: 3,
+			wantWait:     3 * time.Second,
+		},
+		{
+			desc:         "Last attempt is returned",
+			maxAttempts:  2,
+			errors:       []string{transient, transient, ""},
+			wantAttempts: 2,
+			wantWait:     time.Second,
+			wantFailures: true,
+		},
+		{
+			desc:         "Permanent error is not retried",
+			maxAttempts:  3,
+			errors:       []string{permanent, ""},
+			wantAttempts: 1,
+			wantFailures: true,
+		},
+		{
+			desc:         "Limits error is not retried",
+			maxAttempts:  3,
+			errors:       []string{limits, ""},
+			wantAttempts: 1,
+			wantFailures: true,
+		},
+		{
+			desc:         "Context done while waiting",
+			maxAttempts:  3,
+			errors:       []string{transient, ""},
+			timeout:      50 * time.Millisecond,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			desc:         "Option under 2 is ignored",
+			maxAttempts:  1,
+			errors:       []string{transient, ""},
+			wantAttempts: 1,
+			wantFailures: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mu := sync.Mutex{}
+			attempts := 0
+			client := &Client{
+				conn: fakeQueryer{
+					onQuery: func(string) (string, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						body := partialResponse(attempts+1, test.errors[attempts])
+						attempts++
+						return body, nil
+					},
+				},
+			}
+			WithRetryOnPartialFailure(test.maxAttempts)(client)
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			fakeClock := clock.NewFake(start)
+			withClock(fakeClock)(client)
+
+			ctx := context.Background()
+			if test.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, test.timeout)
+				defer cancel()
+			} else {
+				done := make(chan struct{})
+				defer close(done)
+				advanceWhenWaiting(fakeClock, time.Second, done)
+			}
+
+			iter, err := client.Query(ctx, "db", NewStmt("MyTable"))
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, test.wantAttempts, attempts)
+				return
+			}
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			var got []int64
+			err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+				if e != nil {
+					return e
+				}
+				got = append(got, r.Values[0].(value.Long).Value)
+				return nil
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantAttempts, attempts)
+			assert.Equal(t, test.wantWait, fakeClock.Now().Sub(start))
+			assert.Equal(t, []int64{int64(test.wantAttempts)}, got)
+			assert.Equal(t, test.wantFailures, iter.PartialFailures() != nil)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package kusto
+
+// principal_roles.go holds ShowPrincipalRoles(), which lists the roles of the caller, and ExplainAuthError(), which
+// adds them to authorization errors.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+)
+
+// PrincipalRole is a role of the caller, as returned by the ".show principal roles" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/security-roles
+type PrincipalRole struct {
+	// Scope is the entity the role applies to, such as "Database db" or "Cluster".
+	Scope string
+	// DisplayName is the display name of the principal.
+	DisplayName string
+	// AADObjectID is the AAD object ID of the principal.
+	AADObjectID string `kusto:"AADObjectID"`
+	// Role is the name of the role, such as "Viewer" or "Ingestor".
+	Role string
+}
+
+// ShowPrincipalRoles returns the roles the principal of the client has in db and on the cluster.
+func (c *Client) ShowPrincipalRoles(ctx context.Context, db string) ([]PrincipalRole, error) {
+	iter, err := c.Mgmt(ctx, db, NewStmt(".show principal roles"))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Stop()
+
+	var roles []PrincipalRole
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		var role PrincipalRole
+		if err := r.ToStruct(&role); err != nil {
+			return err
+		}
+		roles = append(roles, role)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// ExplainAuthError adds the roles of the principal of the client to err if it is an HTTP 401 or 403 error returned by
+// a call on db, so that an error like "Forbidden" says which roles the caller has. Other errors are returned as
+// they are. If the roles cannot be read, err is returned as it is. The returned error wraps err.
+//
+// Example:
+//
+//	iter, err := client.Query(ctx, db, query)
+//	if err != nil {
+//		return client.ExplainAuthError(ctx, db, err)
+//	}
+func (c *Client) ExplainAuthError(ctx context.Context, db string, err error) error {
+	httpErr, ok := err.(*errors.HttpError)
+	if !ok || (httpErr.StatusCode != http.StatusUnauthorized && httpErr.StatusCode != http.StatusForbidden) {
+		return err
+	}
+
+	roles, rolesErr := c.ShowPrincipalRoles(ctx, db)
+	if rolesErr != nil {
+		return err
+	}
+
+	hint := fmt.Sprintf("the principal has no roles in database %q", db)
+	if len(roles) > 0 {
+		descs := make([]string, len(roles))
+		for i, r := range roles {
+			descs[i] = fmt.Sprintf("%s on %s", r.Role, r.Scope)
+		}
+		hint = fmt.Sprintf("the principal %s has the roles: %s", roles[0].DisplayName, strings.Join(descs, ", "))
+	}
+
+	return errors.E(httpErr.Op, errors.KHTTPError, fmt.Errorf("%s: %w", hint, err)).SetNoRetry()
+}
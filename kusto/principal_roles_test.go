@@ -0,0 +1,107 @@
+package kusto
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const principalRolesResponse = `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"Scope","ColumnType":"string"},
+{"ColumnName":"DisplayName","ColumnType":"string"},
+{"ColumnName":"AADObjectID","ColumnType":"string"},
+{"ColumnName":"Role","ColumnType":"string"}],
+"Rows":[
+["Database db","app (app id: 1)","1","Viewer"]]}]}`
+
+func TestShowPrincipalRoles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		mgmtErr error
+		want    []PrincipalRole
+		err     bool
+	}{
+		{
+			desc: "Success",
+			want: []PrincipalRole{{Scope: "Database db", DisplayName: "app (app id: 1)", AADObjectID: "1", Role: "Viewer"}},
+		},
+		{desc: "Mgmt error", mgmtErr: fmt.Errorf("mgmt error"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, ".show principal roles", query)
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return principalRolesResponse, nil
+			}}}
+
+			got, err := client.ShowPrincipalRoles(context.Background(), "db")
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestExplainAuthError(t *testing.T) {
+	t.Parallel()
+
+	httpErr := func(code int) error {
+		return errors.HTTP(errors.OpQuery, http.StatusText(code), code, ioutil.NopCloser(strings.NewReader("")), "error from Kusto endpoint: ")
+	}
+
+	tests := []struct {
+		desc     string
+		err      error
+		mgmtErr  error
+		wantHint string
+	}{
+		{desc: "Forbidden", err: httpErr(http.StatusForbidden), wantHint: "the principal app (app id: 1) has the roles: Viewer on Database db"},
+		{desc: "Unauthorized", err: httpErr(http.StatusUnauthorized), wantHint: "the principal app (app id: 1) has the roles: Viewer on Database db"},
+		{desc: "Other status", err: httpErr(http.StatusBadRequest)},
+		{desc: "Not an HTTP error", err: fmt.Errorf("error")},
+		{desc: "Roles cannot be read", err: httpErr(http.StatusForbidden), mgmtErr: fmt.Errorf("mgmt error")},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(string) (string, error) {
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return principalRolesResponse, nil
+			}}}
+
+			got := client.ExplainAuthError(context.Background(), "db", test.err)
+			if test.wantHint == "" {
+				assert.Same(t, test.err, got)
+				return
+			}
+			assert.Contains(t, got.Error(), test.wantHint)
+			var inner *errors.HttpError
+			assert.True(t, goErrors.As(got, &inner))
+		})
+	}
+}
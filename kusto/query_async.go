@@ -0,0 +1,81 @@
+package kusto
+
+// query_async.go holds QueryAsync(), which submits a query without blocking and returns a QueryFuture.
+
+import (
+	"context"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// defaultAsyncConcurrency is the number of QueryAsync() requests a Client runs at the same time if
+// WithAsyncConcurrency() is not provided.
+const defaultAsyncConcurrency = 10
+
+// WithAsyncConcurrency sets the maximum number of requests started by QueryAsync() that the client runs at the same
+// time. Other requests wait for one of these to finish. The default is 10. Values less than 1 are ignored.
+func WithAsyncConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.asyncConcurrency = n
+		}
+	}
+}
+
+// QueryFuture is the result of a query submitted with QueryAsync().
+type QueryFuture struct {
+	done chan struct{}
+	iter *RowIterator
+	err  error
+}
+
+// Wait blocks until the query's response started arriving and returns its RowIterator, with the same results and errors
+// as Query(). If ctx is done before that, a KTimeout error is returned, but the query keeps running and Wait can
+// be called again. Wait can be called multiple times and returns the same values every time.
+// Always defer a Stop() call after receiving the RowIterator.
+func (f *QueryFuture) Wait(ctx context.Context) (*RowIterator, error) {
+	select {
+	case <-ctx.Done():
+		return nil, errors.ES(errors.OpQuery, errors.KTimeout, "context was done before the query returned: %s", ctx.Err())
+	case <-f.done:
+		return f.iter, f.err
+	}
+}
+
+// QueryAsync submits a query to Kusto without blocking. The query is run on a separate goroutine, with the client
+// running at most the number of requests set with WithAsyncConcurrency() at the same time. A request counts
+// against this limit until Query() would have returned, reading the rows of the RowIterator does not.
+// ctx controls the whole query, including the time spent waiting for a free slot. To abandon a query, cancel ctx.
+// The arguments are the same as for Query(). Use QueryFuture.Wait() to receive the result.
+func (c *Client) QueryAsync(ctx context.Context, db string, query Stmt, options ...QueryOption) *QueryFuture {
+	f := &QueryFuture{done: make(chan struct{})}
+	sem := c.asyncSem()
+
+	go func() {
+		defer close(f.done)
+
+		select {
+		case <-ctx.Done():
+			f.err = errors.ES(errors.OpQuery, errors.KTimeout, "context was done before the query could be started: %s", ctx.Err())
+			return
+		case sem <- struct{}{}:
+		}
+		defer func() { <-sem }()
+
+		f.iter, f.err = c.Query(ctx, db, query, options...)
+	}()
+
+	return f
+}
+
+// asyncSem returns the semaphore that limits the number of QueryAsync() requests run at the same time.
+func (c *Client) asyncSem() chan struct{} {
+	c.asyncOnce.Do(func() {
+		n := c.asyncConcurrency
+		if n == 0 {
+			n = defaultAsyncConcurrency
+		}
+		c.asyncLimit = make(chan struct{}, n)
+	})
+	return c.asyncLimit
+}
@@ -0,0 +1,114 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAsync(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		queryErr error
+		want     []string
+		err      bool
+	}{
+		{desc: "Success", want: []string{"a"}},
+		{desc: "Query error", queryErr: fmt.Errorf("query error"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) {
+				if test.queryErr != nil {
+					return "", test.queryErr
+				}
+				return splitResponse("a"), nil
+			}}}
+
+			iter, err := client.QueryAsync(context.Background(), "db", NewStmt("table")).Wait(context.Background())
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			var got []string
+			err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+				require.Nil(t, e)
+				got = append(got, r.Values[0].String())
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestQueryAsyncConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	var queries int32
+	client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) {
+		atomic.AddInt32(&queries, 1)
+		return splitResponse("a"), nil
+	}}}
+	WithAsyncConcurrency(1)(client)
+
+	// Take the only slot, so the query must wait for it.
+	sem := client.asyncSem()
+	sem <- struct{}{}
+
+	future := client.QueryAsync(context.Background(), "db", NewStmt("table"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := future.Wait(ctx)
+	require.Error(t, err)
+	e, ok := errors.GetKustoError(err)
+	require.True(t, ok)
+	assert.Equal(t, errors.KTimeout, e.Kind)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&queries))
+
+	<-sem
+
+	iter, err := future.Wait(context.Background())
+	require.NoError(t, err)
+	iter.Stop()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&queries))
+
+	// A second Wait() returns the same result.
+	again, err := future.Wait(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, iter, again)
+}
+
+func TestQueryAsyncCancelledWhileWaiting(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) {
+		panic("query must not be started")
+	}}}
+	WithAsyncConcurrency(1)(client)
+	client.asyncSem() <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	future := client.QueryAsync(ctx, "db", NewStmt("table"))
+	cancel()
+
+	_, err := future.Wait(context.Background())
+	require.Error(t, err)
+}
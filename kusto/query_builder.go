@@ -519,6 +519,22 @@ func (s Stmt) Add(query stringConstant) Stmt {
 	return s
 }
 
+// AddIf returns the Stmt that fn returns when called with the Stmt if cond is true, and the Stmt unchanged otherwise.
+// This allows including optional clauses while building a query fluently:
+//
+//	stmt := kusto.NewStmt("table").
+//		AddIf(filterByName, func(s kusto.Stmt) kusto.Stmt { return s.Add(" | where Name == name") }).
+//		Add(" | take 10")
+//
+// fn builds on the Stmt with the same methods as any other code, so the same injection protections apply to what it
+// adds: Add() only accepts string constants and values should be passed as Parameters.
+func (s Stmt) AddIf(cond bool, fn func(Stmt) Stmt) Stmt {
+	if !cond {
+		return s
+	}
+	return fn(s)
+}
+
 // UnsafeAdd provides a method to add strings that are not injection protected to the Stmt.
 // To utilize this method, you must create the Stmt with the UnsafeStmt() option and pass
 // the unsafe.Stmt with .Add set to true. If not set, THIS WILL PANIC!
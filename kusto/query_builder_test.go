@@ -598,3 +598,34 @@ func buildQueryStr(query string, params Definitions) string {
 	}
 	return query
 }
+
+func TestStmtAddIf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		cond    bool
+		wantStr string
+	}{
+		{desc: "Condition holds", cond: true, wantStr: "table | where Name == name | take 10"},
+		{desc: "Condition does not hold", wantStr: "table | take 10"},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			called := false
+			stmt := NewStmt("table").
+				AddIf(test.cond, func(s Stmt) Stmt {
+					called = true
+					return s.Add(" | where Name == name")
+				}).
+				Add(" | take 10")
+
+			assert.Equal(t, test.wantStr, stmt.String())
+			assert.Equal(t, test.cond, called)
+		})
+	}
+}
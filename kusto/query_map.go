@@ -0,0 +1,115 @@
+package kusto
+
+// query_map.go holds QueryMap(), which reads the rows of a query into a map keyed by a column.
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+)
+
+// QueryMapLastWins makes QueryMap() keep the last row when rows have the same key. By default, QueryMap() returns
+// an error for a duplicate key. This option has no effect on Query().
+func QueryMapLastWins() QueryOption {
+	return func(q *queryOptions) error {
+		q.mapLastWins = true
+		return nil
+	}
+}
+
+// QueryMap runs query and reads all its rows into m, keyed by the value of column keyCol. m must be a non-nil pointer to
+// a map[K]V, which is created if it is nil. Rows are added to the map already there. V is a struct or a pointer to a
+// struct that each row is decoded into with table.Row.ToStruct(). K must be the Go type the column converts to:
+// string for string columns, int32 for int, int64 for long, float64 for real, bool for bool, time.Time for datetime,
+// time.Duration for timespan, uuid.UUID for guid and string for decimal. The value package types, like value.String,
+// can also be used as K.
+// QueryMap returns an error if keyCol is not in the result, if K does not match the column type, if a row has a null key
+// or, unless the QueryMapLastWins() option is passed, if rows have the same key. It is meant for small results, such as
+// reference data, as the whole result is held in memory. The options are the same as for Query().
+func (c *Client) QueryMap(ctx context.Context, db string, query Stmt, keyCol string, m interface{}, options ...QueryOption) error {
+	mp := reflect.ValueOf(m)
+	if mp.Kind() != reflect.Ptr || mp.IsNil() || mp.Elem().Kind() != reflect.Map {
+		return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): m must be a non-nil pointer to a map, was %T", m).SetNoRetry()
+	}
+	mapType := mp.Elem().Type()
+	keyType, valType := mapType.Key(), mapType.Elem()
+	structType := valType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): the map values must be a struct or a pointer to a struct, was %s", valType).SetNoRetry()
+	}
+
+	opts := &queryOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}}}
+	for _, o := range withContextQueryOptions(ctx, options) {
+		if err := o(opts); err != nil {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): %s", err).SetNoRetry()
+		}
+	}
+
+	iter, err := c.Query(ctx, db, query, options...)
+	if err != nil {
+		return err
+	}
+	defer iter.Stop()
+
+	if mp.Elem().IsNil() {
+		mp.Elem().Set(reflect.MakeMap(mapType))
+	}
+	out := mp.Elem()
+
+	index := -1
+	seen := map[interface{}]bool{}
+	return iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		if index == -1 {
+			for i, col := range r.ColumnTypes {
+				if col.Name == keyCol {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): column %q is not in the result", keyCol).SetNoRetry()
+			}
+		}
+
+		k := r.Values[index]
+		if nullValue(k) {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): column %q has a null value", keyCol).SetNoRetry()
+		}
+		key := reflect.New(keyType).Elem()
+		if err := k.Convert(key); err != nil {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): the map key type %s does not match column %q of type %s: %s", keyType, keyCol, r.ColumnTypes[index].Type, err).SetNoRetry()
+		}
+
+		if !opts.mapLastWins {
+			if seen[key.Interface()] {
+				return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryMap(): more than one row has the key %s in column %q", k, keyCol).SetNoRetry()
+			}
+			seen[key.Interface()] = true
+		}
+
+		val := reflect.New(structType)
+		if err := r.ToStruct(val.Interface()); err != nil {
+			return err
+		}
+		if valType.Kind() != reflect.Ptr {
+			val = val.Elem()
+		}
+		out.SetMapIndex(key, val)
+		return nil
+	})
+}
+
+// nullValue reports if k is a null value.
+func nullValue(k value.Kusto) bool {
+	valid := reflect.ValueOf(k).FieldByName("Valid")
+	return valid.IsValid() && !valid.Bool()
+}
@@ -0,0 +1,92 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapResponse returns a v2 response body with a string "Name" and a long "Count" column holding rows.
+func mapResponse(rows string) string {
+	return fmt.Sprintf(`[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Name","ColumnType":"string"},{"ColumnName":"Count","ColumnType":"long"}],"Rows":[%s]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`, rows)
+}
+
+type mapRow struct {
+	Name  string
+	Count int64
+}
+
+func TestQueryMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		rows    string
+		keyCol  string
+		options []QueryOption
+		want    map[string]mapRow
+		err     bool
+	}{
+		{
+			desc:   "Success",
+			rows:   `["a",1],["b",2]`,
+			keyCol: "Name",
+			want:   map[string]mapRow{"a": {Name: "a", Count: 1}, "b": {Name: "b", Count: 2}},
+		},
+		{desc: "No rows", keyCol: "Name", want: map[string]mapRow{}},
+		{desc: "Duplicate key", rows: `["a",1],["a",2]`, keyCol: "Name", err: true},
+		{
+			desc:    "Duplicate key last wins",
+			rows:    `["a",1],["a",2]`,
+			keyCol:  "Name",
+			options: []QueryOption{QueryMapLastWins()},
+			want:    map[string]mapRow{"a": {Name: "a", Count: 2}},
+		},
+		{desc: "Missing column", rows: `["a",1]`, keyCol: "Other", err: true},
+		{desc: "Key type mismatch", rows: `["a",1]`, keyCol: "Count", err: true},
+		{desc: "Null key", rows: `[null,1]`, keyCol: "Name", err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) {
+				return mapResponse(test.rows), nil
+			}}}
+
+			var got map[string]mapRow
+			err := client.QueryMap(context.Background(), "db", NewStmt("table"), test.keyCol, &got, test.options...)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestQueryMapPointerValues(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) {
+		return mapResponse(`["a",1],["b",2]`), nil
+	}}}
+
+	got := map[int64]*mapRow{}
+	require.NoError(t, client.QueryMap(context.Background(), "db", NewStmt("table"), "Count", &got))
+	assert.Equal(t, map[int64]*mapRow{1: {Name: "a", Count: 1}, 2: {Name: "b", Count: 2}}, got)
+
+	var notStruct map[string]string
+	assert.Error(t, client.QueryMap(context.Background(), "db", NewStmt("table"), "Name", &notStruct))
+	assert.Error(t, client.QueryMap(context.Background(), "db", NewStmt("table"), "Name", got))
+}
@@ -0,0 +1,230 @@
+package kusto
+
+// query_split.go holds QueryTimeRangeSplit(), which runs a query as a set of sub-queries over time buckets
+// and merges the results into a single RowIterator.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	v2 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v2"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+)
+
+// defaultSplitConcurrency is the number of sub-queries QueryTimeRangeSplit() runs at the same time if
+// SplitConcurrency() is not provided.
+const defaultSplitConcurrency = 4
+
+// QueryTimeRangeSplit runs query once for every bucket sized time range between start(inclusive) and end(exclusive) and
+// merges the rows of all these sub-queries into a single RowIterator. This allows scanning a time range that would
+// go over the service limits if done in a single query.
+// Each sub-query is query with "| where timeCol >= datetime(<bucket start>) and timeCol < datetime(<bucket end>)"
+// appended to it, so query must output timeCol and the rows of a bucket must not depend on rows of other buckets
+// (such as a summarize over the whole range). All sub-queries must return the same columns.
+// Sub-queries run in parallel, see SplitConcurrency(), and rows are returned in the order they are received,
+// not in time order. By default, the first failed sub-query stops all others and its error is returned by the
+// RowIterator. With SplitCollectErrors(), a failed sub-query is returned as an inline error instead.
+// options are passed to every sub-query. Progressive results are always disabled for sub-queries.
+func (c *Client) QueryTimeRangeSplit(ctx context.Context, db string, query Stmt, timeCol string, start, end time.Time, bucket time.Duration, options ...QueryOption) (*RowIterator, error) {
+	switch {
+	case strings.TrimSpace(timeCol) == "":
+		return nil, errors.ES(errors.OpQuery, errors.KClientArgs, "QueryTimeRangeSplit(): timeCol must be set").SetNoRetry()
+	case !end.After(start):
+		return nil, errors.ES(errors.OpQuery, errors.KClientArgs, "QueryTimeRangeSplit(): end(%s) must be after start(%s)", end, start).SetNoRetry()
+	case bucket <= 0:
+		return nil, errors.ES(errors.OpQuery, errors.KClientArgs, "QueryTimeRangeSplit(): bucket must be greater than 0, was %v", bucket).SetNoRetry()
+	}
+
+	split := &queryOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}}}
+	for _, o := range withContextQueryOptions(ctx, options) {
+		if err := o(split); err != nil {
+			return nil, errors.ES(errors.OpQuery, errors.KClientArgs, "QueryTimeRangeSplit(): %s", err).SetNoRetry()
+		}
+	}
+	concurrency := split.splitConcurrency
+	if concurrency == 0 {
+		concurrency = defaultSplitConcurrency
+	}
+
+	// Progressive frames can replace rows that were already returned, which cannot be merged.
+	options = append(options[:len(options):len(options)], ResultsProgressiveDisable())
+
+	ctx, cancel := context.WithCancel(ctx) // Note: cancel is called when *RowIterator has Stop() called.
+	iter, columnsReady := newRowIterator(ctx, cancel, execResp{}, v2.DataSetHeader{}, errors.OpQuery)
+
+	m := &splitMerger{
+		client:        c,
+		db:            db,
+		query:         query,
+		timeCol:       timeCol,
+		options:       options,
+		collectErrors: split.splitCollectErrors,
+		iter:          iter,
+	}
+	go m.run(ctx, timeBuckets(start, end, bucket), concurrency)
+
+	<-columnsReady
+
+	return iter, nil
+}
+
+// timeBucket is a time range of [start, end).
+type timeBucket struct {
+	start, end time.Time
+}
+
+// timeBuckets splits [start, end) into bucket sized time ranges. The last range may be shorter than bucket.
+func timeBuckets(start, end time.Time, bucket time.Duration) []timeBucket {
+	var buckets []timeBucket
+	for t := start; t.Before(end); t = t.Add(bucket) {
+		e := t.Add(bucket)
+		if e.After(end) {
+			e = end
+		}
+		buckets = append(buckets, timeBucket{start: t, end: e})
+	}
+	return buckets
+}
+
+// splitMerger runs the sub-queries of QueryTimeRangeSplit() and sends their results into a single RowIterator.
+type splitMerger struct {
+	client        *Client
+	db            string
+	query         Stmt
+	timeCol       string
+	options       []QueryOption
+	collectErrors bool
+
+	iter *RowIterator
+
+	columnsOnce sync.Once
+	columns     table.Columns
+	errOnce     sync.Once
+}
+
+// run runs the sub-queries for all buckets with at most concurrency running at the same time.
+func (m *splitMerger) run(ctx context.Context, buckets []timeBucket, concurrency int) {
+	subCtx, cancelSub := context.WithCancel(ctx)
+	defer cancelSub()
+
+	work := make(chan timeBucket)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency && i < len(buckets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range work {
+				if err := m.runBucket(subCtx, b); err != nil {
+					m.fail(ctx, subCtx, cancelSub, b, err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, b := range buckets {
+		select {
+		case <-subCtx.Done():
+			break feed
+		case work <- b:
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	close(m.iter.inRows)
+}
+
+// runBucket runs the sub-query for bucket b and sends its rows to the RowIterator.
+func (m *splitMerger) runBucket(ctx context.Context, b timeBucket) error {
+	sub := m.query
+	sub.queryStr = fmt.Sprintf(
+		"%s\n| where %[2]s >= datetime(%[3]s) and %[2]s < datetime(%[4]s)",
+		strings.TrimRight(strings.TrimSpace(m.query.queryStr), ";"),
+		kql.QuoteName(m.timeCol),
+		b.start.UTC().Format(time.RFC3339Nano),
+		b.end.UTC().Format(time.RFC3339Nano),
+	)
+
+	iter, err := m.client.Query(ctx, m.db, sub, m.options...)
+	if err != nil {
+		return err
+	}
+	defer iter.Stop()
+
+	m.columnsOnce.Do(func() {
+		m.columns = iter.columns
+		select {
+		case <-ctx.Done():
+		case m.iter.inColumns <- send{inColumns: iter.columns}:
+		}
+	})
+	if !columnsEqual(m.columns, iter.columns) {
+		return errors.ES(errors.OpQuery, errors.KInternal, "sub-query returned columns %v, but the first sub-query returned columns %v", iter.columns, m.columns)
+	}
+
+	return iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		s := send{}
+		if e != nil {
+			s.inRowErrors = []errors.Error{*e}
+		} else {
+			s.inRows = []value.Values{r.Values}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m.iter.inRows <- s:
+			return nil
+		}
+	})
+}
+
+// fail handles the failure of the sub-query for bucket b. Unless SplitCollectErrors() was provided, the first
+// failure is sent as the final error of the RowIterator and all other sub-queries are cancelled.
+func (m *splitMerger) fail(ctx, subCtx context.Context, cancelSub context.CancelFunc, b timeBucket, err error) {
+	kind := errors.KOther
+	if e, ok := errors.GetKustoError(err); ok {
+		kind = e.Kind
+	}
+	bucketErr := errors.ES(
+		errors.OpQuery,
+		kind,
+		"sub-query for time range [%s, %s) failed: %s", b.start.UTC().Format(time.RFC3339Nano), b.end.UTC().Format(time.RFC3339Nano), err,
+	)
+
+	if m.collectErrors {
+		select {
+		case <-subCtx.Done():
+		case m.iter.inRows <- send{inRowErrors: []errors.Error{*bucketErr}}:
+		}
+		return
+	}
+
+	m.errOnce.Do(func() {
+		select {
+		case <-ctx.Done():
+		case m.iter.inErr <- send{inErr: bucketErr}:
+		}
+		cancelSub()
+	})
+}
+
+// columnsEqual indicates if a and b have the same column names and types.
+func columnsEqual(a, b table.Columns) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	v1 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v1"
+	v2 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueryer is a queryer that answers Query() calls with the v2 response body returned by onQuery and
+// Mgmt() calls with the v1 response body returned by onMgmt.
+type fakeQueryer struct {
+	onQuery func(query string) (string, error)
+	onMgmt  func(query string) (string, error)
+}
+
+func (f fakeQueryer) Close() error {
+	return nil
+}
+
+func (f fakeQueryer) query(ctx context.Context, _ string, query Stmt, options *queryOptions) (execResp, error) {
+	body, err := f.onQuery(query.String())
+	if err != nil {
+		return execResp{}, err
+	}
+	dec := &v2.Decoder{}
+	if options != nil {
+		dec.BadRowsAsErrors = options.requestProperties.badRowsAsErrors
+		dec.DuplicateColumns = options.requestProperties.duplicateColumns
+	}
+	return execResp{frameCh: dec.Decode(ctx, ioutil.NopCloser(strings.NewReader(body)), errors.OpQuery)}, nil
+}
+
+func (f fakeQueryer) mgmt(ctx context.Context, _ string, query Stmt, options *mgmtOptions) (execResp, error) {
+	body, err := f.onMgmt(query.String())
+	if err != nil {
+		return execResp{}, err
+	}
+	dec := &v1.Decoder{BadRowsAsErrors: options != nil && options.requestProperties.badRowsAsErrors}
+	return execResp{frameCh: dec.Decode(ctx, ioutil.NopCloser(strings.NewReader(body)), errors.OpMgmt)}, nil
+}
+
+// splitResponse returns a v2 response body with a single "Bucket" string column holding bucket.
+func splitResponse(bucket string) string {
+	return fmt.Sprintf(`[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Bucket","ColumnType":"string"}],"Rows":[[%q]]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`, bucket)
+}
+
+var splitWhereRE = regexp.MustCompile(`\| where \['Timestamp'\] >= datetime\((\S+)\) and \['Timestamp'\] < datetime\((\S+)\)$`)
+
+func TestQueryTimeRangeSplit(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(60 * time.Hour)
+
+	tests := []struct {
+		desc       string
+		failBucket string
+		options    []QueryOption
+		wantRows   []string
+		wantInline int
+		err        bool
+	}{
+		{
+			desc: "Success",
+			wantRows: []string{
+				"2020-01-01T00:00:00Z-2020-01-02T00:00:00Z",
+				"2020-01-02T00:00:00Z-2020-01-03T00:00:00Z",
+				"2020-01-03T00:00:00Z-2020-01-03T12:00:00Z",
+			},
+		},
+		{
+			desc:       "Sub-query failure fails the query",
+			failBucket: "2020-01-02T00:00:00Z",
+			options:    []QueryOption{SplitConcurrency(1)},
+			err:        true,
+		},
+		{
+			desc:       "Sub-query failure is collected",
+			failBucket: "2020-01-02T00:00:00Z",
+			options:    []QueryOption{SplitCollectErrors()},
+			wantRows: []string{
+				"2020-01-01T00:00:00Z-2020-01-02T00:00:00Z",
+				"2020-01-03T00:00:00Z-2020-01-03T12:00:00Z",
+			},
+			wantInline: 1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mu := sync.Mutex{}
+			var queries []string
+			client := &Client{
+				conn: fakeQueryer{
+					onQuery: func(query string) (string, error) {
+						mu.Lock()
+						queries = append(queries, query)
+						mu.Unlock()
+
+						match := splitWhereRE.FindStringSubmatch(query)
+						if match == nil {
+							return "", fmt.Errorf("query did not have the expected where clause: %s", query)
+						}
+						if !strings.HasPrefix(query, "MyTable\n") {
+							return "", fmt.Errorf("query did not start with the base query: %s", query)
+						}
+						if match[1] == test.failBucket {
+							return "", errors.ES(errors.OpQuery, errors.KInternal, "some error")
+						}
+						return splitResponse(match[1] + "-" + match[2]), nil
+					},
+				},
+			}
+
+			iter, err := client.QueryTimeRangeSplit(context.Background(), "db", NewStmt("MyTable;"), "Timestamp", start, end, 24*time.Hour, test.options...)
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			var gotRows []string
+			gotInline := 0
+			err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+				if e != nil {
+					gotInline++
+					return nil
+				}
+				gotRows = append(gotRows, r.Values[0].String())
+				return nil
+			})
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			sort.Strings(gotRows)
+			assert.Equal(t, test.wantRows, gotRows)
+			assert.Equal(t, test.wantInline, gotInline)
+			assert.Len(t, queries, 3)
+		})
+	}
+}
+
+func TestQueryTimeRangeSplitArgs(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := &Client{conn: fakeQueryer{}}
+
+	tests := []struct {
+		desc    string
+		timeCol string
+		end     time.Time
+		bucket  time.Duration
+		options []QueryOption
+	}{
+		{desc: "No time column", end: start.Add(time.Hour), bucket: time.Minute},
+		{desc: "End before start", timeCol: "Timestamp", end: start, bucket: time.Minute},
+		{desc: "Bad bucket", timeCol: "Timestamp", end: start.Add(time.Hour)},
+		{desc: "Bad concurrency", timeCol: "Timestamp", end: start.Add(time.Hour), bucket: time.Minute, options: []QueryOption{SplitConcurrency(0)}},
+	}
+
+	for _, test := range tests {
+		_, err := client.QueryTimeRangeSplit(context.Background(), "db", NewStmt("MyTable"), test.timeCol, start, test.end, test.bucket, test.options...)
+		assert.Error(t, err, test.desc)
+	}
+}
@@ -4,10 +4,12 @@ package kusto
 // it clogs up the main kusto.go file.
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/frames"
 )
 
 // requestProperties is a POD used by clients to describe specific needs from the service.
@@ -16,10 +18,27 @@ import (
 type requestProperties struct {
 	Options    map[string]interface{}
 	Parameters map[string]string
+
+	// clientRequestID is sent as the x-ms-client-request-id header instead of being sent to the service in the body.
+	clientRequestID string
+	// badRowsAsErrors is a client side only setting used by BadRowsAsErrors() and MgmtBadRowsAsErrors().
+	badRowsAsErrors bool
+	// duplicateColumns is a client side only setting used by ErrorOnDuplicateColumns() and RenameDuplicateColumns().
+	duplicateColumns frames.DuplicateColumns
 }
 
 type queryOptions struct {
 	requestProperties *requestProperties
+
+	// splitConcurrency and splitCollectErrors are client side only settings used by QueryTimeRangeSplit().
+	splitConcurrency   int
+	splitCollectErrors bool
+
+	// verifyRowCount is a client side only setting used by VerifyRowCount().
+	verifyRowCount bool
+
+	// mapLastWins is a client side only setting used by QueryMap().
+	mapLastWins bool
 }
 
 // TODO(jdoak/daniel): These really need to be tested.  I didn't find that NoTruncation worked, I had to add the
@@ -53,6 +72,142 @@ func ResultsProgressiveDisable() QueryOption {
 	}
 }
 
+// MaxRecords limits the query results to n records (query_take_max_records). Records over this limit are
+// silently dropped by the service, use RowIterator.Truncated() after reading all rows to detect that the limit was hit.
+func MaxRecords(n int64) QueryOption {
+	return func(q *queryOptions) error {
+		if n < 1 {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "MaxRecords option was set to %d, but must be greater than 0", n)
+		}
+		q.requestProperties.Options[takeMaxRecordsOption] = n
+		return nil
+	}
+}
+
+// TruncationMaxSize overrides the default maximum data size in bytes a query is allowed to return (truncationmaxsize).
+// When a query result goes over this size, the service returns a KLimitsExceeded error inline with the rows. Use
+// RowIterator.Truncated() after reading all rows to detect this.
+func TruncationMaxSize(bytes int64) QueryOption {
+	return func(q *queryOptions) error {
+		if bytes < 1 {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "TruncationMaxSize option was set to %d, but must be greater than 0", bytes)
+		}
+		q.requestProperties.Options["truncationmaxsize"] = bytes
+		return nil
+	}
+}
+
+// QueryFanoutNodesPercent sets the percentage of nodes the service fans out execution of a distributed
+// query to (query_fanout_nodes_percent). percent must be between 1 and 100.
+func QueryFanoutNodesPercent(percent int) QueryOption {
+	return func(q *queryOptions) error {
+		if percent < 1 || percent > 100 {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryFanoutNodesPercent option was set to %d, but must be between 1 and 100", percent)
+		}
+		q.requestProperties.Options["query_fanout_nodes_percent"] = percent
+		return nil
+	}
+}
+
+// QueryFanoutThreadsPercent sets the percentage of threads on each node the service fans out execution of a
+// distributed query to (query_fanout_threads_percent). percent must be between 1 and 100.
+func QueryFanoutThreadsPercent(percent int) QueryOption {
+	return func(q *queryOptions) error {
+		if percent < 1 || percent > 100 {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "QueryFanoutThreadsPercent option was set to %d, but must be between 1 and 100", percent)
+		}
+		q.requestProperties.Options["query_fanout_threads_percent"] = percent
+		return nil
+	}
+}
+
+// MaxMemoryConsumptionPerQueryPerNode overrides the default maximum amount of memory in bytes a whole query
+// may allocate per node (max_memory_consumption_per_query_per_node). The service rejects values over half of the
+// memory of a node, which depends on the cluster, so only 0 is rejected by the client.
+func MaxMemoryConsumptionPerQueryPerNode(bytes uint64) QueryOption {
+	return func(q *queryOptions) error {
+		if bytes == 0 {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "MaxMemoryConsumptionPerQueryPerNode option was set to 0, but must be greater than 0")
+		}
+		q.requestProperties.Options["max_memory_consumption_per_query_per_node"] = bytes
+		return nil
+	}
+}
+
+// RequestAppName sets the name of the application that sends the query (request_app_name), which the service shows
+// in ".show queries" and uses for workload group classification. By default, the service uses the name of the
+// process.
+func RequestAppName(name string) QueryOption {
+	return func(q *queryOptions) error {
+		if strings.TrimSpace(name) == "" {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "RequestAppName option was set to an empty name")
+		}
+		q.requestProperties.Options["request_app_name"] = name
+		return nil
+	}
+}
+
+// RequestUser sets the name of the user the application sends the query for (request_user), which the service shows
+// in ".show queries". It does not change the identity the query runs as.
+func RequestUser(user string) QueryOption {
+	return func(q *queryOptions) error {
+		if strings.TrimSpace(user) == "" {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "RequestUser option was set to an empty user")
+		}
+		q.requestProperties.Options["request_user"] = user
+		return nil
+	}
+}
+
+// RequestDescription sets a free text description of the query (request_description), which the service shows in
+// ".show queries".
+func RequestDescription(description string) QueryOption {
+	return func(q *queryOptions) error {
+		if strings.TrimSpace(description) == "" {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "RequestDescription option was set to an empty description")
+		}
+		q.requestProperties.Options["request_description"] = description
+		return nil
+	}
+}
+
+// SplitConcurrency sets the maximum number of sub-queries that QueryTimeRangeSplit() runs at the same time.
+// The default is 4. This option has no effect on Query().
+func SplitConcurrency(n int) QueryOption {
+	return func(q *queryOptions) error {
+		if n < 1 {
+			return errors.ES(errors.OpQuery, errors.KClientArgs, "SplitConcurrency option was set to %d, but must be greater than 0", n)
+		}
+		q.splitConcurrency = n
+		return nil
+	}
+}
+
+// SplitCollectErrors makes QueryTimeRangeSplit() report a failed sub-query as an inline error and keep returning
+// the rows of the other sub-queries. By default, the first failed sub-query stops all other sub-queries and its error
+// is returned by the RowIterator. This option has no effect on Query().
+func SplitCollectErrors() QueryOption {
+	return func(q *queryOptions) error {
+		q.splitCollectErrors = true
+		return nil
+	}
+}
+
+// VerifyRowCount makes the RowIterator compare the number of rows of the primary table it returned with the number
+// of rows the service reported for it (see RowIterator.ServerRowCount()). If they differ, or the service did not report
+// a row count, reading the rows returns an error instead of io.EOF after the last row. This detects results that
+// were cut short, like by a partial failure. Rows that are over a MaxRecords() limit or a truncation limit are not
+// part of the count the service reports, so those are not detected, use RowIterator.Truncated() for them.
+func VerifyRowCount() QueryOption {
+	return func(q *queryOptions) error {
+		q.verifyRowCount = true
+		return nil
+	}
+}
+
+// takeMaxRecordsOption is the request property set by MaxRecords().
+const takeMaxRecordsOption = "query_take_max_records"
+
 // queryServerTimeout is the amount of time the server will allow a query to take.
 // NOTE: I have made the serverTimeout private. For the moment, I'm going to use the context.Context timer
 // to set timeouts via this private method.
@@ -114,14 +269,6 @@ func DeferPartialQueryFailures() QueryOption {
 	}
 }
 
-// MaxMemoryConsumptionPerQueryPerNode overrides the default maximum amount of memory a whole query
-// may allocate per node.
-func MaxMemoryConsumptionPerQueryPerNode(i uint64) QueryOption {
-	return func(q *queryOptions) {
-		q.requestProperties.Options["max_memory_consumption_per_query_per_node"] = i
-	}
-}
-
 // MaxMemoryConsumptionPerIterator overrides the default maximum amount of memory a query operator may allocate.
 func MaxMemoryConsumptionPerIterator(i uint64) QueryOption {
 	return func(q *queryOptions) {
@@ -249,8 +396,6 @@ query_bin_auto_size (QueryBinAutoSize): When evaluating the bin_auto() function,
 
 query_distribution_nodes_span (OptionQueryDistributionNodesSpanSize): If set, controls the way sub-query merge behaves: the executing node will introduce an additional level in the query hierarchy for each sub-group of nodes; the size of the sub-group is set by this option. [Int]
 query_enable_jit_stream (OptionEnableJitStream): If true, enabled JIT streams when sending data from managed code to native code. [Boolean]
-query_fanout_nodes_percent (OptionQueryFanoutNodesPercent): The percentage of nodes to fanout execution to. [Int]
-query_fanout_threads_percent (OptionQueryFanoutThreadsPercent): The percentage of threads to fanout execution to. [Int]
 query_language (OptionQueryLanguage): Controls how the query text is to be interpreted. ['csl','kql' or 'sql']
 query_max_entities_in_union (OptionMaxEntitiesToUnion): Overrides the default maximum number of columns a query is allowed to produce. [Long]
 query_now (OptionQueryNow): Overrides the datetime value returned by the now(0s) function. [DateTime]
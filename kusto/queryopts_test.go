@@ -0,0 +1,56 @@
+package kusto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributedQueryOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		option  QueryOption
+		key     string
+		want    interface{}
+		wantErr bool
+	}{
+		{desc: "QueryFanoutNodesPercent", option: QueryFanoutNodesPercent(50), key: "query_fanout_nodes_percent", want: 50},
+		{desc: "QueryFanoutNodesPercent at 100", option: QueryFanoutNodesPercent(100), key: "query_fanout_nodes_percent", want: 100},
+		{desc: "QueryFanoutNodesPercent at 0", option: QueryFanoutNodesPercent(0), wantErr: true},
+		{desc: "QueryFanoutNodesPercent over 100", option: QueryFanoutNodesPercent(101), wantErr: true},
+		{desc: "QueryFanoutThreadsPercent", option: QueryFanoutThreadsPercent(1), key: "query_fanout_threads_percent", want: 1},
+		{desc: "QueryFanoutThreadsPercent negative", option: QueryFanoutThreadsPercent(-5), wantErr: true},
+		{
+			desc:   "MaxMemoryConsumptionPerQueryPerNode",
+			option: MaxMemoryConsumptionPerQueryPerNode(8 << 30),
+			key:    "max_memory_consumption_per_query_per_node",
+			want:   uint64(8 << 30),
+		},
+		{desc: "MaxMemoryConsumptionPerQueryPerNode at 0", option: MaxMemoryConsumptionPerQueryPerNode(0), wantErr: true},
+		{desc: "RequestAppName", option: RequestAppName("analytics"), key: "request_app_name", want: "analytics"},
+		{desc: "RequestAppName empty", option: RequestAppName(" "), wantErr: true},
+		{desc: "RequestUser", option: RequestUser("user@example.com"), key: "request_user", want: "user@example.com"},
+		{desc: "RequestUser empty", option: RequestUser(""), wantErr: true},
+		{desc: "RequestDescription", option: RequestDescription("monthly report"), key: "request_description", want: "monthly report"},
+		{desc: "RequestDescription empty", option: RequestDescription(""), wantErr: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			opts := &queryOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}}}
+			err := test.option(opts)
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, opts.requestProperties.Options)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{test.key: test.want}, opts.requestProperties.Options)
+		})
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto/data/errors"
 	"github.com/Azure/azure-kusto-go/kusto/data/table"
@@ -76,8 +78,33 @@ type RowIterator struct {
 	nonPrimary map[frames.TableKind]v2.DataTable
 	// dsCompletion is the completion frame for a non-progressive query.
 	dsCompletion v2.DataSetCompletion
+	// hasCompletion indicates dsCompletion was received.
+	hasCompletion bool
 
 	columns table.Columns
+	// columnIndex is the index of columns shared by all rows.
+	columnIndex table.ColumnIndex
+
+	// maxRecords is the limit set with the MaxRecords() option, 0 if not set.
+	maxRecords int64
+	// verifyRowCount is set by the VerifyRowCount() option.
+	verifyRowCount bool
+	// records is the number of primary table rows returned by NextRowOrError().
+	records int64
+	// truncated indicates the service truncated the result set.
+	truncated bool
+	// truncationError is set by the MgmtTruncationError() option.
+	truncationError bool
+	// rowErrors is the number of inline errors returned by NextRowOrError().
+	rowErrors int64
+	// drained indicates NextRowOrError() returned io.EOF, so all rows were read and the query succeeded.
+	drained bool
+	// lastActivity is when data was last received from the service.
+	lastActivity time.Time
+	// sent is when the request was sent to the service.
+	sent time.Time
+	// received is when the response was fully received, zero until then.
+	received time.Time
 
 	// error holds an error that was encountered. Once this is set, all calls on Rowiterator will
 	// just return the error here.
@@ -103,8 +130,13 @@ func newRowIterator(ctx context.Context, cancel context.CancelFunc, execResp exe
 		inCompletion: make(chan send, 1),
 		inErr:        make(chan send),
 
-		rows:       make(chan Row, 1000),
-		nonPrimary: make(map[frames.TableKind]v2.DataTable),
+		rows:         make(chan Row, 1000),
+		nonPrimary:   make(map[frames.TableKind]v2.DataTable),
+		lastActivity: nower(),
+		sent:         execResp.sent,
+	}
+	if ri.sent.IsZero() {
+		ri.sent = ri.lastActivity
 	}
 	columnsReady := ri.start()
 	return ri, columnsReady
@@ -124,14 +156,18 @@ func (r *RowIterator) start() chan struct{} {
 			select {
 			case <-r.ctx.Done():
 			case sent := <-r.inColumns:
+				r.touch()
 				r.columns = sent.inColumns
+				r.columnIndex = sent.inColumns.Index()
 				sent.done()
 				closeDone()
 			case sent, ok := <-r.inRows:
 				if !ok {
+					r.markReceived()
 					close(r.rows)
 					return
 				}
+				r.touch()
 				if sent.inRows != nil {
 					for k, values := range sent.inRows {
 						select {
@@ -153,20 +189,25 @@ func (r *RowIterator) start() chan struct{} {
 				sent.done()
 			case sent := <-r.inProgress:
 				r.mu.Lock()
+				r.lastActivity = nower()
 				r.progress = sent.inProgress
 				sent.done()
 				r.mu.Unlock()
 			case sent := <-r.inNonPrimary:
 				r.mu.Lock()
+				r.lastActivity = nower()
 				r.nonPrimary[sent.inNonPrimary.TableKind] = sent.inNonPrimary
 				sent.done()
 				r.mu.Unlock()
 			case sent := <-r.inCompletion:
 				r.mu.Lock()
+				r.lastActivity = nower()
 				r.dsCompletion = sent.inCompletion
+				r.hasCompletion = true
 				sent.done()
 				r.mu.Unlock()
 			case sent := <-r.inErr:
+				r.markReceived()
 				r.setError(sent.inErr)
 				sent.done()
 				close(r.rows)
@@ -189,9 +230,26 @@ func (r *RowIterator) Mock(m *MockRows) error {
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 
 	r.mock = m
+	r.lastActivity = nower()
+	r.sent, r.received = r.lastActivity, r.lastActivity
 	return nil
 }
 
+// NewRowIteratorFromJSON creates a RowIterator from a Kusto v2 query response body that was captured elsewhere, such
+// as a response saved to disk. The body is decoded with the same frame decoder used by Client.Query(), so the
+// RowIterator behaves as if it was returned from a query. Always defer a Stop() call after receiving the RowIterator.
+func NewRowIteratorFromJSON(r io.Reader) (*RowIterator, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rc, ok := r.(io.ReadCloser)
+	if !ok {
+		rc = ioutil.NopCloser(r)
+	}
+
+	dec := &v2.Decoder{}
+	return newV2RowIterator(ctx, cancel, execResp{frameCh: dec.Decode(ctx, rc, errors.OpQuery)})
+}
+
 // Deprecated: Use DoOnRowOrError() instead for more robust error handling. In a future version, this will be removed, and NextRowOrError will replace it.
 // Do calls f for every row returned by the query. If f returns a non-nil error, iteration stops.
 // This method will fail on errors inline within the rows, even though they could potentially be recovered and more data might be available.
@@ -268,8 +326,16 @@ func (r *RowIterator) NextRowOrError() (row *table.Row, inlineError *errors.Erro
 		}
 		nextRow, err := r.mock.nextRow()
 		if err != nil {
+			if err == io.EOF {
+				r.mu.Lock()
+				r.drained = true
+				r.mu.Unlock()
+			}
 			return nil, nil, err
 		}
+		r.mu.Lock()
+		r.records++
+		r.mu.Unlock()
 		return nextRow, nil, nil
 	}
 
@@ -281,12 +347,43 @@ func (r *RowIterator) NextRowOrError() (row *table.Row, inlineError *errors.Erro
 			if err := r.getError(); err != nil {
 				return nil, nil, err
 			}
+			if err := r.checkRowCount(); err != nil {
+				r.setError(err)
+				return nil, nil, err
+			}
+			if err := r.checkTruncation(); err != nil {
+				r.setError(err)
+				return nil, nil, err
+			}
+			r.mu.Lock()
+			r.drained = true
+			r.mu.Unlock()
 			return nil, nil, io.EOF
 		}
+		r.mu.Lock()
+		switch {
+		case kvs.Error != nil:
+			r.rowErrors++
+			if kvs.Error.Kind == errors.KLimitsExceeded {
+				r.truncated = true
+			}
+		case kvs.Replace:
+			r.records = 1
+		default:
+			r.records++
+		}
+		if r.maxRecords > 0 && r.records >= r.maxRecords {
+			r.truncated = true
+		}
+		r.mu.Unlock()
+
 		if kvs.Error != nil {
 			return nil, kvs.Error, nil
 		}
-		return &table.Row{ColumnTypes: r.columns, Values: kvs.Values, Op: r.op, Replace: kvs.Replace}, nil, nil
+		row := table.NewRow(r.columns, r.columnIndex, kvs.Values)
+		row.Op = r.op
+		row.Replace = kvs.Replace
+		return row, nil, nil
 	}
 }
 
@@ -296,9 +393,16 @@ func (r *RowIterator) getError() error {
 	return r.error
 }
 
+// setError sets the final error of the RowIterator. The headers of the response are attached to errors that do not
+// have them, as the service reports most query failures inside the response.
 func (r *RowIterator) setError(e error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if _, ok := errors.ResponseHeaders(e); !ok && r.ResponseHeader != nil {
+		if ke, ok := errors.GetKustoError(e); ok {
+			ke.SetResponseHeaders(r.ResponseHeader)
+		}
+	}
 	r.error = e
 }
 
@@ -309,6 +413,53 @@ func (r *RowIterator) Progress() float64 {
 	return r.progress.TableProgress
 }
 
+// Truncated indicates that the service may have truncated the result set. This is set when the service returned a
+// KLimitsExceeded error inline with the rows (such as when going over TruncationMaxSize()), when the number of rows
+// returned reached the MaxRecords() limit or when the QueryStatus table of a Mgmt() result reports it. This is only
+// valid after NextRowOrError() has returned io.EOF.
+func (r *RowIterator) Truncated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.truncated
+}
+
+// RowCount returns the number of rows of the primary table returned so far. If rows were replaced by a progressive
+// query, only the rows after the last replacement are counted. Once iteration is done, this is the number of rows
+// in the result. Inline errors are not counted.
+func (r *RowIterator) RowCount() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.records
+}
+
+// Empty indicates that the query succeeded and returned no rows. It only returns true once all rows were read, such as
+// after Do() or DoOnRowOrError() returned nil or NextRowOrError() returned io.EOF. A result that only had inline
+// errors is not empty. Use RowCount() to know how many rows were returned. The other tables of the response,
+// such as GetQueryCompletionInformation(), are available for empty results as well.
+func (r *RowIterator) Empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.drained && r.records == 0 && r.rowErrors == 0
+}
+
+// LastActivity returns when data was last received from the service for this query, starting with the response
+// headers. Rows, progress updates and other tables all count as activity. Checking it periodically while iterating
+// allows distinguishing a query that is slow to return rows from a stalled connection, which can then be abandoned
+// with Stop(). Rows are read from the service ahead of the caller, so there is no activity while the caller is slow
+// to consume rows that were already received.
+func (r *RowIterator) LastActivity() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastActivity
+}
+
+// touch records that data was received from the service.
+func (r *RowIterator) touch() {
+	r.mu.Lock()
+	r.lastActivity = nower()
+	r.mu.Unlock()
+}
+
 // Progressive indicates if the RowIterator is unpacking progressive (streaming) frames.
 func (r *RowIterator) Progressive() bool {
 	return r.progressive
@@ -0,0 +1,405 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	v2 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowIteratorTruncated(t *testing.T) {
+	t.Parallel()
+
+	rows := []value.Values{
+		{value.Long{Value: 1, Valid: true}},
+		{value.Long{Value: 2, Valid: true}},
+	}
+
+	tests := []struct {
+		desc       string
+		maxRecords int64
+		rowErrors  []errors.Error
+		want       bool
+	}{
+		{
+			desc: "No limits",
+		},
+		{
+			desc:       "Under MaxRecords",
+			maxRecords: 3,
+		},
+		{
+			desc:       "Reached MaxRecords",
+			maxRecords: 2,
+			want:       true,
+		},
+		{
+			desc:      "LimitsExceeded inline error",
+			rowErrors: []errors.Error{*errors.ES(errors.OpQuery, errors.KLimitsExceeded, "E_QUERY_RESULT_SET_TOO_LARGE")},
+			want:      true,
+		},
+		{
+			desc:      "Other inline error",
+			rowErrors: []errors.Error{*errors.ES(errors.OpQuery, errors.KInternal, "some error")},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			iter, columnsReady := newRowIterator(ctx, cancel, execResp{}, v2.DataSetHeader{}, errors.OpQuery)
+			defer iter.Stop()
+			iter.maxRecords = test.maxRecords
+
+			iter.inColumns <- send{inColumns: table.Columns{{Name: "Count", Type: types.Long}}}
+			<-columnsReady
+			iter.inRows <- send{inRows: rows, inRowErrors: test.rowErrors}
+			close(iter.inRows)
+
+			err := iter.DoOnRowOrError(func(*table.Row, *errors.Error) error { return nil })
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, iter.Truncated())
+		})
+	}
+}
+
+func TestNewRowIteratorFromJSON(t *testing.T) {
+	t.Parallel()
+
+	body := `[
+  {"FrameType": "dataSetHeader", "IsProgressive": false, "Version": "v2.0"},
+  {
+    "FrameType": "DataTable",
+    "TableId": 1,
+    "TableKind": "PrimaryResult",
+    "TableName": "PrimaryResult",
+    "Columns": [{"ColumnName": "x", "ColumnType": "long"}, {"ColumnName": "y", "ColumnType": "string"}],
+    "Rows": [[1, "a"], [2, "b"]]
+  },
+  {"FrameType": "DataSetCompletion", "HasErrors": false, "Cancelled": false}
+]`
+
+	iter, err := NewRowIteratorFromJSON(strings.NewReader(body))
+	require.NoError(t, err)
+	defer iter.Stop()
+
+	var got []value.Values
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		require.Nil(t, e)
+		got = append(got, r.Values)
+		return nil
+	})
+	require.NoError(t, err)
+
+	want := []value.Values{
+		{value.Long{Value: 1, Valid: true}, value.String{Value: "a", Valid: true}},
+		{value.Long{Value: 2, Valid: true}, value.String{Value: "b", Valid: true}},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestNewRowIteratorFromJSONBadInput(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRowIteratorFromJSON(strings.NewReader("not json"))
+	assert.Error(t, err)
+}
+
+func TestRowIteratorEmpty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		rows      []value.Values
+		rowErrors []errors.Error
+		finalErr  error
+		wantCount int64
+		want      bool
+	}{
+		{
+			desc: "No rows",
+			want: true,
+		},
+		{
+			desc:      "Rows",
+			rows:      []value.Values{{value.Long{Value: 1, Valid: true}}, {value.Long{Value: 2, Valid: true}}},
+			wantCount: 2,
+		},
+		{
+			desc:      "Only inline errors",
+			rowErrors: []errors.Error{*errors.ES(errors.OpQuery, errors.KInternal, "some error")},
+		},
+		{
+			desc:     "Final error",
+			finalErr: errors.ES(errors.OpQuery, errors.KInternal, "final error"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			iter, columnsReady := newRowIterator(ctx, cancel, execResp{}, v2.DataSetHeader{}, errors.OpQuery)
+			defer iter.Stop()
+
+			iter.inColumns <- send{inColumns: table.Columns{{Name: "Count", Type: types.Long}}}
+			<-columnsReady
+			assert.False(t, iter.Empty(), "Empty() before iteration")
+
+			iter.inRows <- send{inRows: test.rows, inRowErrors: test.rowErrors}
+			if test.finalErr != nil {
+				iter.inErr <- send{inErr: test.finalErr}
+			} else {
+				close(iter.inRows)
+			}
+
+			err := iter.DoOnRowOrError(func(*table.Row, *errors.Error) error { return nil })
+			if test.finalErr != nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantCount, iter.RowCount())
+			assert.Equal(t, test.want, iter.Empty())
+		})
+	}
+}
+
+func TestRowIteratorLastActivity(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	iter, columnsReady := newRowIterator(ctx, cancel, execResp{}, v2.DataSetHeader{IsProgressive: true}, errors.OpQuery)
+	defer iter.Stop()
+
+	iter.inColumns <- send{inColumns: table.Columns{{Name: "Count", Type: types.Long}}}
+	<-columnsReady
+
+	// sendAndWait sends s to ch and returns once the RowIterator processed it.
+	sendAndWait := func(ch chan send, s send) {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		s.wg = wg
+		ch <- s
+		wg.Wait()
+	}
+
+	last := iter.LastActivity()
+	assert.False(t, last.IsZero())
+
+	time.Sleep(5 * time.Millisecond)
+	sendAndWait(iter.inProgress, send{inProgress: v2.TableProgress{TableProgress: 50}})
+	assert.True(t, iter.LastActivity().After(last), "progress must count as activity")
+	last = iter.LastActivity()
+
+	time.Sleep(5 * time.Millisecond)
+	sendAndWait(iter.inRows, send{inRows: []value.Values{{value.Long{Value: 1, Valid: true}}}})
+	assert.True(t, iter.LastActivity().After(last), "rows must count as activity")
+	last = iter.LastActivity()
+
+	// Reading rows that were already received is not activity.
+	time.Sleep(5 * time.Millisecond)
+	_, _, err := iter.NextRowOrError()
+	require.NoError(t, err)
+	assert.Equal(t, last, iter.LastActivity())
+}
+
+func TestRowIteratorEmptyCompletionInformation(t *testing.T) {
+	t.Parallel()
+
+	body := `[
+  {"FrameType": "dataSetHeader", "IsProgressive": false, "Version": "v2.0"},
+  {
+    "FrameType": "DataTable",
+    "TableId": 1,
+    "TableKind": "PrimaryResult",
+    "TableName": "PrimaryResult",
+    "Columns": [{"ColumnName": "x", "ColumnType": "long"}],
+    "Rows": []
+  },
+  {
+    "FrameType": "DataTable",
+    "TableId": 2,
+    "TableKind": "QueryCompletionInformation",
+    "TableName": "QueryCompletionInformation",
+    "Columns": [{"ColumnName": "EventTypeName", "ColumnType": "string"}],
+    "Rows": [["QueryInfo"]]
+  },
+  {"FrameType": "DataSetCompletion", "HasErrors": false, "Cancelled": false}
+]`
+
+	iter, err := NewRowIteratorFromJSON(strings.NewReader(body))
+	require.NoError(t, err)
+	defer iter.Stop()
+
+	require.NoError(t, iter.DoOnRowOrError(func(*table.Row, *errors.Error) error { return nil }))
+	assert.True(t, iter.Empty())
+	assert.EqualValues(t, 0, iter.RowCount())
+
+	info, err := iter.GetQueryCompletionInformation()
+	require.NoError(t, err)
+	assert.Len(t, info.KustoRows, 1)
+}
+
+func TestRowIteratorTiming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc   string
+		info   string
+		want   time.Duration
+		wantOk bool
+	}{
+		{
+			desc: "Execution time reported",
+			info: `{
+    "FrameType": "DataTable",
+    "TableId": 2,
+    "TableKind": "QueryCompletionInformation",
+    "TableName": "QueryCompletionInformation",
+    "Columns": [{"ColumnName": "EventTypeName", "ColumnType": "string"}, {"ColumnName": "Payload", "ColumnType": "string"}],
+    "Rows": [
+      ["QueryInfo", "{\"Count\":1,\"Text\":\"Query completed successfully\"}"],
+      ["QueryResourceConsumption", "{\"ExecutionTime\":1.5,\"resource_usage\":{}}"]
+    ]
+  },`,
+			want:   1500 * time.Millisecond,
+			wantOk: true,
+		},
+		{
+			desc: "No execution time",
+			info: `{
+    "FrameType": "DataTable",
+    "TableId": 2,
+    "TableKind": "QueryCompletionInformation",
+    "TableName": "QueryCompletionInformation",
+    "Columns": [{"ColumnName": "EventTypeName", "ColumnType": "string"}, {"ColumnName": "Payload", "ColumnType": "string"}],
+    "Rows": [["QueryInfo", "{\"Count\":1,\"Text\":\"Query completed successfully\"}"]]
+  },`,
+		},
+		{
+			desc: "No completion information",
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			body := `[
+  {"FrameType": "dataSetHeader", "IsProgressive": false, "Version": "v2.0"},
+  {
+    "FrameType": "DataTable",
+    "TableId": 1,
+    "TableKind": "PrimaryResult",
+    "TableName": "PrimaryResult",
+    "Columns": [{"ColumnName": "x", "ColumnType": "long"}],
+    "Rows": [[1]]
+  },
+  ` + test.info + `
+  {"FrameType": "DataSetCompletion", "HasErrors": false, "Cancelled": false}
+]`
+
+			iter, err := NewRowIteratorFromJSON(strings.NewReader(body))
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			require.NoError(t, iter.DoOnRowOrError(func(*table.Row, *errors.Error) error { return nil }))
+
+			// Once the response was fully received, the duration does not change.
+			d := iter.ClientDuration()
+			assert.True(t, d > 0)
+			time.Sleep(5 * time.Millisecond)
+			assert.Equal(t, d, iter.ClientDuration())
+
+			got, ok := iter.ServerExecutionTime()
+			assert.Equal(t, test.wantOk, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVerifyRowCount(t *testing.T) {
+	t.Parallel()
+
+	// verifyResponse returns a response with rows rows and, if reported is not negative, a QueryCompletionInformation
+	// table that reports reported rows.
+	verifyResponse := func(rows, reported int) string {
+		values := make([]string, rows)
+		for i := range values {
+			values[i] = fmt.Sprintf("[%d]", i)
+		}
+		info := ""
+		if reported >= 0 {
+			info = fmt.Sprintf(`{"FrameType":"DataTable","TableId":2,"TableKind":"QueryCompletionInformation","TableName":"QueryCompletionInformation",`+
+				`"Columns":[{"ColumnName":"EventTypeName","ColumnType":"string"},{"ColumnName":"Payload","ColumnType":"string"}],`+
+				`"Rows":[["QueryResourceConsumption","{\"ExecutionTime\":0.1,\"dataset_statistics\":[{\"table_row_count\":%d,\"table_size\":64}]}"]]},`, reported)
+		}
+		return `[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"x","ColumnType":"long"}],"Rows":[` + strings.Join(values, ",") + `]},
+` + info + `
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`
+	}
+
+	tests := []struct {
+		desc       string
+		rows       int
+		reported   int
+		options    []QueryOption
+		wantServer int64
+		wantOk     bool
+		err        bool
+	}{
+		{desc: "Matching", rows: 3, reported: 3, options: []QueryOption{VerifyRowCount()}, wantServer: 3, wantOk: true},
+		{desc: "Mismatch", rows: 2, reported: 3, options: []QueryOption{VerifyRowCount()}, wantServer: 3, wantOk: true, err: true},
+		{desc: "Not reported", rows: 2, reported: -1, options: []QueryOption{VerifyRowCount()}, err: true},
+		{desc: "Mismatch without option", rows: 2, reported: 3, wantServer: 3, wantOk: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onQuery: func(string) (string, error) {
+				return verifyResponse(test.rows, test.reported), nil
+			}}}
+
+			iter, err := client.Query(context.Background(), "db", NewStmt("table"), test.options...)
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			err = iter.DoOnRowOrError(func(_ *table.Row, e *errors.Error) error {
+				require.Nil(t, e)
+				return nil
+			})
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			got, ok := iter.ServerRowCount()
+			assert.Equal(t, test.wantOk, ok)
+			assert.Equal(t, test.wantServer, got)
+		})
+	}
+}
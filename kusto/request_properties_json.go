@@ -0,0 +1,195 @@
+package kusto
+
+// request_properties_json.go holds RequestPropertiesJSON() and MgmtRequestPropertiesJSON(), which read the request
+// properties of calls from JSON, like a configuration file.
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+)
+
+// propertyKind is the JSON kind of the value of a request property.
+type propertyKind int
+
+const (
+	propertyBool propertyKind = iota
+	propertyNumber
+	propertyString
+	propertyArray
+)
+
+func (k propertyKind) String() string {
+	switch k {
+	case propertyBool:
+		return "a boolean"
+	case propertyNumber:
+		return "a number"
+	case propertyString:
+		return "a string"
+	case propertyArray:
+		return "an array"
+	}
+	return "unknown"
+}
+
+// matches reports if v, as decoded by encoding/json with UseNumber(), is of kind k.
+func (k propertyKind) matches(v interface{}) bool {
+	switch v.(type) {
+	case bool:
+		return k == propertyBool
+	case json.Number:
+		return k == propertyNumber
+	case string:
+		return k == propertyString
+	case []interface{}:
+		return k == propertyArray
+	}
+	return false
+}
+
+// knownProperties are the request properties RequestPropertiesJSON() validates, with the kind of their values.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/api/netfx/request-properties
+var knownProperties = map[string]propertyKind{
+	"block_splitting_enabled":                                   propertyBool,
+	"client_max_redirect_count":                                 propertyNumber,
+	"deferpartialqueryfailures":                                 propertyBool,
+	"debug_query_externaldata_projection_fusion_disabled":       propertyBool,
+	"debug_query_fanout_threads_percent_external_data":          propertyNumber,
+	"max_memory_consumption_per_query_per_node":                 propertyNumber,
+	"maxmemoryconsumptionperiterator":                           propertyNumber,
+	"maxoutputcolumns":                                          propertyNumber,
+	"norequesttimeout":                                          propertyBool,
+	"notruncation":                                              propertyBool,
+	"push_selection_through_aggregation":                        propertyBool,
+	"query_admin_super_slacker_mode":                            propertyBool,
+	"query_cursor_after_default":                                propertyString,
+	"query_cursor_allow_referencing_streaming_ingestion_tables": propertyBool,
+	"query_cursor_before_or_at_default":                         propertyString,
+	"query_cursor_current":                                      propertyString,
+	"query_cursor_scoped_tables":                                propertyArray,
+	"query_datascope":                                           propertyString,
+	"query_datetimescope_column":                                propertyString,
+	"query_datetimescope_from":                                  propertyString,
+	"query_datetimescope_to":                                    propertyString,
+	"query_fanout_nodes_percent":                                propertyNumber,
+	"query_fanout_threads_percent":                              propertyNumber,
+	"query_force_row_level_security":                            propertyBool,
+	"query_language":                                            propertyString,
+	"query_max_entities_in_union":                               propertyNumber,
+	"query_now":                                                 propertyString,
+	"query_results_cache_force_refresh":                         propertyBool,
+	"query_results_cache_max_age":                               propertyString,
+	"query_take_max_records":                                    propertyNumber,
+	"query_weakconsistency_session_id":                          propertyString,
+	"queryconsistency":                                          propertyString,
+	"request_app_name":                                          propertyString,
+	"request_block_row_level_security":                          propertyBool,
+	"request_callout_disabled":                                  propertyBool,
+	"request_description":                                       propertyString,
+	"request_external_table_disabled":                           propertyBool,
+	"request_readonly":                                          propertyBool,
+	"request_remote_entities_disabled":                          propertyBool,
+	"request_sandboxed_execution_disabled":                      propertyBool,
+	"request_user":                                              propertyString,
+	"results_progressive_enabled":                               propertyBool,
+	"servertimeout":                                             propertyString,
+	"truncationmaxrecords":                                      propertyNumber,
+	"truncationmaxsize":                                         propertyNumber,
+}
+
+// propertiesJSON is the JSON object read by RequestPropertiesJSON().
+type propertiesJSON struct {
+	Options    map[string]interface{}
+	Parameters map[string]string
+}
+
+// RequestPropertiesJSON sets the request properties of the query to the ones in the JSON object read from r, which has
+// the same layout as the ClientRequestProperties of the other Kusto SDKs:
+//
+//	{
+//		"Options": {"servertimeout": "00:10:00", "queryconsistency": "weakconsistency"},
+//		"Parameters": {"name": "value"}
+//	}
+//
+// This allows operators to change the behavior of queries through configuration. r is read when
+// RequestPropertiesJSON() is called, so the option can be used for any number of calls. The values of the documented
+// properties are checked to be of the right JSON kind. Properties that are not known to the SDK are still sent, as
+// the service may support them. If onUnknown is not nil, it is called with their sorted names when r is read, to log or
+// reject them: if it returns an error, the option returns it, failing the calls it is used with. Properties are applied
+// in the order of the options, so options after this one override the properties it sets, and it overrides the ones
+// before it.
+func RequestPropertiesJSON(r io.Reader, onUnknown func(names []string) error) QueryOption {
+	props, err := readPropertiesJSON(r, "RequestPropertiesJSON", onUnknown)
+	return func(q *queryOptions) error {
+		if err != nil {
+			return err
+		}
+		props.apply(q.requestProperties)
+		return nil
+	}
+}
+
+// MgmtRequestPropertiesJSON is RequestPropertiesJSON() for management commands.
+func MgmtRequestPropertiesJSON(r io.Reader, onUnknown func(names []string) error) MgmtOption {
+	props, err := readPropertiesJSON(r, "MgmtRequestPropertiesJSON", onUnknown)
+	return func(m *mgmtOptions) error {
+		if err != nil {
+			return err
+		}
+		props.apply(m.requestProperties)
+		return nil
+	}
+}
+
+// readPropertiesJSON reads the properties JSON object from r for the option named name and validates it.
+func readPropertiesJSON(r io.Reader, name string, onUnknown func(names []string) error) (propertiesJSON, error) {
+	var props propertiesJSON
+	dec := json.NewDecoder(r)
+	dec.UseNumber() // Keeps numbers as they are written, instead of sending large ones in float64 notation.
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&props); err != nil {
+		return propertiesJSON{}, errors.ES(errors.OpQuery, errors.KClientArgs, "%s(): could not decode the request properties: %s", name, err).SetNoRetry()
+	}
+
+	var unknown []string
+	for key, v := range props.Options {
+		kind, ok := knownProperties[key]
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if !kind.matches(v) {
+			return propertiesJSON{}, errors.ES(errors.OpQuery, errors.KClientArgs, "%s(): request property %q must be %s, was %v", name, key, kind, v).SetNoRetry()
+		}
+	}
+	if len(unknown) > 0 && onUnknown != nil {
+		sort.Strings(unknown)
+		if err := onUnknown(unknown); err != nil {
+			return propertiesJSON{}, errors.ES(errors.OpQuery, errors.KClientArgs, "%s(): unknown request properties %s: %s", name, strings.Join(unknown, ", "), err).SetNoRetry()
+		}
+	}
+	return props, nil
+}
+
+// apply sets the properties in rp. The parameters of rp are copied before they are changed, as they can belong to a
+// Stmt.
+func (p propertiesJSON) apply(rp *requestProperties) {
+	for k, v := range p.Options {
+		rp.Options[k] = v
+	}
+	if len(p.Parameters) == 0 {
+		return
+	}
+	params := make(map[string]string, len(rp.Parameters)+len(p.Parameters))
+	for k, v := range rp.Parameters {
+		params[k] = v
+	}
+	for k, v := range p.Parameters {
+		params[k] = v
+	}
+	rp.Parameters = params
+}
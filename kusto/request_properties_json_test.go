@@ -0,0 +1,100 @@
+package kusto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestPropertiesJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc          string
+		json          string
+		failOnUnknown bool
+		wantOptions   map[string]interface{}
+		wantParams    map[string]string
+		wantUnknown   []string
+		err           bool
+	}{
+		{
+			desc:        "Options and parameters",
+			json:        `{"Options":{"servertimeout":"00:10:00","notruncation":true,"truncationmaxrecords":5000000000},"Parameters":{"p":"v"}}`,
+			wantOptions: map[string]interface{}{"servertimeout": "00:10:00", "notruncation": true, "truncationmaxrecords": json.Number("5000000000")},
+			wantParams:  map[string]string{"a": "1", "p": "v"},
+		},
+		{
+			desc:        "Unknown property is sent",
+			json:        `{"Options":{"some_new_property":1,"another_property":true,"notruncation":true}}`,
+			wantOptions: map[string]interface{}{"some_new_property": json.Number("1"), "another_property": true, "notruncation": true},
+			wantParams:  map[string]string{"a": "1"},
+			wantUnknown: []string{"another_property", "some_new_property"},
+		},
+		{
+			desc:          "Unknown property fails",
+			json:          `{"Options":{"some_new_property":1}}`,
+			failOnUnknown: true,
+			err:           true,
+		},
+		{
+			desc: "Wrong kind",
+			json: `{"Options":{"notruncation":"yes"}}`,
+			err:  true,
+		},
+		{
+			desc: "Unknown field",
+			json: `{"Option":{}}`,
+			err:  true,
+		},
+		{
+			desc: "Invalid JSON",
+			json: `{`,
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stmtParams := map[string]string{"a": "1"}
+			q := &queryOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}, Parameters: stmtParams}}
+
+			var gotUnknown []string
+			onUnknown := func(names []string) error {
+				gotUnknown = names
+				if test.failOnUnknown {
+					return fmt.Errorf("%v", names)
+				}
+				return nil
+			}
+
+			option := RequestPropertiesJSON(strings.NewReader(test.json), onUnknown)
+			err := option(q)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantOptions, q.requestProperties.Options)
+			assert.Equal(t, test.wantParams, q.requestProperties.Parameters)
+			assert.Equal(t, map[string]string{"a": "1"}, stmtParams)
+			assert.Equal(t, test.wantUnknown, gotUnknown)
+
+			// The option can be applied again, as the JSON was read when it was created.
+			again := &queryOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}}}
+			require.NoError(t, option(again))
+			assert.Equal(t, test.wantOptions, again.requestProperties.Options)
+		})
+	}
+
+	m := &mgmtOptions{requestProperties: &requestProperties{Options: map[string]interface{}{}}}
+	require.NoError(t, MgmtRequestPropertiesJSON(strings.NewReader(`{"Options":{"request_readonly":true,"unknown":1}}`), nil)(m))
+	assert.Equal(t, map[string]interface{}{"request_readonly": true, "unknown": json.Number("1")}, m.requestProperties.Options)
+}
@@ -0,0 +1,23 @@
+package kusto
+
+// response_compression.go holds the option that controls the compression of query and management responses.
+
+// WithResponseCompression sets if the client asks the service to gzip compress the responses of queries and management
+// commands, which is on by default. Compressed responses are decompressed by the client as they are read, so large
+// results download faster and use less bandwidth at the cost of some CPU on both ends. Pass false for setups where
+// decompressing costs more than the transfer, such as a client next to the cluster, or where a proxy cannot handle
+// compressed responses. When off, the client asks for uncompressed responses, including from the automatic compression
+// of the Go http.Transport.
+func WithResponseCompression(enabled bool) Option {
+	return func(c *Client) {
+		c.noResponseCompression = !enabled
+	}
+}
+
+// acceptEncoding returns the Accept-Encoding header to send with queries and management commands.
+func (c *conn) acceptEncoding() string {
+	if c.noResponseCompression {
+		return "identity"
+	}
+	return "gzip"
+}
@@ -0,0 +1,79 @@
+package kusto
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponseCompression(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc               string
+		options            []Option
+		wantAcceptEncoding string
+	}{
+		{desc: "Compressed by default", wantAcceptEncoding: "gzip"},
+		{desc: "Compression on", options: []Option{WithResponseCompression(true)}, wantAcceptEncoding: "gzip"},
+		{desc: "Compression off", options: []Option{WithResponseCompression(false)}, wantAcceptEncoding: "identity"},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mu := sync.Mutex{}
+			var acceptEncoding string
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				mu.Unlock()
+
+				w.Header().Set("Content-Type", "application/json")
+				if r.Header.Get("Accept-Encoding") != "gzip" {
+					fmt.Fprint(w, splitResponse("plain"))
+					return
+				}
+				w.Header().Set("Content-Encoding", "gzip")
+				zw := gzip.NewWriter(w)
+				fmt.Fprint(zw, splitResponse("compressed"))
+				zw.Close()
+			}))
+			defer server.Close()
+
+			options := append([]Option{WithHttpClient(server.Client())}, test.options...)
+			client, err := New(server.URL, Authorization{Authorizer: autorest.NewBasicAuthorizer("", "")}, options...)
+			require.NoError(t, err)
+
+			iter, err := client.Query(context.Background(), "db", NewStmt("MyTable"))
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			var got []string
+			err = iter.Do(func(r *table.Row) error {
+				got = append(got, r.Values[0].(value.String).Value)
+				return nil
+			})
+			require.NoError(t, err)
+
+			want := "plain"
+			if test.wantAcceptEncoding == "gzip" {
+				want = "compressed"
+			}
+			assert.Equal(t, test.wantAcceptEncoding, acceptEncoding)
+			assert.Equal(t, []string{want}, got)
+		})
+	}
+}
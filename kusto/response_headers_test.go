@@ -0,0 +1,48 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseHeadersOnError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-activity-id", "activity")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"code": "BadRequest", "message": "bad query"}}`)
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL, Authorization{Authorizer: autorest.NewBasicAuthorizer("", "")}, WithHttpClient(server.Client()))
+	require.NoError(t, err)
+
+	_, err = client.Query(context.Background(), "db", NewStmt("MyTable"))
+	require.Error(t, err)
+
+	headers, ok := errors.ResponseHeaders(err)
+	require.True(t, ok)
+	assert.Equal(t, "activity", headers.Get("x-ms-activity-id"))
+	assert.Equal(t, "[REDACTED]", headers.Get("Set-Cookie"))
+}
+
+func TestRowIteratorErrorHeaders(t *testing.T) {
+	t.Parallel()
+
+	iter := &RowIterator{ResponseHeader: http.Header{"X-Ms-Activity-Id": {"activity"}}}
+	iter.setError(errors.ES(errors.OpQuery, errors.KInternal, "query failed"))
+
+	headers, ok := errors.ResponseHeaders(iter.getError())
+	require.True(t, ok)
+	assert.Equal(t, "activity", headers.Get("x-ms-activity-id"))
+}
@@ -0,0 +1,94 @@
+package kusto
+
+// retention_policy.go holds a helper to read the effective retention policy of a database or table.
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+)
+
+// RetentionPolicy is the retention policy of a database or table. It controls how long data is kept before the
+// service removes it.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/retentionpolicy
+type RetentionPolicy struct {
+	// SoftDeletePeriod is how long data is kept after it was ingested. Zero means the period is not set and data is
+	// kept forever.
+	SoftDeletePeriod time.Duration
+	// Recoverability is "Enabled" if data can be recovered for 14 days after it was deleted, or "Disabled".
+	Recoverability string
+}
+
+// retentionPolicyJSON is the JSON representation of RetentionPolicy used by the service.
+type retentionPolicyJSON struct {
+	SoftDeletePeriod policyTimespan
+	Recoverability   string
+}
+
+// ShowRetentionPolicy returns the effective retention policy of tableName in db: the policy of the table, or the policy
+// of db if the table has none. If tableName is empty, the policy of the database is returned. If no policy applies,
+// the zero value is returned, which means data is kept forever.
+func (c *Client) ShowRetentionPolicy(ctx context.Context, db, tableName string) (RetentionPolicy, error) {
+	policy, err := c.showEffectivePolicy(ctx, db, tableName, "retention")
+	if err != nil {
+		return RetentionPolicy{}, err
+	}
+	if policy == "" {
+		return RetentionPolicy{}, nil
+	}
+
+	j := retentionPolicyJSON{}
+	if err := json.Unmarshal([]byte(policy), &j); err != nil {
+		return RetentionPolicy{}, errors.ES(errors.OpMgmt, errors.KInternal, "could not parse the retention policy %q: %s", policy, err)
+	}
+	return RetentionPolicy{SoftDeletePeriod: time.Duration(j.SoftDeletePeriod), Recoverability: j.Recoverability}, nil
+}
+
+// showEffectivePolicy returns the JSON of the kind policy of tableName in db, falling back to the policy of db if
+// the table has none. It is empty if neither has a policy set.
+func (c *Client) showEffectivePolicy(ctx context.Context, db, tableName, kind string) (string, error) {
+	policy, err := c.showPolicy(ctx, db, tableName, kind)
+	if err != nil {
+		return "", err
+	}
+	if (policy == "" || policy == "null") && tableName != "" {
+		policy, err = c.showPolicy(ctx, db, "", kind)
+		if err != nil {
+			return "", err
+		}
+	}
+	if policy == "null" {
+		return "", nil
+	}
+	return policy, nil
+}
+
+// policyTimespan is a timespan in the JSON of a policy. The service writes it either as a string,
+// like "3.00:00:00", or as an object holding the string in its "Value" field.
+type policyTimespan time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *policyTimespan) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		obj := struct {
+			Value string
+		}{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		s = obj.Value
+	}
+
+	ts := value.Timespan{}
+	if s != "" {
+		if err := ts.Unmarshal(s); err != nil {
+			return err
+		}
+	}
+	*p = policyTimespan(ts.Value)
+	return nil
+}
@@ -0,0 +1,84 @@
+package kusto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowRetentionPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tableQuery = ".show table ['table'] policy retention"
+		dbQuery    = ".show database ['db'] policy retention"
+	)
+
+	tests := []struct {
+		desc        string
+		table       string
+		policies    map[string]string
+		wantQueries []string
+		want        RetentionPolicy
+		err         bool
+	}{
+		{
+			desc:        "Table policy",
+			table:       "table",
+			policies:    map[string]string{tableQuery: `{"SoftDeletePeriod":"30.00:00:00","Recoverability":"Enabled"}`},
+			wantQueries: []string{tableQuery},
+			want:        RetentionPolicy{SoftDeletePeriod: 30 * 24 * time.Hour, Recoverability: "Enabled"},
+		},
+		{
+			desc:        "Database policy for table",
+			table:       "table",
+			policies:    map[string]string{tableQuery: "null", dbQuery: `{"SoftDeletePeriod":"365.00:00:00","Recoverability":"Disabled"}`},
+			wantQueries: []string{tableQuery, dbQuery},
+			want:        RetentionPolicy{SoftDeletePeriod: 365 * 24 * time.Hour, Recoverability: "Disabled"},
+		},
+		{
+			desc:        "Database policy",
+			policies:    map[string]string{dbQuery: `{"SoftDeletePeriod":"1.12:00:00"}`},
+			wantQueries: []string{dbQuery},
+			want:        RetentionPolicy{SoftDeletePeriod: 36 * time.Hour},
+		},
+		{
+			desc:        "No policy",
+			table:       "table",
+			policies:    map[string]string{tableQuery: "null", dbQuery: "null"},
+			wantQueries: []string{tableQuery, dbQuery},
+		},
+		{
+			desc:        "Bad policy",
+			table:       "table",
+			policies:    map[string]string{tableQuery: `{"SoftDeletePeriod":"forever"}`},
+			wantQueries: []string{tableQuery},
+			err:         true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQueries []string
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				gotQueries = append(gotQueries, query)
+				return policyResponse(test.policies[query]), nil
+			}}}
+
+			got, err := client.ShowRetentionPolicy(context.Background(), "db", test.table)
+			assert.Equal(t, test.wantQueries, gotQueries)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package kusto
+
+// row_channel.go holds RowIterator.Channel(), which streams the rows of a RowIterator over a channel.
+
+import (
+	"context"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+)
+
+// RowResult is a row or an error received from RowIterator.Channel(). Exactly one of Row and Err is set.
+type RowResult struct {
+	// Row is the next row of the result.
+	Row *table.Row
+	// Err is an error inline within the rows, after which more rows can follow, or the error that ended the iteration,
+	// which is the last result before the channel is closed.
+	Err error
+}
+
+// Channel reads the rows of the RowIterator on a separate goroutine and sends them to the returned channel, so they
+// can be read with range. Errors inline within the rows are sent as a RowResult with Err set and the iteration
+// continues, as with DoOnRowOrError(). Other errors are sent as the last RowResult. The channel is closed when all
+// rows were sent or the iteration failed.
+// If ctx is done, the RowIterator is stopped, releasing its resources, and the channel is closed without sending
+// the rest of the rows. Stop the RowIterator or cancel ctx if the channel is not read to its end.
+// The RowIterator must not be read in any other way after calling Channel. An error is returned if the RowIterator
+// already failed.
+func (r *RowIterator) Channel(ctx context.Context) (<-chan RowResult, error) {
+	if err := r.getError(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan RowResult)
+	go func() {
+		defer close(ch)
+
+		send := func(res RowResult) bool {
+			select {
+			case <-ctx.Done():
+				r.Stop()
+				return false
+			case ch <- res:
+				return true
+			}
+		}
+
+		for {
+			if ctx.Err() != nil {
+				r.Stop()
+				return
+			}
+
+			row, inlineErr, err := r.NextRowOrError()
+			switch {
+			case err == io.EOF:
+				return
+			case err != nil:
+				send(RowResult{Err: err})
+				return
+			case inlineErr != nil:
+				if !send(RowResult{Err: inlineErr}) {
+					return
+				}
+			default:
+				if !send(RowResult{Row: row}) {
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
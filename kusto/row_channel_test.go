@@ -0,0 +1,92 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIterator returns a RowIterator returning a row for each of names, followed by err if it is not nil.
+func mockIterator(t *testing.T, names []string, err error) *RowIterator {
+	m, e := NewMockRows(table.Columns{{Name: "Name", Type: types.String}})
+	require.NoError(t, e)
+	for _, name := range names {
+		require.NoError(t, m.Row(value.Values{value.String{Value: name, Valid: true}}))
+	}
+	if err != nil {
+		require.NoError(t, m.Error(err))
+	}
+
+	iter := &RowIterator{}
+	require.NoError(t, iter.Mock(m))
+	return iter
+}
+
+func TestRowIteratorChannel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		err      error
+		wantRows []string
+		wantErr  bool
+	}{
+		{desc: "Success", wantRows: []string{"a", "b", "c"}},
+		{desc: "No rows"},
+		{desc: "Error", err: fmt.Errorf("query failed"), wantRows: []string{"a"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			iter := mockIterator(t, test.wantRows, test.err)
+			defer iter.Stop()
+
+			ch, err := iter.Channel(context.Background())
+			require.NoError(t, err)
+
+			var gotRows []string
+			var gotErr error
+			for res := range ch {
+				if res.Err != nil {
+					gotErr = res.Err
+					continue
+				}
+				gotRows = append(gotRows, res.Row.Values[0].String())
+			}
+			assert.Equal(t, test.wantRows, gotRows)
+			assert.Equal(t, test.wantErr, gotErr != nil)
+		})
+	}
+}
+
+func TestRowIteratorChannelCancel(t *testing.T) {
+	t.Parallel()
+
+	iter := mockIterator(t, []string{"a", "b", "c"}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := iter.Channel(ctx)
+	require.NoError(t, err)
+
+	res := <-ch
+	require.NoError(t, res.Err)
+	assert.Equal(t, "a", res.Row.Values[0].String())
+	cancel()
+
+	// The channel is closed without the remaining rows, apart from one that may have been sent before the cancel.
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.LessOrEqual(t, n, 1)
+	assert.Error(t, iter.ctx.Err())
+}
@@ -0,0 +1,82 @@
+package kusto
+
+// running_queries.go holds ShowRunningQueries(), which lists the queries that are running on the cluster, and
+// CancelQuery(), which cancels one of them.
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// RunningQuery is a query that is running on the cluster, as returned by the ".show running queries" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/queries
+type RunningQuery struct {
+	// ClientActivityID is the client request ID of the query. It can be passed to ".cancel query".
+	ClientActivityID string `kusto:"ClientActivityId"`
+	// Text is the text of the query.
+	Text string
+	// Database is the database the query runs in.
+	Database string
+	// User is the user that runs the query.
+	User string
+	// Principal is the AAD principal that runs the query.
+	Principal string
+	// Application is the name of the application that sent the query.
+	Application string
+	// State is the state of the query, such as "InProgress".
+	State string
+	// StartedOn is the time the query was started.
+	StartedOn time.Time
+	// Duration is how long the query has been running.
+	Duration time.Duration
+	// TotalCPU is the CPU time the query has used so far.
+	TotalCPU time.Duration `kusto:"TotalCpu"`
+	// MemoryPeak is the peak memory use of the query so far, in bytes.
+	MemoryPeak int64
+}
+
+// ShowRunningQueries returns the queries that are running in db. Users with database admin or monitor permissions on
+// db see the queries of all users, others only see their own queries.
+func (c *Client) ShowRunningQueries(ctx context.Context, db string) ([]RunningQuery, error) {
+	iter, err := c.Mgmt(ctx, db, NewStmt(".show running queries"))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Stop()
+
+	var queries []RunningQuery
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		var q RunningQuery
+		if err := r.ToStruct(&q); err != nil {
+			return err
+		}
+		queries = append(queries, q)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// CancelQuery cancels the query running in db with clientActivityID, the client request ID the query was sent with,
+// on the service. Unlike cancelling the context of a Query() call, which only closes the connection, this stops the
+// query on the cluster and frees the resources it uses. The ID of a query is the value set with ClientRequestID(),
+// the "x-ms-client-request-id" header of RowIterator.RequestHeader or RunningQuery.ClientActivityID. Users can cancel
+// their own queries, database admins can cancel the queries of all users. The service returns an error if no query
+// with the ID is running.
+func (c *Client) CancelQuery(ctx context.Context, db, clientActivityID string) error {
+	if clientActivityID == "" {
+		return errors.ES(errors.OpMgmt, errors.KClientArgs, "CancelQuery() requires a client activity ID").SetNoRetry()
+	}
+	stmt := NewStmt(".cancel query ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).UnsafeAdd(kql.QuoteString(clientActivityID))
+	return c.mgmtNoRows(ctx, db, stmt)
+}
@@ -0,0 +1,122 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runningQueriesResponse = `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"ClientActivityId","ColumnType":"string"},
+{"ColumnName":"Text","ColumnType":"string"},
+{"ColumnName":"Database","ColumnType":"string"},
+{"ColumnName":"StartedOn","ColumnType":"datetime"},
+{"ColumnName":"Duration","ColumnType":"timespan"},
+{"ColumnName":"User","ColumnType":"string"},
+{"ColumnName":"State","ColumnType":"string"},
+{"ColumnName":"RootActivityId","ColumnType":"guid"},
+{"ColumnName":"TotalCpu","ColumnType":"timespan"},
+{"ColumnName":"Application","ColumnType":"string"},
+{"ColumnName":"MemoryPeak","ColumnType":"long"},
+{"ColumnName":"Principal","ColumnType":"string"}],
+"Rows":[
+["KD2RunQuery;1","T | count","db","2022-01-02T03:04:05Z","00:01:30","user@example.com","InProgress","00000000-0000-0000-0000-000000000000","00:00:10","Kusto.Explorer",1048576,"aaduser=1"]]}]}`
+
+func TestShowRunningQueries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		mgmtErr error
+		want    []RunningQuery
+		err     bool
+	}{
+		{
+			desc: "Success",
+			want: []RunningQuery{
+				{
+					ClientActivityID: "KD2RunQuery;1",
+					Text:             "T | count",
+					Database:         "db",
+					User:             "user@example.com",
+					Principal:        "aaduser=1",
+					Application:      "Kusto.Explorer",
+					State:            "InProgress",
+					StartedOn:        time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+					Duration:         90 * time.Second,
+					TotalCPU:         10 * time.Second,
+					MemoryPeak:       1048576,
+				},
+			},
+		},
+		{desc: "Mgmt error", mgmtErr: fmt.Errorf("mgmt error"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, ".show running queries", query)
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return runningQueriesResponse, nil
+			}}}
+
+			got, err := client.ShowRunningQueries(context.Background(), "db")
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCancelQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		id       string
+		mgmtErr  error
+		wantMgmt string
+		err      bool
+	}{
+		{desc: "Success", id: "KGC.execute;1", wantMgmt: ".cancel query 'KGC.execute;1'"},
+		{desc: "ID is quoted", id: `a'b`, wantMgmt: `.cancel query 'a\'b'`},
+		{desc: "Empty ID", id: "", err: true},
+		{desc: "Mgmt error", id: "id", mgmtErr: fmt.Errorf("mgmt error"), wantMgmt: ".cancel query 'id'", err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotMgmt string
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				gotMgmt = query
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"ReasonPhrase","ColumnType":"string"}],"Rows":[["Cancelled"]]}]}`, nil
+			}}}
+
+			err := client.CancelQuery(context.Background(), "db", test.id)
+			assert.Equal(t, test.wantMgmt, gotMgmt)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package kusto
+
+// schema.go holds ShowSchemaAsJSON(), which reads the schema of all the databases of a cluster in a single call, and
+// ShowTableSchema(), which reads the schema of a table.
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// clusterDB is the database sent with commands that apply to the whole cluster, as done by the other Kusto SDKs.
+const clusterDB = "NetDefaultDB"
+
+// ClusterSchema is the schema of the databases of a cluster, as returned by the ".show schema as json" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/show-schema-database
+type ClusterSchema struct {
+	// Databases are the schemas of the databases by database name.
+	Databases map[string]DatabaseSchema
+}
+
+// DatabaseSchema is the schema of a database.
+type DatabaseSchema struct {
+	// Name is the name of the database.
+	Name string
+	// Tables are the schemas of the tables of the database by table name.
+	Tables map[string]TableSchema
+	// MajorVersion and MinorVersion are the version of the database schema, which changes with every schema change.
+	MajorVersion, MinorVersion int
+}
+
+// TableSchema is the schema of a table.
+type TableSchema struct {
+	// Name is the name of the table.
+	Name string
+	// DocString is the documentation of the table, if set.
+	DocString string
+	// OrderedColumns are the columns of the table, in order.
+	OrderedColumns []ColumnSchema
+}
+
+// ColumnSchema is the schema of a column of a table.
+type ColumnSchema struct {
+	// Name is the name of the column.
+	Name string
+	// Type is the .NET type of the column, like "System.String".
+	Type string
+	// CslType is the Kusto type of the column, like "string".
+	CslType types.Column
+	// DocString is the documentation of the column, if set.
+	DocString string
+}
+
+// Columns returns the columns of the table, as used by EnsureTable().
+func (t TableSchema) Columns() table.Columns {
+	columns := make(table.Columns, len(t.OrderedColumns))
+	for i, col := range t.OrderedColumns {
+		columns[i] = table.Column{Name: col.Name, Type: col.CslType}
+	}
+	return columns
+}
+
+// ShowSchemaAsJSON returns the schemas of all the databases of the cluster the principal can see, with a single
+// ".show schema as json" command instead of one command per database. Reading the schema of every database requires
+// the principal to be allowed to run the command at the cluster level. Clusters with many databases return large
+// schemas, which are held in memory.
+func (c *Client) ShowSchemaAsJSON(ctx context.Context) (ClusterSchema, error) {
+	var schema ClusterSchema
+	if err := showSchemaJSON(ctx, c, clusterDB, NewStmt(".show schema as json"), "DatabaseSchema", &schema); err != nil {
+		return ClusterSchema{}, err
+	}
+	return schema, nil
+}
+
+// Mgmter runs management commands. It is implemented by *Client and by clients that wrap it, like the QueryClient of
+// the ingest package.
+type Mgmter interface {
+	Mgmt(ctx context.Context, db string, query Stmt, options ...MgmtOption) (*RowIterator, error)
+}
+
+// ShowTableSchema returns the schema of tableName in db, read with client. Errors of the command are returned as they
+// are, like the one the service returns if the table does not exist.
+func ShowTableSchema(ctx context.Context, client Mgmter, db, tableName string) (TableSchema, error) {
+	stmt := NewStmt(".show table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" schema as json")
+	var schema TableSchema
+	if err := showSchemaJSON(ctx, client, db, stmt, "Schema", &schema); err != nil {
+		return TableSchema{}, err
+	}
+	return schema, nil
+}
+
+// showSchemaJSON runs stmt with client, a command that returns a schema as JSON in column, and decodes the first one
+// into v.
+func showSchemaJSON(ctx context.Context, client Mgmter, db string, stmt Stmt, column string, v interface{}) error {
+	var schemas []string
+	if err := mgmtRows(ctx, client, db, stmt, func(r *table.Row) error {
+		if val, ok := r.Value(column); ok {
+			if s, ok := val.(value.String); ok {
+				schemas = append(schemas, s.Value)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(schemas) == 0 {
+		return errors.ES(errors.OpMgmt, errors.KInternal, "the schema was not returned by %q", stmt.String())
+	}
+
+	if err := json.Unmarshal([]byte(schemas[0]), v); err != nil {
+		return errors.ES(errors.OpMgmt, errors.KInternal, "the schema returned by %q could not be decoded: %s", stmt.String(), err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clusterSchemaResponse returns a ".show schema as json" response holding schema.
+func clusterSchemaResponse(schema string) string {
+	return fmt.Sprintf(`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"DatabaseSchema","ColumnType":"string"}],"Rows":[[%q]]}]}`, schema)
+}
+
+func TestShowSchemaAsJSON(t *testing.T) {
+	t.Parallel()
+
+	const schema = `{"Databases":{
+"db1":{"Name":"db1","MajorVersion":3,"MinorVersion":7,"Tables":{
+	"t1":{"Name":"t1","DocString":"the first table","OrderedColumns":[
+		{"Name":"a","Type":"System.String","CslType":"string","DocString":"column a"},
+		{"Name":"b","Type":"System.Int64","CslType":"long"}]}},
+	"Functions":{}},
+"db2":{"Name":"db2","MajorVersion":1,"MinorVersion":0,"Tables":{}}}}`
+
+	tests := []struct {
+		desc     string
+		response string
+		want     ClusterSchema
+		err      bool
+	}{
+		{
+			desc:     "Success",
+			response: clusterSchemaResponse(schema),
+			want: ClusterSchema{Databases: map[string]DatabaseSchema{
+				"db1": {
+					Name:         "db1",
+					MajorVersion: 3,
+					MinorVersion: 7,
+					Tables: map[string]TableSchema{
+						"t1": {
+							Name:      "t1",
+							DocString: "the first table",
+							OrderedColumns: []ColumnSchema{
+								{Name: "a", Type: "System.String", CslType: types.String, DocString: "column a"},
+								{Name: "b", Type: "System.Int64", CslType: types.Long},
+							},
+						},
+					},
+				},
+				"db2": {Name: "db2", MajorVersion: 1, Tables: map[string]TableSchema{}},
+			}},
+		},
+		{
+			desc:     "No schema returned",
+			response: `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"DatabaseSchema","ColumnType":"string"}],"Rows":[]}]}`,
+			err:      true,
+		},
+		{
+			desc:     "Invalid schema",
+			response: clusterSchemaResponse("{"),
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery string
+			client := &Client{
+				conn: fakeQueryer{
+					onMgmt: func(query string) (string, error) {
+						gotQuery = query
+						return test.response, nil
+					},
+				},
+			}
+
+			got, err := client.ShowSchemaAsJSON(context.Background())
+			assert.Equal(t, ".show schema as json", gotQuery)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, table.Columns{{Name: "a", Type: types.String}, {Name: "b", Type: types.Long}}, got.Databases["db1"].Tables["t1"].Columns())
+		})
+	}
+}
+
+func TestShowTableSchema(t *testing.T) {
+	t.Parallel()
+
+	var gotQuery string
+	client := &Client{
+		conn: fakeQueryer{
+			onMgmt: func(query string) (string, error) {
+				gotQuery = query
+				return `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"TableName","ColumnType":"string"},{"ColumnName":"Schema","ColumnType":"string"}],` +
+					`"Rows":[["it's",` + fmt.Sprintf("%q", `{"Name":"it's","OrderedColumns":[{"Name":"a","Type":"System.String","CslType":"string"}]}`) + `]]}]}`, nil
+			},
+		},
+	}
+
+	got, err := ShowTableSchema(context.Background(), client, "db", "it's")
+	require.NoError(t, err)
+	assert.Equal(t, `.show table ['it\'s'] schema as json`, gotQuery)
+	assert.Equal(t, TableSchema{Name: "it's", OrderedColumns: []ColumnSchema{{Name: "a", Type: "System.String", CslType: types.String}}}, got)
+}
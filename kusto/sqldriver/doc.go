@@ -0,0 +1,21 @@
+// Package sqldriver provides a database/sql driver for Kusto queries. Importing the package registers the
+// driver under the name "kusto":
+//
+//	import _ "github.com/Azure/azure-kusto-go/kusto/sqldriver"
+//
+//	db, err := sql.Open("kusto", "Data Source=https://cluster.westus.kusto.windows.net;Initial Catalog=database;"+
+//		"Application Client Id=<id>;Application Key=<key>;Authority Id=<tenant>")
+//	if err != nil {
+//		panic("add error handling")
+//	}
+//	rows, err := db.QueryContext(ctx, "table | where Name == name", sql.Named("name", "value"))
+//
+// The DSN is a Kusto connection string. If "Application Key" is not provided, a managed identity is used, with
+// "Application Client Id" selecting a user assigned identity. To use any other authorization, create a *kusto.Client
+// and pass it to NewConnector() and sql.OpenDB(). SafeString() renders what a DSN resolves to without its secrets,
+// for logging.
+//
+// Only read queries are supported. Management commands, ingestion and transactions are not, and Exec() returns
+// an error. Query arguments are passed to Kusto as query parameters, see QueryContext() for how they map.
+// Values are returned as described in kusto.DriverRows.
+package sqldriver
@@ -0,0 +1,241 @@
+package sqldriver
+
+// driver.go holds the database/sql/driver implementation.
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+
+	"github.com/google/uuid"
+)
+
+func init() {
+	sql.Register("kusto", Driver{})
+}
+
+// Driver is the database/sql driver for Kusto. It is registered as "kusto" and is not usually used directly.
+type Driver struct{}
+
+// Open implements driver.Driver.Open(). name is a Kusto connection string, see the package documentation.
+func (d Driver) Open(name string) (driver.Conn, error) {
+	c, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+// OpenConnector implements driver.DriverContext.OpenConnector(). It creates a *kusto.Client from the connection string
+// name, which is shared by all connections of the sql.DB and closed with it.
+func (d Driver) OpenConnector(name string) (driver.Connector, error) {
+	settings, err := parseDSN(name)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kusto.New(settings.endpoint, settings.authorization())
+	if err != nil {
+		return nil, err
+	}
+	return &connector{client: client, db: settings.db, owned: true}, nil
+}
+
+// connector implements driver.Connector.
+type connector struct {
+	client *kusto.Client
+	db     string
+	// owned is set if the connector created client and must close it.
+	owned bool
+}
+
+// NewConnector returns a driver.Connector that runs queries against db with client, to be passed to sql.OpenDB().
+// Use this instead of a connection string to use any authorization or client option. Closing the sql.DB does not
+// close client.
+func NewConnector(client *kusto.Client, db string) driver.Connector {
+	return &connector{client: client, db: db}
+}
+
+// Connect implements driver.Connector.Connect().
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{client: c.client, db: c.db}, nil
+}
+
+// Driver implements driver.Connector.Driver().
+func (c *connector) Driver() driver.Driver {
+	return Driver{}
+}
+
+// Close closes the *kusto.Client created by Driver.OpenConnector(). sql.DB.Close() calls it.
+func (c *connector) Close() error {
+	if c.owned {
+		return c.client.Close()
+	}
+	return nil
+}
+
+// conn implements driver.Conn. Connections are cheap, they all share the connector's *kusto.Client.
+type conn struct {
+	client *kusto.Client
+	db     string
+}
+
+// Prepare implements driver.Conn.Prepare(). Nothing is sent to Kusto until the statement is queried.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+// Close implements driver.Conn.Close().
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin implements driver.Conn.Begin(). Kusto does not support transactions, so this always returns an error.
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.ES(errors.OpQuery, errors.KClientArgs, "the kusto driver does not support transactions").SetNoRetry()
+}
+
+// CheckNamedValue implements driver.NamedValueChecker. It allows int32, time.Duration and uuid.UUID arguments,
+// which become int, timespan and guid query parameters, in addition to the standard driver.Value types.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch nv.Value.(type) {
+	case int32, time.Duration, uuid.UUID:
+		return nil
+	}
+	var err error
+	nv.Value, err = driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	return err
+}
+
+// QueryContext implements driver.QueryerContext. Arguments are passed as Kusto query parameters. Named arguments
+// (sql.Named()) use their name, positional arguments are named p1, p2 and so on, so they are referred to in the query
+// as p1, p2, .... Arguments map to parameter types as follows:
+//
+//	bool: bool
+//	int32: int
+//	int64 and other integers: long
+//	float64, float32: real
+//	string: string
+//	time.Time: datetime
+//	time.Duration: timespan
+//	uuid.UUID: guid
+//	[]byte: dynamic, the bytes must be JSON
+//
+// nil arguments are not supported, as a Kusto query parameter must have a type.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s, err := newStmt(query, args)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := c.client.Query(ctx, c.db, s)
+	if err != nil {
+		return nil, err
+	}
+	return kusto.NewDriverRows(iter), nil
+}
+
+// newStmt builds a kusto.Stmt from query and its arguments.
+func newStmt(query string, args []driver.NamedValue) (kusto.Stmt, error) {
+	s := kusto.NewStmt("", kusto.UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).UnsafeAdd(query)
+	if len(args) == 0 {
+		return s, nil
+	}
+
+	paramTypes := make(kusto.ParamTypes, len(args))
+	values := make(kusto.QueryValues, len(args))
+	for _, arg := range args {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("p%d", arg.Ordinal)
+		}
+
+		v := arg.Value
+		var t types.Column
+		switch val := v.(type) {
+		case bool:
+			t = types.Bool
+		case int32:
+			t = types.Int
+		case int64:
+			t = types.Long
+		case float64:
+			t = types.Real
+		case string:
+			t = types.String
+		case time.Time:
+			t = types.DateTime
+		case time.Duration:
+			t = types.Timespan
+		case uuid.UUID:
+			t = types.GUID
+		case []byte:
+			t = types.Dynamic
+			v = json.RawMessage(val)
+		case nil:
+			return kusto.Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "argument %q is nil, which cannot be a Kusto query parameter", name).SetNoRetry()
+		default:
+			return kusto.Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "argument %q is a %T, which cannot be a Kusto query parameter", name, v).SetNoRetry()
+		}
+		if _, ok := paramTypes[name]; ok {
+			return kusto.Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "argument %q is passed more than once", name).SetNoRetry()
+		}
+		paramTypes[name] = kusto.ParamType{Type: t}
+		values[name] = v
+	}
+
+	defs, err := kusto.NewDefinitions().With(paramTypes)
+	if err != nil {
+		return kusto.Stmt{}, errors.E(errors.OpQuery, errors.KClientArgs, err).SetNoRetry()
+	}
+	s, err = s.WithDefinitions(defs)
+	if err != nil {
+		return kusto.Stmt{}, errors.E(errors.OpQuery, errors.KClientArgs, err).SetNoRetry()
+	}
+	s, err = s.WithParameters(kusto.NewParameters().Must(values))
+	if err != nil {
+		return kusto.Stmt{}, errors.E(errors.OpQuery, errors.KClientArgs, err).SetNoRetry()
+	}
+	return s, nil
+}
+
+// stmt implements driver.Stmt.
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+// Close implements driver.Stmt.Close().
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput implements driver.Stmt.NumInput(). The number of arguments is not checked by database/sql.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec implements driver.Stmt.Exec(). Management commands are not supported, so this always returns an error.
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.ES(errors.OpQuery, errors.KClientArgs, "the kusto driver does not support Exec(), only read queries with Query() are supported").SetNoRetry()
+}
+
+// Query implements driver.Stmt.Query().
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return s.QueryContext(context.Background(), named)
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
@@ -0,0 +1,184 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const queryResponse = `[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Name","ColumnType":"string"},{"ColumnName":"Count","ColumnType":"long"}],"Rows":[["a",1],["b",null]]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`
+
+// fakeKusto is a fake Kusto query endpoint that records the last request.
+type fakeKusto struct {
+	mu         sync.Mutex
+	db, csl    string
+	parameters map[string]string
+}
+
+func (f *fakeKusto) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg struct {
+		DB         string `json:"db"`
+		CSL        string `json:"csl"`
+		Properties struct {
+			Parameters map[string]string
+		} `json:"properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.db, f.csl, f.parameters = msg.DB, msg.CSL, msg.Properties.Parameters
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, queryResponse)
+}
+
+func newTestDB(t *testing.T) (*sql.DB, *fakeKusto) {
+	fake := &fakeKusto{}
+	server := httptest.NewTLSServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := kusto.New(server.URL, kusto.Authorization{Authorizer: autorest.NewBasicAuthorizer("", "")}, kusto.WithHttpClient(server.Client()))
+	require.NoError(t, err)
+
+	db := sql.OpenDB(NewConnector(client, "database"))
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		desc           string
+		query          string
+		args           []interface{}
+		wantCSL        string
+		wantParameters map[string]string
+		err            bool
+	}{
+		{
+			desc:    "No arguments",
+			query:   "table",
+			wantCSL: "table",
+		},
+		{
+			desc:    "Named and positional arguments",
+			query:   "table | where Name == name and Count > p2",
+			args:    []interface{}{sql.Named("name", "a"), 3},
+			wantCSL: "declare query_parameters(name:string, p2:long);\ntable | where Name == name and Count > p2",
+			wantParameters: map[string]string{
+				"name": "a",
+				"p2":   "long(3)",
+			},
+		},
+		{
+			desc:  "Extended types",
+			query: "table",
+			args: []interface{}{
+				sql.Named("i", int32(1)),
+				sql.Named("b", true),
+				sql.Named("d", 90*time.Second),
+				sql.Named("g", id),
+				sql.Named("t", when),
+				sql.Named("j", []byte(`{"a":1}`)),
+			},
+			wantCSL: "declare query_parameters(b:bool, d:timespan, g:guid, i:int, j:dynamic, t:datetime);\ntable",
+			wantParameters: map[string]string{
+				"b": "bool(true)",
+				"d": "timespan(00:01:30)",
+				"g": fmt.Sprintf("guid(%s)", id),
+				"i": "int(1)",
+				"j": `dynamic({"a":1})`,
+				"t": "datetime(2020-01-02T03:04:05Z)",
+			},
+		},
+		{
+			desc:  "Nil argument",
+			query: "table",
+			args:  []interface{}{nil},
+			err:   true,
+		},
+		{
+			desc:  "Management command",
+			query: ".show tables",
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			db, fake := newTestDB(t)
+
+			rows, err := db.QueryContext(context.Background(), test.query, test.args...)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer rows.Close()
+
+			columns, err := rows.Columns()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Name", "Count"}, columns)
+
+			type result struct {
+				name  string
+				count sql.NullInt64
+			}
+			var got []result
+			for rows.Next() {
+				r := result{}
+				require.NoError(t, rows.Scan(&r.name, &r.count))
+				got = append(got, r)
+			}
+			require.NoError(t, rows.Err())
+			assert.Equal(t, []result{{name: "a", count: sql.NullInt64{Int64: 1, Valid: true}}, {name: "b"}}, got)
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			assert.Equal(t, "database", fake.db)
+			assert.Equal(t, test.wantCSL, fake.csl)
+			if test.wantParameters != nil {
+				assert.Equal(t, test.wantParameters, fake.parameters)
+			}
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	t.Parallel()
+
+	db, _ := newTestDB(t)
+
+	_, err := db.Exec(".drop table table")
+	assert.Error(t, err)
+
+	_, err = db.Begin()
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package sqldriver
+
+// dsn.go holds the parsing of the Kusto connection strings used as a DSN.
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto"
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+)
+
+// dsn holds the settings read from a Kusto connection string.
+type dsn struct {
+	endpoint string
+	db       string
+	clientID string
+	appKey   string
+	tenantID string
+}
+
+// dsnKeys maps the normalized keywords of a Kusto connection string, and their aliases, to the dsn field they set.
+var dsnKeys = map[string]func(d *dsn) *string{
+	"datasource":          func(d *dsn) *string { return &d.endpoint },
+	"addr":                func(d *dsn) *string { return &d.endpoint },
+	"address":             func(d *dsn) *string { return &d.endpoint },
+	"networkaddress":      func(d *dsn) *string { return &d.endpoint },
+	"server":              func(d *dsn) *string { return &d.endpoint },
+	"initialcatalog":      func(d *dsn) *string { return &d.db },
+	"database":            func(d *dsn) *string { return &d.db },
+	"applicationclientid": func(d *dsn) *string { return &d.clientID },
+	"appclientid":         func(d *dsn) *string { return &d.clientID },
+	"applicationkey":      func(d *dsn) *string { return &d.appKey },
+	"appkey":              func(d *dsn) *string { return &d.appKey },
+	"authorityid":         func(d *dsn) *string { return &d.tenantID },
+	"authority":           func(d *dsn) *string { return &d.tenantID },
+	"tenantid":            func(d *dsn) *string { return &d.tenantID },
+}
+
+// ignoredDSNKeys are keywords that are accepted but have no effect, as the driver always uses AAD.
+var ignoredDSNKeys = map[string]bool{
+	"aadfederatedsecurity": true,
+	"federatedsecurity":    true,
+	"fed":                  true,
+}
+
+// parseDSN parses a Kusto connection string, like "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db".
+// Keywords are case insensitive and spaces in them are ignored.
+func parseDSN(s string) (dsn, error) {
+	d := dsn{}
+	for i, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			// The segment is not quoted in the error, as it could be part of a secret that holds a semicolon.
+			return dsn{}, errors.ES(errors.OpServConn, errors.KClientArgs, "connection string segment %d is not in the form keyword=value", i+1).SetNoRetry()
+		}
+		key := strings.ToLower(strings.Join(strings.Fields(kv[0]), ""))
+		if ignoredDSNKeys[key] {
+			continue
+		}
+		field, ok := dsnKeys[key]
+		if !ok {
+			// The keyword is not quoted either, as it could also be part of a secret that holds a semicolon.
+			return dsn{}, errors.ES(errors.OpServConn, errors.KClientArgs, "connection string segment %d has a keyword that is not supported", i+1).SetNoRetry()
+		}
+		*field(&d) = strings.TrimSpace(kv[1])
+	}
+
+	switch {
+	case d.endpoint == "":
+		return dsn{}, errors.ES(errors.OpServConn, errors.KClientArgs, "connection string must have a Data Source").SetNoRetry()
+	case d.db == "":
+		return dsn{}, errors.ES(errors.OpServConn, errors.KClientArgs, "connection string must have an Initial Catalog").SetNoRetry()
+	case d.appKey != "" && (d.clientID == "" || d.tenantID == ""):
+		return dsn{}, errors.ES(errors.OpServConn, errors.KClientArgs, "connection string with an Application Key must have an Application Client Id and an Authority Id").SetNoRetry()
+	}
+	return d, nil
+}
+
+// authorization returns the kusto.Authorization for the connection string's settings.
+func (d dsn) authorization() kusto.Authorization {
+	if d.appKey != "" {
+		return kusto.Authorization{Config: auth.NewClientCredentialsConfig(d.clientID, d.appKey, d.tenantID)}
+	}
+	msi := auth.NewMSIConfig()
+	msi.ClientID = d.clientID
+	return kusto.Authorization{Config: msi}
+}
+
+// SafeString returns the settings the connection string dsn resolves to, with the Application Key masked, so that it can
+// be logged when investigating why a connection fails. It names the authentication that is used, like:
+//
+//	Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id;Application Key=***;Authority Id=tenant (application key authentication)
+//
+// The error returned for an invalid connection string does not hold any of its values either.
+func SafeString(dsn string) (string, error) {
+	d, err := parseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+// String implements fmt.Stringer. The Application Key is masked.
+func (d dsn) String() string {
+	parts := []string{"Data Source=" + d.endpoint, "Initial Catalog=" + d.db}
+	if d.clientID != "" {
+		parts = append(parts, "Application Client Id="+d.clientID)
+	}
+	if d.appKey != "" {
+		parts = append(parts, "Application Key=***")
+	}
+	if d.tenantID != "" {
+		parts = append(parts, "Authority Id="+d.tenantID)
+	}
+
+	authMode := "managed identity authentication"
+	if d.appKey != "" {
+		authMode = "application key authentication"
+	}
+	return strings.Join(parts, ";") + " (" + authMode + ")"
+}
@@ -0,0 +1,124 @@
+package sqldriver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDSN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		dsn  string
+		want dsn
+		err  bool
+	}{
+		{
+			desc: "Application key",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;AAD Federated Security=True;Application Client Id=id;Application Key=key;Authority Id=tenant",
+			want: dsn{endpoint: "https://cluster.kusto.windows.net", db: "db", clientID: "id", appKey: "key", tenantID: "tenant"},
+		},
+		{
+			desc: "Aliases, case and spaces",
+			dsn:  " server = https://cluster.kusto.windows.net ; DATABASE=db; AppClientId=id;",
+			want: dsn{endpoint: "https://cluster.kusto.windows.net", db: "db", clientID: "id"},
+		},
+		{
+			desc: "Missing Data Source",
+			dsn:  "Initial Catalog=db",
+			err:  true,
+		},
+		{
+			desc: "Missing Initial Catalog",
+			dsn:  "Data Source=https://cluster.kusto.windows.net",
+			err:  true,
+		},
+		{
+			desc: "Application key without tenant",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id;Application Key=key",
+			err:  true,
+		},
+		{
+			desc: "Unknown keyword",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Password=secret",
+			err:  true,
+		},
+		{
+			desc: "Segment without value",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDSN(test.dsn)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSafeString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		dsn  string
+		want string
+		err  bool
+	}{
+		{
+			desc: "Application key",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id;Application Key=secret;Authority Id=tenant",
+			want: "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id;Application Key=***;Authority Id=tenant (application key authentication)",
+		},
+		{
+			desc: "Managed identity",
+			dsn:  "Server=https://cluster.kusto.windows.net;Database=db;AppClientId=id",
+			want: "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id (managed identity authentication)",
+		},
+		{
+			desc: "Unknown keyword",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Password=secret",
+			err:  true,
+		},
+		{
+			desc: "Key with a semicolon",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id;Application Key=sec;ret;Authority Id=tenant",
+			err:  true,
+		},
+		{
+			desc: "Key with a semicolon and an equal sign",
+			dsn:  "Data Source=https://cluster.kusto.windows.net;Initial Catalog=db;Application Client Id=id;Application Key=sec;ret=x;Authority Id=tenant",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := SafeString(test.dsn)
+			if test.err {
+				require.Error(t, err)
+				assert.NotContains(t, err.Error(), "sec")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.NotContains(t, got, "secret")
+		})
+	}
+}
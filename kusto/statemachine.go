@@ -350,6 +350,9 @@ func (p *v1SM) nextFrame() (stateFn, error) {
 			}
 			if len(p.tables) <= 2 {
 				p.currentTable = p.tables[0]
+				if v1Truncated(nil, p.tables[:1]) {
+					p.iter.setTruncated()
+				}
 			} else {
 				p.currentTable = p.tables[len(p.tables)-1]
 				return p.tableOfContents, nil
@@ -383,20 +386,35 @@ func (p *v1SM) tableOfContents() (stateFn, error) {
 		return nil, err
 	}
 
-	current := TableOfContents{}
+	var results []v1.DataTable
+	var status *v1.DataTable
 	for _, kustoRow := range tableOfContents.KustoRows {
+		current := TableOfContents{}
 		row := table.Row{ColumnTypes: columns, Values: kustoRow, Op: p.op}
 		err := row.ToStruct(&current)
 		if err != nil {
 			return nil, err
 		}
+		if current.Ordinal < 0 || current.Ordinal >= int64(len(p.tables)) {
+			continue
+		}
 
-		kind := frames.TableKind(current.Kind)
-		if kind == frames.QueryResult {
-			p.currentTable = p.tables[current.Ordinal]
-			if _, err := p.dataTable(); err != nil {
-				return nil, err
-			}
+		switch frames.TableKind(current.Kind) {
+		case frames.QueryResult:
+			results = append(results, p.tables[current.Ordinal])
+		case frames.QueryStatus:
+			status = &p.tables[current.Ordinal]
+		}
+	}
+
+	// Truncation is recorded before the rows are sent, so that Mgmt() can see it once the columns are ready.
+	if v1Truncated(status, results) {
+		p.iter.setTruncated()
+	}
+	for _, result := range results {
+		p.currentTable = result
+		if _, err := p.dataTable(); err != nil {
+			return nil, err
 		}
 	}
 	return p.done, nil
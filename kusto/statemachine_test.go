@@ -63,7 +63,15 @@ func assertValues(t *testing.T, wantErr error, gotErr error, want table.Rows, go
 		assert.NoError(t, gotErr)
 	}
 
-	assert.Equal(t, want, got)
+	// The rows share the index of their columns, which the expected rows do not have, so only their fields are compared.
+	var gotFields table.Rows
+	if got != nil {
+		gotFields = make(table.Rows, len(got))
+		for i, r := range got {
+			gotFields[i] = &table.Row{ColumnTypes: r.ColumnTypes, Values: r.Values, Op: r.Op, Replace: r.Replace}
+		}
+	}
+	assert.Equal(t, want, gotFields)
 	assert.Equal(t, wantInlineErrors, gotInlineErrors)
 }
 
@@ -0,0 +1,66 @@
+package kusto
+
+// streaming_policy.go holds helpers to enable and disable streaming ingestion on a database or table.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// EnableStreamingIngestion enables streaming ingestion on tableName in db. If tableName is empty, it is enabled on
+// the database. Other settings of the streaming ingestion policy, such as the hint allocated rate, are kept.
+// The cluster must also have streaming ingestion enabled. This requires table admin permissions (database admin for
+// a database) and returns a KClientArgs error if the caller does not have them.
+func (c *Client) EnableStreamingIngestion(ctx context.Context, db, tableName string) error {
+	return c.setStreamingIngestion(ctx, db, tableName, true)
+}
+
+// DisableStreamingIngestion disables streaming ingestion on tableName in db. If tableName is empty, it is disabled on
+// the database. Other settings of the streaming ingestion policy are kept. This requires table admin permissions
+// (database admin for a database) and returns a KClientArgs error if the caller does not have them.
+func (c *Client) DisableStreamingIngestion(ctx context.Context, db, tableName string) error {
+	return c.setStreamingIngestion(ctx, db, tableName, false)
+}
+
+// setStreamingIngestion merges IsEnabled into the streaming ingestion policy of tableName in db.
+func (c *Client) setStreamingIngestion(ctx context.Context, db, tableName string, enabled bool) error {
+	policy := `{"IsEnabled":false}`
+	if enabled {
+		policy = `{"IsEnabled":true}`
+	}
+
+	stmt := NewStmt(".alter-merge ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(policyEntity(db, tableName)).Add(" policy streamingingestion @'").UnsafeAdd(policy).Add("'")
+
+	iter, err := c.Mgmt(ctx, db, stmt)
+	if err == nil {
+		defer iter.Stop()
+		err = iter.DoOnRowOrError(func(_ *table.Row, e *errors.Error) error {
+			if e != nil {
+				return e
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		return nil
+	}
+
+	if httpErr, ok := err.(*errors.HttpError); ok && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden) {
+		entity := fmt.Sprintf("table %q in database %q", tableName, db)
+		if tableName == "" {
+			entity = fmt.Sprintf("database %q", db)
+		}
+		return errors.E(
+			errors.OpMgmt,
+			errors.KClientArgs,
+			fmt.Errorf("the caller does not have permission to alter the streaming ingestion policy of %s: %w", entity, err),
+		).SetNoRetry()
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package kusto
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingIngestionPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		table     string
+		enable    bool
+		mgmtErr   error
+		wantQuery string
+		wantKind  errors.Kind
+		err       bool
+	}{
+		{
+			desc:      "Enable on table",
+			table:     "table",
+			enable:    true,
+			wantQuery: `.alter-merge table ['table'] policy streamingingestion @'{"IsEnabled":true}'`,
+		},
+		{
+			desc:      "Disable on table",
+			table:     "table",
+			wantQuery: `.alter-merge table ['table'] policy streamingingestion @'{"IsEnabled":false}'`,
+		},
+		{
+			desc:      "Enable on database",
+			enable:    true,
+			wantQuery: `.alter-merge database ['db'] policy streamingingestion @'{"IsEnabled":true}'`,
+		},
+		{
+			desc:     "Forbidden",
+			table:    "table",
+			enable:   true,
+			mgmtErr:  errors.HTTP(errors.OpMgmt, "403 Forbidden", http.StatusForbidden, ioutil.NopCloser(strings.NewReader("{}")), ""),
+			wantKind: errors.KClientArgs,
+			err:      true,
+		},
+		{
+			desc:     "Other error",
+			table:    "table",
+			enable:   true,
+			mgmtErr:  errors.HTTP(errors.OpMgmt, "400 Bad Request", http.StatusBadRequest, ioutil.NopCloser(strings.NewReader("{}")), ""),
+			wantKind: errors.KHTTPError,
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery string
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				gotQuery = query
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return policyResponse(`{"IsEnabled":true}`), nil
+			}}}
+
+			var err error
+			if test.enable {
+				err = client.EnableStreamingIngestion(context.Background(), "db", test.table)
+			} else {
+				err = client.DisableStreamingIngestion(context.Background(), "db", test.table)
+			}
+			if test.err {
+				require.Error(t, err)
+				e, ok := errors.GetKustoError(err)
+				require.True(t, ok)
+				assert.Equal(t, test.wantKind, e.Kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantQuery, gotQuery)
+		})
+	}
+}
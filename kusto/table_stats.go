@@ -0,0 +1,99 @@
+package kusto
+
+// table_stats.go holds TableRowCount(), ApproxTableRowCount() and TableSize(), which read the size of a table.
+
+import (
+	"context"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/kql"
+	"github.com/Azure/azure-kusto-go/kusto/unsafe"
+)
+
+// tableDetails is the part of a ".show table details" row read by ApproxTableRowCount() and TableSize().
+type tableDetails struct {
+	TotalExtentSize float64
+	TotalRowCount   int64
+}
+
+// TableRowCount returns the exact number of rows of tableName in db by running a "| count" query on it. This scans
+// the table and is subject to its row level security policy and to the query limits of the cluster, use
+// ApproxTableRowCount() when an estimate is enough.
+func (c *Client) TableRowCount(ctx context.Context, db, tableName string) (int64, error) {
+	stmt := NewStmt("", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).UnsafeAdd(kql.QuoteName(tableName)).Add(" | count")
+
+	iter, err := c.Query(ctx, db, stmt)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Stop()
+
+	var (
+		count int64
+		found bool
+	)
+	err = iter.DoOnRowOrError(func(r *table.Row, e *errors.Error) error {
+		if e != nil {
+			return e
+		}
+		if v, ok := r.Value("Count"); ok {
+			if l, ok := v.(value.Long); ok {
+				count, found = l.Value, true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, errors.ES(errors.OpQuery, errors.KInternal, "the row count of table %s was not returned", tableName)
+	}
+	return count, nil
+}
+
+// ApproxTableRowCount returns the number of rows of tableName in db from the metadata of its extents, with the
+// ".show table details" command. This does not scan the table, so it is fast on tables of any size, but it does not
+// apply the row level security policy and may not include rows that were just ingested or still are in the streaming
+// ingestion row store. Use TableRowCount() for an exact count.
+func (c *Client) ApproxTableRowCount(ctx context.Context, db, tableName string) (int64, error) {
+	details, err := c.tableDetails(ctx, db, tableName)
+	if err != nil {
+		return 0, err
+	}
+	return details.TotalRowCount, nil
+}
+
+// TableSize returns the size of tableName in db in storage, including its indexes, in bytes, from the metadata of its
+// extents. The size of the data as it was ingested is the sum of Extent.OriginalSize from ShowTableExtents().
+func (c *Client) TableSize(ctx context.Context, db, tableName string) (int64, error) {
+	details, err := c.tableDetails(ctx, db, tableName)
+	if err != nil {
+		return 0, err
+	}
+	return int64(details.TotalExtentSize), nil
+}
+
+// tableDetails returns the ".show table details" row of tableName in db.
+func (c *Client) tableDetails(ctx context.Context, db, tableName string) (tableDetails, error) {
+	stmt := NewStmt(".show table ", UnsafeStmt(unsafe.Stmt{Add: true, SuppressWarning: true})).
+		UnsafeAdd(kql.QuoteName(tableName)).Add(" details")
+
+	var (
+		details tableDetails
+		found   bool
+	)
+	err := c.mgmtRows(ctx, db, stmt, func(r *table.Row) error {
+		found = true
+		return r.ToStruct(&details)
+	})
+	if err != nil {
+		return tableDetails{}, err
+	}
+	if !found {
+		return tableDetails{}, errors.ES(errors.OpMgmt, errors.KInternal, "the details of table %s were not returned", tableName)
+	}
+	return details, nil
+}
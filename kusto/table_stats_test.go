@@ -0,0 +1,112 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableRowCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		response string
+		want     int64
+		err      bool
+	}{
+		{
+			desc: "Success",
+			response: `[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Count","ColumnType":"long"}],"Rows":[[42]]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`,
+			want: 42,
+		},
+		{
+			desc: "No count returned",
+			response: `[
+{"FrameType":"dataSetHeader","IsProgressive":false,"Version":"v2.0"},
+{"FrameType":"DataTable","TableId":1,"TableKind":"PrimaryResult","TableName":"PrimaryResult","Columns":[{"ColumnName":"Count","ColumnType":"long"}],"Rows":[]},
+{"FrameType":"DataSetCompletion","HasErrors":false,"Cancelled":false}
+]`,
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onQuery: func(query string) (string, error) {
+				assert.Equal(t, "['table'] | count", query)
+				return test.response, nil
+			}}}
+
+			got, err := client.TableRowCount(context.Background(), "db", "table")
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestTableDetails(t *testing.T) {
+	t.Parallel()
+
+	const details = `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"TableName","ColumnType":"string"},
+{"ColumnName":"DatabaseName","ColumnType":"string"},
+{"ColumnName":"TotalExtents","ColumnType":"long"},
+{"ColumnName":"TotalExtentSize","ColumnType":"real"},
+{"ColumnName":"TotalOriginalSize","ColumnType":"real"},
+{"ColumnName":"TotalRowCount","ColumnType":"long"}],
+"Rows":%s}]}`
+
+	tests := []struct {
+		desc      string
+		rows      string
+		wantCount int64
+		wantSize  int64
+		err       bool
+	}{
+		{desc: "Success", rows: `[["table","db",3,2048.0,8192.0,100]]`, wantCount: 100, wantSize: 2048},
+		{desc: "No details returned", rows: `[]`, err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, ".show table ['table'] details", query)
+				return fmt.Sprintf(details, test.rows), nil
+			}}}
+
+			count, err := client.ApproxTableRowCount(context.Background(), "db", "table")
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantCount, count)
+			}
+
+			size, err := client.TableSize(context.Background(), "db", "table")
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantSize, size)
+		})
+	}
+}
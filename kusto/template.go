@@ -0,0 +1,249 @@
+package kusto
+
+// template.go holds Template, which builds a Stmt from a query with named placeholders.
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/google/uuid"
+)
+
+var (
+	// templatePlaceholder matches the {{name}} placeholders of a Template.
+	templatePlaceholder = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+	// templateName matches the valid names of placeholders, which are used as the names of query parameters.
+	templateName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Template is a query with named placeholders, like "{{name}}", that are bound to values with Bind(). Stmt() turns the
+// placeholders into query parameters, so the values are sent to the service as Parameters instead of being formatted
+// into the query, which protects against injection without escaping them:
+//
+//	stmt, err := kusto.NewTemplate("Events | where Name == {{name}} and Timestamp > ago({{window}})").
+//		Bind("name", name).
+//		Bind("window", time.Hour).
+//		Stmt()
+//
+// The text of a template is sent as it is, like the string constants of NewStmt(), so templates must come from a
+// trusted source, like files maintained with the code. Unlike NewStmt(), they do not need to be compiled in.
+// A Template is not changed by its methods, which return a new Template.
+type Template struct {
+	text   string
+	values map[string]interface{}
+}
+
+// NewTemplate returns a Template of text. Placeholders are "{{name}}", where name is a valid KQL identifier
+// (letters, digits and underscores, not starting with a digit) that is not a KQL keyword, as it becomes the name of a
+// query parameter. Spaces around the name are allowed. A placeholder must not be inside a string literal, like
+// "'{{name}}'", where it would be text instead of a parameter: Stmt() returns an error for it. Bind the whole string
+// instead, or build it with strcat().
+func NewTemplate(text string) Template {
+	return Template{text: text}
+}
+
+// Bind returns a copy of the Template with the placeholder name bound to v. The type of the query parameter comes from
+// the type of v:
+//
+//	bool: bool
+//	time.Time: datetime
+//	uuid.UUID: guid
+//	int32: int
+//	int, int64: long
+//	float64: real
+//	string: string
+//	time.Duration: timespan
+//	*big.Float, *big.Int: decimal, with all the digits of the value
+//	maps, slices, arrays and structs: dynamic, marshalled to JSON
+//
+// Other types make Stmt() return an error. Binding a name again replaces its value.
+func (t Template) Bind(name string, v interface{}) Template {
+	values := make(map[string]interface{}, len(t.values)+1)
+	for k, val := range t.values {
+		values[k] = val
+	}
+	values[name] = v
+	t.values = values
+	return t
+}
+
+// Stmt returns the Stmt of the Template, with a query parameter for every placeholder. It returns an error if a
+// placeholder is not bound, is inside a string literal, a bound name has no placeholder, or a value has a type Bind()
+// does not support.
+func (t Template) Stmt() (Stmt, error) {
+	used := map[string]bool{}
+	literals := stringLiterals(t.text)
+	query := &strings.Builder{}
+	last := 0
+	for _, m := range templatePlaceholder.FindAllStringSubmatchIndex(t.text, -1) {
+		name := t.text[m[2]:m[3]]
+		if !templateName.MatchString(name) {
+			return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template placeholder {{%s}} is not a valid name", name).SetNoRetry()
+		}
+		for _, l := range literals {
+			if m[0] >= l[0] && m[0] < l[1] {
+				return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template placeholder {{%s}} is inside a string literal, where it cannot be a query parameter", name).SetNoRetry()
+			}
+		}
+		query.WriteString(t.text[last:m[0]])
+		query.WriteString(name)
+		last = m[1]
+		used[name] = true
+	}
+	query.WriteString(t.text[last:])
+
+	for _, name := range sortedKeys(used) {
+		if _, ok := t.values[name]; !ok {
+			return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template placeholder {{%s}} is not bound", name).SetNoRetry()
+		}
+	}
+	names := make([]string, 0, len(t.values))
+	for name := range t.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !used[name] {
+			return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template value %q is bound, but the template has no {{%s}} placeholder", name, name).SetNoRetry()
+		}
+	}
+
+	stmt := Stmt{queryStr: query.String()}
+	if len(used) == 0 {
+		return stmt, nil
+	}
+
+	paramTypes := make(ParamTypes, len(used))
+	values := make(QueryValues, len(used))
+	for _, name := range names {
+		typ, v, err := templateValue(t.values[name])
+		if err != nil {
+			return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template value %q: %s", name, err).SetNoRetry()
+		}
+		paramTypes[name] = ParamType{Type: typ}
+		values[name] = v
+	}
+
+	defs, err := NewDefinitions().With(paramTypes)
+	if err != nil {
+		return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template definitions: %s", err).SetNoRetry()
+	}
+	params, err := NewParameters().With(values)
+	if err != nil {
+		return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template parameters: %s", err).SetNoRetry()
+	}
+	if stmt, err = stmt.WithDefinitions(defs); err != nil {
+		return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template definitions: %s", err).SetNoRetry()
+	}
+	if stmt, err = stmt.WithParameters(params); err != nil {
+		return Stmt{}, errors.ES(errors.OpQuery, errors.KClientArgs, "template parameters: %s", err).SetNoRetry()
+	}
+	return stmt, nil
+}
+
+// templateValue returns the column type of v and v as the type Parameters expects for it.
+func templateValue(v interface{}) (types.Column, interface{}, error) {
+	switch v := v.(type) {
+	case bool:
+		return types.Bool, v, nil
+	case time.Time:
+		return types.DateTime, v, nil
+	case uuid.UUID:
+		return types.GUID, v, nil
+	case int32:
+		return types.Int, v, nil
+	case int:
+		return types.Long, int64(v), nil
+	case int64:
+		return types.Long, v, nil
+	case float64:
+		return types.Real, v, nil
+	case string:
+		return types.String, v, nil
+	case time.Duration:
+		return types.Timespan, v, nil
+	case *big.Float:
+		// Parameters formats a *big.Float with String(), which rounds it to 10 digits.
+		if v == nil || v.IsInf() {
+			return "", nil, fmt.Errorf("decimal value %v is not a number", v)
+		}
+		return types.Decimal, v.Text('f', -1), nil
+	case *big.Int:
+		if v == nil {
+			return "", nil, fmt.Errorf("decimal value is nil")
+		}
+		return types.Decimal, v.String(), nil
+	}
+
+	if v != nil {
+		switch reflect.TypeOf(v).Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+			return types.Dynamic, v, nil
+		}
+	}
+	return "", nil, fmt.Errorf("type %T is not supported", v)
+}
+
+// stringLiterals returns the start and end offsets of the string literals of the KQL text: quoted with ' or ", with
+// backslash escapes, verbatim with @ and doubled quotes, or multi-line between ```. Comments are skipped, so that
+// quotes in them do not start a literal.
+func stringLiterals(text string) [][2]int {
+	var literals [][2]int
+	for i := 0; i < len(text); i++ {
+		start := i
+		switch {
+		case strings.HasPrefix(text[i:], "//"):
+			if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(text)
+			}
+			continue
+		case strings.HasPrefix(text[i:], "```"):
+			if end := strings.Index(text[i+3:], "```"); end >= 0 {
+				i += 3 + end + 2
+			} else {
+				i = len(text)
+			}
+		case text[i] == '@' && i+1 < len(text) && (text[i+1] == '\'' || text[i+1] == '"'):
+			quote := text[i+1]
+			for i += 2; i < len(text); i++ {
+				if text[i] == quote {
+					if i+1 < len(text) && text[i+1] == quote {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case text[i] == '\'' || text[i] == '"':
+			quote := text[i]
+			for i++; i < len(text) && text[i] != quote; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+		default:
+			continue
+		}
+		literals = append(literals, [2]int{start, i + 1})
+	}
+	return literals
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,175 @@
+package kusto
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		template   Template
+		wantQuery  string
+		wantDefs   ParamTypes
+		wantParams map[string]string
+		err        bool
+	}{
+		{
+			desc:      "No placeholders",
+			template:  NewTemplate("MyTable | count"),
+			wantQuery: "MyTable | count",
+		},
+		{
+			desc: "Placeholders",
+			template: NewTemplate("MyTable | where Name == {{ name }} and Timestamp > ago({{window}}) | take {{n}} | where Tags has_any ({{tags}})").
+				Bind("name", "it's").
+				Bind("window", time.Hour).
+				Bind("n", 10).
+				Bind("tags", []string{"a", "b"}),
+			wantQuery: "declare query_parameters(n:long, name:string, tags:dynamic, window:timespan);\n" +
+				"MyTable | where Name == name and Timestamp > ago(window) | take n | where Tags has_any (tags)",
+			wantDefs: ParamTypes{
+				"n":      ParamType{Type: types.Long},
+				"name":   ParamType{Type: types.String},
+				"tags":   ParamType{Type: types.Dynamic},
+				"window": ParamType{Type: types.Timespan},
+			},
+			wantParams: map[string]string{
+				"n":      "long(10)",
+				"name":   "it's",
+				"tags":   `dynamic(["a","b"])`,
+				"window": "timespan(01:00:00)",
+			},
+		},
+		{
+			desc:       "Placeholder used twice",
+			template:   NewTemplate("T | where A == {{x}} or B == {{x}}").Bind("x", true),
+			wantQuery:  "declare query_parameters(x:bool);\nT | where A == x or B == x",
+			wantDefs:   ParamTypes{"x": ParamType{Type: types.Bool}},
+			wantParams: map[string]string{"x": "bool(true)"},
+		},
+		{
+			desc:     "Unbound placeholder",
+			template: NewTemplate("T | where A == {{x}}"),
+			err:      true,
+		},
+		{
+			desc:     "Unused value",
+			template: NewTemplate("T | where A == {{x}}").Bind("x", "a").Bind("y", "b"),
+			err:      true,
+		},
+		{
+			desc:     "Invalid name",
+			template: NewTemplate("T | where A == {{1x}}").Bind("1x", "a"),
+			err:      true,
+		},
+		{
+			desc: "Decimals keep their digits",
+			template: NewTemplate("T | where A == {{f}} or A == {{i}}").
+				Bind("f", mustParseFloat(t, "1234567890.123456789")).
+				Bind("i", mustParseInt(t, "123456789012345678901234567890")),
+			wantQuery: "declare query_parameters(f:decimal, i:decimal);\nT | where A == f or A == i",
+			wantParams: map[string]string{
+				"f": "decimal(1234567890.123456789)",
+				"i": "decimal(123456789012345678901234567890)",
+			},
+		},
+		{
+			desc: "Placeholders after string literals and comments",
+			template: NewTemplate("T // it's a comment\n| where A == 'it\\'s' and B == @'x''y' and C == ```'``` and D == \"\\\"\" and E == {{x}}").
+				Bind("x", "a"),
+			wantQuery:  "declare query_parameters(x:string);\nT // it's a comment\n| where A == 'it\\'s' and B == @'x''y' and C == ```'``` and D == \"\\\"\" and E == x",
+			wantParams: map[string]string{"x": "a"},
+		},
+		{
+			desc:     "Placeholder in a single quoted string",
+			template: NewTemplate("T | where A == 'a {{x}}'").Bind("x", "a"),
+			err:      true,
+		},
+		{
+			desc:     "Placeholder in a double quoted string",
+			template: NewTemplate(`T | where A == "a\" {{x}}"`).Bind("x", "a"),
+			err:      true,
+		},
+		{
+			desc:     "Placeholder in a verbatim string",
+			template: NewTemplate("T | where A == @'a'' {{x}}'").Bind("x", "a"),
+			err:      true,
+		},
+		{
+			desc:     "Placeholder in a multi-line string",
+			template: NewTemplate("T | where A == ```a\n{{x}}```").Bind("x", "a"),
+			err:      true,
+		},
+		{
+			desc:     "Nil decimal",
+			template: NewTemplate("T | where A == {{x}}").Bind("x", (*big.Float)(nil)),
+			err:      true,
+		},
+		{
+			desc:     "Unsupported type",
+			template: NewTemplate("T | where A == {{x}}").Bind("x", uint8(1)),
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stmt, err := test.template.Stmt()
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantQuery, stmt.String())
+			params, err := stmt.params.toParameters(stmt.defs)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantParams, params)
+			if test.wantDefs != nil {
+				assert.Equal(t, test.wantDefs, stmt.defs.m)
+			}
+		})
+	}
+}
+
+func mustParseFloat(t *testing.T, s string) *big.Float {
+	f, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	require.NoError(t, err)
+	return f
+}
+
+func mustParseInt(t *testing.T, s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 10)
+	require.True(t, ok)
+	return i
+}
+
+func TestTemplateBindCopies(t *testing.T) {
+	t.Parallel()
+
+	base := NewTemplate("T | where A == {{x}}").Bind("x", "a")
+	other := base.Bind("x", "b")
+
+	stmt, err := base.Stmt()
+	require.NoError(t, err)
+	params, err := stmt.params.toParameters(stmt.defs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"x": "a"}, params)
+
+	stmt, err = other.Stmt()
+	require.NoError(t, err)
+	params, err = stmt.params.toParameters(stmt.defs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"x": "b"}, params)
+}
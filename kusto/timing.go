@@ -0,0 +1,127 @@
+package kusto
+
+// timing.go holds the accessors for how long a query took, as seen by the client and as reported by the service, and
+// for the other query statistics the service reports.
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/Azure/azure-kusto-go/kusto/internal/frames"
+)
+
+// markReceived records that the response was fully received.
+func (r *RowIterator) markReceived() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.received.IsZero() {
+		r.received = nower()
+	}
+}
+
+// ClientDuration returns the time from sending the request until the response was fully received, which includes
+// the network round-trip and the time the service took. While the response is still being received, it returns the
+// time since the request was sent. Rows are read from the service ahead of the caller, so the time the caller takes
+// to process rows is only part of it when the caller is slower than the service.
+func (r *RowIterator) ClientDuration() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.received.IsZero() {
+		return nower().Sub(r.sent)
+	}
+	return r.received.Sub(r.sent)
+}
+
+// ServerExecutionTime returns the execution time the service reported for the query in the QueryCompletionInformation
+// table. That table is sent after the rows, so call it after all rows were read. It returns false if the service did
+// not report an execution time, like for Mgmt() calls or a query that failed.
+func (r *RowIterator) ServerExecutionTime() (time.Duration, bool) {
+	consumption, ok := r.resourceConsumption()
+	if !ok || consumption.ExecutionTime == nil {
+		return 0, false
+	}
+	return time.Duration(*consumption.ExecutionTime * float64(time.Second)), true
+}
+
+// resourceConsumption is the payload of the QueryResourceConsumption event of the QueryCompletionInformation table.
+type resourceConsumption struct {
+	// ExecutionTime is in seconds.
+	ExecutionTime *float64
+	// DatasetStatistics has an entry for every table of the result, starting with the primary table.
+	DatasetStatistics []struct {
+		TableRowCount *int64 `json:"table_row_count"`
+	} `json:"dataset_statistics"`
+}
+
+// resourceConsumption returns the QueryResourceConsumption payload of the QueryCompletionInformation table, or false
+// if it was not received.
+func (r *RowIterator) resourceConsumption() (resourceConsumption, bool) {
+	r.mu.Lock()
+	info, ok := r.nonPrimary[frames.QueryCompletionInformation]
+	r.mu.Unlock()
+	if !ok {
+		return resourceConsumption{}, false
+	}
+
+	payload := -1
+	for i, c := range info.Columns {
+		if c.Name == "Payload" {
+			payload = i
+			break
+		}
+	}
+	if payload < 0 {
+		return resourceConsumption{}, false
+	}
+
+	for _, row := range info.KustoRows {
+		if payload >= len(row) {
+			continue
+		}
+		s, ok := row[payload].(value.String)
+		if !ok || !s.Valid {
+			continue
+		}
+		var consumption resourceConsumption
+		if err := json.Unmarshal([]byte(s.Value), &consumption); err != nil {
+			continue
+		}
+		if consumption.ExecutionTime != nil || len(consumption.DatasetStatistics) > 0 {
+			return consumption, true
+		}
+	}
+	return resourceConsumption{}, false
+}
+
+// ServerRowCount returns the number of rows the service reported for the primary table in the
+// QueryCompletionInformation table. That table is sent after the rows, so call it after all rows were read.
+// It returns false if the service did not report a row count, like for Mgmt() calls or a query that failed.
+func (r *RowIterator) ServerRowCount() (int64, bool) {
+	consumption, ok := r.resourceConsumption()
+	if !ok || len(consumption.DatasetStatistics) == 0 || consumption.DatasetStatistics[0].TableRowCount == nil {
+		return 0, false
+	}
+	return *consumption.DatasetStatistics[0].TableRowCount, true
+}
+
+// checkRowCount returns an error if the VerifyRowCount() option was provided and the number of rows returned does not
+// match ServerRowCount(). It is called once all rows were read.
+func (r *RowIterator) checkRowCount() error {
+	if !r.verifyRowCount {
+		return nil
+	}
+
+	want, ok := r.ServerRowCount()
+	if !ok {
+		return errors.ES(r.op, errors.KOther, "VerifyRowCount() was set, but the service did not report the row count of the result")
+	}
+	r.mu.Lock()
+	got := r.records
+	r.mu.Unlock()
+	if got != want {
+		return errors.ES(r.op, errors.KOther, "VerifyRowCount() was set and %d rows were received, but the service reported %d rows", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package kusto
+
+// to_columns.go holds RowIterator.ToColumns(), which reads the rows of a RowIterator into a slice per column.
+
+import (
+	"context"
+	"io"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+)
+
+// ToColumns reads all the rows of the RowIterator into a slice per column, keyed by the name of the column, with the
+// columns of the result in their order. This suits processing that works on whole columns, like computing percentiles,
+// better than reading rows and gathering a column at a time. All slices have one value per row, converted to Go types:
+//
+//	bool: bool
+//	int: int32
+//	long: int64
+//	real: float64
+//	datetime: time.Time
+//	timespan: time.Duration
+//	guid: uuid.UUID
+//	string: string
+//	decimal: string holding the decimal number
+//	dynamic: []byte holding the JSON value
+//
+// Null values of any type are nil. Errors inline within the rows stop the iteration and are returned. If ctx is done,
+// the RowIterator is stopped and the error of ctx is returned. An error is also returned if columns have the same name,
+// which RenameDuplicateColumns() avoids. The whole result is held in memory, so this is meant for results that fit in it.
+// The RowIterator must not be read in any other way after calling ToColumns.
+func (r *RowIterator) ToColumns(ctx context.Context) (map[string][]interface{}, table.Columns, error) {
+	columns := r.columns
+	if r.mock != nil {
+		columns = r.mock.columns
+	}
+
+	cols := make(map[string][]interface{}, len(columns))
+	for _, c := range columns {
+		if _, ok := cols[c.Name]; ok {
+			return nil, nil, errors.ES(r.op, errors.KClientArgs, "ToColumns(): more than one column is named %q, use RenameDuplicateColumns() to read them", c.Name).SetNoRetry()
+		}
+		cols[c.Name] = []interface{}{}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			r.Stop()
+			return nil, nil, err
+		}
+
+		row, inlineErr, err := r.NextRowOrError()
+		switch {
+		case err == io.EOF:
+			return cols, columns, nil
+		case err != nil:
+			return nil, nil, err
+		case inlineErr != nil:
+			return nil, nil, inlineErr
+		}
+
+		if len(row.Values) != len(columns) {
+			return nil, nil, errors.ES(r.op, errors.KInternal, "ToColumns(): a row has %d values for %d columns", len(row.Values), len(columns))
+		}
+		for i, v := range row.Values {
+			cv, err := columnValue(v)
+			if err != nil {
+				return nil, nil, err
+			}
+			name := columns[i].Name
+			cols[name] = append(cols[name], cv)
+		}
+	}
+}
+
+// columnValue converts a Kusto value to the Go type ToColumns() returns for it.
+func columnValue(v value.Kusto) (interface{}, error) {
+	switch t := v.(type) {
+	case value.Bool:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Int:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Long:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Real:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Decimal:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.String:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.DateTime:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Timespan:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.GUID:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	case value.Dynamic:
+		if !t.Valid {
+			return nil, nil
+		}
+		return t.Value, nil
+	}
+	return nil, errors.ES(errors.OpQuery, errors.KInternal, "value of type %T cannot be converted to a column value", v)
+}
@@ -0,0 +1,147 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/types"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToColumns(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	id := uuid.New()
+
+	columns := table.Columns{
+		{Name: "Bool", Type: types.Bool},
+		{Name: "Int", Type: types.Int},
+		{Name: "Long", Type: types.Long},
+		{Name: "Real", Type: types.Real},
+		{Name: "DateTime", Type: types.DateTime},
+		{Name: "Timespan", Type: types.Timespan},
+		{Name: "GUID", Type: types.GUID},
+		{Name: "String", Type: types.String},
+		{Name: "Decimal", Type: types.Decimal},
+		{Name: "Dynamic", Type: types.Dynamic},
+	}
+	full := value.Values{
+		value.Bool{Value: true, Valid: true},
+		value.Int{Value: 1, Valid: true},
+		value.Long{Value: 2, Valid: true},
+		value.Real{Value: 3.5, Valid: true},
+		value.DateTime{Value: now, Valid: true},
+		value.Timespan{Value: time.Minute, Valid: true},
+		value.GUID{Value: id, Valid: true},
+		value.String{Value: "s", Valid: true},
+		value.Decimal{Value: "1.5", Valid: true},
+		value.Dynamic{Value: []byte(`{"a":1}`), Valid: true},
+	}
+	null := value.Values{
+		value.Bool{}, value.Int{}, value.Long{}, value.Real{}, value.DateTime{}, value.Timespan{}, value.GUID{},
+		value.String{}, value.Decimal{}, value.Dynamic{},
+	}
+
+	tests := []struct {
+		desc    string
+		columns table.Columns
+		// duplicate is added to the columns once the mock is set, as MockRows does not allow duplicate names.
+		duplicate bool
+		rows      []value.Values
+		mockErr   error
+		cancel    bool
+		want      map[string][]interface{}
+		err       bool
+	}{
+		{
+			desc:    "No rows",
+			columns: columns,
+			want: map[string][]interface{}{
+				"Bool": {}, "Int": {}, "Long": {}, "Real": {}, "DateTime": {}, "Timespan": {}, "GUID": {}, "String": {},
+				"Decimal": {}, "Dynamic": {},
+			},
+		},
+		{
+			desc:    "Values and nulls",
+			columns: columns,
+			rows:    []value.Values{full, null},
+			want: map[string][]interface{}{
+				"Bool":     {true, nil},
+				"Int":      {int32(1), nil},
+				"Long":     {int64(2), nil},
+				"Real":     {3.5, nil},
+				"DateTime": {now, nil},
+				"Timespan": {time.Minute, nil},
+				"GUID":     {id, nil},
+				"String":   {"s", nil},
+				"Decimal":  {"1.5", nil},
+				"Dynamic":  {[]byte(`{"a":1}`), nil},
+			},
+		},
+		{
+			desc:      "Duplicate column names",
+			columns:   table.Columns{{Name: "A", Type: types.Long}},
+			duplicate: true,
+			err:       true,
+		},
+		{
+			desc:    "Iteration error",
+			columns: columns,
+			rows:    []value.Values{full},
+			mockErr: fmt.Errorf("iteration error"),
+			err:     true,
+		},
+		{
+			desc:    "Context done",
+			columns: columns,
+			rows:    []value.Values{full},
+			cancel:  true,
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewMockRows(test.columns)
+			require.NoError(t, err)
+			for _, r := range test.rows {
+				require.NoError(t, m.Row(r))
+			}
+			if test.mockErr != nil {
+				require.NoError(t, m.Error(test.mockErr))
+			}
+
+			iter := &RowIterator{}
+			require.NoError(t, iter.Mock(m))
+			defer iter.Stop()
+			if test.duplicate {
+				iter.mock.columns = append(iter.mock.columns, iter.mock.columns[0])
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+
+			got, gotColumns, err := iter.ToColumns(ctx)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.columns, gotColumns)
+		})
+	}
+}
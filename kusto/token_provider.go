@@ -0,0 +1,90 @@
+package kusto
+
+// token_provider.go holds authorizers for bearer tokens that are acquired outside of this package.
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// tokenRefreshMargin is how long before its expiry a token is refreshed.
+const tokenRefreshMargin = 5 * time.Minute
+
+// TokenProvider returns a bearer token for the Kusto endpoint and the time it expires. A zero expiresOn means the
+// token does not expire. It is called with the context of the request the token is needed for.
+type TokenProvider func(ctx context.Context) (token string, expiresOn time.Time, err error)
+
+// NewTokenAuthorizer returns an Authorizer, to set as Authorization.Authorizer, that authorizes all requests with
+// token. It is used when token acquisition is done outside of this package and the token outlives the client.
+// Use NewTokenProviderAuthorizer() for tokens that expire.
+func NewTokenAuthorizer(token string) autorest.Authorizer {
+	return NewTokenProviderAuthorizer(func(context.Context) (string, time.Time, error) {
+		return token, time.Time{}, nil
+	})
+}
+
+// NewTokenProviderAuthorizer returns an Authorizer, to set as Authorization.Authorizer, that authorizes requests with
+// the tokens of provider. The token is kept until 5 minutes before it expires, and provider is then called for a new
+// one. If provider fails while the previous token has not expired yet, the previous token is used and provider is
+// called again on the next request. provider is not called concurrently.
+func NewTokenProviderAuthorizer(provider TokenProvider) autorest.Authorizer {
+	return &tokenProviderAuthorizer{provider: provider, clock: clock.Real{}}
+}
+
+// tokenProviderAuthorizer implements autorest.Authorizer with a TokenProvider.
+type tokenProviderAuthorizer struct {
+	provider TokenProvider
+	clock    clock.Clock
+
+	mu        sync.Mutex
+	token     string
+	expiresOn time.Time
+}
+
+// WithAuthorization implements autorest.Authorizer.
+func (a *tokenProviderAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+			token, err := a.getToken(r.Context())
+			if err != nil {
+				return r, err
+			}
+			return autorest.Prepare(r, autorest.WithBearerAuthorization(token))
+		})
+	}
+}
+
+// getToken returns the current token, calling the provider if there is none or it is about to expire.
+func (a *tokenProviderAuthorizer) getToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+	if a.token != "" && (a.expiresOn.IsZero() || now.Before(a.expiresOn.Add(-tokenRefreshMargin))) {
+		return a.token, nil
+	}
+
+	token, expiresOn, err := a.provider(ctx)
+	if err == nil && token == "" {
+		err = errors.ES(errors.OpServConn, errors.KClientArgs, "the TokenProvider returned an empty token")
+	}
+	if err != nil {
+		if a.token != "" && now.Before(a.expiresOn) {
+			return a.token, nil
+		}
+		return "", err
+	}
+
+	a.token, a.expiresOn = token, expiresOn
+	return token, nil
+}
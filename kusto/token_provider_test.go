@@ -0,0 +1,117 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenProviderAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2022, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	type step struct {
+		at        time.Duration
+		token     string
+		expiresIn time.Duration
+		err       error
+		want      string
+		wantErr   bool
+		wantCall  bool
+	}
+
+	tests := []struct {
+		desc  string
+		steps []step
+	}{
+		{
+			desc: "Token is reused until it is about to expire",
+			steps: []step{
+				{at: 0, token: "a", expiresIn: time.Hour, want: "a", wantCall: true},
+				{at: 30 * time.Minute, want: "a"},
+				{at: 56 * time.Minute, token: "b", expiresIn: time.Hour, want: "b", wantCall: true},
+			},
+		},
+		{
+			desc: "Token without expiry",
+			steps: []step{
+				{at: 0, token: "a", want: "a", wantCall: true},
+				{at: 24 * time.Hour, want: "a"},
+			},
+		},
+		{
+			desc: "Refresh error keeps the token until it expires",
+			steps: []step{
+				{at: 0, token: "a", expiresIn: time.Hour, want: "a", wantCall: true},
+				{at: 56 * time.Minute, err: fmt.Errorf("broker error"), want: "a", wantCall: true},
+				{at: 61 * time.Minute, err: fmt.Errorf("broker error"), wantErr: true, wantCall: true},
+				{at: 62 * time.Minute, token: "b", expiresIn: time.Hour, want: "b", wantCall: true},
+			},
+		},
+		{
+			desc:  "Provider error",
+			steps: []step{{at: 0, err: fmt.Errorf("broker error"), wantErr: true, wantCall: true}},
+		},
+		{
+			desc:  "Empty token",
+			steps: []step{{at: 0, wantErr: true, wantCall: true}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var current step
+			fakeClock := clock.NewFake(start)
+			called := false
+			a := NewTokenProviderAuthorizer(func(context.Context) (string, time.Time, error) {
+				called = true
+				expiresOn := time.Time{}
+				if current.expiresIn > 0 {
+					expiresOn = fakeClock.Now().Add(current.expiresIn)
+				}
+				return current.token, expiresOn, current.err
+			}).(*tokenProviderAuthorizer)
+			a.clock = fakeClock
+
+			for i, s := range test.steps {
+				current, called = s, false
+				fakeClock.Advance(start.Add(s.at).Sub(fakeClock.Now()))
+
+				req, err := http.NewRequest(http.MethodPost, "https://cluster.kusto.windows.net/v2/rest/query", nil)
+				require.NoError(t, err)
+				got, err := autorest.Prepare(req, a.WithAuthorization())
+				assert.Equal(t, s.wantCall, called, "step %d", i)
+				if s.wantErr {
+					assert.Error(t, err, "step %d", i)
+					continue
+				}
+				require.NoError(t, err, "step %d", i)
+				assert.Equal(t, "Bearer "+s.want, got.Header.Get("Authorization"), "step %d", i)
+			}
+		})
+	}
+}
+
+func TestTokenAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	auth := Authorization{Authorizer: NewTokenAuthorizer("token")}
+	require.NoError(t, auth.Validate("https://cluster.kusto.windows.net"))
+
+	req, err := http.NewRequest(http.MethodPost, "https://cluster.kusto.windows.net/v2/rest/query", nil)
+	require.NoError(t, err)
+	got, err := autorest.Prepare(req, auth.Authorizer.WithAuthorization())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", got.Header.Get("Authorization"))
+}
@@ -0,0 +1,78 @@
+package kusto
+
+// token_retry.go holds the retry of token acquisition configured with WithTokenAcquisitionRetry().
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// tokenRetry holds how many times and how fast a conn tries to acquire a token. The zero value tries once.
+type tokenRetry struct {
+	attempts  int
+	baseDelay time.Duration
+	// clock is the client's clock, which New() sets. If nil, the system clock is used.
+	clock clock.Clock
+}
+
+// after returns a channel that receives once d has passed on the retry's clock.
+func (r tokenRetry) after(d time.Duration) <-chan time.Time {
+	if r.clock == nil {
+		return clock.Real{}.After(d)
+	}
+	return r.clock.After(d)
+}
+
+// WithTokenAcquisitionRetry makes the client retry acquiring a token from Azure Active Directory up to maxAttempts
+// times in total when it fails with a transient error, such as a network error, a throttling (429) or a server
+// (5xx) error. The first retry waits baseDelay and every following retry waits twice as long as the one before.
+// Permanent errors, such as invalid credentials, are returned at once. This covers the first token acquisition,
+// which makes a client started during an outage of Azure Active Directory more resilient, and every refresh.
+// By default, acquiring a token is not retried. Values less than 1 are ignored.
+func WithTokenAcquisitionRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		if maxAttempts > 0 && baseDelay > 0 {
+			c.tokenRetry = tokenRetry{attempts: maxAttempts, baseDelay: baseDelay}
+		}
+	}
+}
+
+// authorize adds the authorization header to req, acquiring or refreshing the token if needed. Transient failures to
+// acquire the token are retried as set with WithTokenAcquisitionRetry(), until ctx is done.
+func (c *conn) authorize(ctx context.Context, req *http.Request) (*http.Request, error) {
+	prep := c.auth.WithAuthorization()(autorest.CreatePreparer())
+
+	delay := c.tokenRetry.baseDelay
+	for attempt := 1; ; attempt++ {
+		authorized, err := prep.Prepare(req.WithContext(ctx))
+		if err == nil {
+			return authorized, nil
+		}
+		if attempt >= c.tokenRetry.attempts || !transientTokenError(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-c.tokenRetry.after(delay):
+		}
+		delay *= 2
+	}
+}
+
+// transientTokenError reports if err, returned when acquiring a token, may succeed if retried. Errors without an HTTP
+// response, like network errors, are transient, as are throttling and server errors. Other HTTP errors, like the 400
+// and 401 returned for invalid credentials, are permanent.
+func transientTokenError(err error) bool {
+	detailed, ok := err.(autorest.DetailedError)
+	if !ok || detailed.Response == nil {
+		return true
+	}
+	code := detailed.Response.StatusCode
+	return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= http.StatusInternalServerError
+}
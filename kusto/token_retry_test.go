@@ -0,0 +1,133 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/internal/clock"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingAuthorizer is an autorest.Authorizer that fails to acquire a token with errs, one per call, before it
+// succeeds.
+type failingAuthorizer struct {
+	errs  []error
+	calls int
+}
+
+func (f *failingAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			f.calls++
+			if len(f.errs) > 0 {
+				err := f.errs[0]
+				f.errs = f.errs[1:]
+				return r, err
+			}
+			return autorest.Prepare(r, autorest.WithHeader("Authorization", "Bearer token"))
+		})
+	}
+}
+
+func tokenError(statusCode int) error {
+	return autorest.NewErrorWithError(fmt.Errorf("token refresh failed"), "azure.BearerAuthorizer", "WithAuthorization",
+		&http.Response{StatusCode: statusCode}, "Failed to refresh the Token")
+}
+
+func TestAuthorizeTokenRetry(t *testing.T) {
+	t.Parallel()
+
+	networkErr := autorest.NewErrorWithError(fmt.Errorf("connection reset"), "azure.BearerAuthorizer", "WithAuthorization", nil, "Failed to refresh the Token")
+
+	tests := []struct {
+		desc      string
+		retry     []Option
+		errs      []error
+		wantCalls int
+		// wantWait is the time waited between the attempts.
+		wantWait time.Duration
+		err      bool
+	}{
+		{desc: "Success", wantCalls: 1},
+		{desc: "No retry by default", errs: []error{tokenError(http.StatusServiceUnavailable)}, wantCalls: 1, err: true},
+		{
+			desc:      "Transient errors are retried",
+			retry:     []Option{WithTokenAcquisitionRetry(3, time.Second)},
+			errs:      []error{networkErr, tokenError(http.StatusTooManyRequests)},
+			wantCalls: 3,
+			wantWait:  3 * time.Second,
+		},
+		{
+			desc:      "Attempts run out",
+			retry:     []Option{WithTokenAcquisitionRetry(2, time.Second)},
+			errs:      []error{tokenError(http.StatusInternalServerError), tokenError(http.StatusBadGateway), tokenError(http.StatusBadGateway)},
+			wantCalls: 2,
+			wantWait:  time.Second,
+			err:       true,
+		},
+		{
+			desc:      "Permanent errors are not retried",
+			retry:     []Option{WithTokenAcquisitionRetry(3, time.Second)},
+			errs:      []error{tokenError(http.StatusUnauthorized)},
+			wantCalls: 1,
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{}
+			for _, o := range test.retry {
+				o(client)
+			}
+			auth := &failingAuthorizer{errs: test.errs}
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			fakeClock := clock.NewFake(start)
+			c := &conn{auth: auth, tokenRetry: client.tokenRetry}
+			c.tokenRetry.clock = fakeClock
+
+			done := make(chan struct{})
+			defer close(done)
+			advanceWhenWaiting(fakeClock, time.Second, done)
+
+			req, err := http.NewRequest(http.MethodPost, "https://cluster.kusto.windows.net/v2/rest/query", nil)
+			require.NoError(t, err)
+
+			got, err := c.authorize(context.Background(), req)
+			assert.Equal(t, test.wantCalls, auth.calls)
+			assert.Equal(t, test.wantWait, fakeClock.Now().Sub(start))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Bearer token", got.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestAuthorizeTokenRetryContextDone(t *testing.T) {
+	t.Parallel()
+
+	auth := &failingAuthorizer{errs: []error{tokenError(http.StatusServiceUnavailable)}}
+	fakeClock := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := &conn{auth: auth, tokenRetry: tokenRetry{attempts: 3, baseDelay: time.Hour, clock: fakeClock}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, "https://cluster.kusto.windows.net/v2/rest/query", nil)
+	require.NoError(t, err)
+
+	_, err = c.authorize(ctx, req)
+	assert.Error(t, err)
+	assert.Equal(t, 1, auth.calls)
+}
@@ -0,0 +1,91 @@
+package kusto
+
+// truncation.go holds the options that make truncated Mgmt() results explicit.
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+	v1 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v1"
+)
+
+// truncatedStatusCode is in the QueryStatus table of a v1 response when the service truncated the result set.
+const truncatedStatusCode = "E_QUERY_RESULT_SET_TOO_LARGE"
+
+// ErrResultTruncated is returned by the RowIterator of a Mgmt() call with the MgmtTruncationError() option once all
+// the rows were read, if the service truncated the result set.
+var ErrResultTruncated = errors.ES(errors.OpMgmt, errors.KLimitsExceeded, "the service truncated the result set, use MgmtRetryWithoutTruncation() or reduce the size of the result").SetNoRetry()
+
+// MgmtTruncationError makes the RowIterator return ErrResultTruncated instead of io.EOF after the last row if the
+// service truncated the result set, so that partial results are not mistaken for complete ones.
+// Without this option, only RowIterator.Truncated() reports it.
+func MgmtTruncationError() MgmtOption {
+	return func(m *mgmtOptions) error {
+		m.truncationError = true
+		return nil
+	}
+}
+
+// MgmtRetryWithoutTruncation makes Mgmt() send the command again with the "notruncation" request property if the
+// service truncated the result set. The first result is discarded before any row is returned, as v1 results are
+// complete before they are returned. The result of the second call can still be truncated by other service limits,
+// which can be detected with MgmtTruncationError().
+func MgmtRetryWithoutTruncation() MgmtOption {
+	return func(m *mgmtOptions) error {
+		m.retryWithoutTruncation = true
+		return nil
+	}
+}
+
+// v1Truncated reports if a v1 response was truncated, which is when its QueryStatus table has a row about it or one
+// of its results has a KLimitsExceeded inline error.
+func v1Truncated(status *v1.DataTable, results []v1.DataTable) bool {
+	for _, result := range results {
+		for _, e := range result.RowErrors {
+			if e.Kind == errors.KLimitsExceeded {
+				return true
+			}
+		}
+	}
+	if status == nil {
+		return false
+	}
+
+	columns, err := status.DataTypes.ToColumns()
+	if err != nil {
+		return false
+	}
+	index := columns.Index()
+	for _, row := range status.KustoRows {
+		for _, name := range []string{"StatusDescription", "StatusCode"} {
+			i, ok := index[name]
+			if !ok || i >= len(row) {
+				continue
+			}
+			if s, ok := row[i].(value.String); ok && strings.Contains(s.Value, truncatedStatusCode) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkTruncation returns ErrResultTruncated if the MgmtTruncationError() option was provided and the result set was
+// truncated. It is called once all rows were read.
+func (r *RowIterator) checkTruncation() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.truncationError && r.truncated {
+		return ErrResultTruncated
+	}
+	return nil
+}
+
+// setTruncated records that the service truncated the result set.
+func (r *RowIterator) setTruncated() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.truncated = true
+}
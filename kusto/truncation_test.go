@@ -0,0 +1,114 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	v1 "github.com/Azure/azure-kusto-go/kusto/internal/frames/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// truncationResponse returns a v1 response holding rows, whose QueryStatus table reports a truncation if truncated.
+func truncationResponse(rows int, truncated bool) string {
+	var values []string
+	for i := 0; i < rows; i++ {
+		values = append(values, fmt.Sprintf("[%q]", fmt.Sprint(i)))
+	}
+	status := "Query completed successfully"
+	if truncated {
+		status = "Query result set has exceeded the internal record count limit 2 (E_QUERY_RESULT_SET_TOO_LARGE; see https://aka.ms/kustoquerylimits)"
+	}
+	return fmt.Sprintf(`{"Tables":[
+{"TableName":"Table_0","Columns":[{"ColumnName":"Name","DataType":"String"}],"Rows":[%s]},
+{"TableName":"Table_1","Columns":[{"ColumnName":"Value","DataType":"String"}],"Rows":[["{}"]]},
+{"TableName":"Table_2","Columns":[{"ColumnName":"Severity","DataType":"Int32"},{"ColumnName":"StatusDescription","DataType":"String"}],"Rows":[[4,%q]]},
+{"TableName":"Table_3","Columns":[{"ColumnName":"Ordinal","DataType":"Int64"},{"ColumnName":"Kind","DataType":"String"},{"ColumnName":"Name","DataType":"String"},{"ColumnName":"Id","DataType":"String"},{"ColumnName":"PrettyName","DataType":"String"}],"Rows":[
+[0,"QueryResult","PrimaryResult","1",""],
+[1,"QueryProperties","@ExtendedProperties","2",""],
+[2,"QueryStatus","QueryStatus","3",""]]}
+]}`, strings.Join(values, ","), status)
+}
+
+// truncationQueryer is a queryer whose Mgmt() results are truncated unless the "notruncation" property is set.
+type truncationQueryer struct {
+	fakeQueryer
+	calls *int
+}
+
+func (f truncationQueryer) mgmt(ctx context.Context, _ string, _ Stmt, opts *mgmtOptions) (execResp, error) {
+	*f.calls++
+	body := truncationResponse(2, true)
+	if opts.requestProperties.Options["notruncation"] == true {
+		body = truncationResponse(3, false)
+	}
+	dec := &v1.Decoder{}
+	return execResp{frameCh: dec.Decode(ctx, ioutil.NopCloser(strings.NewReader(body)), errors.OpMgmt)}, nil
+}
+
+func TestMgmtTruncation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc          string
+		options       []MgmtOption
+		wantRows      int
+		wantCalls     int
+		wantTruncated bool
+		wantErr       error
+	}{
+		{desc: "No option", wantRows: 2, wantCalls: 1, wantTruncated: true},
+		{desc: "MgmtTruncationError", options: []MgmtOption{MgmtTruncationError()}, wantRows: 2, wantCalls: 1, wantTruncated: true, wantErr: ErrResultTruncated},
+		{desc: "MgmtRetryWithoutTruncation", options: []MgmtOption{MgmtRetryWithoutTruncation()}, wantRows: 3, wantCalls: 2},
+		{
+			desc:      "Both options",
+			options:   []MgmtOption{MgmtRetryWithoutTruncation(), MgmtTruncationError()},
+			wantRows:  3,
+			wantCalls: 2,
+		},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			client := &Client{conn: truncationQueryer{calls: &calls}}
+
+			iter, err := client.Mgmt(context.Background(), "db", NewStmt(".show things"), test.options...)
+			require.NoError(t, err)
+			defer iter.Stop()
+
+			rows := 0
+			err = iter.Do(func(*table.Row) error {
+				rows++
+				return nil
+			})
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantRows, rows)
+			assert.Equal(t, test.wantCalls, calls)
+			assert.Equal(t, test.wantTruncated, iter.Truncated())
+		})
+	}
+}
+
+func TestMgmtNotTruncated(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{conn: fakeQueryer{onMgmt: func(string) (string, error) {
+		return truncationResponse(1, false), nil
+	}}}
+
+	iter, err := client.Mgmt(context.Background(), "db", NewStmt(".show things"), MgmtTruncationError())
+	require.NoError(t, err)
+	defer iter.Stop()
+
+	require.NoError(t, iter.Do(func(*table.Row) error { return nil }))
+	assert.False(t, iter.Truncated())
+}
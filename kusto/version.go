@@ -0,0 +1,114 @@
+package kusto
+
+// version.go holds ShowVersion(), which reads the version of the service.
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-kusto-go/kusto/data/errors"
+	"github.com/Azure/azure-kusto-go/kusto/data/table"
+	"github.com/Azure/azure-kusto-go/kusto/data/value"
+)
+
+// Version is the version of the service, as returned by the ".show version" command.
+// See: https://docs.microsoft.com/en-us/azure/data-explorer/kusto/management/diagnostics#show-version
+type Version struct {
+	// BuildVersion is the version of the build of the service, like "1.0.7783.27655".
+	BuildVersion string
+	// BuildTime is when the service was built. It is the zero time if the service did not return it.
+	BuildTime time.Time
+	// ServiceType is the type of the service, like "Engine" or "DataManagement".
+	ServiceType string
+	// ProductVersion is the version of the product, like "KustoMain_2021.04.26.3".
+	ProductVersion string
+}
+
+// AtLeast reports if the BuildVersion of v is buildVersion or later. Versions are compared by their dot separated
+// numbers, so "1.0.10000.0" is later than "1.0.7783.27655", and missing numbers count as 0. This is meant for enabling
+// features that depend on the version of the service. It returns an error if either version is not made of numbers.
+func (v Version) AtLeast(buildVersion string) (bool, error) {
+	have, err := parseBuildVersion(v.BuildVersion)
+	if err != nil {
+		return false, err
+	}
+	want, err := parseBuildVersion(buildVersion)
+	if err != nil {
+		return false, err
+	}
+
+	for len(have) < len(want) {
+		have = append(have, 0)
+	}
+	for len(want) < len(have) {
+		want = append(want, 0)
+	}
+	for i := range have {
+		if have[i] != want[i] {
+			return have[i] > want[i], nil
+		}
+	}
+	return true, nil
+}
+
+// parseBuildVersion returns the numbers of a dot separated build version.
+func parseBuildVersion(s string) ([]int64, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	nums := make([]int64, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || n < 0 {
+			return nil, errors.ES(errors.OpUnknown, errors.KClientArgs, "%q is not a build version of dot separated numbers", s).SetNoRetry()
+		}
+		nums = append(nums, n)
+	}
+	return nums, nil
+}
+
+// ShowVersion returns the version of the service, with the ".show version" command run against the NetDefaultDB
+// database. The columns the command returns are read by name, so columns added by later versions of the service are
+// ignored and the fields of columns it does not return are left empty.
+func (c *Client) ShowVersion(ctx context.Context) (Version, error) {
+	var (
+		version Version
+		found   bool
+	)
+	err := c.mgmtRows(ctx, clusterDB, NewStmt(".show version"), func(r *table.Row) error {
+		found = true
+		version.BuildVersion = rowString(r, "BuildVersion")
+		version.ServiceType = rowString(r, "ServiceType")
+		version.ProductVersion = rowString(r, "ProductVersion")
+		if v, ok := r.Value("BuildTime"); ok {
+			switch t := v.(type) {
+			case value.DateTime:
+				version.BuildTime = t.Value
+			case value.String:
+				if parsed, err := time.Parse(time.RFC3339Nano, t.Value); err == nil {
+					version.BuildTime = parsed
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Version{}, err
+	}
+	if !found {
+		return Version{}, errors.ES(errors.OpMgmt, errors.KInternal, "the version of the service was not returned by .show version")
+	}
+	return version, nil
+}
+
+// rowString returns the value of the string column name of r, or "" if r has no such column.
+func rowString(r *table.Row, name string) string {
+	v, ok := r.Value(name)
+	if !ok {
+		return ""
+	}
+	if s, ok := v.(value.String); ok {
+		return s.Value
+	}
+	return v.String()
+}
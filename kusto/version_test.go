@@ -0,0 +1,113 @@
+package kusto
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		response string
+		mgmtErr  error
+		want     Version
+		err      bool
+	}{
+		{
+			desc: "Success",
+			response: `{"Tables":[{"TableName":"Table_0","Columns":[
+{"ColumnName":"BuildVersion","ColumnType":"string"},
+{"ColumnName":"BuildTime","ColumnType":"datetime"},
+{"ColumnName":"ServiceType","ColumnType":"string"},
+{"ColumnName":"ProductVersion","ColumnType":"string"},
+{"ColumnName":"ServiceOffering","ColumnType":"string"}],
+"Rows":[["1.0.7783.27655","2021-04-26T15:21:50Z","Engine","KustoMain_2021.04.26.3","{\"Type\":\"Azure Data Explorer\"}"]]}]}`,
+			want: Version{
+				BuildVersion:   "1.0.7783.27655",
+				BuildTime:      time.Date(2021, 4, 26, 15, 21, 50, 0, time.UTC),
+				ServiceType:    "Engine",
+				ProductVersion: "KustoMain_2021.04.26.3",
+			},
+		},
+		{
+			desc: "Missing columns",
+			response: `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"BuildVersion","ColumnType":"string"}],
+"Rows":[["1.0.7783.27655"]]}]}`,
+			want: Version{BuildVersion: "1.0.7783.27655"},
+		},
+		{
+			desc:     "No rows",
+			response: `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"BuildVersion","ColumnType":"string"}],"Rows":[]}]}`,
+			err:      true,
+		},
+		{desc: "Mgmt error", mgmtErr: fmt.Errorf("mgmt error"), err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{conn: fakeQueryer{onMgmt: func(query string) (string, error) {
+				assert.Equal(t, ".show version", query)
+				if test.mgmtErr != nil {
+					return "", test.mgmtErr
+				}
+				return test.response, nil
+			}}}
+
+			got, err := client.ShowVersion(context.Background())
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want.BuildVersion, got.BuildVersion)
+			assert.True(t, test.want.BuildTime.Equal(got.BuildTime), "BuildTime: got %s, want %s", got.BuildTime, test.want.BuildTime)
+			assert.Equal(t, test.want.ServiceType, got.ServiceType)
+			assert.Equal(t, test.want.ProductVersion, got.ProductVersion)
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		version string
+		min     string
+		want    bool
+		err     bool
+	}{
+		{desc: "Equal", version: "1.0.7783.27655", min: "1.0.7783.27655", want: true},
+		{desc: "Later", version: "1.0.10000.0", min: "1.0.7783.27655", want: true},
+		{desc: "Earlier", version: "1.0.7783.27655", min: "1.0.7784", want: false},
+		{desc: "Missing numbers", version: "1.0", min: "1.0.0.0", want: true},
+		{desc: "Missing numbers earlier", version: "1.0", min: "1.0.0.1", want: false},
+		{desc: "Bad version", version: "KustoMain_2021", min: "1.0", err: true},
+		{desc: "Bad minimum", version: "1.0", min: "1.x", err: true},
+	}
+
+	for _, test := range tests {
+		test := test // capture
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Version{BuildVersion: test.version}.AtLeast(test.min)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}